@@ -1,16 +1,23 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/app"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
-	"log"
 )
 
 func main() {
-	cfg, err := config.New(".env.public", ".env.private")
+	loader := config.Loader{
+		Args:     os.Args[1:],
+		EnvFiles: []string{".env.public", ".env.private"},
+	}
+
+	cfg, err := loader.Load()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	app.Run(cfg)
+	app.Run(loader, cfg)
 }
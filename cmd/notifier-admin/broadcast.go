@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api"
+)
+
+func printBroadcasts(out *printer, value interface{}, broadcasts []api.Broadcast) error {
+	rows := make([][]string, 0, len(broadcasts))
+	for _, broadcast := range broadcasts {
+		target := make([]string, 0, 3)
+		if broadcast.Target.TenantID != "" {
+			target = append(target, "tenant="+broadcast.Target.TenantID)
+		}
+		if broadcast.Target.Region != "" {
+			target = append(target, "region="+broadcast.Target.Region)
+		}
+		if len(broadcast.Target.Tags) != 0 {
+			target = append(target, "tags="+strings.Join(broadcast.Target.Tags, ","))
+		}
+		if len(target) == 0 {
+			target = append(target, "all")
+		}
+
+		rows = append(rows, []string{
+			broadcast.ID,
+			broadcast.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			broadcast.Status,
+			fmt.Sprintf("%d/%d", broadcast.Sent+broadcast.Failed, broadcast.Total),
+			strconv.Itoa(broadcast.Failed),
+			strings.Join(target, " "),
+			broadcast.Error,
+		})
+	}
+
+	return out.print(value, []string{"ID", "CREATED", "STATUS", "PROGRESS", "FAILED", "TARGET", "ERROR"}, rows)
+}
+
+// printBroadcast shows the broadcast and, as a table, the chats it failed for.
+func printBroadcast(out *printer, broadcast api.Broadcast) error {
+	if err := printBroadcasts(out, broadcast, []api.Broadcast{broadcast}); err != nil {
+		return err
+	}
+
+	if out.json || len(broadcast.Failures) == 0 {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(broadcast.Failures))
+	for _, failure := range broadcast.Failures {
+		rows = append(rows, []string{
+			failure.At.Local().Format("2006-01-02 15:04:05"),
+			failure.ClientID,
+			strconv.FormatInt(failure.ChatID, 10),
+			failure.Error,
+		})
+	}
+
+	fmt.Fprintln(out.w)
+
+	return out.print(nil, []string{"FAILED AT", "CLIENT", "CHAT", "ERROR"}, rows)
+}
+
+func runBroadcast(ctx context.Context, e *env, args []string) error {
+	name, args, err := subcommand(args, "create", "get", "list", "cancel")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("broadcast "+name, flag.ContinueOnError)
+	id := fs.String("id", "", "broadcast ID (get, cancel)")
+	text := fs.String("text", "", "the announcement (create)")
+	tenantID := fs.String("tenant", "", "only the clients of the tenant bots (create)")
+	region := fs.String("region", "", "only the clients in the region (create)")
+	tags := fs.String("tags", "", "only the clients with one of the comma separated tags (create)")
+
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+
+	if (name == "get" || name == "cancel") && *id == "" {
+		return fmt.Errorf("broadcast %s: -id is required", name)
+	}
+
+	if name == "create" && *text == "" {
+		return fmt.Errorf("broadcast create: -text is required")
+	}
+
+	client, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Shutdown(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	switch name {
+	case "create":
+		target := api.BroadcastTarget{TenantID: *tenantID, Region: *region, Tags: splitList(*tags)}
+
+		broadcast, err := client.CreateBroadcast(ctx, *text, target)
+		if err != nil {
+			return err
+		}
+
+		return printBroadcast(e.out, broadcast)
+	case "get":
+		broadcast, err := client.GetBroadcast(ctx, *id)
+		if err != nil {
+			return err
+		}
+
+		return printBroadcast(e.out, broadcast)
+	case "cancel":
+		broadcast, err := client.CancelBroadcast(ctx, *id)
+		if err != nil {
+			return err
+		}
+
+		return printBroadcast(e.out, broadcast)
+	default:
+		broadcasts, err := client.ListBroadcasts(ctx)
+		if err != nil {
+			return err
+		}
+
+		return printBroadcasts(e.out, broadcasts, broadcasts)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api"
+)
+
+func (e *env) dial() (*api.TGNotificationServiceClient, error) {
+	client, err := api.NewTGNotificationServiceClient(e.addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", e.addr, err)
+	}
+
+	return client, nil
+}
+
+func printClients(out *printer, clients []api.Client) error {
+	rows := make([][]string, 0, len(clients))
+	for _, client := range clients {
+		bot := client.BotID
+		if bot == "" {
+			bot = "default"
+		}
+
+		rows = append(rows, []string{
+			client.ClientID, strconv.FormatInt(client.ChatID, 10), bot, client.Region, strings.Join(client.Tags, ","),
+		})
+	}
+
+	return out.print(clients, []string{"CLIENT", "CHAT", "BOT", "REGION", "TAGS"}, rows)
+}
+
+func runClient(ctx context.Context, e *env, args []string) error {
+	name, args, err := subcommand(args, "create", "get", "list", "delete")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("client "+name, flag.ContinueOnError)
+	clientID := fs.String("id", "", "client ID")
+	chatID := fs.Int64("chat", 0, "Telegram chat ID (create)")
+	botID := fs.String("bot", "", "registered bot ID, the default bot when empty (create)")
+	region := fs.String("region", "", "region the client is in (create)")
+	tags := fs.String("tags", "", "comma separated tags (create)")
+
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+
+	if name != "list" && *clientID == "" {
+		return fmt.Errorf("client %s: -id is required", name)
+	}
+
+	client, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Shutdown(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	switch name {
+	case "create":
+		if *chatID == 0 {
+			return fmt.Errorf("client create: -chat is required")
+		}
+
+		registration := api.Client{
+			ClientID: *clientID, ChatID: *chatID, BotID: *botID, Region: *region, Tags: splitList(*tags),
+		}
+
+		if err = client.CreateWithBot(ctx, registration); err != nil {
+			return err
+		}
+
+		return e.out.message("created " + *clientID)
+	case "get":
+		registered, err := client.Get(ctx, *clientID)
+		if err != nil {
+			return err
+		}
+
+		return printClients(e.out, []api.Client{registered})
+	case "delete":
+		if err = client.Delete(ctx, *clientID); err != nil {
+			return err
+		}
+
+		return e.out.message("deleted " + *clientID)
+	default:
+		clients := make([]api.Client, 0)
+		err = client.ExportClients(ctx, func(registered api.Client) error {
+			clients = append(clients, registered)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return printClients(e.out, clients)
+	}
+}
+
+func runDeliveries(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("deliveries", flag.ContinueOnError)
+	clientID := fs.String("client", "", "only the deliveries to the client")
+	requestID := fs.String("request", "", "only the deliveries of the alert")
+	since := fs.String("since", "", "RFC 3339 time or a duration ago, e.g. 24h")
+	until := fs.String("until", "", "RFC 3339 time or a duration ago")
+	limit := fs.Int("limit", 50, "maximum number of deliveries")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	filter := api.DeliveryFilter{ClientID: *clientID, RequestID: *requestID, Limit: *limit}
+
+	var err error
+	if filter.Since, err = parseTime(*since); err != nil {
+		return fmt.Errorf("-since: %w", err)
+	}
+	if filter.Until, err = parseTime(*until); err != nil {
+		return fmt.Errorf("-until: %w", err)
+	}
+
+	client, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Shutdown(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	deliveries, err := client.ListDeliveries(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		rows = append(rows, []string{
+			delivery.SentAt.Local().Format("2006-01-02 15:04:05"),
+			delivery.RequestID,
+			delivery.ClientID,
+			strconv.FormatInt(delivery.ChatID, 10),
+			strconv.FormatInt(delivery.MessageID, 10),
+			delivery.Status,
+		})
+	}
+
+	return e.out.print(deliveries, []string{"SENT", "REQUEST", "CLIENT", "CHAT", "MESSAGE", "STATUS"}, rows)
+}
+
+// runTest sends a test alert to the client and shows the Telegram message it was delivered as.
+func runTest(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	clientID := fs.String("client", "", "client to alert")
+	sensorID := fs.String("sensor", "", "sensor of the client to take the name and location from")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *clientID == "" {
+		return fmt.Errorf("test: -client is required")
+	}
+
+	client, err := e.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Shutdown(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	sent, err := client.SendTestNotification(ctx, *clientID, *sensorID)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(sent, []string{"REQUEST", "CHAT", "MESSAGE"}, [][]string{{
+		sent.RequestID, strconv.FormatInt(sent.ChatID, 10), strconv.FormatInt(sent.MessageID, 10),
+	}})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+)
+
+// runConfig validates a configuration file the way the service loads it: defaults, the file,
+// the environment and the env files given with -env. The required secrets may be missing unless
+// -require-secrets is set, a file meant for another environment gets them there.
+func runConfig(_ context.Context, e *env, args []string) error {
+	_, args, err := subcommand(args, "validate")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	envFiles := fs.String("env", "", "comma separated env files loaded before the environment, e.g. .env.public,.env.private")
+	requireSecrets := fs.Bool("require-secrets", false, "report the required secrets missing from the file and the environment")
+
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("config validate: exactly one file expected")
+	}
+
+	loader := config.Loader{Args: []string{"-config", fs.Arg(0)}}
+	if *envFiles != "" {
+		loader.EnvFiles = strings.Split(*envFiles, ",")
+	}
+
+	cfg, err := loader.Build()
+	if err == nil {
+		if !*requireSecrets {
+			*cfg = cfg.WithSecretPlaceholders()
+		}
+
+		err = cfg.Validate()
+	}
+
+	var invalid *config.ValidationError
+	if errors.As(err, &invalid) {
+		rows := make([][]string, 0, len(invalid.Problems))
+		for _, problem := range invalid.Problems {
+			rows = append(rows, []string{problem})
+		}
+
+		if err = e.out.print(invalid.Problems, []string{"PROBLEM"}, rows); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%s is invalid", fs.Arg(0))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return e.out.message(fs.Arg(0) + " is valid")
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/msbroker"
+)
+
+type kafkaOptions struct {
+	peers           *string
+	topic           *string
+	group           *string
+	deadLetterTopic *string
+}
+
+// addKafkaFlags registers the Kafka settings, they default to the service defaults and its environment.
+func addKafkaFlags(fs *flag.FlagSet) kafkaOptions {
+	defaults := config.Default().Kafka
+
+	return kafkaOptions{
+		peers:           fs.String("peers", envOr("KAFKA_PEERS", defaults.Peers), "comma separated Kafka brokers (KAFKA_PEERS)"),
+		topic:           fs.String("topic", envOr("KAFKA_TOPIC", defaults.Topic), "input topic of the notifier (KAFKA_TOPIC)"),
+		group:           fs.String("group", envOr("KAFKA_GROUP", defaults.Group), "consumer group of the notifier (KAFKA_GROUP)"),
+		deadLetterTopic: fs.String("dlq", envOr("KAFKA_DEAD_LETTER_TOPIC", defaults.DeadLetterTopic), "dead-letter topic (KAFKA_DEAD_LETTER_TOPIC)"),
+	}
+}
+
+func (k kafkaOptions) client() (sarama.Client, error) {
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V3_3_0_0
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Consumer.Offsets.AutoCommit.Enable = false
+
+	client, err := sarama.NewClient(strings.Split(*k.peers, ","), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect to kafka %s: %w", *k.peers, err)
+	}
+
+	return client, nil
+}
+
+type replayed struct {
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+	Topic     string `json:"topic"`
+	Error     string `json:"error"`
+}
+
+// runDeadLetter republishes the dead-letter messages to the topics they failed on. The progress is kept
+// as the offsets of a "<group>-dlq-replay" consumer group, so every message is replayed once.
+func runDeadLetter(ctx context.Context, e *env, args []string) error {
+	_, args, err := subcommand(args, "replay")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("dlq replay", flag.ContinueOnError)
+	kafka := addKafkaFlags(fs)
+	limit := fs.Int("limit", 0, "maximum number of messages to replay, 0 for all")
+	dryRun := fs.Bool("dry-run", false, "only list the messages")
+
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *kafka.deadLetterTopic == "" {
+		return fmt.Errorf("dlq replay: the dead-letter topic is not set (-dlq or KAFKA_DEAD_LETTER_TOPIC)")
+	}
+
+	client, err := kafka.client()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	replay := &deadLetterReplay{
+		client: client,
+		topic:  *kafka.deadLetterTopic,
+		target: strings.Split(*kafka.topic, ",")[0],
+		group:  *kafka.group + "-dlq-replay",
+		limit:  *limit,
+		dryRun: *dryRun,
+	}
+
+	messages, err := replay.run(ctx)
+	if err != nil && len(messages) == 0 {
+		return err
+	}
+
+	rows := make([][]string, 0, len(messages))
+	for _, m := range messages {
+		rows = append(rows, []string{strconv.Itoa(int(m.Partition)), strconv.FormatInt(m.Offset, 10), m.Topic, m.Error})
+	}
+
+	if printErr := e.out.print(messages, []string{"PARTITION", "OFFSET", "TOPIC", "ERROR"}, rows); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+type deadLetterReplay struct {
+	client sarama.Client
+	topic  string
+	target string
+	group  string
+	limit  int
+	dryRun bool
+}
+
+func (r *deadLetterReplay) run(ctx context.Context) ([]replayed, error) {
+	partitions, err := r.client.Partitions(r.topic)
+	if err != nil {
+		return nil, fmt.Errorf("partitions of %s: %w", r.topic, err)
+	}
+
+	offsets, err := sarama.NewOffsetManagerFromClient(r.group, r.client)
+	if err != nil {
+		return nil, err
+	}
+	defer offsets.Close()
+
+	consumer, err := sarama.NewConsumerFromClient(r.client)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	var producer sarama.SyncProducer
+	if !r.dryRun {
+		if producer, err = sarama.NewSyncProducerFromClient(r.client); err != nil {
+			return nil, err
+		}
+		defer producer.Close()
+	}
+
+	result := make([]replayed, 0)
+	for _, partition := range partitions {
+		done, err := r.partition(ctx, consumer, producer, offsets, partition, &result)
+		if err != nil || done {
+			offsets.Commit()
+			return result, err
+		}
+	}
+
+	offsets.Commit()
+
+	return result, nil
+}
+
+// partition replays the messages of the partition present when it started, it returns true
+// once the limit is reached.
+func (r *deadLetterReplay) partition(
+	ctx context.Context, consumer sarama.Consumer, producer sarama.SyncProducer, offsets sarama.OffsetManager,
+	partition int32, result *[]replayed,
+) (bool, error) {
+	end, err := r.client.GetOffset(r.topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return false, err
+	}
+
+	// Closed asynchronously, the partition is released by the Commit of the offset manager.
+	manager, err := offsets.ManagePartition(r.topic, partition)
+	if err != nil {
+		return false, err
+	}
+	defer manager.AsyncClose()
+
+	start, _ := manager.NextOffset()
+	if start < 0 {
+		if start, err = r.client.GetOffset(r.topic, partition, sarama.OffsetOldest); err != nil {
+			return false, err
+		}
+	}
+
+	if start >= end {
+		return false, nil
+	}
+
+	claim, err := consumer.ConsumePartition(r.topic, partition, start)
+	if err != nil {
+		return false, err
+	}
+	defer claim.Close()
+
+	for {
+		var message *sarama.ConsumerMessage
+
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-claim.Errors():
+			return false, err
+		case message = <-claim.Messages():
+		}
+
+		entry := replayed{Partition: partition, Offset: message.Offset, Topic: r.target}
+		headers := make([]sarama.RecordHeader, 0, len(message.Headers))
+		for _, header := range message.Headers {
+			switch string(header.Key) {
+			case msbroker.HeaderDeadLetterError:
+				entry.Error = string(header.Value)
+			case msbroker.HeaderDeadLetterTopic:
+				entry.Topic = string(header.Value)
+			case msbroker.HeaderDeadLetterPartition, msbroker.HeaderDeadLetterOffset:
+			default:
+				headers = append(headers, *header)
+			}
+		}
+
+		if !r.dryRun {
+			republished := &sarama.ProducerMessage{Topic: entry.Topic, Value: sarama.ByteEncoder(message.Value), Headers: headers}
+			if message.Key != nil {
+				republished.Key = sarama.ByteEncoder(message.Key)
+			}
+
+			if _, _, err = producer.SendMessage(republished); err != nil {
+				return false, fmt.Errorf("republish offset %d of partition %d: %w", message.Offset, partition, err)
+			}
+
+			manager.MarkOffset(message.Offset+1, "")
+		}
+
+		*result = append(*result, entry)
+
+		if r.limit > 0 && len(*result) >= r.limit {
+			return true, nil
+		}
+
+		if message.Offset+1 >= end {
+			return false, nil
+		}
+	}
+}
+
+type offsetReset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	From      int64  `json:"from"`
+	To        int64  `json:"to"`
+}
+
+// runOffsets moves the committed offsets of the notifier consumer group to the first message
+// at or after the time, so the messages since then are consumed again.
+func runOffsets(_ context.Context, e *env, args []string) error {
+	_, args, err := subcommand(args, "reset")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("offsets reset", flag.ContinueOnError)
+	kafka := addKafkaFlags(fs)
+	to := fs.String("to", "", "RFC 3339 time or a duration ago, e.g. 1h")
+	dryRun := fs.Bool("dry-run", false, "only show the new offsets")
+	force := fs.Bool("force", false, "reset even when the group has active members")
+
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+
+	at, err := parseTime(*to)
+	if err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	if at.IsZero() {
+		return fmt.Errorf("offsets reset: -to is required")
+	}
+
+	client, err := kafka.client()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if !*force && !*dryRun {
+		if err = ensureGroupInactive(kafka); err != nil {
+			return err
+		}
+	}
+
+	offsets, err := sarama.NewOffsetManagerFromClient(*kafka.group, client)
+	if err != nil {
+		return err
+	}
+	defer offsets.Close()
+
+	resets := make([]offsetReset, 0)
+	for _, topic := range strings.Split(*kafka.topic, ",") {
+		partitions, err := client.Partitions(topic)
+		if err != nil {
+			return fmt.Errorf("partitions of %s: %w", topic, err)
+		}
+
+		for _, partition := range partitions {
+			reset, err := resetPartition(client, offsets, topic, partition, at, *dryRun)
+			if err != nil {
+				return err
+			}
+
+			resets = append(resets, reset)
+		}
+	}
+
+	if !*dryRun {
+		offsets.Commit()
+	}
+
+	rows := make([][]string, 0, len(resets))
+	for _, reset := range resets {
+		rows = append(rows, []string{
+			reset.Topic, strconv.Itoa(int(reset.Partition)), strconv.FormatInt(reset.From, 10), strconv.FormatInt(reset.To, 10),
+		})
+	}
+
+	return e.out.print(resets, []string{"TOPIC", "PARTITION", "FROM", "TO"}, rows)
+}
+
+func resetPartition(
+	client sarama.Client, offsets sarama.OffsetManager, topic string, partition int32, at time.Time, dryRun bool,
+) (offsetReset, error) {
+	reset := offsetReset{Topic: topic, Partition: partition}
+
+	target, err := client.GetOffset(topic, partition, at.UnixMilli())
+	if err != nil {
+		return reset, fmt.Errorf("offset of %s/%d at %s: %w", topic, partition, at, err)
+	}
+
+	// No message at or after the time, the group starts from the end.
+	if target < 0 {
+		if target, err = client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+			return reset, err
+		}
+	}
+
+	manager, err := offsets.ManagePartition(topic, partition)
+	if err != nil {
+		return reset, err
+	}
+	defer manager.AsyncClose()
+
+	reset.From, _ = manager.NextOffset()
+	reset.To = target
+
+	if !dryRun {
+		manager.ResetOffset(target, "")
+	}
+
+	return reset, nil
+}
+
+// ensureGroupInactive refuses to move the offsets under running consumers, they would overwrite them.
+// The admin has a client of its own, closing it closes the client too.
+func ensureGroupInactive(kafka kafkaOptions) error {
+	client, err := kafka.client()
+	if err != nil {
+		return err
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return err
+	}
+	defer admin.Close()
+
+	group := *kafka.group
+
+	groups, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return fmt.Errorf("describe group %s: %w", group, err)
+	}
+
+	for _, description := range groups {
+		if len(description.Members) != 0 {
+			return fmt.Errorf("group %s has %d active members, stop the notifier or use -force",
+				group, len(description.Members))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Command notifier-admin operates a running notifier: it manages client registrations, sends test alerts
+// and broadcasts, shows the delivery history, replays the dead-letter topic, resets the consumer group offsets
+// and validates configuration files.
+//
+//	notifier-admin [-addr host:port] [-output table|json] <command> <subcommand> [flags]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+type env struct {
+	addr    string
+	timeout time.Duration
+	out     *printer
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"client":     {usage: "client create|get|list|delete", run: runClient},
+	"deliveries": {usage: "deliveries [-client id] [-request id] [-since time] [-until time] [-limit n]", run: runDeliveries},
+	"test":       {usage: "test -client id [-sensor id]", run: runTest},
+	"broadcast":  {usage: "broadcast create|get|list|cancel", run: runBroadcast},
+	"dlq":        {usage: "dlq replay [-limit n] [-dry-run]", run: runDeadLetter},
+	"offsets":    {usage: "offsets reset -to time [-dry-run] [-force]", run: runOffsets},
+	"config":     {usage: "config validate [-env files] file", run: runConfig},
+}
+
+func usage(fs *flag.FlagSet) func() {
+	return func() {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(fs.Output(), "Usage: notifier-admin [flags] <command>\n\nCommands:\n")
+		for _, name := range names {
+			fmt.Fprintf(fs.Output(), "  %s\n", commands[name].usage)
+		}
+
+		fmt.Fprintf(fs.Output(), "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+}
+
+func main() {
+	fs := flag.NewFlagSet("notifier-admin", flag.ExitOnError)
+
+	addr := fs.String("addr", envOr("NOTIFIER_ADDR", "localhost:7076"), "gRPC address of the notifier (NOTIFIER_ADDR)")
+	output := fs.String("output", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of a single command")
+	fs.Usage = usage(fs)
+
+	_ = fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	out, err := newPrinter(*output, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	e := &env{addr: *addr, timeout: *timeout, out: out}
+	if err = cmd.run(ctx, e, fs.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// subcommand splits the arguments into the subcommand name and its flags.
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("subcommand required: %s", strings.Join(names, ", "))
+	}
+
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("unknown subcommand %q, want one of: %s", args[0], strings.Join(names, ", "))
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// parseTime accepts RFC 3339 times and durations meaning that long ago, e.g. 2h.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a duration", value)
+	}
+
+	return t, nil
+}
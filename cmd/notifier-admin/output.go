@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// printer writes results either as an aligned table or as indented JSON.
+type printer struct {
+	json bool
+	w    io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{json: true, w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, want table or json", format)
+	}
+}
+
+// print writes value as JSON or the rows under the header as a table.
+func (p *printer) print(value interface{}, header []string, rows [][]string) error {
+	if p.json {
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+// message writes a one line result, as {"status": text} in JSON.
+func (p *printer) message(text string) error {
+	if p.json {
+		return p.print(map[string]string{"status": text}, nil, nil)
+	}
+
+	_, err := fmt.Fprintln(p.w, text)
+
+	return err
+}
@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -23,12 +24,19 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
+	grpcLib "google.golang.org/grpc"
 
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/grpc"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/http"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/msbroker"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/bot"
-	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/cipher"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/fallback"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/media"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/publisher"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/spectrogram"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
 )
 
@@ -52,25 +60,32 @@ func createTraceProvider(cfg config.OTELConfig) func(context.Context) error {
 		),
 	)
 	if err != nil {
-		log.Printf("Could not set resources: ", err)
+		log.Printf("Could not set resources: %v", err)
 	}
 
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}))
-	otel.SetTracerProvider(
-		sdktrace.NewTracerProvider(
-			sdktrace.WithSampler(sdktrace.AlwaysSample()),
-			sdktrace.WithBatcher(exporter),
-			sdktrace.WithResource(resources),
-		),
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resources),
 	)
 
-	return exporter.Shutdown
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}))
+	otel.SetTracerProvider(provider)
+
+	// The provider flushes the batched spans before shutting the exporter down.
+	return provider.Shutdown
 }
 
 func createDB(cfg config.DBConfig) (*mongo.Database, func(context.Context) error, error) {
 	clientOptions := options.Client()
 	clientOptions.Monitor = otelmongo.NewMonitor()
 	clientOptions.ApplyURI(fmt.Sprintf("mongodb://%s:%s/", cfg.Host, cfg.Port))
+	if cfg.User != "" {
+		clientOptions.SetAuth(options.Credential{
+			Username: cfg.User,
+			Password: cfg.Password,
+		})
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -84,52 +99,199 @@ func createDB(cfg config.DBConfig) (*mongo.Database, func(context.Context) error
 		return nil, nil, errors.Wrap(err, "client.Ping")
 	}
 
-	disconnect := func(ctx2 context.Context) error {
+	disconnect := func(ctx context.Context) error {
 		return client.Disconnect(ctx)
 	}
 
 	return client.Database(cfg.Name), disconnect, nil
 }
 
-func Run(cfg *config.Config) {
+// createFallback returns the notifier for the alerts while Telegram is unavailable, nil when there is none.
+func createFallback(cfg config.FallbackConfig) (ucase.FallbackNotifier, error) {
+	switch cfg.Kind {
+	case "":
+		return nil, nil
+	case config.FallbackWebhook:
+		return fallback.NewWebhook(cfg.WebhookURL), nil
+	case config.FallbackEmail:
+		return fallback.NewEmail(cfg), nil
+	default:
+		return nil, errors.Errorf("unknown fallback kind %q", cfg.Kind)
+	}
+}
+
+// createMedia returns the storage of the media referenced by the alerts, nil when there is none.
+func createMedia(cfg config.MediaConfig) (*media.Source, error) {
+	switch cfg.Kind {
+	case "":
+		return nil, nil
+	case config.MediaS3:
+		backend, err := media.NewS3(cfg, &stdHttp.Client{Timeout: cfg.RequestTimeout})
+		if err != nil {
+			return nil, errors.Wrap(err, "media.NewS3")
+		}
+
+		return media.NewSource(backend, cfg), nil
+	case config.MediaDir:
+		return media.NewSource(media.NewDir(cfg.Dir), cfg), nil
+	default:
+		return nil, errors.Errorf("unknown media kind %q", cfg.Kind)
+	}
+}
+
+// createOutbox starts relaying the outbox to the outbox topic, the outbox is nil when the topic isn't set.
+func createOutbox(
+	ctx context.Context, cfg config.KafkaConsumerConfig, repo storage, owner string,
+) (*ucase.Outbox, func() error, error) {
+	if cfg.OutboxTopic == "" {
+		return nil, func() error { return nil }, nil
+	}
+
+	kafkaPublisher, err := publisher.NewKafkaPublisher(cfg.Peers, cfg.OutboxTopic)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "publisher.NewKafkaPublisher")
+	}
+
+	relay := ucase.NewOutboxRelay(repo, kafkaPublisher, cfg.OutboxRetention, owner)
+
+	go relay.Run(ctx)
+
+	return ucase.NewOutbox(repo), kafkaPublisher.Close, nil
+}
+
+func createLogger(cfg config.LogConfig) (*zap.Logger, zap.AtomicLevel, error) {
+	level, err := zap.ParseAtomicLevel(cfg.Level)
+	if err != nil {
+		return nil, level, errors.Wrap(err, "zap.ParseAtomicLevel")
+	}
+
+	logCfg := zap.NewDevelopmentConfig()
+	logCfg.Level = level
+
+	logger, err := logCfg.Build()
+	if err != nil {
+		return nil, level, errors.Wrap(err, "logCfg.Build")
+	}
+
+	return logger, level, nil
+}
+
+func Run(loader config.Loader, cfg *config.Config) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
-	logger, err := zap.NewDevelopment()
+	logger, logLevel, err := createLogger(cfg.Log)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	shutdownTrace := createTraceProvider(cfg.OTEL)
 
-	db, dbDisconnect, err := createDB(cfg.DB)
+	// instanceID tells the replicas apart when they compete for the leases of the background jobs.
+	hostname, _ := os.Hostname()
+	instanceID := hostname + "-" + uuid.NewString()
+
+	repo, dbDisconnect, err := createRepository(cfg.DB)
 	if err != nil {
 		logger.Fatal("can't create db connection", zap.Error(err))
 	}
 
-	repo := repository.NewRepository(db)
-	notifierBot, err := bot.NewBot(cfg.Bot)
+	tokenCipher, err := cipher.NewTokenCipher(cfg.Bot.TokenKey)
+	if err != nil {
+		logger.Fatal("can't create token cipher", zap.Error(err))
+	}
+
+	botClient, err := bot.NewHTTPClient(cfg.Bot)
+	if err != nil {
+		logger.Fatal("can't create bot http client", zap.Error(err))
+	}
+
+	registry := bot.NewRegistry(cfg.Bot, botClient, repo, tokenCipher)
+
+	var renderer *spectrogram.Renderer
+	if cfg.Spectrogram.Enabled {
+		renderer = spectrogram.NewRenderer(cfg.Spectrogram)
+	}
+
+	mediaSource, err := createMedia(cfg.Media)
+	if err != nil {
+		logger.Fatal("can't create media storage", zap.Error(err))
+	}
+
+	notifierBot, err := bot.NewBot(cfg.Bot, cfg.Notify.Template, registry, renderer, mediaSource)
 	if err != nil {
 		logger.Fatal("can't create bot", zap.Error(err))
 	}
 
+	outbox, closeOutbox, err := createOutbox(ctx, cfg.Kafka, repo, instanceID)
+	if err != nil {
+		logger.Fatal("can't create outbox", zap.Error(err))
+	}
+
+	fallbackNotifier, err := createFallback(cfg.Fallback)
+	if err != nil {
+		logger.Fatal("can't create fallback notifier", zap.Error(err))
+	}
+
+	notifierBot.Breaker().OnChange(func(state entities.BreakerState) {
+		logger.Warn("telegram circuit breaker changed state", zap.String("state", string(state)))
+	})
+
+	notifyUCase := ucase.NewNotifyUCase(repo, notifierBot, outbox, fallbackNotifier, cfg.Bot.RetryMaxAge)
+	notifyUCase.SetMuteRules(cfg.Notify.MuteRules)
+
+	broadcastUCase := ucase.NewBroadcastUCase(repo, notifierBot)
+	digestUCase := ucase.NewDigestUCase(repo, notifierBot)
+
 	uCase := ucase.NewUCase(
-		ucase.NewClientUCase(repo),
-		ucase.NewNotifyUCase(repo, notifierBot),
+		ucase.NewClientUCase(repo, outbox, notifierBot),
+		notifyUCase,
+		ucase.NewBotUCase(repo, registry, tokenCipher),
+		ucase.NewSensorUCase(repo),
+		ucase.NewDeliveryUCase(repo),
+		broadcastUCase,
+		ucase.NewOrganizationUCase(repo),
+		ucase.NewZoneUCase(repo),
+		digestUCase,
+		ucase.NewStatsUCase(repo),
+		ucase.NewHealthUCase(notifierBot.Breaker(), repo, fallbackNotifier != nil),
 	)
 
+	watcher := config.NewWatcher(loader, cfg, logger)
+	watcher.Subscribe(func(cfg *config.Config) {
+		if err := logLevel.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+			logger.Error("can't apply log level", zap.Error(err))
+		}
+
+		if err := notifierBot.SetTemplate(cfg.Notify.Template); err != nil {
+			logger.Error("can't apply message template", zap.Error(err))
+		}
+
+		notifierBot.SetRateLimit(cfg.Bot.RateLimit, cfg.Bot.RateBurst)
+		notifyUCase.SetMuteRules(cfg.Notify.MuteRules)
+	})
+
+	go watcher.Run(ctx)
+	go broadcastUCase.Run(ctx)
+	go notifyUCase.RunRetries(ctx, instanceID)
+	go notifyUCase.RunMemberUpdates(ctx, instanceID, cfg.Bot.UpdatesInterval, cfg.Bot.PollUpdates)
+	go digestUCase.Run(ctx, instanceID)
+
 	broker, err := msbroker.NewKafkaConsumer(cfg.Kafka, uCase, logger)
 	if err != nil {
 		logger.Fatal("can't create kafka consumer", zap.Error(err))
 	}
 
+	brokerDone := make(chan struct{})
 	go func() {
+		defer close(brokerDone)
+
 		if err := broker.Run(ctx); err != nil {
 			logger.Fatal("error running consumer", zap.Error(err))
 		}
 	}()
 
-	handler := http.NewHTTPServer(logger, uCase)
+	handler := http.NewHTTPServer(logger, uCase, cfg.API)
 	server := stdHttp.Server{
 		Addr:    net.JoinHostPort("", cfg.GRPC.Port),
 		Handler: handler,
@@ -141,19 +303,72 @@ func Run(cfg *config.Config) {
 		}
 	}()
 
+	grpcServer := grpc.NewGRPCServer(logger, uCase, cfg.API)
+	listener, err := net.Listen("tcp", net.JoinHostPort("", cfg.GRPC.ServerPort))
+	if err != nil {
+		logger.Fatal("can't listen grpc port", zap.Error(err))
+	}
+
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Fatal("error running grpc server", zap.Error(err))
+		}
+	}()
+
 	<-ctx.Done()
 
-	ctx, shutdownFunc := context.WithTimeout(context.Background(), time.Second*10)
+	// The consumer stops fetching as soon as ctx is canceled, its claims get the drain timeout to finish
+	// the notifications in flight and commit their offsets.
+	logger.Info("shutdown: draining kafka consumer", zap.Duration("timeout", cfg.Kafka.DrainTimeout))
+	select {
+	case <-brokerDone:
+		logger.Info("shutdown: kafka consumer stopped")
+	case <-time.After(cfg.Kafka.DrainTimeout + cfg.Shutdown.Timeout):
+		logger.Error("shutdown: kafka consumer didn't stop in time")
+	}
+
+	ctx, shutdownFunc := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
 	defer shutdownFunc()
 
+	logger.Info("shutdown: closing bot")
+	if err = notifierBot.Close(ctx); err != nil {
+		logger.Error("error closing bot", zap.Error(err))
+	}
+
+	logger.Info("shutdown: stopping http and grpc servers")
 	if err = server.Shutdown(ctx); err != nil {
 		logger.Error("error shutting down http server", zap.Error(err))
 	}
+	stopGRPC(ctx, grpcServer)
 
+	logger.Info("shutdown: closing outbox publisher and database")
+	if err = closeOutbox(); err != nil {
+		logger.Error("error closing outbox publisher", zap.Error(err))
+	}
 	if err = dbDisconnect(ctx); err != nil {
 		logger.Error("error disconnecting db", zap.Error(err))
 	}
+
+	logger.Info("shutdown: flushing traces")
 	if err = shutdownTrace(ctx); err != nil {
-		logger.Error("error shutting down tracing")
+		logger.Error("error shutting down tracing", zap.Error(err))
+	}
+
+	logger.Info("shutdown: done")
+	_ = logger.Sync()
+}
+
+// stopGRPC waits for the running calls until the context is done, then cuts them off.
+func stopGRPC(ctx context.Context, server *grpcLib.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
 	}
 }
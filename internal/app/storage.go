@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/bot"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/memory"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/postgres"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+// storage is everything the application needs from a repository backend.
+type storage interface {
+	ucase.ClientRepo
+	ucase.NotifyRepo
+	ucase.BotRepo
+	ucase.SensorRepo
+	ucase.BroadcastRepo
+	ucase.OrganizationRepo
+	ucase.ZoneRepo
+	ucase.DigestRepo
+	ucase.StatsRepo
+	ucase.OutboxRepo
+	ucase.OutboxRelayRepo
+	bot.BotGetter
+}
+
+func createRepository(cfg config.DBConfig) (storage, func(context.Context) error, error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		db, err := createPostgres(cfg)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "createPostgres")
+		}
+
+		disconnect := func(context.Context) error {
+			return db.Close()
+		}
+
+		return postgres.NewRepository(db), disconnect, nil
+	case config.DriverMemory:
+		disconnect := func(context.Context) error {
+			return nil
+		}
+
+		return memory.NewRepository(), disconnect, nil
+	default:
+		db, disconnect, err := createDB(cfg)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "createDB")
+		}
+
+		repo := repository.NewRepository(db)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err = repo.EnsureIndexes(ctx); err != nil {
+			_ = disconnect(ctx)
+			return nil, nil, errors.Wrap(err, "repo.EnsureIndexes")
+		}
+
+		return repo, disconnect, nil
+	}
+}
+
+func createPostgres(cfg config.DBConfig) (*sql.DB, error) {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Path:     "/" + cfg.Name,
+		RawQuery: fmt.Sprintf("sslmode=%s", url.QueryEscape(cfg.SSLMode)),
+	}
+	if cfg.User != "" {
+		dsn.User = url.UserPassword(cfg.User, cfg.Password)
+	}
+
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "sql.Open")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "db.PingContext")
+	}
+
+	if err = postgres.Migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "postgres.Migrate")
+	}
+
+	return db, nil
+}
@@ -1,56 +1,391 @@
 package config
 
 import (
+	"crypto/subtle"
+	"os"
+	"time"
+
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
 )
 
 type KafkaConsumerConfig struct {
-	Peers string `env:"KAFKA_PEERS"`
-	Group string `env:"KAFKA_GROUP"`
-	Topic string `env:"KAFKA_TOPIC"`
+	Peers string `env:"KAFKA_PEERS" yaml:"peers"`
+	Group string `env:"KAFKA_GROUP" yaml:"group"`
+	Topic string `env:"KAFKA_TOPIC" yaml:"topic"`
+
+	DeadLetterTopic string `env:"KAFKA_DEAD_LETTER_TOPIC" yaml:"deadLetterTopic" split_words:"true"` // DeadLetterTopic - receives the messages that failed, empty disables it
+
+	// DrainTimeout - how long a rebalance or a shutdown waits for the in-flight notification of each partition
+	DrainTimeout time.Duration `env:"KAFKA_DRAIN_TIMEOUT" yaml:"drainTimeout" split_words:"true"`
+
+	// OutboxTopic - receives the client and delivery events, empty disables the outbox. With Mongo it needs
+	// a replica set, the events are written in transactions.
+	OutboxTopic     string        `env:"KAFKA_OUTBOX_TOPIC" yaml:"outboxTopic" split_words:"true"`
+	OutboxRetention time.Duration `env:"KAFKA_OUTBOX_RETENTION" yaml:"outboxRetention" split_words:"true"` // OutboxRetention - how long published events are kept
 }
 
 type BotConfig struct {
-	Token string `env:"BOT_TOKEN"`
+	Token     string  `env:"BOT_TOKEN" yaml:"token" secret:"true"`
+	RateLimit float64 `env:"BOT_RATE_LIMIT" yaml:"rateLimit" split_words:"true"` // RateLimit - messages per second
+	RateBurst int     `env:"BOT_RATE_BURST" yaml:"rateBurst" split_words:"true"`
+	TokenKey  string  `env:"BOT_TOKEN_KEY" yaml:"tokenKey" secret:"true" split_words:"true"` // TokenKey - base64 AES-256 key for tenant bot tokens
+	// APIEndpoint - Bot API method URL format with the token and the method placeholders
+	APIEndpoint string `env:"BOT_API_ENDPOINT" yaml:"apiEndpoint" split_words:"true"`
+	// Proxy - http, https or socks5 URL the Bot API calls go through, empty connects directly
+	Proxy          string        `env:"BOT_PROXY" yaml:"proxy" secret:"true"`
+	RequestTimeout time.Duration `env:"BOT_REQUEST_TIMEOUT" yaml:"requestTimeout" split_words:"true"` // RequestTimeout - limit of a whole Bot API call
+	DialTimeout    time.Duration `env:"BOT_DIAL_TIMEOUT" yaml:"dialTimeout" split_words:"true"`       // DialTimeout - limit of establishing the connection
+
+	BreakerFailures int           `env:"BOT_BREAKER_FAILURES" yaml:"breakerFailures" split_words:"true"` // BreakerFailures - consecutive failures opening the breaker
+	BreakerCooldown time.Duration `env:"BOT_BREAKER_COOLDOWN" yaml:"breakerCooldown" split_words:"true"` // BreakerCooldown - how long the breaker stays open before a trial call
+	RetryMaxAge     time.Duration `env:"BOT_RETRY_MAX_AGE" yaml:"retryMaxAge" split_words:"true"`        // RetryMaxAge - parked alerts older than this are dropped
+
+	// AdminChatID - told when a chat of the default bot stops accepting the alerts, zero for nobody
+	AdminChatID     int64         `env:"BOT_ADMIN_CHAT_ID" yaml:"adminChatID" split_words:"true"`
+	UpdatesInterval time.Duration `env:"BOT_UPDATES_INTERVAL" yaml:"updatesInterval" split_words:"true"` // UpdatesInterval - how often the bots are polled for membership changes
+	// PollUpdates - poll the default bot for membership changes, getUpdates consumes its updates and
+	// fails while it has a webhook
+	PollUpdates bool `env:"BOT_POLL_UPDATES" yaml:"pollUpdates" split_words:"true"`
+}
+
+const (
+	FallbackWebhook = "webhook"
+	FallbackEmail   = "email"
+)
+
+// FallbackConfig is the channel the alerts go to while Telegram is unreachable.
+type FallbackConfig struct {
+	Kind       string `env:"FALLBACK_KIND" yaml:"kind"` // Kind - webhook or email, empty disables the fallback
+	WebhookURL string `env:"FALLBACK_WEBHOOK_URL" yaml:"webhookURL" split_words:"true"`
+
+	SMTPHost     string `env:"FALLBACK_SMTP_HOST" yaml:"smtpHost" split_words:"true"`
+	SMTPPort     string `env:"FALLBACK_SMTP_PORT" yaml:"smtpPort" split_words:"true"`
+	SMTPUser     string `env:"FALLBACK_SMTP_USER" yaml:"smtpUser" split_words:"true"`
+	SMTPPassword string `env:"FALLBACK_SMTP_PASSWORD" yaml:"smtpPassword" split_words:"true" secret:"true"`
+	From         string `env:"FALLBACK_FROM" yaml:"from"`
+	To           string `env:"FALLBACK_TO" yaml:"to"` // To - comma separated addresses of the operators
 }
 
+// SpectrogramConfig is the image rendered from the audio of an alert, it's sent as a photo along with the audio.
+type SpectrogramConfig struct {
+	Enabled      bool    `env:"SPECTROGRAM_ENABLED" yaml:"enabled"`
+	Width        int     `env:"SPECTROGRAM_WIDTH" yaml:"width"`                                   // Width - pixels, one spectrum per column
+	Height       int     `env:"SPECTROGRAM_HEIGHT" yaml:"height"`                                 // Height - pixels, the top quarter is the waveform
+	MinFrequency float64 `env:"SPECTROGRAM_MIN_FREQUENCY" yaml:"minFrequency" split_words:"true"` // MinFrequency - Hz at the bottom of the image
+	// MaxFrequency - Hz at the top of the image, zero or above half the sample rate means half the sample rate
+	MaxFrequency float64 `env:"SPECTROGRAM_MAX_FREQUENCY" yaml:"maxFrequency" split_words:"true"`
+}
+
+const (
+	MediaS3  = "s3"
+	MediaDir = "dir"
+)
+
+// MediaConfig is the object storage the media referenced by the alerts is fetched from.
+type MediaConfig struct {
+	Kind string `env:"MEDIA_KIND" yaml:"kind"` // Kind - s3 or dir, empty ignores the media references
+	Dir  string `env:"MEDIA_DIR" yaml:"dir"`   // Dir - the root of the object keys for kind dir
+
+	// Endpoint - S3 API URL, e.g. https://s3.eu-central-1.amazonaws.com or http://minio:9000
+	Endpoint  string `env:"MEDIA_ENDPOINT" yaml:"endpoint"`
+	Region    string `env:"MEDIA_REGION" yaml:"region"`
+	Bucket    string `env:"MEDIA_BUCKET" yaml:"bucket"`
+	AccessKey string `env:"MEDIA_ACCESS_KEY" yaml:"accessKey" split_words:"true"`
+	SecretKey string `env:"MEDIA_SECRET_KEY" yaml:"secretKey" split_words:"true" secret:"true"`
+	PathStyle bool   `env:"MEDIA_PATH_STYLE" yaml:"pathStyle" split_words:"true"` // PathStyle - the bucket in the path instead of the host, MinIO needs it
+
+	// RequestTimeout - limit of a whole fetch, including the upload to Telegram it's streamed into
+	RequestTimeout time.Duration `env:"MEDIA_REQUEST_TIMEOUT" yaml:"requestTimeout" split_words:"true"`
+	MaxSize        int64         `env:"MEDIA_MAX_SIZE" yaml:"maxSize" split_words:"true"` // MaxSize - bytes, larger objects are not sent
+	// Presign - Telegram fetches the objects by presigned URLs instead of the bot uploading them, the endpoint
+	// must be reachable from the internet
+	Presign       bool          `env:"MEDIA_PRESIGN" yaml:"presign"`
+	PresignExpiry time.Duration `env:"MEDIA_PRESIGN_EXPIRY" yaml:"presignExpiry" split_words:"true"`
+}
+
+const (
+	DriverMongo    = "mongo"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
 type DBConfig struct {
-	Host     string `env:"DB_HOST"`
-	Port     string `env:"DB_PORT"`
-	User     string `env:"DB_USER"`
-	Password string `env:"DB_PASSWORD"`
-	Name     string `env:"DB_NAME"`
+	Driver   string `env:"DB_DRIVER" yaml:"driver"` // Driver - mongo, postgres or memory
+	Host     string `env:"DB_HOST" yaml:"host"`
+	Port     string `env:"DB_PORT" yaml:"port"`
+	User     string `env:"DB_USER" yaml:"user"`
+	Password string `env:"DB_PASSWORD" yaml:"password" secret:"true"`
+	Name     string `env:"DB_NAME" yaml:"name"`
+	SSLMode  string `env:"DB_SSL_MODE" yaml:"sslMode" split_words:"true"` // SSLMode - postgres only
 }
 
 type GRPCConfig struct {
-	Port string `env:"GRPC_PORT"`
+	Port       string `env:"GRPC_PORT" yaml:"port"`                                 // Port - of the HTTP API
+	ServerPort string `env:"GRPC_SERVER_PORT" yaml:"serverPort" split_words:"true"` // ServerPort - of the gRPC API
+}
+
+// APIKey grants access to the HTTP and gRPC APIs, a key without a tenant is an operator key.
+type APIKey struct {
+	Key      string `yaml:"key"`
+	TenantID string `yaml:"tenantID"` // TenantID - the only tenant the key sees, empty for all of them
+}
+
+type APIConfig struct {
+	Keys []APIKey `yaml:"keys" ignored:"true"` // Keys - empty leaves the APIs open
+}
+
+// Tenant returns the tenant of the key, ok is false for an unknown key.
+func (c APIConfig) Tenant(key string) (tenantID string, ok bool) {
+	for _, apiKey := range c.Keys {
+		if subtle.ConstantTimeCompare([]byte(apiKey.Key), []byte(key)) == 1 {
+			return apiKey.TenantID, true
+		}
+	}
+
+	return "", false
 }
 
 type OTELConfig struct {
-	Host string `env:"OTEL_HOST"`
-	Port string `env:"OTEL_PORT"`
+	Host string `env:"OTEL_HOST" yaml:"host"`
+	Port string `env:"OTEL_PORT" yaml:"port"`
+}
+
+type LogConfig struct {
+	Level string `env:"LOG_LEVEL" yaml:"level"`
+}
+
+type NotifyConfig struct {
+	Template  string              `env:"NOTIFY_TEMPLATE" yaml:"template"`
+	MuteRules []entities.MuteRule `yaml:"muteRules" ignored:"true"`
+}
+
+type ShutdownConfig struct {
+	Timeout time.Duration `env:"SHUTDOWN_TIMEOUT" yaml:"timeout"` // Timeout - for closing everything after the consumer drained
+}
+
+type ReloadConfig struct {
+	Interval time.Duration `env:"RELOAD_INTERVAL" yaml:"interval"`
 }
 
 type Config struct {
-	Bot   BotConfig
-	DB    DBConfig
-	Kafka KafkaConsumerConfig
-	OTEL  OTELConfig
-	GRPC  GRPCConfig
+	Bot         BotConfig           `yaml:"bot"`
+	DB          DBConfig            `yaml:"db"`
+	Kafka       KafkaConsumerConfig `yaml:"kafka"`
+	OTEL        OTELConfig          `yaml:"otel"`
+	GRPC        GRPCConfig          `yaml:"grpc"`
+	API         APIConfig           `yaml:"api"`
+	Log         LogConfig           `yaml:"log"`
+	Notify      NotifyConfig        `yaml:"notify"`
+	Reload      ReloadConfig        `yaml:"reload"`
+	Shutdown    ShutdownConfig      `yaml:"shutdown"`
+	Fallback    FallbackConfig      `yaml:"fallback"`
+	Spectrogram SpectrogramConfig   `yaml:"spectrogram"`
+	Media       MediaConfig         `yaml:"media"`
+}
+
+const DefaultTemplate = "Attention!\n" +
+	"The system has been triggered for message from {{.Timestamp}}" +
+	"{{with .SensorName}}\nSensor: {{.}}{{end}}" +
+	"{{with .Address}}\nAddress: {{.}}{{end}}" +
+	"{{with .Location}}\n{{.MapLink}}{{end}}"
+
+// Default returns the bottom configuration layer, everything else is applied on top of it.
+func Default() Config {
+	return Config{
+		Bot: BotConfig{
+			RateLimit:   25,
+			RateBurst:   5,
+			APIEndpoint: "https://api.telegram.org/bot%s/%s",
+
+			RequestTimeout: 30 * time.Second,
+			DialTimeout:    10 * time.Second,
+
+			BreakerFailures: 5,
+			BreakerCooldown: 30 * time.Second,
+			RetryMaxAge:     time.Hour,
+
+			UpdatesInterval: 10 * time.Second,
+		},
+		DB: DBConfig{
+			Driver:  DriverMongo,
+			Host:    "localhost",
+			Port:    "27017",
+			Name:    "Gunshot",
+			SSLMode: "disable",
+		},
+		Kafka: KafkaConsumerConfig{
+			Peers: "localhost:9092",
+			Group: "GunshotTelegramNotification",
+			Topic: "GunshotNotificationInput",
+
+			DrainTimeout:    20 * time.Second,
+			OutboxRetention: 24 * time.Hour,
+		},
+		OTEL: OTELConfig{
+			Host: "localhost",
+			Port: "4317",
+		},
+		GRPC: GRPCConfig{
+			Port:       "7076",
+			ServerPort: "7077",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Notify: NotifyConfig{
+			Template: DefaultTemplate,
+		},
+		Reload: ReloadConfig{
+			Interval: 10 * time.Second,
+		},
+		Shutdown: ShutdownConfig{
+			Timeout: 10 * time.Second,
+		},
+		Fallback: FallbackConfig{
+			SMTPPort: "587",
+		},
+		Spectrogram: SpectrogramConfig{
+			Enabled:      true,
+			Width:        800,
+			Height:       400,
+			MaxFrequency: 8000,
+		},
+		Media: MediaConfig{
+			Region:         "us-east-1",
+			RequestTimeout: 2 * time.Minute,
+			MaxSize:        50 << 20,
+			PresignExpiry:  15 * time.Minute,
+		},
+	}
+}
+
+// Loader builds the configuration from layers: defaults, YAML file, environment (including .env files
+// and *_FILE secrets) and command line flags. Later layers override earlier ones.
+type Loader struct {
+	Args     []string
+	EnvFiles []string
 }
 
-func New(envFiles ...string) (*Config, error) {
-	err := godotenv.Load(envFiles...)
+func New(args []string, envFiles ...string) (*Config, error) {
+	return Loader{Args: args, EnvFiles: envFiles}.Load()
+}
+
+func (l Loader) Load() (*Config, error) {
+	cfg, err := l.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Build applies the layers without validating the result.
+func (l Loader) Build() (*Config, error) {
+	flags, err := parseFlags(l.Args)
 	if err != nil {
-		return nil, errors.Wrap(err, "godotenv.Load")
+		return nil, errors.Wrap(err, "parseFlags")
+	}
+
+	if err = loadEnvFiles(l.EnvFiles); err != nil {
+		return nil, errors.Wrap(err, "loadEnvFiles")
+	}
+
+	cfg := Default()
+
+	if path := configPath(flags); path != "" {
+		if err = loadFile(path, &cfg); err != nil {
+			return nil, errors.Wrap(err, "loadFile")
+		}
 	}
 
-	var cfg Config
-	if err := envconfig.Process("", &cfg); err != nil {
+	if err = envconfig.Process("", &cfg); err != nil {
 		return nil, errors.Wrap(err, "envconfig.Process")
 	}
 
+	if err = loadSecrets(&cfg); err != nil {
+		return nil, errors.Wrap(err, "loadSecrets")
+	}
+
+	if err = flags.apply(&cfg); err != nil {
+		return nil, errors.Wrap(err, "flags.apply")
+	}
+
 	return &cfg, nil
 }
+
+// _secretPlaceholder stands in for the required secrets a configuration gets in another environment.
+const _secretPlaceholder = "placeholder"
+
+// WithSecretPlaceholders returns the configuration with the missing required secrets filled in, so that
+// a file meant for another environment, which provides them there, can be validated here.
+func (c Config) WithSecretPlaceholders() Config {
+	if c.Bot.Token == "" {
+		c.Bot.Token = _secretPlaceholder
+	}
+
+	if c.Media.Kind == MediaS3 && c.Media.SecretKey == "" {
+		c.Media.SecretKey = _secretPlaceholder
+	}
+
+	return c
+}
+
+// Path returns the YAML file the loader reads, empty if there is none.
+func (l Loader) Path() string {
+	flags, err := parseFlags(l.Args)
+	if err != nil {
+		return ""
+	}
+
+	return configPath(flags)
+}
+
+func configPath(flags *flagValues) string {
+	if flags.configPath != "" {
+		return flags.configPath
+	}
+
+	return os.Getenv("CONFIG_FILE")
+}
+
+// loadEnvFiles loads the files that exist, so a deployment configured purely by the environment
+// doesn't need any of them.
+func loadEnvFiles(files []string) error {
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return errors.Wrap(err, "os.Stat")
+		}
+
+		if err := godotenv.Load(file); err != nil {
+			return errors.Wrap(err, "godotenv.Load")
+		}
+	}
+
+	return nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "os.ReadFile")
+	}
+
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return errors.Wrapf(err, "yaml.Unmarshal %s", path)
+	}
+
+	return nil
+}
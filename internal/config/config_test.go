@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// unsetEnv removes the variable for the test, the loader must not see what the environment running
+// the tests happens to have.
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	return path
+}
+
+func TestLoaderLayers(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string            // file - YAML content, empty for no file
+		env    map[string]string // env - set in the environment
+		dotenv string            // dotenv - content of an env file
+		secret string            // secret - content of the file BOT_TOKEN_FILE names, empty for none
+		args   []string
+		want   func(Config) Config
+	}{
+		{
+			name: "defaults",
+			want: func(c Config) Config { return c },
+		},
+		{
+			name: "file over defaults",
+			file: "kafka:\n  peers: file:9092\nbot:\n  token: file-token\n",
+			want: func(c Config) Config {
+				c.Kafka.Peers = "file:9092"
+				c.Bot.Token = "file-token"
+				return c
+			},
+		},
+		{
+			name: "environment over file",
+			file: "kafka:\n  peers: file:9092\n  group: file-group\n",
+			env:  map[string]string{"KAFKA_PEERS": "env:9092"},
+			want: func(c Config) Config {
+				c.Kafka.Peers = "env:9092"
+				c.Kafka.Group = "file-group"
+				return c
+			},
+		},
+		{
+			name:   "environment over env file",
+			dotenv: "KAFKA_PEERS=dotenv:9092\nKAFKA_GROUP=dotenv-group\n",
+			env:    map[string]string{"KAFKA_PEERS": "env:9092"},
+			want: func(c Config) Config {
+				c.Kafka.Peers = "env:9092"
+				c.Kafka.Group = "dotenv-group"
+				return c
+			},
+		},
+		{
+			name:   "secret file over environment",
+			env:    map[string]string{"BOT_TOKEN": "env-token"},
+			secret: "file-token\n",
+			want: func(c Config) Config {
+				c.Bot.Token = "file-token"
+				return c
+			},
+		},
+		{
+			name: "flags over everything",
+			file: "kafka:\n  peers: file:9092\nbot:\n  rateLimit: 5\n",
+			env:  map[string]string{"KAFKA_PEERS": "env:9092", "BOT_RATE_LIMIT": "7"},
+			args: []string{"-kafka.peers", "flag:9092", "-bot.rateLimit", "9", "-bot.breakerCooldown", "1m"},
+			want: func(c Config) Config {
+				c.Kafka.Peers = "flag:9092"
+				c.Bot.RateLimit = 9
+				c.Bot.BreakerCooldown = time.Minute
+				return c
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CONFIG_FILE", "KAFKA_PEERS", "KAFKA_GROUP", "BOT_TOKEN", "BOT_TOKEN_FILE", "BOT_RATE_LIMIT"} {
+				unsetEnv(t, key)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			loader := Loader{Args: tt.args}
+			if tt.file != "" {
+				loader.Args = append([]string{"-config", writeFile(t, "config.yaml", tt.file)}, loader.Args...)
+			}
+			if tt.dotenv != "" {
+				loader.EnvFiles = []string{filepath.Join(t.TempDir(), "missing.env"), writeFile(t, ".env", tt.dotenv)}
+			}
+			if tt.secret != "" {
+				t.Setenv("BOT_TOKEN_FILE", writeFile(t, "token", tt.secret))
+			}
+
+			got, err := loader.Build()
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+
+			want := tt.want(Default())
+			if got.Kafka != want.Kafka || got.Bot != want.Bot {
+				t.Errorf("Build:\n got kafka %+v\nwant kafka %+v\n got bot %+v\nwant bot %+v", got.Kafka, want.Kafka, got.Bot, want.Bot)
+			}
+		})
+	}
+}
+
+func TestLoaderLoadValidates(t *testing.T) {
+	unsetEnv(t, "BOT_TOKEN")
+	unsetEnv(t, "BOT_TOKEN_FILE")
+	unsetEnv(t, "CONFIG_FILE")
+
+	if _, err := (Loader{}).Load(); err == nil {
+		t.Fatal("Load without a token: want an error")
+	}
+
+	cfg, err := Loader{Args: []string{"-bot.token", "token"}}.Load()
+	if err != nil || cfg.Bot.Token != "token" {
+		t.Fatalf("Load: want the token from the flag, got %+v, %v", cfg, err)
+	}
+}
+
+func TestLoaderRejectsBadInput(t *testing.T) {
+	unsetEnv(t, "CONFIG_FILE")
+
+	for name, loader := range map[string]Loader{
+		"unknown flag":   {Args: []string{"-no.such.flag", "1"}},
+		"malformed flag": {Args: []string{"-bot.rateLimit", "fast"}},
+		"missing file":   {Args: []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}},
+		"malformed file": {Args: []string{"-config", writeFile(t, "config.yaml", "bot: [")}},
+	} {
+		if _, err := loader.Build(); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestWithSecretPlaceholders(t *testing.T) {
+	cfg := Default()
+	cfg.Media.Kind = MediaS3
+	cfg.Media.Endpoint = "http://minio:9000"
+	cfg.Media.Bucket = "media"
+	cfg.Media.AccessKey = "access"
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate without the secrets: want an error")
+	}
+
+	if err := cfg.WithSecretPlaceholders().Validate(); err != nil {
+		t.Fatalf("Validate with the placeholders: %v", err)
+	}
+
+	cfg.Bot.Token = "token"
+	if got := cfg.WithSecretPlaceholders().Bot.Token; got != "token" {
+		t.Errorf("WithSecretPlaceholders replaced a set secret with %q", got)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// TestEnvironment sets every variable named by an env tag to a value other than the default and
+// checks that the env layer sets the field, envconfig derives the names from the field names on its own.
+func TestEnvironment(t *testing.T) {
+	unsetEnv(t, "CONFIG_FILE")
+
+	defaults := Default()
+	want := make(map[string]interface{})
+
+	walkFields(reflect.ValueOf(&defaults).Elem(), "", func(path string, field reflect.Value, meta reflect.StructField) {
+		name := meta.Tag.Get("env")
+		if name == "" {
+			return
+		}
+
+		// The secrets would be read from the files instead.
+		unsetEnv(t, name+"_FILE")
+
+		var value string
+		switch {
+		case field.Type() == _durationType:
+			d := time.Duration(field.Int()) + 3*time.Second
+			value, want[path] = d.String(), d
+		case field.Kind() == reflect.String:
+			value = "env-" + path
+			want[path] = value
+		case field.Kind() == reflect.Bool:
+			value, want[path] = strconv.FormatBool(!field.Bool()), !field.Bool()
+		case field.Kind() == reflect.Int, field.Kind() == reflect.Int64:
+			n := field.Int() + 7
+			value, want[path] = strconv.FormatInt(n, 10), n
+		case field.Kind() == reflect.Float64:
+			f := field.Float() + 1.5
+			value, want[path] = strconv.FormatFloat(f, 'f', -1, 64), f
+		default:
+			t.Fatalf("%s: no test value for %s", name, field.Type())
+		}
+
+		t.Setenv(name, value)
+	})
+
+	if len(want) == 0 {
+		t.Fatal("no env tags found")
+	}
+
+	cfg := Default()
+	if err := envconfig.Process("", &cfg); err != nil {
+		t.Fatalf("envconfig.Process: %v", err)
+	}
+
+	walkFields(reflect.ValueOf(&cfg).Elem(), "", func(path string, field reflect.Value, meta reflect.StructField) {
+		expected, ok := want[path]
+		if !ok {
+			return
+		}
+
+		var got interface{}
+		switch {
+		case field.Type() == _durationType:
+			got = time.Duration(field.Int())
+		case field.Kind() == reflect.Int, field.Kind() == reflect.Int64:
+			got = field.Int()
+		default:
+			got = field.Interface()
+		}
+
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("%s: want %v from %s, got %v", path, expected, meta.Tag.Get("env"), got)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var _durationType = reflect.TypeOf(time.Duration(0))
+
+type flagValues struct {
+	configPath string
+	values     map[string]string
+}
+
+type flagRecorder struct {
+	name   string
+	values map[string]string
+}
+
+func (f flagRecorder) String() string { return "" }
+
+func (f flagRecorder) Set(value string) error {
+	f.values[f.name] = value
+	return nil
+}
+
+// parseFlags registers a flag per configuration field, named after its YAML path (for example -kafka.peers),
+// and records only the flags that were actually passed so that they can be applied as the last layer.
+func parseFlags(args []string) (*flagValues, error) {
+	result := &flagValues{values: make(map[string]string)}
+
+	fs := flag.NewFlagSet("gunshot-telegram-notifier", flag.ContinueOnError)
+	fs.StringVar(&result.configPath, "config", "", "path to the YAML configuration file")
+
+	cfg := Default()
+	walkFields(reflect.ValueOf(&cfg).Elem(), "", func(path string, field reflect.Value, _ reflect.StructField) {
+		fs.Var(flagRecorder{name: path, values: result.values}, path, "overrides "+path)
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, errors.Wrap(err, "fs.Parse")
+	}
+
+	return result, nil
+}
+
+func (f *flagValues) apply(cfg *Config) error {
+	var applyErr error
+
+	walkFields(reflect.ValueOf(cfg).Elem(), "", func(path string, field reflect.Value, _ reflect.StructField) {
+		value, ok := f.values[path]
+		if !ok || applyErr != nil {
+			return
+		}
+
+		if err := setField(field, value); err != nil {
+			applyErr = errors.Wrapf(err, "flag -%s", path)
+		}
+	})
+
+	return applyErr
+}
+
+// walkFields calls fn for every scalar field of the struct, skipping fields that can't be set from a string.
+func walkFields(v reflect.Value, prefix string, fn func(path string, field reflect.Value, meta reflect.StructField)) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		meta := t.Field(i)
+		name := strings.Split(meta.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			walkFields(field, path, fn)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.String:
+			continue
+		default:
+			fn(path, field, meta)
+		}
+	}
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == _durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.Wrap(err, "time.ParseDuration")
+		}
+
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseBool")
+		}
+
+		field.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseInt")
+		}
+
+		field.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.Wrap(err, "strconv.ParseFloat")
+		}
+
+		field.SetFloat(n)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(strings.Split(value, ",")))
+	default:
+		return errors.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
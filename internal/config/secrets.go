@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// loadSecrets reads fields tagged with secret:"true" from the file named by <ENV>_FILE,
+// which is how Docker and Kubernetes secrets are usually mounted.
+func loadSecrets(cfg *Config) error {
+	var loadErr error
+
+	walkFields(reflect.ValueOf(cfg).Elem(), "", func(path string, field reflect.Value, meta reflect.StructField) {
+		if loadErr != nil || meta.Tag.Get("secret") != "true" || field.Kind() != reflect.String {
+			return
+		}
+
+		file := os.Getenv(meta.Tag.Get("env") + "_FILE")
+		if file == "" {
+			return
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			loadErr = errors.Wrapf(err, "reading secret %s", path)
+			return
+		}
+
+		field.SetString(strings.TrimSpace(string(data)))
+	})
+
+	return loadErr
+}
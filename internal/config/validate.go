@@ -0,0 +1,277 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ValidationError lists every problem found in the configuration, so that all of them can be fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (v *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(v.Problems, "\n  ")
+}
+
+func (v *ValidationError) add(field, format string, args ...interface{}) {
+	v.Problems = append(v.Problems, field+": "+fmt.Sprintf(format, args...))
+}
+
+func (c Config) Validate() error {
+	v := &ValidationError{}
+
+	if c.Bot.Token == "" {
+		v.add("bot.token", "must be set (BOT_TOKEN or BOT_TOKEN_FILE)")
+	}
+	if c.Bot.RateLimit <= 0 {
+		v.add("bot.rateLimit", "must be positive, got %v", c.Bot.RateLimit)
+	}
+	if c.Bot.RateBurst < 1 {
+		v.add("bot.rateBurst", "must be at least 1, got %d", c.Bot.RateBurst)
+	}
+	if c.Bot.TokenKey != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.Bot.TokenKey); err != nil || len(key) != 32 {
+			v.add("bot.tokenKey", "must be a base64 encoded 32 byte key")
+		}
+	}
+
+	if strings.Count(c.Bot.APIEndpoint, "%s") != 2 {
+		v.add("bot.apiEndpoint", "must contain two %%s placeholders for the token and the method, got %q", c.Bot.APIEndpoint)
+	}
+
+	if c.Bot.Proxy != "" {
+		if u, err := url.Parse(c.Bot.Proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+			v.add("bot.proxy", "must be an http, https or socks5 URL")
+		}
+	}
+	if c.Bot.RequestTimeout <= 0 {
+		v.add("bot.requestTimeout", "must be positive, got %s", c.Bot.RequestTimeout)
+	}
+	if c.Bot.DialTimeout <= 0 {
+		v.add("bot.dialTimeout", "must be positive, got %s", c.Bot.DialTimeout)
+	}
+	if c.Bot.BreakerFailures < 1 {
+		v.add("bot.breakerFailures", "must be at least 1, got %d", c.Bot.BreakerFailures)
+	}
+	if c.Bot.BreakerCooldown <= 0 {
+		v.add("bot.breakerCooldown", "must be positive, got %s", c.Bot.BreakerCooldown)
+	}
+	if c.Bot.RetryMaxAge <= 0 {
+		v.add("bot.retryMaxAge", "must be positive, got %s", c.Bot.RetryMaxAge)
+	}
+	if c.Bot.UpdatesInterval <= 0 {
+		v.add("bot.updatesInterval", "must be positive, got %s", c.Bot.UpdatesInterval)
+	}
+
+	switch c.DB.Driver {
+	case DriverMongo, DriverPostgres, DriverMemory:
+	default:
+		v.add("db.driver", "must be %q, %q or %q, got %q", DriverMongo, DriverPostgres, DriverMemory, c.DB.Driver)
+	}
+	if c.DB.Host == "" {
+		v.add("db.host", "must not be empty")
+	}
+	validatePort(v, "db.port", c.DB.Port)
+	if c.DB.Name == "" {
+		v.add("db.name", "must not be empty")
+	}
+	if c.DB.Password != "" && c.DB.User == "" {
+		v.add("db.user", "must be set when db.password is set")
+	}
+
+	if c.Kafka.Peers == "" {
+		v.add("kafka.peers", "must not be empty")
+	}
+	if c.Kafka.Group == "" {
+		v.add("kafka.group", "must not be empty")
+	}
+	if c.Kafka.Topic == "" {
+		v.add("kafka.topic", "must not be empty")
+	}
+	if c.Kafka.DeadLetterTopic != "" && containsString(strings.Split(c.Kafka.Topic, ","), c.Kafka.DeadLetterTopic) {
+		v.add("kafka.deadLetterTopic", "must differ from the consumed topics")
+	}
+	if c.Kafka.DrainTimeout <= 0 {
+		v.add("kafka.drainTimeout", "must be positive, got %s", c.Kafka.DrainTimeout)
+	}
+	if c.Kafka.OutboxTopic != "" {
+		if containsString(strings.Split(c.Kafka.Topic, ","), c.Kafka.OutboxTopic) || c.Kafka.OutboxTopic == c.Kafka.DeadLetterTopic {
+			v.add("kafka.outboxTopic", "must differ from the consumed and the dead-letter topics")
+		}
+		if c.Kafka.OutboxRetention <= 0 {
+			v.add("kafka.outboxRetention", "must be positive, got %s", c.Kafka.OutboxRetention)
+		}
+	}
+
+	if c.OTEL.Host == "" {
+		v.add("otel.host", "must not be empty")
+	}
+	validatePort(v, "otel.port", c.OTEL.Port)
+	validatePort(v, "grpc.port", c.GRPC.Port)
+	validatePort(v, "grpc.serverPort", c.GRPC.ServerPort)
+	if c.GRPC.Port == c.GRPC.ServerPort {
+		v.add("grpc.serverPort", "must differ from grpc.port %q", c.GRPC.Port)
+	}
+
+	c.validateFallback(v)
+	c.validateSpectrogram(v)
+	c.validateMedia(v)
+
+	keys := make(map[string]bool, len(c.API.Keys))
+	for i, key := range c.API.Keys {
+		switch {
+		case len(key.Key) < 16:
+			v.add(fmt.Sprintf("api.keys[%d].key", i), "must be at least 16 characters long")
+		case keys[key.Key]:
+			v.add(fmt.Sprintf("api.keys[%d].key", i), "must be unique")
+		}
+
+		keys[key.Key] = true
+	}
+
+	if c.Shutdown.Timeout <= 0 {
+		v.add("shutdown.timeout", "must be positive, got %s", c.Shutdown.Timeout)
+	}
+
+	c.validateSafe(v)
+
+	if c.Reload.Interval < 0 {
+		v.add("reload.interval", "must not be negative, got %s", c.Reload.Interval)
+	}
+
+	if len(v.Problems) != 0 {
+		return v
+	}
+
+	return nil
+}
+
+// validateSafe checks the settings that can be changed by a hot reload.
+func (c Config) validateSafe(v *ValidationError) {
+	if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+		v.add("log.level", "unknown level %q", c.Log.Level)
+	}
+
+	if _, err := template.New("notify").Parse(c.Notify.Template); err != nil {
+		v.add("notify.template", "%s", err)
+	}
+
+	for i, rule := range c.Notify.MuteRules {
+		if rule.ClientID == "" && rule.MessageType == "" {
+			v.add(fmt.Sprintf("notify.muteRules[%d]", i), "must set clientID or messageType")
+		}
+	}
+}
+
+func (c Config) validateFallback(v *ValidationError) {
+	switch c.Fallback.Kind {
+	case "":
+	case FallbackWebhook:
+		if u, err := url.Parse(c.Fallback.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("fallback.webhookURL", "must be an http or https URL, got %q", c.Fallback.WebhookURL)
+		}
+	case FallbackEmail:
+		if c.Fallback.SMTPHost == "" {
+			v.add("fallback.smtpHost", "must not be empty")
+		}
+		validatePort(v, "fallback.smtpPort", c.Fallback.SMTPPort)
+		if _, err := mail.ParseAddress(c.Fallback.From); err != nil {
+			v.add("fallback.from", "must be an email address, got %q", c.Fallback.From)
+		}
+		if _, err := mail.ParseAddressList(c.Fallback.To); err != nil {
+			v.add("fallback.to", "must be a comma separated list of email addresses, got %q", c.Fallback.To)
+		}
+		if c.Fallback.SMTPPassword != "" && c.Fallback.SMTPUser == "" {
+			v.add("fallback.smtpUser", "must be set when fallback.smtpPassword is set")
+		}
+	default:
+		v.add("fallback.kind", "must be empty, %q or %q, got %q", FallbackWebhook, FallbackEmail, c.Fallback.Kind)
+	}
+}
+
+func (c Config) validateSpectrogram(v *ValidationError) {
+	if !c.Spectrogram.Enabled {
+		return
+	}
+
+	// Telegram rejects photos over 10000 pixels in width and height together or longer than 20 to 1.
+	if c.Spectrogram.Width < 64 || c.Spectrogram.Width > 5000 {
+		v.add("spectrogram.width", "must be from 64 to 5000, got %d", c.Spectrogram.Width)
+	}
+	if c.Spectrogram.Height < 64 || c.Spectrogram.Height > 5000 {
+		v.add("spectrogram.height", "must be from 64 to 5000, got %d", c.Spectrogram.Height)
+	}
+	if c.Spectrogram.MinFrequency < 0 {
+		v.add("spectrogram.minFrequency", "must not be negative, got %v", c.Spectrogram.MinFrequency)
+	}
+	if c.Spectrogram.MaxFrequency != 0 && c.Spectrogram.MaxFrequency <= c.Spectrogram.MinFrequency {
+		v.add("spectrogram.maxFrequency", "must be zero or above spectrogram.minFrequency, got %v", c.Spectrogram.MaxFrequency)
+	}
+}
+
+func (c Config) validateMedia(v *ValidationError) {
+	switch c.Media.Kind {
+	case "":
+		return
+	case MediaS3:
+		if u, err := url.Parse(c.Media.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add("media.endpoint", "must be an http or https URL, got %q", c.Media.Endpoint)
+		}
+		if c.Media.Region == "" {
+			v.add("media.region", "must not be empty")
+		}
+		if c.Media.Bucket == "" {
+			v.add("media.bucket", "must not be empty")
+		}
+		if c.Media.AccessKey == "" || c.Media.SecretKey == "" {
+			v.add("media.accessKey", "must be set together with media.secretKey (MEDIA_SECRET_KEY or MEDIA_SECRET_KEY_FILE)")
+		}
+		// S3 doesn't accept presigned URLs valid for more than a week.
+		if c.Media.Presign && (c.Media.PresignExpiry < time.Second || c.Media.PresignExpiry > 7*24*time.Hour) {
+			v.add("media.presignExpiry", "must be from 1s to 168h, got %s", c.Media.PresignExpiry)
+		}
+	case MediaDir:
+		if c.Media.Dir == "" {
+			v.add("media.dir", "must not be empty")
+		}
+		if c.Media.Presign {
+			v.add("media.presign", "must be false for kind %q, the objects have no URL", MediaDir)
+		}
+	default:
+		v.add("media.kind", "must be empty, %q or %q, got %q", MediaS3, MediaDir, c.Media.Kind)
+	}
+
+	if c.Media.RequestTimeout <= 0 {
+		v.add("media.requestTimeout", "must be positive, got %s", c.Media.RequestTimeout)
+	}
+	// Telegram doesn't take uploads over 50 MB from bots.
+	if c.Media.MaxSize < 1 || c.Media.MaxSize > 50<<20 {
+		v.add("media.maxSize", "must be from 1 to %d bytes, got %d", 50<<20, c.Media.MaxSize)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validatePort(v *ValidationError, field, port string) {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		v.add(field, "must be a port number between 1 and 65535, got %q", port)
+	}
+}
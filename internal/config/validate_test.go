@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func validConfig() Config {
+	cfg := Default()
+	cfg.Bot.Token = "token"
+
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string // want - the fields with problems, in order
+	}{
+		{name: "defaults with a token", modify: func(*Config) {}},
+		{name: "no token", modify: func(c *Config) { c.Bot.Token = "" }, want: []string{"bot.token"}},
+		{
+			name: "every bot problem at once",
+			modify: func(c *Config) {
+				c.Bot.RateLimit = 0
+				c.Bot.RateBurst = 0
+				c.Bot.TokenKey = "short"
+				c.Bot.APIEndpoint = "https://api.telegram.org/bot%s"
+			},
+			want: []string{"bot.rateLimit", "bot.rateBurst", "bot.tokenKey", "bot.apiEndpoint"},
+		},
+		{
+			name: "ports",
+			modify: func(c *Config) {
+				c.DB.Port = "mongo"
+				c.GRPC.Port = "70000"
+			},
+			want: []string{"db.port", "grpc.port"},
+		},
+		{
+			name: "shared port",
+			modify: func(c *Config) {
+				c.GRPC.ServerPort = c.GRPC.Port
+			},
+			want: []string{"grpc.serverPort"},
+		},
+		{
+			name: "reloadable settings",
+			modify: func(c *Config) {
+				c.Log.Level = "loud"
+				c.Notify.Template = "{{.Timestamp"
+				c.Notify.MuteRules = []entities.MuteRule{{}}
+			},
+			want: []string{"log.level", "notify.template", "notify.muteRules[0]"},
+		},
+		{name: "proxy scheme", modify: func(c *Config) { c.Bot.Proxy = "ftp://proxy:21" }, want: []string{"bot.proxy"}},
+		{
+			name: "webhook fallback",
+			modify: func(c *Config) {
+				c.Fallback.Kind = FallbackWebhook
+				c.Fallback.WebhookURL = "not a url"
+			},
+			want: []string{"fallback.webhookURL"},
+		},
+		{
+			name: "email fallback",
+			modify: func(c *Config) {
+				c.Fallback.Kind = FallbackEmail
+				c.Fallback.SMTPHost = "smtp"
+				c.Fallback.From = "ops@example.com"
+				c.Fallback.To = "a@example.com, b@example.com"
+			},
+		},
+		{name: "unknown fallback", modify: func(c *Config) { c.Fallback.Kind = "pager" }, want: []string{"fallback.kind"}},
+		{
+			name: "spectrogram",
+			modify: func(c *Config) {
+				c.Spectrogram.Width = 10
+				c.Spectrogram.MinFrequency = 9000
+			},
+			want: []string{"spectrogram.width", "spectrogram.maxFrequency"},
+		},
+		{name: "disabled spectrogram", modify: func(c *Config) { c.Spectrogram = SpectrogramConfig{} }},
+		{
+			name: "s3 media",
+			modify: func(c *Config) {
+				c.Media.Kind = MediaS3
+				c.Media.Endpoint = "minio:9000"
+				c.Media.Presign = true
+				c.Media.PresignExpiry = 8 * 24 * time.Hour
+			},
+			want: []string{"media.endpoint", "media.bucket", "media.accessKey", "media.presignExpiry"},
+		},
+		{
+			name: "dir media",
+			modify: func(c *Config) {
+				c.Media.Kind = MediaDir
+				c.Media.Presign = true
+				c.Media.MaxSize = 100 << 20
+			},
+			want: []string{"media.dir", "media.presign", "media.maxSize"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Validate: want a *ValidationError, got %v", err)
+			}
+
+			if len(invalid.Problems) != len(tt.want) {
+				t.Fatalf("Validate: want problems with %v, got %q", tt.want, invalid.Problems)
+			}
+
+			for i, field := range tt.want {
+				if !strings.HasPrefix(invalid.Problems[i], field+": ") {
+					t.Errorf("problem %d: want one with %s, got %q", i, field, invalid.Problems[i])
+				}
+			}
+
+			if !strings.Contains(err.Error(), invalid.Problems[0]) {
+				t.Errorf("Error() %q doesn't list the problem %q", err.Error(), invalid.Problems[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Watcher reloads the configuration when the YAML file changes or SIGHUP is received and hands
+// the new settings to the subscribers. Only the safe settings (log level, template, rate limits and
+// mute rules) take effect, everything else requires a restart.
+type Watcher struct {
+	loader  Loader
+	logger  *zap.Logger
+	mu      sync.Mutex
+	current *Config
+	subs    []func(*Config)
+	modTime time.Time
+}
+
+func NewWatcher(loader Loader, current *Config, logger *zap.Logger) *Watcher {
+	w := &Watcher{
+		loader:  loader,
+		logger:  logger.Named("config-watcher"),
+		current: current,
+	}
+
+	w.modTime, _ = w.fileModTime()
+
+	return w
+}
+
+// Subscribe registers fn to be called with the configuration after every successful reload.
+func (w *Watcher) Subscribe(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subs = append(w.subs, fn)
+}
+
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if w.loader.Path() != "" && w.current.Reload.Interval > 0 {
+		ticker := time.NewTicker(w.current.Reload.Interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.logger.Info("SIGHUP received, reloading configuration")
+			w.Reload()
+		case <-tick:
+			modTime, err := w.fileModTime()
+			if err != nil {
+				w.logger.Error("can't stat configuration file", zap.Error(err))
+				continue
+			}
+
+			if modTime.Equal(w.modTime) {
+				continue
+			}
+
+			w.modTime = modTime
+			w.logger.Info("configuration file changed, reloading")
+			w.Reload()
+		}
+	}
+}
+
+// Reload loads the configuration again and applies its safe part. An invalid configuration is
+// rejected as a whole and the previous one stays in effect.
+func (w *Watcher) Reload() {
+	next, err := w.loader.Load()
+	if err != nil {
+		w.logger.Error("configuration reload rejected", zap.Error(err))
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if restartRequired(w.current, next) {
+		w.logger.Warn("configuration contains changes that require a restart, they are ignored")
+	}
+
+	applied := *w.current
+	applied.Log = next.Log
+	applied.Notify = next.Notify
+	applied.Bot.RateLimit = next.Bot.RateLimit
+	applied.Bot.RateBurst = next.Bot.RateBurst
+	w.current = &applied
+
+	for _, fn := range w.subs {
+		fn(&applied)
+	}
+
+	w.logger.Info("configuration reloaded")
+}
+
+func (w *Watcher) fileModTime() (time.Time, error) {
+	path := w.loader.Path()
+	if path == "" {
+		return time.Time{}, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
+
+func restartRequired(current, next *Config) bool {
+	masked := *next
+	masked.Log = current.Log
+	masked.Notify = current.Notify
+	masked.Bot.RateLimit = current.Bot.RateLimit
+	masked.Bot.RateBurst = current.Bot.RateBurst
+
+	return !reflect.DeepEqual(*current, masked)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestWatcherReload(t *testing.T) {
+	unsetEnv(t, "BOT_TOKEN")
+	unsetEnv(t, "BOT_RATE_LIMIT")
+	unsetEnv(t, "KAFKA_PEERS")
+	unsetEnv(t, "LOG_LEVEL")
+
+	path := writeFile(t, "config.yaml", "bot:\n  token: token\n  rateLimit: 10\n")
+	loader := Loader{Args: []string{"-config", path}}
+
+	current, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	w := NewWatcher(loader, current, zap.NewNop())
+
+	var applied []*Config
+	w.Subscribe(func(cfg *Config) { applied = append(applied, cfg) })
+
+	reload := func(content string) {
+		t.Helper()
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile: %v", err)
+		}
+
+		w.Reload()
+	}
+
+	// The safe settings are applied, the rest waits for a restart.
+	reload("bot:\n  token: token\n  rateLimit: 20\nlog:\n  level: debug\nkafka:\n  peers: other:9092\n")
+
+	if len(applied) != 1 {
+		t.Fatalf("Reload: want the subscriber called once, got %d", len(applied))
+	}
+
+	got := applied[0]
+	if got.Bot.RateLimit != 20 || got.Log.Level != "debug" {
+		t.Errorf("Reload: want the rate limit and the log level applied, got %v and %q", got.Bot.RateLimit, got.Log.Level)
+	}
+	if got.Kafka.Peers != current.Kafka.Peers {
+		t.Errorf("Reload: want kafka.peers kept at %q until a restart, got %q", current.Kafka.Peers, got.Kafka.Peers)
+	}
+
+	// An invalid configuration is rejected as a whole.
+	reload("bot:\n  token: token\n  rateLimit: -1\nlog:\n  level: info\n")
+
+	if len(applied) != 1 {
+		t.Fatalf("Reload of an invalid configuration: want no subscriber call, got %d", len(applied))
+	}
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+// _tenantMethods are the only calls a tenant key may make, the others span all tenants.
+var _tenantMethods = map[string]bool{
+	"/api.ClientService/CreateClientV1":         true,
+	"/api.ClientService/GetClientV1":            true,
+	"/api.ClientService/DeleteClientV1":         true,
+	"/api.ClientService/SendTestNotificationV1": true,
+	"/api.StatsService/GetStatsV1":              true,
+}
+
+// _healthPrefix is left open for the probes.
+const _healthPrefix = "/grpc.health.v1.Health/"
+
+type authenticator struct {
+	cfg config.APIConfig
+}
+
+// authorize checks the API key in the "authorization" metadata of the call and returns the context
+// scoped to the tenant of the key.
+func (a authenticator) authorize(ctx context.Context, method string) (context.Context, error) {
+	if len(a.cfg.Keys) == 0 || strings.HasPrefix(method, _healthPrefix) {
+		return ctx, nil
+	}
+
+	var key string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) != 0 {
+			key = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+
+	tenantID, ok := a.cfg.Tenant(key)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+
+	if tenantID == "" {
+		return ctx, nil
+	}
+
+	if !_tenantMethods[method] {
+		return nil, status.Error(codes.PermissionDenied, "the method needs an operator key")
+	}
+
+	return ucase.WithTenant(ctx, tenantID), nil
+}
+
+func (a authenticator) unary(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// stream authorizes the streaming calls, they are all operator only so the context needn't be replaced.
+func (a authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if _, err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
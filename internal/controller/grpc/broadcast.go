@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+type broadcastServer struct {
+	api.UnimplementedBroadcastServiceServer
+
+	domain *ucase.UCase
+	logger *zap.Logger
+}
+
+func (s *broadcastServer) CreateBroadcastV1(ctx context.Context, req *api.CreateBroadcastRequest) (*api.Broadcast, error) {
+	target := entities.BroadcastTarget{
+		TenantID: req.GetTarget().GetTenantId(),
+		Region:   req.GetTarget().GetRegion(),
+		Tags:     req.GetTarget().GetTags(),
+	}
+
+	broadcast, err := s.domain.BroadcastUCase.Create(ctx, req.GetText(), target)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return broadcastToProto(broadcast), nil
+}
+
+func (s *broadcastServer) GetBroadcastV1(ctx context.Context, req *api.GetBroadcastRequest) (*api.Broadcast, error) {
+	broadcast, err := s.domain.BroadcastUCase.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return broadcastToProto(broadcast), nil
+}
+
+func (s *broadcastServer) ListBroadcastsV1(ctx context.Context, _ *emptypb.Empty) (*api.ListBroadcastsResponse, error) {
+	broadcasts, err := s.domain.BroadcastUCase.List(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &api.ListBroadcastsResponse{Broadcasts: make([]*api.Broadcast, 0, len(broadcasts))}
+	for _, broadcast := range broadcasts {
+		res.Broadcasts = append(res.Broadcasts, broadcastToProto(broadcast))
+	}
+
+	return res, nil
+}
+
+func (s *broadcastServer) CancelBroadcastV1(ctx context.Context, req *api.CancelBroadcastRequest) (*api.Broadcast, error) {
+	broadcast, err := s.domain.BroadcastUCase.Cancel(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return broadcastToProto(broadcast), nil
+}
+
+func broadcastToProto(broadcast entities.Broadcast) *api.Broadcast {
+	res := &api.Broadcast{
+		Id:   broadcast.ID.Hex(),
+		Text: broadcast.Text,
+		Target: &api.BroadcastTarget{
+			TenantId: broadcast.Target.TenantID,
+			Region:   broadcast.Target.Region,
+			Tags:     broadcast.Target.Tags,
+		},
+		Status:    string(broadcast.Status),
+		Total:     int32(broadcast.Total),
+		Sent:      int32(broadcast.Sent),
+		Failed:    int32(broadcast.Failed),
+		Failures:  make([]*api.BroadcastFailure, 0, len(broadcast.Failures)),
+		Error:     broadcast.Error,
+		CreatedAt: timestamppb.New(broadcast.CreatedAt),
+		UpdatedAt: timestamppb.New(broadcast.UpdatedAt),
+	}
+
+	if !broadcast.FinishedAt.IsZero() {
+		res.FinishedAt = timestamppb.New(broadcast.FinishedAt)
+	}
+
+	for _, failure := range broadcast.Failures {
+		res.Failures = append(res.Failures, &api.BroadcastFailure{
+			ClientId: failure.ClientID,
+			ChatId:   failure.ChatID,
+			Error:    failure.Error,
+			At:       timestamppb.New(failure.At),
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+type clientServer struct {
+	api.UnimplementedClientServiceServer
+
+	domain *ucase.UCase
+	logger *zap.Logger
+}
+
+func (s *clientServer) CreateClientV1(ctx context.Context, req *api.CreateClientRequest) (*emptypb.Empty, error) {
+	clientID, err := primitive.ObjectIDFromHex(req.GetClientId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	account := entities.TGAccount{
+		ClientID: clientID,
+		ChatID:   req.GetChatId(),
+		BotID:    req.GetBotId(),
+		Region:   req.GetRegion(),
+		Tags:     req.GetTags(),
+		ThreadID: int(req.GetMessageThreadId()),
+
+		OrganizationID: req.GetOrganizationId(),
+		Rules:          req.GetRules(),
+	}
+
+	if err = s.domain.ClientUCase.Create(ctx, account, req.GetTopicName()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *clientServer) GetClientV1(ctx context.Context, req *api.GetClientRequest) (*api.Client, error) {
+	account, err := s.domain.ClientUCase.Get(ctx, req.GetClientId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return clientToProto(account), nil
+}
+
+func clientToProto(account entities.TGAccount) *api.Client {
+	return &api.Client{
+		ClientId:        account.ClientID.Hex(),
+		ChatId:          account.ChatID,
+		BotId:           account.BotID,
+		Region:          account.Region,
+		Tags:            account.Tags,
+		MessageThreadId: int32(account.ThreadID),
+		OrganizationId:  account.OrganizationID,
+		Rules:           account.Rules,
+	}
+}
+
+func (s *clientServer) DeleteClientV1(ctx context.Context, req *api.DeleteClientRequest) (*emptypb.Empty, error) {
+	if err := s.domain.ClientUCase.Delete(ctx, req.GetClientId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// SendTestNotificationV1 sends a labelled test alert, a Telegram failure is returned as Unavailable
+// with the Bot API error.
+func (s *clientServer) SendTestNotificationV1(
+	ctx context.Context, req *api.SendTestNotificationRequest,
+) (*api.SendTestNotificationResponse, error) {
+	delivery, err := s.domain.NotificationUCase.Test(ctx, req.GetClientId(), req.GetSensorId())
+	if err != nil {
+		var deliveryErr *ucase.DeliveryError
+		if errors.As(err, &deliveryErr) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+
+		return nil, toStatus(err)
+	}
+
+	return &api.SendTestNotificationResponse{
+		RequestId:         delivery.RequestID,
+		ChatId:            delivery.ChatID,
+		MessageId:         int64(delivery.MessageID),
+		LocationMessageId: int64(delivery.LocationMessageID),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+type deliveryServer struct {
+	api.UnimplementedDeliveryServiceServer
+
+	domain *ucase.UCase
+	logger *zap.Logger
+}
+
+func (s *deliveryServer) ListDeliveriesV1(ctx context.Context, req *api.ListDeliveriesRequest) (*api.ListDeliveriesResponse, error) {
+	filter := entities.DeliveryFilter{
+		RequestID: req.GetRequestId(),
+		ClientID:  req.GetClientId(),
+		Limit:     int(req.GetLimit()),
+	}
+	if req.GetSince() != nil {
+		filter.Since = req.GetSince().AsTime()
+	}
+	if req.GetUntil() != nil {
+		filter.Until = req.GetUntil().AsTime()
+	}
+
+	deliveries, err := s.domain.DeliveryUCase.List(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &api.ListDeliveriesResponse{Deliveries: make([]*api.Delivery, 0, len(deliveries))}
+	for _, delivery := range deliveries {
+		res.Deliveries = append(res.Deliveries, &api.Delivery{
+			RequestId:            delivery.RequestID,
+			ClientId:             delivery.ClientID,
+			ChatId:               delivery.ChatID,
+			BotId:                delivery.BotID,
+			MessageId:            int64(delivery.MessageID),
+			LocationMessageId:    int64(delivery.LocationMessageID),
+			Text:                 delivery.Text,
+			Status:               string(delivery.Status),
+			SentAt:               timestamppb.New(delivery.SentAt),
+			AudioMessageId:       int64(delivery.AudioMessageID),
+			SpectrogramMessageId: int64(delivery.SpectrogramMessageID),
+			PhotoMessageId:       int64(delivery.PhotoMessageID),
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+// _telegramService is the health service checking that Telegram is reachable, the empty name is the whole server.
+const _telegramService = "telegram"
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	domain *ucase.UCase
+	logger *zap.Logger
+}
+
+func (s *healthServer) Check(
+	ctx context.Context, req *grpc_health_v1.HealthCheckRequest,
+) (*grpc_health_v1.HealthCheckResponse, error) {
+	health := s.domain.HealthUCase.Check(ctx)
+
+	serving := grpc_health_v1.HealthCheckResponse_SERVING
+
+	switch req.GetService() {
+	case "":
+		if health.Error != "" {
+			serving = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+	case _telegramService:
+		if health.Telegram.State != entities.BreakerClosed {
+			serving = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+	default:
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+
+	return &grpc_health_v1.HealthCheckResponse{Status: serving}, nil
+}
@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+// importStream turns the client stream into an import source, a line is the position of the message
+// in the stream. The first message is read ahead for its dry_run flag.
+type importStream struct {
+	stream api.ClientService_ImportClientsV1Server
+	first  *api.ImportClientRequest
+	line   int
+}
+
+func (s *importStream) Next() (entities.ImportRow, error) {
+	req := s.first
+	s.first = nil
+
+	if req == nil {
+		var err error
+		if req, err = s.stream.Recv(); err != nil {
+			return entities.ImportRow{}, err
+		}
+	}
+
+	s.line++
+	row := entities.ImportRow{
+		Line: s.line,
+		Account: entities.TGAccount{
+			ChatID:   req.GetClient().GetChatId(),
+			BotID:    req.GetClient().GetBotId(),
+			Region:   req.GetClient().GetRegion(),
+			Tags:     req.GetClient().GetTags(),
+			ThreadID: int(req.GetClient().GetMessageThreadId()),
+
+			OrganizationID: req.GetClient().GetOrganizationId(),
+			Rules:          req.GetClient().GetRules(),
+		},
+	}
+
+	if clientID := req.GetClient().GetClientId(); clientID != "" {
+		row.Account.ClientID, row.Err = primitive.ObjectIDFromHex(clientID)
+	}
+
+	return row, nil
+}
+
+func importReportToProto(report entities.ImportReport) *api.ImportClientsResponse {
+	res := &api.ImportClientsResponse{
+		DryRun:    report.DryRun,
+		Created:   int32(report.Created),
+		Updated:   int32(report.Updated),
+		Unchanged: int32(report.Unchanged),
+		Invalid:   int32(report.Invalid),
+		Rows:      make([]*api.ImportRowResult, 0, len(report.Rows)),
+	}
+
+	for _, row := range report.Rows {
+		res.Rows = append(res.Rows, &api.ImportRowResult{
+			Line:     int32(row.Line),
+			ClientId: row.ClientID,
+			Status:   string(row.Status),
+			Error:    row.Error,
+		})
+	}
+
+	return res
+}
+
+// ImportClientsV1 imports the streamed clients, dry_run is taken from the first message.
+func (s *clientServer) ImportClientsV1(stream api.ClientService_ImportClientsV1Server) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return stream.SendAndClose(importReportToProto(entities.ImportReport{}))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	src := &importStream{stream: stream, first: first}
+
+	report, err := s.domain.ClientUCase.Import(stream.Context(), src, first.GetDryRun())
+	if err != nil {
+		s.logger.Error("import failed", zap.Int("rows", len(report.Rows)), zap.Error(err))
+		return toStatus(err)
+	}
+
+	return stream.SendAndClose(importReportToProto(report))
+}
+
+func (s *clientServer) ExportClientsV1(_ *emptypb.Empty, stream api.ClientService_ExportClientsV1Server) error {
+	err := s.domain.ClientUCase.Export(stream.Context(), func(account entities.TGAccount) error {
+		return stream.Send(clientToProto(account))
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+
+	return nil
+}
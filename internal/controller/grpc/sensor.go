@@ -0,0 +1,100 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+type sensorServer struct {
+	api.UnimplementedSensorServiceServer
+
+	domain *ucase.UCase
+	logger *zap.Logger
+}
+
+func sensorFromProto(req *api.Sensor) entities.Sensor {
+	sensor := entities.Sensor{
+		ID:        req.GetId(),
+		Name:      req.GetName(),
+		Address:   req.GetAddress(),
+		ClientIDs: req.GetClientIds(),
+		Enabled:   req.GetEnabled(),
+		Tags:      req.GetTags(),
+	}
+
+	if location := req.GetLocation(); location != nil {
+		sensor.Location = &entities.Location{Latitude: location.GetLatitude(), Longitude: location.GetLongitude()}
+	}
+
+	return sensor
+}
+
+func sensorToProto(sensor entities.Sensor) *api.Sensor {
+	res := &api.Sensor{
+		Id:        sensor.ID,
+		Name:      sensor.Name,
+		Address:   sensor.Address,
+		ClientIds: sensor.ClientIDs,
+		Enabled:   sensor.Enabled,
+		Tags:      sensor.Tags,
+	}
+
+	if sensor.Location != nil {
+		res.Location = &api.Location{Latitude: sensor.Location.Latitude, Longitude: sensor.Location.Longitude}
+	}
+
+	return res
+}
+
+func (s *sensorServer) CreateSensorV1(ctx context.Context, req *api.Sensor) (*emptypb.Empty, error) {
+	if err := s.domain.SensorUCase.Create(ctx, sensorFromProto(req)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *sensorServer) GetSensorV1(ctx context.Context, req *api.GetSensorRequest) (*api.Sensor, error) {
+	sensor, err := s.domain.SensorUCase.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return sensorToProto(sensor), nil
+}
+
+func (s *sensorServer) ListSensorsV1(ctx context.Context, req *api.ListSensorsRequest) (*api.ListSensorsResponse, error) {
+	sensors, err := s.domain.SensorUCase.List(ctx, req.GetClientId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &api.ListSensorsResponse{Sensors: make([]*api.Sensor, 0, len(sensors))}
+	for _, sensor := range sensors {
+		res.Sensors = append(res.Sensors, sensorToProto(sensor))
+	}
+
+	return res, nil
+}
+
+func (s *sensorServer) UpdateSensorV1(ctx context.Context, req *api.Sensor) (*emptypb.Empty, error) {
+	if err := s.domain.SensorUCase.Update(ctx, sensorFromProto(req)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *sensorServer) DeleteSensorV1(ctx context.Context, req *api.DeleteSensorRequest) (*emptypb.Empty, error) {
+	if err := s.domain.SensorUCase.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
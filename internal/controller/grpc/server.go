@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+func NewGRPCServer(logger *zap.Logger, domain *ucase.UCase, apiCfg config.APIConfig) *grpc.Server {
+	auth := authenticator{cfg: apiCfg}
+	server := grpc.NewServer(grpc.UnaryInterceptor(auth.unary), grpc.StreamInterceptor(auth.stream))
+
+	api.RegisterClientServiceServer(server, &clientServer{domain: domain, logger: logger})
+	api.RegisterSensorServiceServer(server, &sensorServer{domain: domain, logger: logger})
+	api.RegisterDeliveryServiceServer(server, &deliveryServer{domain: domain, logger: logger})
+	api.RegisterBroadcastServiceServer(server, &broadcastServer{domain: domain, logger: logger})
+	api.RegisterStatsServiceServer(server, &statsServer{domain: domain, logger: logger})
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{domain: domain, logger: logger})
+
+	return server
+}
+
+// toStatus maps the domain errors to gRPC codes the same way the HTTP handlers map them to status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrRecordExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, entities.ErrRecordNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ucase.ErrInvalidSensor), errors.Is(err, ucase.ErrUnreadableImport),
+		errors.Is(err, ucase.ErrInvalidBroadcast), errors.Is(err, ucase.ErrInvalidAccount),
+		errors.Is(err, ucase.ErrInvalidOrganization), errors.Is(err, ucase.ErrInvalidStats):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ucase.ErrBroadcastFinished), errors.Is(err, ucase.ErrInactiveAccount),
+		errors.Is(err, ucase.ErrTopicNotCreated), errors.Is(err, ucase.ErrOrganizationInUse):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+)
+
+type statsServer struct {
+	api.UnimplementedStatsServiceServer
+
+	domain *ucase.UCase
+	logger *zap.Logger
+}
+
+func (s *statsServer) GetStatsV1(ctx context.Context, req *api.GetStatsRequest) (*api.Stats, error) {
+	filter := entities.DeliveryStatsFilter{TopSensors: int(req.GetTopSensors())}
+	if req.GetSince() != nil {
+		filter.Since = req.GetSince().AsTime()
+	}
+	if req.GetUntil() != nil {
+		filter.Until = req.GetUntil().AsTime()
+	}
+
+	var (
+		stats entities.DeliveryStats
+		err   error
+	)
+
+	switch {
+	case req.GetClientId() != "" && req.GetOrganizationId() != "":
+		return nil, status.Error(codes.InvalidArgument, "set either the client or the organization")
+	case req.GetClientId() != "":
+		stats, err = s.domain.StatsUCase.Client(ctx, req.GetClientId(), filter)
+	case req.GetOrganizationId() != "":
+		stats, err = s.domain.StatsUCase.Organization(ctx, req.GetOrganizationId(), filter)
+	default:
+		if _, scoped := ucase.TenantFromContext(ctx); scoped {
+			return nil, status.Error(codes.PermissionDenied, "the statistics of all the clients need an operator key")
+		}
+
+		stats, err = s.domain.StatsUCase.Global(ctx, filter)
+	}
+
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &api.Stats{
+		Since:        timestamppb.New(stats.Since),
+		Until:        timestamppb.New(stats.Until),
+		Deliveries:   stats.Deliveries,
+		Alerts:       stats.Alerts,
+		Latency:      toPercentiles(stats.Latency),
+		Acknowledged: stats.Acknowledged,
+		AckTime:      toPercentiles(stats.AckTime),
+		TopSensors:   make([]*api.SensorCount, 0, len(stats.TopSensors)),
+	}
+
+	for key, count := range stats.ByStatus {
+		res.ByStatus = append(res.ByStatus, &api.StatsCount{Key: string(key), Count: count})
+	}
+	for key, count := range stats.ByType {
+		res.ByType = append(res.ByType, &api.StatsCount{Key: key, Count: count})
+	}
+
+	sortCounts(res.ByStatus)
+	sortCounts(res.ByType)
+
+	for _, sensor := range stats.TopSensors {
+		res.TopSensors = append(res.TopSensors, &api.SensorCount{SensorId: sensor.SensorID, Alerts: sensor.Alerts})
+	}
+
+	return res, nil
+}
+
+func toPercentiles(p entities.Percentiles) *api.Percentiles {
+	return &api.Percentiles{
+		P50Ms: p.P50.Milliseconds(),
+		P90Ms: p.P90.Milliseconds(),
+		P99Ms: p.P99.Milliseconds(),
+		MaxMs: p.Max.Milliseconds(),
+	}
+}
+
+// sortCounts orders the counts by key, the maps they come from have none.
+func sortCounts(counts []*api.StatsCount) {
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Key < counts[j].Key })
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+// authenticate checks the bearer API key of the request when keys are configured, a tenant key
+// scopes the request to its tenant.
+func authenticate(cfg config.APIConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(cfg.Keys) == 0 {
+			c.Next()
+			return
+		}
+
+		key := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		tenantID, ok := cfg.Tenant(key)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+			return
+		}
+
+		if tenantID != "" {
+			c.Request = c.Request.WithContext(ucase.WithTenant(c.Request.Context(), tenantID))
+		}
+
+		c.Next()
+	}
+}
+
+// operatorOnly rejects the tenant keys on the endpoints that span all tenants.
+func operatorOnly(c *gin.Context) {
+	if _, scoped := ucase.TenantFromContext(c.Request.Context()); scoped {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "the endpoint needs an operator key"})
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/cipher"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+type createBotRequest struct {
+	TenantID string `json:"tenantID" binding:"required"`
+	Token    string `json:"token" binding:"required"`
+	// AdminChatID - the chat told when a client chat stops accepting the alerts of the bot
+	AdminChatID int64 `json:"adminChatID"`
+	// PollUpdates - follow the membership of the bot with getUpdates, leave off for a bot with a webhook
+	PollUpdates bool `json:"pollUpdates"`
+}
+
+func (h *Handler) CreateBot(c *gin.Context) {
+	var req createBotRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	bot, err := h.domain.BotUCase.Create(c.Request.Context(), req.TenantID, req.Token, req.AdminChatID, req.PollUpdates)
+	if err != nil {
+		if errors.Is(err, cipher.ErrNoKey) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "bot token encryption is not configured"})
+			return
+		}
+
+		if errors.Is(err, entities.ErrRecordExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "bot already exists"})
+			return
+		}
+
+		if errors.Is(err, entities.ErrUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "telegram is unavailable, try again later"})
+			return
+		}
+
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, bot)
+}
+
+func (h *Handler) ListBots(c *gin.Context) {
+	bots, err := h.domain.BotUCase.List(c.Request.Context(), c.Query("tenantID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, bots)
+}
+
+func (h *Handler) DeleteBot(c *gin.Context) {
+	err := h.domain.BotUCase.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the bot not found"})
+			return
+		}
+
+		if errors.Is(err, ucase.ErrBotInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
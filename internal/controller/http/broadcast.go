@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+type createBroadcastRequest struct {
+	Text   string                   `json:"text" binding:"required"`
+	Target entities.BroadcastTarget `json:"target"`
+}
+
+// CreateBroadcast queues the broadcast and returns at once, its progress is polled with GetBroadcast.
+func (h *Handler) CreateBroadcast(c *gin.Context) {
+	var req createBroadcastRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	broadcast, err := h.domain.BroadcastUCase.Create(c.Request.Context(), req.Text, req.Target)
+	if err != nil {
+		if errors.Is(err, ucase.ErrInvalidBroadcast) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, broadcast)
+}
+
+func (h *Handler) ListBroadcasts(c *gin.Context) {
+	broadcasts, err := h.domain.BroadcastUCase.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, broadcasts)
+}
+
+func (h *Handler) GetBroadcast(c *gin.Context) {
+	broadcast, err := h.domain.BroadcastUCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the broadcast not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, broadcast)
+}
+
+func (h *Handler) CancelBroadcast(c *gin.Context) {
+	broadcast, err := h.domain.BroadcastUCase.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the broadcast not found"})
+			return
+		}
+
+		if errors.Is(err, ucase.ErrBroadcastFinished) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, broadcast)
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// ListDeliveries returns the delivery history, filtered by the requestID, clientID, since, until (RFC 3339)
+// and limit query parameters.
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	filter := entities.DeliveryFilter{
+		RequestID: c.Query("requestID"),
+		ClientID:  c.Query("clientID"),
+	}
+
+	var err error
+	if value := c.Query("since"); value != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return
+		}
+	}
+	if value := c.Query("until"); value != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be an RFC 3339 time"})
+			return
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+	}
+
+	deliveries, err := h.domain.DeliveryUCase.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+func digestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrInvalidDigest):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, entities.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type scheduleDigestRequest struct {
+	Cron     string                `json:"cron" binding:"required"`
+	Timezone string                `json:"timezone"`
+	Period   entities.DigestPeriod `json:"period"`
+}
+
+// ScheduleDigest makes the client get a digest of its detections on the cron schedule.
+func (h *Handler) ScheduleDigest(c *gin.Context) {
+	var req scheduleDigestRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.domain.DigestUCase.Schedule(c.Request.Context(), entities.DigestSchedule{
+		ClientID: c.Param("id"),
+		Cron:     req.Cron,
+		Timezone: req.Timezone,
+		Period:   req.Period,
+	})
+	if err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *Handler) ListDigestSchedules(c *gin.Context) {
+	schedules, err := h.domain.DigestUCase.ListSchedules(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (h *Handler) UnscheduleDigest(c *gin.Context) {
+	scheduleID := c.Param("scheduleID")
+	if _, err := primitive.ObjectIDFromHex(scheduleID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the digest schedule not found"})
+		return
+	}
+
+	if err := h.domain.DigestUCase.Unschedule(c.Request.Context(), c.Param("id"), scheduleID); err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ComposeDigest returns the digest of the client for the period (day or week) ending now, without sending it.
+// The hours are in the timezone query parameter, UTC when it's missing.
+func (h *Handler) ComposeDigest(c *gin.Context) {
+	period := entities.DigestPeriod(c.DefaultQuery("period", string(entities.DigestDaily)))
+
+	digest, err := h.domain.DigestUCase.Compose(c.Request.Context(), c.Param("id"), period, c.Query("timezone"))
+	if err != nil {
+		c.JSON(digestErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// acknowledgeRequest limits the acknowledgment to the chats of a client.
+type acknowledgeRequest struct {
+	ClientID string `json:"clientID"`
+}
+
+// AcknowledgeDelivery records that an operator has seen the alert of the request.
+func (h *Handler) AcknowledgeDelivery(c *gin.Context) {
+	var req acknowledgeRequest
+
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	deliveries, err := h.domain.DeliveryUCase.Acknowledge(c.Request.Context(), c.Param("requestID"), req.ClientID)
+	if err != nil {
+		if errors.Is(err, ucase.ErrNoDelivery) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the alert was not delivered"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
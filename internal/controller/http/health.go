@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health reports the Telegram circuit breaker and the parked notifications. A degraded service still answers
+// 200 since the API works without Telegram, only a failing storage makes it 503.
+func (h *Handler) Health(c *gin.Context) {
+	health := h.domain.HealthUCase.Check(c.Request.Context())
+
+	code := http.StatusOK
+	if health.Error != "" {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, health)
+}
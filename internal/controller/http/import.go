@@ -0,0 +1,111 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/accountfile"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+// importBody returns the uploaded file, either the "file" field of a multipart form or the raw body,
+// and its format taken from the format query parameter, the file name or the content type.
+func importBody(c *gin.Context) (io.ReadCloser, string, error) {
+	format := c.Query("format")
+
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+
+		if format == "" {
+			format = accountfile.FormatByContentType(header.Filename)
+		}
+		if format == "" {
+			format = accountfile.FormatByContentType(header.Header.Get("Content-Type"))
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return file, format, nil
+	}
+
+	if format == "" {
+		format = accountfile.FormatByContentType(c.ContentType())
+	}
+
+	return c.Request.Body, format, nil
+}
+
+func (h *Handler) Import(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dryRun must be a boolean"})
+		return
+	}
+
+	body, format, err := importBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	reader, err := accountfile.NewReader(format, body)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.domain.ClientUCase.Import(c.Request.Context(), reader, dryRun)
+	if err != nil {
+		if errors.Is(err, ucase.ErrUnreadableImport) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "report": report})
+			return
+		}
+
+		h.logger.Error("import failed", zap.Int("rows", len(report.Rows)), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": report})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", accountfile.FormatNDJSON)
+
+	writer, err := accountfile.NewWriter(format, c.Writer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", accountfile.ContentType(format))
+	c.Header("Content-Disposition", "attachment; filename=clients."+format)
+	c.Status(http.StatusOK)
+
+	err = h.domain.ClientUCase.Export(c.Request.Context(), func(account entities.TGAccount) error {
+		return writer.Write(account)
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	// The status is already sent, a broken export can only be cut short.
+	if err != nil {
+		h.logger.Error("export failed", zap.Error(err))
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+func organizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrInvalidOrganization):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ucase.ErrOrganizationInUse):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	var req entities.Organization
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	org, err := h.domain.OrganizationUCase.Create(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(organizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+func (h *Handler) GetOrganization(c *gin.Context) {
+	org, err := h.domain.OrganizationUCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(organizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+func (h *Handler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.domain.OrganizationUCase.List(c.Request.Context(), c.Query("tenantID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+func (h *Handler) UpdateOrganization(c *gin.Context) {
+	var req entities.Organization
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the organization not found"})
+		return
+	}
+
+	req.ID = id
+
+	if err = h.domain.OrganizationUCase.Update(c.Request.Context(), req); err != nil {
+		c.JSON(organizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *Handler) DeleteOrganization(c *gin.Context) {
+	if err := h.domain.OrganizationUCase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(organizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ListSites returns the clients registered as sites of the organization.
+func (h *Handler) ListSites(c *gin.Context) {
+	sites, err := h.domain.OrganizationUCase.ListSites(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(organizationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sites)
+}
@@ -7,8 +7,8 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
-	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository"
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
 )
 
@@ -17,7 +17,7 @@ type Handler struct {
 	logger *zap.Logger
 }
 
-func NewHTTPServer(logger *zap.Logger, domain *ucase.UCase) *gin.Engine {
+func NewHTTPServer(logger *zap.Logger, domain *ucase.UCase, apiCfg config.APIConfig) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Logger())
 
@@ -28,30 +28,111 @@ func NewHTTPServer(logger *zap.Logger, domain *ucase.UCase) *gin.Engine {
 		logger: logger,
 	}
 
-	api := router.Group("/api/v1")
+	router.GET("/health", handler.Health)
+
+	api := router.Group("/api/v1", authenticate(apiCfg))
 	{
 		api.POST("/", handler.Create)
+		api.GET("/:id", handler.Get)
 		api.DELETE("/:id", handler.Delete)
+		api.POST("/:id/test", handler.SendTestNotification)
+		api.GET("/:id/migrations", handler.ListMigrations)
+		api.PUT("/:id/rules", handler.SetRules)
+		api.POST("/:id/digests", handler.ScheduleDigest)
+		api.GET("/:id/digests", handler.ListDigestSchedules)
+		api.DELETE("/:id/digests/:scheduleID", handler.UnscheduleDigest)
+		api.GET("/:id/digest", handler.ComposeDigest)
+		api.GET("/:id/stats", handler.ClientStats)
+		api.GET("/stats", operatorOnly, handler.GlobalStats)
+		api.POST("/rules/validate", handler.ValidateRules)
+		api.POST("/import", operatorOnly, handler.Import)
+		api.GET("/export", operatorOnly, handler.Export)
+		api.GET("/deliveries", operatorOnly, handler.ListDeliveries)
+		api.POST("/deliveries/:requestID/acknowledge", operatorOnly, handler.AcknowledgeDelivery)
+	}
+
+	bots := api.Group("/bots")
+	{
+		bots.POST("/", handler.CreateBot)
+		bots.GET("/", handler.ListBots)
+		bots.DELETE("/:id", handler.DeleteBot)
+	}
+
+	organizations := api.Group("/organizations")
+	{
+		organizations.POST("/", handler.CreateOrganization)
+		organizations.GET("/", handler.ListOrganizations)
+		organizations.GET("/:id", handler.GetOrganization)
+		organizations.PUT("/:id", handler.UpdateOrganization)
+		organizations.DELETE("/:id", handler.DeleteOrganization)
+		organizations.GET("/:id/sites", handler.ListSites)
+		organizations.GET("/:id/stats", handler.OrganizationStats)
+	}
+
+	zones := api.Group("/zones")
+	{
+		zones.POST("/", handler.CreateZone)
+		zones.GET("/", handler.ListZones)
+		zones.GET("/:id", handler.GetZone)
+		zones.PUT("/:id", handler.UpdateZone)
+		zones.DELETE("/:id", handler.DeleteZone)
+		zones.POST("/:id/subscriptions", handler.SubscribeZone)
+		zones.GET("/:id/subscriptions", handler.ListZoneSubscriptions)
+		zones.PUT("/:id/subscriptions/:subscriptionID/rules", handler.SetZoneSubscriptionRules)
+		zones.DELETE("/:id/subscriptions/:subscriptionID", handler.UnsubscribeZone)
+	}
+
+	sensors := api.Group("/sensors", operatorOnly)
+	{
+		sensors.POST("/", handler.CreateSensor)
+		sensors.GET("/", handler.ListSensors)
+		sensors.GET("/:id", handler.GetSensor)
+		sensors.PUT("/:id", handler.UpdateSensor)
+		sensors.DELETE("/:id", handler.DeleteSensor)
+	}
+
+	broadcasts := api.Group("/broadcasts", operatorOnly)
+	{
+		broadcasts.POST("/", handler.CreateBroadcast)
+		broadcasts.GET("/", handler.ListBroadcasts)
+		broadcasts.GET("/:id", handler.GetBroadcast)
+		broadcasts.POST("/:id/cancel", handler.CancelBroadcast)
 	}
 
 	return router
 }
 
+// createClientRequest is the account with the name of the forum topic to create for it, if any.
+type createClientRequest struct {
+	entities.TGAccount
+	TopicName string `json:"topicName"`
+}
+
 func (h *Handler) Create(c *gin.Context) {
-	var req entities.TGAccount
+	var req createClientRequest
 
 	if err := c.BindJSON(&req); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := h.domain.ClientUCase.Create(c.Request.Context(), req)
+	err := h.domain.ClientUCase.Create(c.Request.Context(), req.TGAccount, req.TopicName)
 	if err != nil {
-		if errors.Is(err, repository.ErrRecordExists) {
+		if errors.Is(err, entities.ErrRecordExists) {
 			c.JSON(http.StatusConflict, gin.H{"error": "user already exists"})
 			return
 		}
 
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "the bot or the organization not found"})
+			return
+		}
+
+		if errors.Is(err, ucase.ErrInvalidAccount) || errors.Is(err, ucase.ErrTopicNotCreated) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -59,12 +140,43 @@ func (h *Handler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"status": "ok"})
 }
 
+func (h *Handler) Get(c *gin.Context) {
+	account, err := h.domain.ClientUCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the user not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ListMigrations returns the audit trail of the group to supergroup upgrades the chat of the client went through.
+func (h *Handler) ListMigrations(c *gin.Context) {
+	migrations, err := h.domain.ClientUCase.ListMigrations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "the user not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, migrations)
+}
+
 func (h *Handler) Delete(c *gin.Context) {
 	id := c.Param("id")
 
 	err := h.domain.ClientUCase.Delete(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, repository.ErrRecordExists) {
+		if errors.Is(err, entities.ErrRecordExists) || errors.Is(err, entities.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "the user not found"})
 			return
 		}
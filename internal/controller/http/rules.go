@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+type rulesRequest struct {
+	Rules []string `json:"rules"`
+}
+
+// validateRulesRequest is the rules to check with an optional sample message to apply them to.
+type validateRulesRequest struct {
+	Rules   []string                      `json:"rules"`
+	Message *entities.NotificationMessage `json:"message"`
+}
+
+// ValidateRules checks the filter rule expressions, with a sample message it also tells which rule
+// suppresses it.
+func (h *Handler) ValidateRules(c *gin.Context) {
+	var req validateRulesRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.domain.NotificationUCase.CheckRules(req.Rules, req.Message))
+}
+
+// SetRules replaces the filter rules of the client.
+func (h *Handler) SetRules(c *gin.Context) {
+	var req rulesRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.domain.ClientUCase.SetRules(c.Request.Context(), c.Param("id"), req.Rules); err != nil {
+		switch {
+		case errors.Is(err, ucase.ErrInvalidAccount):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, entities.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "the user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+func sensorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrInvalidSensor):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, entities.ErrRecordExists):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) CreateSensor(c *gin.Context) {
+	var req entities.Sensor
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.domain.SensorUCase.Create(c.Request.Context(), req); err != nil {
+		c.JSON(sensorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "ok"})
+}
+
+func (h *Handler) GetSensor(c *gin.Context) {
+	sensor, err := h.domain.SensorUCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(sensorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sensor)
+}
+
+func (h *Handler) ListSensors(c *gin.Context) {
+	sensors, err := h.domain.SensorUCase.List(c.Request.Context(), c.Query("clientID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sensors)
+}
+
+func (h *Handler) UpdateSensor(c *gin.Context) {
+	var req entities.Sensor
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.ID = c.Param("id")
+
+	if err := h.domain.SensorUCase.Update(c.Request.Context(), req); err != nil {
+		c.JSON(sensorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *Handler) DeleteSensor(c *gin.Context) {
+	if err := h.domain.SensorUCase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(sensorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+func statsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrInvalidStats):
+		return http.StatusBadRequest
+	case errors.Is(err, entities.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// statsFilter reads the since, until (RFC 3339) and top query parameters, the period defaults
+// to the last 30 days.
+func statsFilter(c *gin.Context) (entities.DeliveryStatsFilter, bool) {
+	var (
+		filter entities.DeliveryStatsFilter
+		err    error
+	)
+
+	if value := c.Query("since"); value != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time"})
+			return filter, false
+		}
+	}
+	if value := c.Query("until"); value != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be an RFC 3339 time"})
+			return filter, false
+		}
+	}
+	if value := c.Query("top"); value != "" {
+		if filter.TopSensors, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "top must be a number"})
+			return filter, false
+		}
+	}
+
+	return filter, true
+}
+
+// ClientStats returns the delivery statistics of the client.
+func (h *Handler) ClientStats(c *gin.Context) {
+	filter, ok := statsFilter(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.domain.StatsUCase.Client(c.Request.Context(), c.Param("id"), filter)
+	if err != nil {
+		c.JSON(statsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// OrganizationStats returns the delivery statistics of all the sites of the organization.
+func (h *Handler) OrganizationStats(c *gin.Context) {
+	filter, ok := statsFilter(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.domain.StatsUCase.Organization(c.Request.Context(), c.Param("id"), filter)
+	if err != nil {
+		c.JSON(statsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GlobalStats returns the delivery statistics of all the clients.
+func (h *Handler) GlobalStats(c *gin.Context) {
+	filter, ok := statsFilter(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.domain.StatsUCase.Global(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(statsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
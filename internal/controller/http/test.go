@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+type testNotificationRequest struct {
+	SensorID string `json:"sensorID"`
+}
+
+// SendTestNotification sends a labelled test alert to the client, the body may name a sensor of the client
+// to include its location. A Telegram failure is returned as 502 with the Bot API error.
+func (h *Handler) SendTestNotification(c *gin.Context) {
+	var req testNotificationRequest
+
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	delivery, err := h.domain.NotificationUCase.Test(c.Request.Context(), c.Param("id"), req.SensorID)
+	if err != nil {
+		var deliveryErr *ucase.DeliveryError
+
+		switch {
+		case errors.Is(err, entities.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, ucase.ErrInvalidSensor):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, ucase.ErrInactiveAccount):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.As(err, &deliveryErr):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "delivery": delivery})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
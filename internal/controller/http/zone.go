@@ -0,0 +1,164 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+func zoneErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ucase.ErrInvalidZone):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, entities.ErrRecordExists):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CreateZone stores a zone, its geometry is a GeoJSON Polygon or MultiPolygon.
+func (h *Handler) CreateZone(c *gin.Context) {
+	var req entities.Zone
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	zone, err := h.domain.ZoneUCase.Create(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, zone)
+}
+
+func (h *Handler) GetZone(c *gin.Context) {
+	zone, err := h.domain.ZoneUCase.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, zone)
+}
+
+func (h *Handler) ListZones(c *gin.Context) {
+	zones, err := h.domain.ZoneUCase.List(c.Request.Context(), c.Query("tenantID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, zones)
+}
+
+func (h *Handler) UpdateZone(c *gin.Context) {
+	var req entities.Zone
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "the zone not found"})
+		return
+	}
+
+	req.ID = id
+
+	if err = h.domain.ZoneUCase.Update(c.Request.Context(), req); err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *Handler) DeleteZone(c *gin.Context) {
+	if err := h.domain.ZoneUCase.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+type subscribeZoneRequest struct {
+	ChatID   int64    `json:"chatID" binding:"required"`
+	ThreadID int      `json:"threadID"`
+	BotID    string   `json:"botID"`
+	Rules    []string `json:"rules"`
+}
+
+// SubscribeZone makes the chat get the alerts of the detections inside the zone.
+func (h *Handler) SubscribeZone(c *gin.Context) {
+	var req subscribeZoneRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := h.domain.ZoneUCase.Subscribe(c.Request.Context(), entities.ZoneSubscription{
+		ZoneID:   c.Param("id"),
+		ChatID:   req.ChatID,
+		ThreadID: req.ThreadID,
+		BotID:    req.BotID,
+		Rules:    req.Rules,
+	})
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (h *Handler) ListZoneSubscriptions(c *gin.Context) {
+	subs, err := h.domain.ZoneUCase.ListSubscriptions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// SetZoneSubscriptionRules replaces the filter rules of the subscription.
+func (h *Handler) SetZoneSubscriptionRules(c *gin.Context) {
+	var req rulesRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.domain.ZoneUCase.SetSubscriptionRules(c.Request.Context(), c.Param("id"), c.Param("subscriptionID"), req.Rules)
+	if err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *Handler) UnsubscribeZone(c *gin.Context) {
+	if err := h.domain.ZoneUCase.Unsubscribe(c.Request.Context(), c.Param("id"), c.Param("subscriptionID")); err != nil {
+		c.JSON(zoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -11,18 +11,36 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const _notificationMethod = "telegram"
 
+// Headers added to a message copied to the dead-letter topic.
+const (
+	HeaderDeadLetterError     = "x-dead-letter-error"
+	HeaderDeadLetterTopic     = "x-dead-letter-topic"
+	HeaderDeadLetterPartition = "x-dead-letter-partition"
+	HeaderDeadLetterOffset    = "x-dead-letter-offset"
+)
+
+// _defaultDrainTimeout bounds the wait for the in-flight notification of a consumer created by NewHandler.
+const _defaultDrainTimeout = 20 * time.Second
+
+// _deadLetterRetryInterval is the pause between the attempts to copy a message to the dead-letter topic.
+const _deadLetterRetryInterval = time.Second
+
 type KafkaConsumer struct {
-	logger *zap.Logger
-	domain *ucase.UCase
-	group  sarama.ConsumerGroup
-	ready  chan struct{}
-	topics []string
+	logger          *zap.Logger
+	domain          *ucase.UCase
+	group           sarama.ConsumerGroup
+	topics          []string
+	deadLetter      sarama.SyncProducer
+	deadLetterTopic string
+	deadLetterRetry time.Duration
+	drainTimeout    time.Duration
 }
 
 func NewKafkaConsumer(cfg config.KafkaConsumerConfig, domain *ucase.UCase, logger *zap.Logger) (*KafkaConsumer, error) {
@@ -39,46 +57,72 @@ func NewKafkaConsumer(cfg config.KafkaConsumerConfig, domain *ucase.UCase, logge
 		return nil, errors.Wrap(err, "error creating consumer group client")
 	}
 
-	return &KafkaConsumer{
-		domain: domain,
-		logger: logger.Named("kafka-consumer"),
-		ready:  make(chan struct{}),
-		group:  group,
-		topics: strings.Split(cfg.Topic, ","),
-	}, nil
-}
+	consumer := &KafkaConsumer{
+		domain:       domain,
+		logger:       logger.Named("kafka-consumer"),
+		group:        group,
+		topics:       strings.Split(cfg.Topic, ","),
+		drainTimeout: cfg.DrainTimeout,
 
-func (k KafkaConsumer) Run(ctx context.Context) error {
-	errChan := make(chan error)
+		deadLetterRetry: _deadLetterRetryInterval,
+	}
 
-	go func() {
-		for {
-			if err := k.group.Consume(ctx, k.topics, &k); err != nil {
-				k.logger.Error("error from consumer", zap.Error(err))
-				errChan <- err
-			}
+	if cfg.DeadLetterTopic != "" {
+		producerConfig := sarama.NewConfig()
+		producerConfig.Version = saramaConfig.Version
+		producerConfig.Producer.Return.Successes = true
+		producerConfig.Producer.RequiredAcks = sarama.WaitForAll
 
-			if ctx.Err() != nil {
-				return
-			}
-
-			k.ready = make(chan struct{})
+		producer, err := sarama.NewSyncProducer(strings.Split(cfg.Peers, ","), producerConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating dead-letter producer")
 		}
-	}()
 
-	<-k.ready
+		consumer.SetDeadLetter(producer, cfg.DeadLetterTopic)
+	}
+
+	return consumer, nil
+}
+
+// NewHandler returns the consumer without a consumer group, to feed it claims of a group created elsewhere.
+func NewHandler(domain *ucase.UCase, logger *zap.Logger) *KafkaConsumer {
+	return &KafkaConsumer{
+		domain:       domain,
+		logger:       logger.Named("kafka-consumer"),
+		drainTimeout: _defaultDrainTimeout,
 
-	select {
-	case <-ctx.Done():
-		k.logger.Info("terminating consume: context canceled")
-	case err := <-errChan:
-		return err
+		deadLetterRetry: _deadLetterRetryInterval,
 	}
+}
+
+// SetDeadLetter makes the consumer copy the messages it fails to handle to the topic.
+func (k *KafkaConsumer) SetDeadLetter(producer sarama.SyncProducer, topic string) {
+	k.deadLetter = producer
+	k.deadLetterTopic = topic
+}
+
+// Run consumes until the context is canceled or the group fails. On cancellation it stops fetching, lets
+// the claims finish their in-flight notifications and commit the offsets, then leaves the group.
+func (k KafkaConsumer) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		if err := k.group.Consume(ctx, k.topics, k); err != nil {
+			k.logger.Error("error from consumer", zap.Error(err))
+			return errors.Wrap(err, "group.Consume")
+		}
+	}
+
+	k.logger.Info("consumer stopped, leaving the group")
 
 	if err := k.group.Close(); err != nil {
 		return errors.Wrap(err, "error closing consumer group")
 	}
 
+	if k.deadLetter != nil {
+		if err := k.deadLetter.Close(); err != nil {
+			return errors.Wrap(err, "error closing dead-letter producer")
+		}
+	}
+
 	return nil
 }
 
@@ -87,8 +131,11 @@ func (k KafkaConsumer) Setup(sarama.ConsumerGroupSession) error {
 	return nil
 }
 
-func (k KafkaConsumer) Cleanup(sarama.ConsumerGroupSession) error {
+// Cleanup runs after every claim has drained, the offsets marked by then are committed.
+func (k KafkaConsumer) Cleanup(session sarama.ConsumerGroupSession) error {
 	k.logger.Debug("Cleanup")
+	session.Commit()
+
 	return nil
 }
 
@@ -102,40 +149,146 @@ func containsMethod(necessary string, methods []string) bool {
 	return false
 }
 
+// ConsumeClaim handles the messages of the partition one at a time, in their order, and marks each one
+// when it's done. The message in hand when the session ends gets up to the drain timeout to finish.
 func (k KafkaConsumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
-	for {
+	ctx, cancel := k.drainContext(session.Context())
+	defer cancel()
+
+	for session.Context().Err() == nil {
 		select {
-		case message := <-claim.Messages():
-			go func() {
-				var (
-					msg entities.NotificationMessage
-					err error
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			if !k.handle(ctx, message) {
+				// Neither marked nor dead-lettered, the next owner of the partition consumes it again.
+				k.logger.Warn("in-flight message didn't finish before the drain timeout",
+					zap.String("topic", claim.Topic()),
+					zap.Int32("partition", claim.Partition()),
+					zap.Int64("offset", message.Offset),
+					zap.Duration("timeout", k.drainTimeout),
 				)
 
-				if err = json.Unmarshal(message.Value, &msg); err != nil {
-					k.logger.Error("json.Unmarshal error: ", zap.Error(err))
-				}
+				return nil
+			}
+
+			session.MarkMessage(message, "")
+		case <-session.Context().Done():
+		}
+	}
 
-				if !containsMethod(_notificationMethod, msg.NotificationMethods) {
-					session.MarkMessage(message, "")
-					return
-				}
+	k.logger.Debug("claim drained", zap.String("topic", claim.Topic()), zap.Int32("partition", claim.Partition()))
 
-				ctx := otel.GetTextMapPropagator().Extract(
-					context.Background(), otelsarama.NewConsumerMessageCarrier(message),
-				)
+	return nil
+}
 
-				if err = k.domain.NotificationUCase.Notify(ctx, msg); err != nil {
-					k.logger.Error("domain.Notify error",
-						zap.String("requestID", msg.RequestID.String()),
-						zap.Error(err),
-					)
-				}
+// drainContext returns the context of the notifications, it's canceled the drain timeout after the session ends.
+func (k KafkaConsumer) drainContext(session context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 
-				session.MarkMessage(message, "")
-			}()
-		case <-session.Context().Done():
+	go func() {
+		select {
+		case <-session.Done():
+		case <-ctx.Done():
+			return
+		}
+
+		timer := time.NewTimer(k.drainTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// handle notifies about the message, a message that fails is sent to the dead-letter topic. It returns
+// false for a message that must be consumed again: its notification or the copy to the dead-letter topic
+// was cut short by the drain timeout.
+func (k KafkaConsumer) handle(ctx context.Context, message *sarama.ConsumerMessage) bool {
+	var msg entities.NotificationMessage
+
+	if err := json.Unmarshal(message.Value, &msg); err != nil {
+		k.logger.Error("json.Unmarshal error: ", zap.Error(err))
+
+		return k.sendToDeadLetter(ctx, message, err) == nil
+	}
+
+	if !containsMethod(_notificationMethod, msg.NotificationMethods) {
+		return true
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, otelsarama.NewConsumerMessageCarrier(message))
+
+	if err := k.domain.NotificationUCase.Notify(ctx, msg); err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		k.logger.Error("domain.Notify error",
+			zap.String("requestID", msg.RequestID.String()),
+			zap.Error(err),
+		)
+
+		return k.sendToDeadLetter(ctx, message, err) == nil
+	}
+
+	return true
+}
+
+// sendToDeadLetter copies the failed message with its headers to the dead-letter topic, the added headers
+// tell where it came from and why it failed. A failed copy is retried until the context is done, the
+// message mustn't be marked before it's stored somewhere.
+func (k KafkaConsumer) sendToDeadLetter(ctx context.Context, message *sarama.ConsumerMessage, cause error) error {
+	if k.deadLetter == nil {
+		return nil
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+4)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+
+	headers = append(headers,
+		sarama.RecordHeader{Key: []byte(HeaderDeadLetterError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderDeadLetterTopic), Value: []byte(message.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDeadLetterPartition), Value: []byte(strconv.Itoa(int(message.Partition)))},
+		sarama.RecordHeader{Key: []byte(HeaderDeadLetterOffset), Value: []byte(strconv.FormatInt(message.Offset, 10))},
+	)
+
+	producerMessage := &sarama.ProducerMessage{
+		Topic:   k.deadLetterTopic,
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	}
+	if message.Key != nil {
+		producerMessage.Key = sarama.ByteEncoder(message.Key)
+	}
+
+	for {
+		_, _, err := k.deadLetter.SendMessage(producerMessage)
+		if err == nil {
 			return nil
 		}
+
+		k.logger.Error("can't send message to the dead-letter topic",
+			zap.String("topic", message.Topic),
+			zap.Int64("offset", message.Offset),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(err, "deadLetter.SendMessage")
+		case <-time.After(k.deadLetterRetry):
+		}
 	}
 }
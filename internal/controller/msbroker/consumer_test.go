@@ -0,0 +1,210 @@
+package msbroker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/msbroker/msbrokertest"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+type notifier struct {
+	ucase.NotificationUseCase
+	mu       sync.Mutex
+	clients  []string
+	block    string
+	fail     string
+	inFlight int
+	maxFlow  int
+}
+
+func (n *notifier) Notify(ctx context.Context, msg entities.NotificationMessage) error {
+	n.mu.Lock()
+	n.clients = append(n.clients, msg.ClientID)
+	n.inFlight++
+	if n.inFlight > n.maxFlow {
+		n.maxFlow = n.inFlight
+	}
+	n.mu.Unlock()
+
+	defer func() {
+		n.mu.Lock()
+		n.inFlight--
+		n.mu.Unlock()
+	}()
+
+	if msg.ClientID == n.block {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if msg.ClientID == n.fail {
+		return errors.New("notification failed")
+	}
+
+	return nil
+}
+
+// failingProducer fails every send to the dead-letter topic.
+type failingProducer struct {
+	sarama.SyncProducer
+	mu       sync.Mutex
+	attempts int
+}
+
+func (p *failingProducer) SendMessage(*sarama.ProducerMessage) (int32, int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts++
+
+	return 0, 0, sarama.ErrOutOfBrokers
+}
+
+func message(t *testing.T, clientID string) []byte {
+	t.Helper()
+
+	value, err := json.Marshal(entities.NotificationMessage{
+		NotificationMethods: []string{_notificationMethod},
+		RequestID:           uuid.New(),
+		ClientID:            clientID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+func TestConsumeClaimInOrder(t *testing.T) {
+	n := &notifier{}
+	h := msbrokertest.New(t, NewHandler(&ucase.UCase{NotificationUCase: n}, zap.NewNop()), "input")
+	defer h.Close()
+
+	clients := []string{"1", "2", "3", "4", "5"}
+	for _, clientID := range clients {
+		h.Send(message(t, clientID))
+	}
+
+	if err := h.WaitMarked(len(clients), time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, offset := range h.Session().Marked() {
+		if offset != int64(i+1) {
+			t.Fatalf("marked %v, want offsets in order", h.Session().Marked())
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i := range clients {
+		if n.clients[i] != clients[i] {
+			t.Fatalf("notified %v, want %v", n.clients, clients)
+		}
+	}
+
+	if n.maxFlow != 1 {
+		t.Fatalf("%d notifications in flight, want 1", n.maxFlow)
+	}
+}
+
+func TestConsumeClaimDrainTimeout(t *testing.T) {
+	n := &notifier{block: "2"}
+	handler := NewHandler(&ucase.UCase{NotificationUCase: n}, zap.NewNop())
+	handler.drainTimeout = 50 * time.Millisecond
+
+	h := msbrokertest.New(t, handler, "input")
+	h.Send(message(t, "1"))
+	h.Send(message(t, "2"))
+	h.Send(message(t, "3"))
+
+	if err := h.WaitMarked(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cut-off message stays unmarked and the one after it isn't consumed.
+	if marked := h.Session().Marked(); len(marked) != 1 || marked[0] != 1 {
+		t.Fatalf("marked %v, want [1]", marked)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.clients) != 2 {
+		t.Fatalf("notified %v, want [1 2]", n.clients)
+	}
+}
+
+func TestConsumeClaimDeadLetterRetried(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	producer.ExpectSendMessageAndSucceed()
+
+	handler := NewHandler(&ucase.UCase{NotificationUCase: &notifier{fail: "1"}}, zap.NewNop())
+	handler.SetDeadLetter(producer, "dead-letter")
+	handler.deadLetterRetry = time.Millisecond
+
+	h := msbrokertest.New(t, handler, "input")
+	defer h.Close()
+
+	h.Send(message(t, "1"))
+
+	// Marked once the second attempt stored the copy.
+	if err := h.WaitMarked(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := producer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsumeClaimDeadLetterUnavailable(t *testing.T) {
+	producer := &failingProducer{}
+
+	handler := NewHandler(&ucase.UCase{NotificationUCase: &notifier{fail: "1"}}, zap.NewNop())
+	handler.SetDeadLetter(producer, "dead-letter")
+	handler.deadLetterRetry = time.Millisecond
+	handler.drainTimeout = 50 * time.Millisecond
+
+	h := msbrokertest.New(t, handler, "input")
+	h.Send(message(t, "1"))
+	h.Send(message(t, "2"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed message is neither stored nor marked, the next owner of the partition consumes it again.
+	if marked := h.Session().Marked(); len(marked) != 0 {
+		t.Fatalf("marked %v, want none", marked)
+	}
+
+	producer.mu.Lock()
+	defer producer.mu.Unlock()
+
+	if producer.attempts < 2 {
+		t.Fatalf("%d dead-letter attempts, want retries", producer.attempts)
+	}
+}
@@ -0,0 +1,168 @@
+// Package msbrokertest runs a sarama.ConsumerGroupHandler against an in-process partition built on
+// sarama mocks, so the Kafka consumer can be tested without a broker:
+//
+//	h := msbrokertest.New(t, msbroker.NewHandler(domain, logger), "GunshotNotificationInput")
+//	defer h.Close()
+//	h.Send(payload)
+//	h.WaitMarked(1, time.Second)
+package msbrokertest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+)
+
+const _partition = 0
+
+type Harness struct {
+	handler   sarama.ConsumerGroupHandler
+	consumer  *mocks.Consumer
+	expected  *mocks.PartitionConsumer
+	session   *Session
+	claim     *claim
+	cancel    context.CancelFunc
+	done      chan error
+	closeOnce sync.Once
+}
+
+// New sets the handler up and starts consuming a single partition of the topic.
+func New(t mocks.ErrorReporter, handler sarama.ConsumerGroupHandler, topic string) *Harness {
+	consumer := mocks.NewConsumer(t, mocks.NewTestConfig())
+	expected := consumer.ExpectConsumePartition(topic, _partition, sarama.OffsetOldest)
+
+	partition, err := consumer.ConsumePartition(topic, _partition, sarama.OffsetOldest)
+	if err != nil {
+		t.Errorf("msbrokertest: ConsumePartition: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h := &Harness{
+		handler:  handler,
+		consumer: consumer,
+		expected: expected,
+		session:  newSession(ctx, topic),
+		claim:    &claim{topic: topic, partition: partition},
+		cancel:   cancel,
+		done:     make(chan error, 1),
+	}
+
+	if err = handler.Setup(h.session); err != nil {
+		t.Errorf("msbrokertest: Setup: %v", err)
+	}
+
+	go func() {
+		h.done <- handler.ConsumeClaim(h.session, h.claim)
+	}()
+
+	return h
+}
+
+// Send delivers a message with the value and headers to the handler.
+func (h *Harness) Send(value []byte, headers ...sarama.RecordHeader) {
+	message := &sarama.ConsumerMessage{Value: value, Timestamp: time.Now()}
+	for i := range headers {
+		message.Headers = append(message.Headers, &headers[i])
+	}
+
+	h.expected.YieldMessage(message)
+}
+
+// Session is the session the handler consumes in.
+func (h *Harness) Session() *Session {
+	return h.session
+}
+
+// WaitMarked waits until the handler has marked at least n messages as processed.
+func (h *Harness) WaitMarked(n int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		marked := len(h.session.Marked())
+		if marked >= n {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("msbrokertest: %d messages marked after %s, want %d", marked, timeout, n)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Close ends the session the way a rebalance does and waits for the handler to return.
+func (h *Harness) Close() error {
+	var err error
+
+	h.closeOnce.Do(func() {
+		h.cancel()
+
+		if err = <-h.done; err != nil {
+			return
+		}
+
+		if err = h.handler.Cleanup(h.session); err != nil {
+			return
+		}
+
+		err = h.consumer.Close()
+	})
+
+	return err
+}
+
+type claim struct {
+	topic     string
+	partition sarama.PartitionConsumer
+}
+
+func (c *claim) Topic() string                            { return c.topic }
+func (c *claim) Partition() int32                         { return _partition }
+func (c *claim) InitialOffset() int64                     { return sarama.OffsetOldest }
+func (c *claim) HighWaterMarkOffset() int64               { return c.partition.HighWaterMarkOffset() }
+func (c *claim) Messages() <-chan *sarama.ConsumerMessage { return c.partition.Messages() }
+
+// Session records the offsets marked by the handler.
+type Session struct {
+	ctx    context.Context
+	topic  string
+	mu     sync.Mutex
+	marked []int64
+}
+
+func newSession(ctx context.Context, topic string) *Session {
+	return &Session{ctx: ctx, topic: topic}
+}
+
+// Marked returns the offsets of the marked messages in the order they were marked.
+func (s *Session) Marked() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]int64(nil), s.marked...)
+}
+
+func (s *Session) Claims() map[string][]int32 { return map[string][]int32{s.topic: {_partition}} }
+func (s *Session) MemberID() string           { return "msbrokertest" }
+func (s *Session) GenerationID() int32        { return 1 }
+func (s *Session) Context() context.Context   { return s.ctx }
+func (s *Session) Commit()                    {}
+
+func (s *Session) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.marked = append(s.marked, offset)
+}
+
+func (s *Session) ResetOffset(string, int32, int64, string) {}
+
+func (s *Session) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
@@ -0,0 +1,359 @@
+package msbroker_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama/mocks"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/msbroker"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/controller/msbroker/msbrokertest"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/bot"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/bot/telegramtest"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/cipher"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/memory"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+const _chatID = -100500
+
+// pipeline consumes the input topic with the notify use case sending through the fake Bot API.
+type pipeline struct {
+	harness    *msbrokertest.Harness
+	telegram   *telegramtest.Server
+	repo       *memory.Repository
+	cipher     *cipher.TokenCipher
+	deadLetter *mocks.SyncProducer
+	clientID   string
+}
+
+func newPipeline(t *testing.T) *pipeline {
+	t.Helper()
+
+	telegram := telegramtest.NewServer()
+	t.Cleanup(telegram.Close)
+
+	cfg := config.BotConfig{
+		Token:           "token",
+		APIEndpoint:     telegram.Endpoint(),
+		RateLimit:       100,
+		RateBurst:       10,
+		BreakerFailures: 5,
+		BreakerCooldown: time.Minute,
+	}
+
+	repo := memory.NewRepository()
+	account := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: _chatID}
+	if err := repo.Create(context.Background(), account); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	tokenCipher, err := cipher.NewTokenCipher(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if err != nil {
+		t.Fatalf("NewTokenCipher: %v", err)
+	}
+
+	registry := bot.NewRegistry(cfg, http.DefaultClient, repo, tokenCipher)
+
+	tgBot, err := bot.NewBot(cfg, "Shot near {{.ClientID}}", registry, nil, nil)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	deadLetter := mocks.NewSyncProducer(t, nil)
+	t.Cleanup(func() { deadLetter.Close() })
+
+	handler := msbroker.NewHandler(&ucase.UCase{
+		NotificationUCase: ucase.NewNotifyUCase(repo, tgBot, nil, nil, time.Hour),
+	}, zap.NewNop())
+	handler.SetDeadLetter(deadLetter, "GunshotNotificationDeadLetter")
+
+	harness := msbrokertest.New(t, handler, "GunshotNotificationInput")
+	t.Cleanup(func() {
+		if err := harness.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+
+	return &pipeline{
+		harness:    harness,
+		telegram:   telegram,
+		repo:       repo,
+		cipher:     tokenCipher,
+		deadLetter: deadLetter,
+		clientID:   account.ClientID.Hex(),
+	}
+}
+
+// useTenantBot moves the client to a tenant bot with the token.
+func (p *pipeline) useTenantBot(t *testing.T, token string) {
+	t.Helper()
+
+	encrypted, err := p.cipher.Encrypt(token)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	tenantBot := entities.Bot{ID: primitive.NewObjectID(), TenantID: "acme", Username: "acme_bot", Token: encrypted}
+	if err = p.repo.CreateBot(context.Background(), tenantBot); err != nil {
+		t.Fatalf("CreateBot: %v", err)
+	}
+
+	account, err := p.repo.GetAccountByClientID(context.Background(), p.clientID)
+	if err != nil {
+		t.Fatalf("GetAccountByClientID: %v", err)
+	}
+
+	account.BotID = tenantBot.ID.Hex()
+	if _, err = p.repo.UpsertAccount(context.Background(), account); err != nil {
+		t.Fatalf("UpsertAccount: %v", err)
+	}
+}
+
+func (p *pipeline) message() entities.NotificationMessage {
+	return entities.NotificationMessage{
+		NotificationMethods: []string{"telegram"},
+		Timestamp:           time.Now(),
+		RequestID:           uuid.New(),
+		ClientID:            p.clientID,
+		MessageType:         "text",
+	}
+}
+
+// alert sends an alert for the client and waits for the consumer to mark it, marked counts the messages
+// marked before.
+func (p *pipeline) alert(t *testing.T, marked int) {
+	t.Helper()
+	p.consume(t, p.message(), marked)
+}
+
+func (p *pipeline) consume(t *testing.T, msg entities.NotificationMessage, marked int) {
+	t.Helper()
+
+	value, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	p.harness.Send(value)
+
+	if err = p.harness.WaitMarked(marked+1, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *pipeline) deliveries(t *testing.T) []entities.Delivery {
+	t.Helper()
+
+	deliveries, err := p.repo.ListDeliveries(context.Background(), entities.DeliveryFilter{ClientID: p.clientID})
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+
+	return deliveries
+}
+
+func (p *pipeline) parked(t *testing.T) int64 {
+	t.Helper()
+
+	parked, err := p.repo.CountParkedNotifications(context.Background())
+	if err != nil {
+		t.Fatalf("CountParkedNotifications: %v", err)
+	}
+
+	return parked
+}
+
+func TestPipelineDelivered(t *testing.T) {
+	p := newPipeline(t)
+
+	// Starting doesn't depend on Telegram, the default bot is created on first use without getMe.
+	if calls := p.telegram.Calls(""); len(calls) != 0 {
+		t.Fatalf("Bot API calls %+v before the first alert, want none", calls)
+	}
+
+	p.alert(t, 0)
+
+	calls := p.telegram.Calls("sendMessage")
+	if len(calls) != 1 || calls[0].ChatID() != _chatID {
+		t.Fatalf("sendMessage calls %+v, want one to chat %d", calls, _chatID)
+	}
+
+	deliveries := p.deliveries(t)
+	if len(deliveries) != 1 || deliveries[0].MessageID == 0 || deliveries[0].Status != entities.DeliverySent {
+		t.Fatalf("deliveries %+v, want one sent", deliveries)
+	}
+
+	if parked := p.parked(t); parked != 0 {
+		t.Fatalf("%d notifications parked, want none", parked)
+	}
+}
+
+func TestPipelineLocationFailed(t *testing.T) {
+	p := newPipeline(t)
+	p.telegram.FailNext("sendLocation", telegramtest.BadGateway)
+
+	msg := p.message()
+	msg.Location = &entities.Location{Latitude: 55.75, Longitude: 37.62}
+	p.consume(t, msg, 0)
+
+	// The alert is out, it's neither sent again nor dead-lettered.
+	deliveries := p.deliveries(t)
+	if len(deliveries) != 1 || deliveries[0].MessageID == 0 || deliveries[0].LocationMessageID != 0 {
+		t.Fatalf("deliveries %+v, want one sent without the location", deliveries)
+	}
+
+	if parked := p.parked(t); parked != 0 {
+		t.Fatalf("%d notifications parked, want none", parked)
+	}
+}
+
+func TestPipelineUnavailable(t *testing.T) {
+	for name, failure := range map[string]telegramtest.Failure{
+		"too many requests": telegramtest.TooManyRequests,
+		"bad gateway":       telegramtest.BadGateway,
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newPipeline(t)
+			p.telegram.FailNext("sendMessage", failure)
+			p.alert(t, 0)
+
+			// The alert waits for Telegram in the retry queue, it isn't dead-lettered.
+			if parked := p.parked(t); parked != 1 {
+				t.Fatalf("%d notifications parked, want 1", parked)
+			}
+
+			if deliveries := p.deliveries(t); len(deliveries) != 0 {
+				t.Fatalf("deliveries %+v, want none", deliveries)
+			}
+		})
+	}
+}
+
+func TestPipelineTenantBotUnavailable(t *testing.T) {
+	p := newPipeline(t)
+	p.useTenantBot(t, "tenant-token")
+	p.telegram.FailNext("getMe", telegramtest.BadGateway)
+	p.telegram.FailNext("sendMessage", telegramtest.BadGateway)
+	p.alert(t, 0)
+
+	// Loading the bot doesn't call Telegram, the failed send parks the alert like for the default bot.
+	if calls := p.telegram.Calls("getMe"); len(calls) != 0 {
+		t.Fatalf("%d getMe calls, want none", len(calls))
+	}
+
+	calls := p.telegram.Calls("sendMessage")
+	if len(calls) != 1 || calls[0].Token != "tenant-token" {
+		t.Fatalf("sendMessage calls %+v, want one with the tenant token", calls)
+	}
+
+	if parked := p.parked(t); parked != 1 {
+		t.Fatalf("%d notifications parked, want 1", parked)
+	}
+}
+
+func TestPipelineBotBlocked(t *testing.T) {
+	p := newPipeline(t)
+	p.telegram.FailChat(_chatID, telegramtest.BotBlocked)
+	p.deadLetter.ExpectSendMessageAndSucceed()
+	p.alert(t, 0)
+
+	account, err := p.repo.GetAccountByClientID(context.Background(), p.clientID)
+	if err != nil {
+		t.Fatalf("GetAccountByClientID: %v", err)
+	}
+
+	if account.Active() {
+		t.Fatal("account of the chat blocking the bot is still active")
+	}
+
+	if parked := p.parked(t); parked != 0 {
+		t.Fatalf("%d notifications parked, want none", parked)
+	}
+
+	// The next alert isn't sent to the deactivated chat.
+	p.alert(t, 1)
+
+	if calls := p.telegram.Calls("sendMessage"); len(calls) != 1 {
+		t.Fatalf("%d sendMessage calls, want 1", len(calls))
+	}
+}
+
+func TestPipelineRetract(t *testing.T) {
+	tests := []struct {
+		name     string
+		location bool
+		failures []telegramtest.Failure // failures - of the deleteMessage calls in order
+		edits    int                    // edits - editMessageText calls
+		status   entities.DeliveryStatus
+		kept     bool // kept - the alert message stays in the chat
+		parked   int64
+	}{
+		{name: "deleted", location: true, status: entities.DeliveryRetracted},
+		{
+			name:     "reply already gone",
+			location: true,
+			failures: []telegramtest.Failure{telegramtest.MessageNotFound},
+			status:   entities.DeliveryRetracted,
+		},
+		{
+			name:     "alert too old to delete",
+			failures: []telegramtest.Failure{telegramtest.MessageCantBeDeleted},
+			edits:    1,
+			status:   entities.DeliveryRetracted,
+			kept:     true,
+		},
+		{
+			name:     "outage after the reply",
+			location: true,
+			failures: []telegramtest.Failure{telegramtest.MessageNotFound, telegramtest.BadGateway},
+			status:   entities.DeliverySent,
+			kept:     true,
+			parked:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPipeline(t)
+
+			msg := p.message()
+			if tt.location {
+				msg.Location = &entities.Location{Latitude: 55.75, Longitude: 37.62}
+			}
+			p.consume(t, msg, 0)
+
+			p.telegram.FailNext("deleteMessage", tt.failures...)
+			msg.Kind = entities.KindRetract
+			p.consume(t, msg, 1)
+
+			if edits := p.telegram.Calls("editMessageText"); len(edits) != tt.edits {
+				t.Fatalf("%d editMessageText calls, want %d", len(edits), tt.edits)
+			}
+
+			deliveries := p.deliveries(t)
+			if len(deliveries) != 1 {
+				t.Fatalf("deliveries %+v, want one", deliveries)
+			}
+
+			// The reply is done with in every case, it isn't deleted again on a retry.
+			delivery := deliveries[0]
+			if delivery.Status != tt.status || (delivery.MessageID != 0) != tt.kept || delivery.LocationMessageID != 0 {
+				t.Fatalf("delivery %+v, want status %s with the alert kept %v and the reply gone", delivery, tt.status, tt.kept)
+			}
+
+			if parked := p.parked(t); parked != tt.parked {
+				t.Fatalf("%d notifications parked, want %d", parked, tt.parked)
+			}
+		})
+	}
+}
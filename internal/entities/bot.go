@@ -0,0 +1,15 @@
+package entities
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type Bot struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	TenantID string             `bson:"tenantID" json:"tenantID"`
+	Username string             `bson:"username" json:"username"`
+	Token    string             `bson:"token" json:"-"` // Token - encrypted, never leaves the service
+	// AdminChatID - the chat of the tenant admin, told when a chat stops accepting the alerts, zero for none
+	AdminChatID int64 `bson:"adminChatID,omitempty" json:"adminChatID,omitempty"`
+	// PollUpdates - follow the membership of the bot in chats with getUpdates, only for a bot without
+	// a webhook whose updates nothing else consumes
+	PollUpdates bool `bson:"pollUpdates,omitempty" json:"pollUpdates,omitempty"`
+}
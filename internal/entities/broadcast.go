@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BroadcastStatus is the stage of a broadcast job, done and canceled are final.
+type BroadcastStatus string
+
+const (
+	BroadcastPending  BroadcastStatus = "pending"
+	BroadcastRunning  BroadcastStatus = "running"
+	BroadcastDone     BroadcastStatus = "done"
+	BroadcastCanceled BroadcastStatus = "canceled"
+)
+
+// BroadcastTarget selects the chats of a broadcast, zero fields match every client.
+type BroadcastTarget struct {
+	TenantID string   `bson:"tenantID,omitempty" json:"tenantID,omitempty"` // TenantID - clients using one of the tenant bots
+	Region   string   `bson:"region,omitempty" json:"region,omitempty"`
+	Tags     []string `bson:"tags,omitempty" json:"tags,omitempty"` // Tags - clients carrying at least one of them
+}
+
+// BroadcastFailure is a chat the broadcast could not be delivered to.
+type BroadcastFailure struct {
+	ClientID string    `bson:"clientID" json:"clientID"`
+	ChatID   int64     `bson:"chatID" json:"chatID"`
+	Error    string    `bson:"error" json:"error"`
+	At       time.Time `bson:"at" json:"at"`
+}
+
+// Broadcast is an operator announcement sent to many chats by a background job. The job walks the clients
+// in ID order and stores the last one handled as Cursor, so that it resumes where it stopped after a restart.
+type Broadcast struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Text       string             `bson:"text" json:"text"`
+	Target     BroadcastTarget    `bson:"target" json:"target"`
+	Status     BroadcastStatus    `bson:"status" json:"status"`
+	Total      int                `bson:"total" json:"total"` // Total - the matching clients counted when the job started
+	Sent       int                `bson:"sent" json:"sent"`
+	Failed     int                `bson:"failed" json:"failed"`
+	Failures   []BroadcastFailure `bson:"failures" json:"failures"` // Failures - the first of the failed chats, Failed counts them all
+	Cursor     string             `bson:"cursor" json:"-"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"` // Error - the last error of the job itself, the job is retried
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
+	FinishedAt time.Time          `bson:"finishedAt,omitempty" json:"finishedAt"`
+}
+
+func (b Broadcast) Finished() bool {
+	return b.Status == BroadcastDone || b.Status == BroadcastCanceled
+}
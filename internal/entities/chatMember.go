@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// ChatMemberUpdate is a change of the bot membership in a chat, reported by Telegram as my_chat_member.
+type ChatMemberUpdate struct {
+	BotID  string
+	ChatID int64
+	Member bool   // Member - the bot can post to the chat again
+	Reason string // Reason - why the bot can't post anymore, empty for a member
+	At     time.Time
+}
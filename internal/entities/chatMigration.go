@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChatMigration records a group chat upgraded to a supergroup, which changes its chat ID. The accounts
+// registered with the old chat ID are moved to the new one.
+type ChatMigration struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	BotID      string             `json:"botID,omitempty" bson:"botID,omitempty"`
+	FromChatID int64              `json:"fromChatID" bson:"fromChatID"`
+	ToChatID   int64              `json:"toChatID" bson:"toChatID"`
+	ClientIDs  []string           `json:"clientIDs" bson:"clientIDs"` // ClientIDs - the accounts moved to the new chat
+	MigratedAt time.Time          `json:"migratedAt" bson:"migratedAt"`
+}
@@ -0,0 +1,187 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Cron is a parsed five field schedule: minute, hour, day of month, month and day of week. A field is
+// *, a value, a range a-b or a list of them, each optionally stepped with /n. Sunday is 0 or 7.
+// The shortcuts @daily, @weekly and @monthly are accepted too.
+type Cron struct {
+	Expr string
+
+	minutes, hours, days, months, weekdays uint64
+	// anyDay, anyWeekday - the field is *, a day matches when both are restricted and either matches
+	anyDay, anyWeekday bool
+}
+
+var _cronShortcuts = map[string]string{
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// _cronSearchLimit stops Next for the schedules that never fire, such as February 30.
+const _cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// _cronEveryHour is the hours field of a schedule that fires every hour.
+const _cronEveryHour = 1<<24 - 1
+
+func ParseCron(expr string) (Cron, error) {
+	cron := Cron{Expr: expr}
+
+	spec := strings.TrimSpace(expr)
+	if shortcut, ok := _cronShortcuts[spec]; ok {
+		spec = shortcut
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return cron, errors.Errorf("expected 5 fields or a shortcut, got %q", expr)
+	}
+
+	var err error
+	if cron.minutes, err = cronField(fields[0], 0, 59); err != nil {
+		return cron, errors.Wrap(err, "minute")
+	}
+
+	if cron.hours, err = cronField(fields[1], 0, 23); err != nil {
+		return cron, errors.Wrap(err, "hour")
+	}
+
+	if cron.days, err = cronField(fields[2], 1, 31); err != nil {
+		return cron, errors.Wrap(err, "day of month")
+	}
+
+	if cron.months, err = cronField(fields[3], 1, 12); err != nil {
+		return cron, errors.Wrap(err, "month")
+	}
+
+	if cron.weekdays, err = cronField(fields[4], 0, 7); err != nil {
+		return cron, errors.Wrap(err, "day of week")
+	}
+
+	// Sunday is both 0 and 7.
+	if cron.weekdays&(1<<7) != 0 {
+		cron.weekdays |= 1
+	}
+
+	cron.anyDay, cron.anyWeekday = fields[2] == "*", fields[4] == "*"
+
+	return cron, nil
+}
+
+// cronField returns the bits of the values the field matches.
+func cronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, errors.Errorf("bad step in %q", part)
+			}
+
+			part = part[:i]
+		}
+
+		from, to := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("bad range %q", part)
+			}
+
+			if to, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, errors.Errorf("bad range %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, errors.Errorf("bad value %q", part)
+			}
+
+			from, to = value, value
+			if step > 1 {
+				to = max
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, errors.Errorf("%q is out of %d-%d", part, min, max)
+		}
+
+		for value := from; value <= to; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func (c Cron) dayMatches(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Next returns the first time after the moment the schedule fires at, in the location of the moment.
+// It is zero for a schedule that never fires. A time the clock skips on a DST change doesn't fire that
+// day, a time the clock repeats fires once unless the schedule fires every hour.
+func (c Cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(_cronSearchLimit)
+
+	for t.Before(limit) {
+		switch {
+		case c.months&(1<<uint(t.Month())) == 0:
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location()))
+		case !c.dayMatches(t):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()))
+		case c.hours&(1<<uint(t.Hour())) == 0, c.hours != _cronEveryHour && repeatedHour(t):
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case c.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// forward returns next unless the clock skips it, time.Date puts a skipped midnight before the change.
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+// repeatedHour reports whether the clock shows the hour of t the second time, after it was set back.
+func repeatedHour(t time.Time) bool {
+	before := t.Add(-time.Hour)
+
+	return before.Hour() == t.Hour() && before.Day() == t.Day()
+}
@@ -0,0 +1,163 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronMalformed(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@hourly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+	} {
+		if cron, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): want error, got %+v", expr, cron)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  []time.Time // want - the next times, each found after the previous one
+	}{
+		{
+			name:  "every quarter of an hour",
+			expr:  "*/15 * * * *",
+			after: utc(time.May, 4, 10, 7),
+			want:  []time.Time{utc(time.May, 4, 10, 15), utc(time.May, 4, 10, 30), utc(time.May, 4, 10, 45), utc(time.May, 4, 11, 0)},
+		},
+		{
+			name:  "stepped range",
+			expr:  "5-20/5 9 * * *",
+			after: utc(time.May, 4, 9, 5),
+			want:  []time.Time{utc(time.May, 4, 9, 10), utc(time.May, 4, 9, 15), utc(time.May, 4, 9, 20), utc(time.May, 5, 9, 5)},
+		},
+		{
+			name:  "stepped value runs to the end of the field",
+			expr:  "0 18/3 * * *",
+			after: utc(time.May, 4, 12, 0),
+			want:  []time.Time{utc(time.May, 4, 18, 0), utc(time.May, 4, 21, 0), utc(time.May, 5, 18, 0)},
+		},
+		{
+			name:  "sunday as 0",
+			expr:  "0 12 * * 0",
+			after: utc(time.May, 4, 0, 0),
+			want:  []time.Time{utc(time.May, 10, 12, 0), utc(time.May, 17, 12, 0)},
+		},
+		{
+			name:  "sunday as 7",
+			expr:  "0 12 * * 7",
+			after: utc(time.May, 4, 0, 0),
+			want:  []time.Time{utc(time.May, 10, 12, 0), utc(time.May, 17, 12, 0)},
+		},
+		{
+			name:  "day of month or day of week",
+			expr:  "0 0 13 * 5",
+			after: utc(time.May, 7, 0, 0),
+			want:  []time.Time{utc(time.May, 8, 0, 0), utc(time.May, 13, 0, 0), utc(time.May, 15, 0, 0)},
+		},
+		{
+			name:  "monthly shortcut",
+			expr:  "@monthly",
+			after: utc(time.January, 31, 23, 59),
+			want:  []time.Time{utc(time.February, 1, 0, 0), utc(time.March, 1, 0, 0)},
+		},
+		{
+			name:  "31st skips the short months",
+			expr:  "0 0 31 * *",
+			after: utc(time.March, 31, 0, 0),
+			want:  []time.Time{utc(time.May, 31, 0, 0), utc(time.July, 31, 0, 0)},
+		},
+		{
+			name:  "29th of February",
+			expr:  "0 0 29 2 *",
+			after: utc(time.March, 1, 0, 0),
+			want:  []time.Time{time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:  "30th of February never fires",
+			expr:  "0 0 30 2 *",
+			after: utc(time.January, 1, 0, 0),
+			want:  []time.Time{{}},
+		},
+		{
+			name:  "skipped hour doesn't fire that day",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, time.March, 7, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.March, 9, 2, 30, 0, 0, newYork),
+				time.Date(2026, time.March, 10, 2, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name:  "hour after the skipped one",
+			expr:  "0 3 * * *",
+			after: time.Date(2026, time.March, 7, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.March, 8, 3, 0, 0, 0, newYork),
+				time.Date(2026, time.March, 9, 3, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name:  "repeated hour fires once",
+			expr:  "30 1 * * *",
+			after: time.Date(2026, time.October, 31, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.November, 1, 1, 30, 0, 0, newYork),
+				time.Date(2026, time.November, 2, 1, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name:  "hourly schedule fires in both repeated hours",
+			expr:  "0 * * * *",
+			after: time.Date(2026, time.November, 1, 0, 30, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, time.November, 1, 1, 0, 0, 0, newYork),
+				time.Date(2026, time.November, 1, 1, 0, 0, 0, newYork).Add(time.Hour),
+				time.Date(2026, time.November, 1, 2, 0, 0, 0, newYork),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cron, err := ParseCron(tc.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tc.expr, err)
+			}
+
+			after := tc.after
+			for i, want := range tc.want {
+				got := cron.Next(after)
+				if !got.Equal(want) {
+					t.Fatalf("Next #%d after %v: want %v, got %v", i+1, after, want, got)
+				}
+
+				after = got
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package entities
+
+import "time"
+
+// DeliveryStatus is what happened to a delivered alert after it was sent.
+type DeliveryStatus string
+
+const (
+	DeliverySent      DeliveryStatus = "sent"
+	DeliveryUpdated   DeliveryStatus = "updated"
+	DeliveryRetracted DeliveryStatus = "retracted"
+)
+
+// Delivery records the Telegram message an alert was delivered as, so that a later reclassification
+// of the detection can edit or delete it.
+type Delivery struct {
+	RequestID         string         `json:"requestID" bson:"requestID"`
+	ClientID          string         `json:"clientID" bson:"clientID"`
+	ChatID            int64          `json:"chatID" bson:"chatID"`
+	BotID             string         `json:"botID,omitempty" bson:"botID,omitempty"`
+	MessageID         int            `json:"messageID" bson:"messageID"`
+	LocationMessageID int            `json:"locationMessageID,omitempty" bson:"locationMessageID,omitempty"` // LocationMessageID - the reply with the sensor position, zero if none was sent
+	Text              string         `json:"text" bson:"text"`                                               // Text - the rendered alert as it was sent
+	Status            DeliveryStatus `json:"status" bson:"status"`
+	SentAt            time.Time      `json:"sentAt" bson:"sentAt"`
+	SensorID          string         `json:"sensorID,omitempty" bson:"sensorID,omitempty"` // SensorID - the sensor of the detection, empty if the alert named none
+	MessageType       string         `json:"messageType,omitempty" bson:"messageType,omitempty"`
+	// DetectedAt - the time of the detection, zero for the alerts that carried none
+	DetectedAt time.Time `json:"detectedAt" bson:"detectedAt,omitempty"`
+	// AudioMessageID, SpectrogramMessageID - the replies with the recording and its spectrogram, zero if none was sent
+	AudioMessageID       int `json:"audioMessageID,omitempty" bson:"audioMessageID,omitempty"`
+	SpectrogramMessageID int `json:"spectrogramMessageID,omitempty" bson:"spectrogramMessageID,omitempty"`
+	// PhotoMessageID - the reply with the photo the alert referenced in the media storage, zero if none was sent
+	PhotoMessageID int `json:"photoMessageID,omitempty" bson:"photoMessageID,omitempty"`
+	// AcknowledgedAt - when an operator confirmed seeing the alert, nil until then
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty" bson:"acknowledgedAt,omitempty"`
+}
+
+// DeliveryFilter selects deliveries, zero fields match everything. Deliveries are returned newest first.
+type DeliveryFilter struct {
+	RequestID string
+	ClientID  string
+	Since     time.Time // Since - inclusive
+	Until     time.Time // Until - exclusive
+	Limit     int
+}
+
+func (f DeliveryFilter) Matches(delivery Delivery) bool {
+	return (f.RequestID == "" || delivery.RequestID == f.RequestID) &&
+		(f.ClientID == "" || delivery.ClientID == f.ClientID) &&
+		(f.Since.IsZero() || !delivery.SentAt.Before(f.Since)) &&
+		(f.Until.IsZero() || delivery.SentAt.Before(f.Until))
+}
@@ -0,0 +1,181 @@
+package entities
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DigestPeriod is the span of history a digest summarizes.
+type DigestPeriod string
+
+const (
+	DigestDaily  DigestPeriod = "day"
+	DigestWeekly DigestPeriod = "week"
+)
+
+// Duration returns the length of the period, zero for an unknown one.
+func (p DigestPeriod) Duration() time.Duration {
+	switch p {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestSchedule makes the client get a summary of its detections instead of, or along with, the alerts.
+type DigestSchedule struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	ClientID string             `json:"clientID" bson:"clientID"`
+	Cron     string             `json:"cron" bson:"cron"`                             // Cron - when the digest is sent, see Cron
+	Timezone string             `json:"timezone,omitempty" bson:"timezone,omitempty"` // Timezone - the cron is in, UTC when empty
+	Period   DigestPeriod       `json:"period" bson:"period"`                         // Period - summarized by the digest, ending when it's sent
+	// NextRunAt - when the digest is sent next, set by the service
+	NextRunAt time.Time `json:"nextRunAt" bson:"nextRunAt"`
+	// LastRunAt - when the last digest was sent, zero before the first one
+	LastRunAt time.Time `json:"lastRunAt" bson:"lastRunAt"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+func (s DigestSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, errors.Errorf("unknown time zone %q", s.Timezone)
+	}
+
+	return location, nil
+}
+
+func (s DigestSchedule) Validate() error {
+	if s.Period.Duration() == 0 {
+		return errors.Errorf("period must be %q or %q, got %q", DigestDaily, DigestWeekly, s.Period)
+	}
+
+	if _, err := s.location(); err != nil {
+		return err
+	}
+
+	if _, err := ParseCron(s.Cron); err != nil {
+		return errors.Wrap(err, "cron")
+	}
+
+	return nil
+}
+
+// Next returns when the digest is sent after the moment, in UTC.
+func (s DigestSchedule) Next(after time.Time) (time.Time, error) {
+	location, err := s.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "cron")
+	}
+
+	next := cron.Next(after.In(location))
+	if next.IsZero() {
+		return next, errors.Errorf("cron %q never fires", s.Cron)
+	}
+
+	return next.UTC(), nil
+}
+
+// Digest summarizes the alerts delivered to a client from From to Until. An alert delivered to several
+// chats, through an organization or a zone, is counted once.
+type Digest struct {
+	ClientID string    `json:"clientID"`
+	From     time.Time `json:"from"`
+	Until    time.Time `json:"until"`
+	Timezone string    `json:"timezone,omitempty"` // Timezone - of the hours in ByHour, UTC when empty
+
+	Alerts       int            `json:"alerts"`
+	ByHour       [24]int        `json:"byHour"`   // ByHour - the alerts by the local hour they were sent at
+	BySensor     map[string]int `json:"bySensor"` // BySensor - the alerts by sensor ID, empty for the alerts without a sensor
+	Acknowledged int            `json:"acknowledged"`
+	// AckDelay - the median time from the alert to its acknowledgment
+	AckDelay     time.Duration `json:"ackDelay,omitempty"`
+	Reclassified int           `json:"reclassified"` // Reclassified - the alerts struck through by a follow-up
+	Retracted    int           `json:"retracted"`
+	// FalseAlarmRatio - the reclassified and retracted alerts out of all of them
+	FalseAlarmRatio float64 `json:"falseAlarmRatio"`
+}
+
+// NewDigest counts the deliveries, the hours are taken in the location.
+func NewDigest(clientID string, from, until time.Time, location *time.Location, deliveries []Delivery) Digest {
+	digest := Digest{ClientID: clientID, From: from, Until: until, BySensor: make(map[string]int)}
+	if location != time.UTC {
+		digest.Timezone = location.String()
+	}
+
+	alerts := make(map[string]Delivery, len(deliveries))
+	for _, delivery := range deliveries {
+		if seen, ok := alerts[delivery.RequestID]; ok {
+			delivery = mergeDeliveries(seen, delivery)
+		}
+
+		alerts[delivery.RequestID] = delivery
+	}
+
+	var delays []time.Duration
+	for _, alert := range alerts {
+		digest.Alerts++
+		digest.ByHour[alert.SentAt.In(location).Hour()]++
+		digest.BySensor[alert.SensorID]++
+
+		if alert.AcknowledgedAt != nil {
+			digest.Acknowledged++
+			delays = append(delays, alert.AcknowledgedAt.Sub(alert.SentAt))
+		}
+
+		switch alert.Status {
+		case DeliveryUpdated:
+			digest.Reclassified++
+		case DeliveryRetracted:
+			digest.Retracted++
+		}
+	}
+
+	if len(delays) != 0 {
+		sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+		digest.AckDelay = delays[len(delays)/2]
+	}
+
+	if digest.Alerts != 0 {
+		digest.FalseAlarmRatio = float64(digest.Reclassified+digest.Retracted) / float64(digest.Alerts)
+	}
+
+	return digest
+}
+
+// mergeDeliveries makes one alert of the deliveries of a request to several chats: it was sent with the first
+// of them, acknowledged with the first acknowledgment and revised when any of them was.
+func mergeDeliveries(a, b Delivery) Delivery {
+	if b.SentAt.Before(a.SentAt) {
+		a.SentAt = b.SentAt
+	}
+
+	if b.AcknowledgedAt != nil && (a.AcknowledgedAt == nil || b.AcknowledgedAt.Before(*a.AcknowledgedAt)) {
+		a.AcknowledgedAt = b.AcknowledgedAt
+	}
+
+	if a.Status == DeliverySent || b.Status == DeliveryRetracted {
+		a.Status = b.Status
+	}
+
+	if a.SensorID == "" {
+		a.SensorID = b.SensorID
+	}
+
+	return a
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "github.com/pkg/errors"
+
+// The errors of the storage, every repository implementation wraps them so the use cases and the
+// controllers don't depend on a concrete one.
+var (
+	ErrRecordExists   = errors.New("error record exists")
+	ErrRecordNotFound = errors.New("error record not found")
+	ErrRecordInUse    = errors.New("error record is in use")
+)
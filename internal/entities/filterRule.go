@@ -0,0 +1,302 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FilterRule is a parsed rule expression of a subscription, an alert is sent to the chat only when it meets
+// every rule of the subscription. The expressions are:
+//
+//	confidence >= 0.8                          - also >, <=, <, =; a message without a confidence has 0
+//	messageType in (audio, photo)              - or "not in"
+//	tags in (perimeter, gate)                  - the sensor has one of the tags, or none of them with "not in"
+//	time between 22:00 and 06:00 Europe/Moscow - or "not between", the zone defaults to UTC
+//
+// The rules are parsed once when they are set and stored next to the expressions.
+type FilterRule struct {
+	Expr   string   `bson:"expr" json:"expr"`
+	Field  string   `bson:"field" json:"field"`
+	Negate bool     `bson:"negate,omitempty" json:"negate,omitempty"`
+	Op     string   `bson:"op,omitempty" json:"op,omitempty"`
+	Number float64  `bson:"number,omitempty" json:"number,omitempty"`
+	Values []string `bson:"values,omitempty" json:"values,omitempty"`
+	// From, To - the window in minutes of the day, To is exclusive
+	From int    `bson:"from,omitempty" json:"from,omitempty"`
+	To   int    `bson:"to,omitempty" json:"to,omitempty"`
+	Zone string `bson:"zone,omitempty" json:"zone,omitempty"` // Zone - of the window, empty for UTC
+}
+
+const (
+	_ruleConfidence  = "confidence"
+	_ruleMessageType = "messageType"
+	_ruleTags        = "tags"
+	_ruleTime        = "time"
+)
+
+func ParseFilterRule(expr string) (FilterRule, error) {
+	rule := FilterRule{Expr: expr}
+
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return rule, errors.New("the rule is empty")
+	}
+
+	rule.Field = fields[0]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(expr), rule.Field))
+
+	switch rule.Field {
+	case _ruleConfidence:
+		return rule, rule.parseConfidence(fields[1:])
+	case _ruleMessageType, _ruleTags:
+		return rule, rule.parseList(rest)
+	case _ruleTime:
+		return rule, rule.parseWindow(fields[1:])
+	default:
+		return rule, errors.Errorf("unknown field %q, expected confidence, messageType, tags or time", rule.Field)
+	}
+}
+
+func (r *FilterRule) parseConfidence(args []string) error {
+	if len(args) != 2 {
+		return errors.New("expected confidence <operator> <number>")
+	}
+
+	switch args[0] {
+	case ">=", ">", "<=", "<", "=":
+		r.Op = args[0]
+	default:
+		return errors.Errorf("unknown operator %q, expected >=, >, <=, < or =", args[0])
+	}
+
+	number, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || number < 0 || number > 1 {
+		return errors.Errorf("the confidence %q is not a number from 0 to 1", args[1])
+	}
+
+	r.Number = number
+
+	return nil
+}
+
+func (r *FilterRule) parseList(rest string) error {
+	if strings.HasPrefix(rest, "not ") {
+		r.Negate = true
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "not "))
+	}
+
+	if !strings.HasPrefix(rest, "in") {
+		return errors.Errorf("expected %s [not] in (value, ...)", r.Field)
+	}
+
+	list := strings.TrimSpace(strings.TrimPrefix(rest, "in"))
+	if !strings.HasPrefix(list, "(") || !strings.HasSuffix(list, ")") {
+		return errors.New("the values must be in parentheses")
+	}
+
+	for _, value := range strings.Split(list[1:len(list)-1], ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return errors.New("the list has an empty value")
+		}
+
+		r.Values = append(r.Values, value)
+	}
+
+	return nil
+}
+
+func (r *FilterRule) parseWindow(args []string) error {
+	if len(args) != 0 && args[0] == "not" {
+		r.Negate = true
+		args = args[1:]
+	}
+
+	if len(args) < 4 || len(args) > 5 || args[0] != "between" || args[2] != "and" {
+		return errors.New("expected time [not] between HH:MM and HH:MM [zone]")
+	}
+
+	var err error
+	if r.From, err = minuteOfDay(args[1]); err != nil {
+		return err
+	}
+
+	if r.To, err = minuteOfDay(args[3]); err != nil {
+		return err
+	}
+
+	if r.From == r.To {
+		return errors.New("the window is empty")
+	}
+
+	if len(args) == 5 {
+		if _, err = loadLocation(args[4]); err != nil {
+			return errors.Errorf("unknown time zone %q", args[4])
+		}
+
+		r.Zone = args[4]
+	}
+
+	return nil
+}
+
+// _locations caches the time zones of the windows, time.LoadLocation reads the zone database on every call.
+var _locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if location, ok := _locations.Load(name); ok {
+		return location.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	_locations.Store(name, location)
+
+	return location, nil
+}
+
+func minuteOfDay(clock string) (int, error) {
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, errors.Errorf("the time %q is not HH:MM", clock)
+	}
+
+	return at.Hour()*60 + at.Minute(), nil
+}
+
+// Matches reports whether the message meets the rule, the time windows apply to the moment of the detection.
+func (r FilterRule) Matches(msg NotificationMessage) bool {
+	switch r.Field {
+	case _ruleConfidence:
+		return r.compare(msg.Confidence)
+	case _ruleMessageType:
+		return r.Negate != containsFold(r.Values, msg.MessageType)
+	case _ruleTags:
+		has := false
+		for _, tag := range msg.SensorTags {
+			if containsFold(r.Values, tag) {
+				has = true
+				break
+			}
+		}
+
+		return r.Negate != has
+	case _ruleTime:
+		location, err := loadLocation(r.Zone)
+		if err != nil {
+			location = time.UTC
+		}
+
+		local := msg.Timestamp.In(location)
+		minute := local.Hour()*60 + local.Minute()
+
+		inside := minute >= r.From && minute < r.To
+		if r.From > r.To {
+			// The window spans midnight.
+			inside = minute >= r.From || minute < r.To
+		}
+
+		return r.Negate != inside
+	default:
+		return true
+	}
+}
+
+func (r FilterRule) compare(value float64) bool {
+	switch r.Op {
+	case ">=":
+		return value >= r.Number
+	case ">":
+		return value > r.Number
+	case "<=":
+		return value <= r.Number
+	case "<":
+		return value < r.Number
+	default:
+		return value == r.Number
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RuleError is a rule expression that doesn't parse.
+type RuleError struct {
+	Index int    `json:"index"`
+	Rule  string `json:"rule"`
+	Error string `json:"error"`
+}
+
+// ParseFilterRules parses the rule expressions, it returns the rules that parse and an error for every
+// rule that doesn't.
+func ParseFilterRules(rules []string) ([]FilterRule, []RuleError) {
+	var (
+		parsed     []FilterRule
+		ruleErrors []RuleError
+	)
+
+	for i, expr := range rules {
+		rule, err := ParseFilterRule(expr)
+		if err != nil {
+			ruleErrors = append(ruleErrors, RuleError{Index: i, Rule: expr, Error: err.Error()})
+			continue
+		}
+
+		parsed = append(parsed, rule)
+	}
+
+	return parsed, ruleErrors
+}
+
+// ValidateFilterRules returns an error for every rule that doesn't parse.
+func ValidateFilterRules(rules []string) []RuleError {
+	_, ruleErrors := ParseFilterRules(rules)
+
+	return ruleErrors
+}
+
+// storedFilters returns the parsed rules stored with the expressions. The records stored before the
+// parsed rules were kept have the expressions only, they are parsed then.
+func storedFilters(rules []string, filters []FilterRule) []FilterRule {
+	if len(filters) != 0 || len(rules) == 0 {
+		return filters
+	}
+
+	parsed, _ := ParseFilterRules(rules)
+
+	return parsed
+}
+
+// SuppressingRule returns the expression of the first rule the message doesn't meet, ok is false when
+// the message meets them all.
+func SuppressingRule(rules []FilterRule, msg NotificationMessage) (rule string, ok bool) {
+	for _, parsed := range rules {
+		if !parsed.Matches(msg) {
+			return parsed.Expr, true
+		}
+	}
+
+	return "", false
+}
+
+// RuleCheck is the outcome of checking rule expressions, and of applying them to a sample message when
+// one is given.
+type RuleCheck struct {
+	Valid        bool        `json:"valid"`
+	Errors       []RuleError `json:"errors,omitempty"`
+	SuppressedBy string      `json:"suppressedBy,omitempty"` // SuppressedBy - the rule the sample message fails
+}
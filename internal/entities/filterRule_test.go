@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFilterRuleMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: "  "},
+		{name: "unknown field", expr: "loudness >= 3"},
+		{name: "confidence without a number", expr: "confidence >="},
+		{name: "unknown operator", expr: "confidence != 0.5"},
+		{name: "confidence above 1", expr: "confidence >= 1.5"},
+		{name: "confidence not a number", expr: "confidence >= high"},
+		{name: "list without in", expr: "messageType (audio)"},
+		{name: "list without parentheses", expr: "messageType in audio, photo"},
+		{name: "list with an empty value", expr: "tags in (gate, )"},
+		{name: "window without and", expr: "time between 22:00 06:00"},
+		{name: "window with a bad clock", expr: "time between 25:00 and 06:00"},
+		{name: "empty window", expr: "time between 06:00 and 06:00"},
+		{name: "unknown zone", expr: "time between 22:00 and 06:00 Mars/Olympus"},
+		{name: "trailing words", expr: "time between 22:00 and 06:00 UTC today"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if rule, err := ParseFilterRule(tc.expr); err == nil {
+				t.Fatalf("ParseFilterRule(%q): want error, got %+v", tc.expr, rule)
+			}
+		})
+	}
+}
+
+func TestFilterRuleMatches(t *testing.T) {
+	at := func(clock string) time.Time {
+		ts, err := time.Parse(time.RFC3339, "2026-03-14T"+clock+":00Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ts
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		msg  NotificationMessage
+		want bool
+	}{
+		{name: "confidence above", expr: "confidence >= 0.8", msg: NotificationMessage{Confidence: 0.9}, want: true},
+		{name: "confidence at the bound", expr: "confidence > 0.8", msg: NotificationMessage{Confidence: 0.8}},
+		{name: "no confidence", expr: "confidence >= 0.1", msg: NotificationMessage{}},
+		{name: "type ignores case", expr: "messageType in (audio, photo)", msg: NotificationMessage{MessageType: "Photo"}, want: true},
+		{name: "type excluded", expr: "messageType not in (text)", msg: NotificationMessage{MessageType: "text"}},
+		{name: "one of the tags", expr: "tags in (gate, perimeter)", msg: NotificationMessage{SensorTags: []string{"roof", "gate"}}, want: true},
+		{name: "none of the tags", expr: "tags not in (indoor)", msg: NotificationMessage{SensorTags: []string{"roof"}}, want: true},
+		{name: "no tags", expr: "tags in (gate)", msg: NotificationMessage{}},
+		{name: "inside a day window", expr: "time between 09:00 and 18:00", msg: NotificationMessage{Timestamp: at("09:00")}, want: true},
+		{name: "end of a day window", expr: "time between 09:00 and 18:00", msg: NotificationMessage{Timestamp: at("18:00")}},
+		{name: "night window before midnight", expr: "time between 22:00 and 06:00", msg: NotificationMessage{Timestamp: at("23:30")}, want: true},
+		{name: "night window after midnight", expr: "time between 22:00 and 06:00", msg: NotificationMessage{Timestamp: at("05:59")}, want: true},
+		{name: "end of a night window", expr: "time between 22:00 and 06:00", msg: NotificationMessage{Timestamp: at("06:00")}},
+		{name: "outside a night window", expr: "time between 22:00 and 06:00", msg: NotificationMessage{Timestamp: at("12:00")}},
+		{name: "outside a negated night window", expr: "time not between 22:00 and 06:00", msg: NotificationMessage{Timestamp: at("12:00")}, want: true},
+		{name: "inside a negated night window", expr: "time not between 22:00 and 06:00", msg: NotificationMessage{Timestamp: at("00:00")}},
+		// 06:30 UTC is 09:30 in Moscow, 15:30 UTC is 18:30.
+		{name: "zone of the window", expr: "time between 09:00 and 18:00 Europe/Moscow", msg: NotificationMessage{Timestamp: at("06:30")}, want: true},
+		{name: "outside in the zone", expr: "time between 09:00 and 18:00 Europe/Moscow", msg: NotificationMessage{Timestamp: at("15:30")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseFilterRule(tc.expr)
+			if err != nil {
+				t.Fatalf("ParseFilterRule(%q): %v", tc.expr, err)
+			}
+
+			if got := rule.Matches(tc.msg); got != tc.want {
+				t.Fatalf("Matches: want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSuppressingRule(t *testing.T) {
+	rules, ruleErrors := ParseFilterRules([]string{"confidence >= 0.5", "bogus", "time between 22:00 and 06:00"})
+	if len(rules) != 2 || len(ruleErrors) != 1 || ruleErrors[0].Index != 1 {
+		t.Fatalf("ParseFilterRules: got %+v, %+v", rules, ruleErrors)
+	}
+
+	// A parked alert retried in the morning is filtered by the time it was detected at.
+	night := time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)
+	if rule, ok := SuppressingRule(rules, NotificationMessage{Confidence: 0.7, Timestamp: night}); ok {
+		t.Fatalf("SuppressingRule of a night alert: suppressed by %q", rule)
+	}
+
+	rule, ok := SuppressingRule(rules, NotificationMessage{Confidence: 0.7, Timestamp: night.Add(12 * time.Hour)})
+	if !ok || rule != "time between 22:00 and 06:00" {
+		t.Fatalf("SuppressingRule of a day alert: got %q, %v", rule, ok)
+	}
+}
+
+func TestStoredFilters(t *testing.T) {
+	// A record stored before the parsed rules were kept has the expressions only.
+	legacy := TGAccount{Rules: []string{"confidence >= 0.5"}}
+	if filters := legacy.FilterRules(); len(filters) != 1 || filters[0].Expr != "confidence >= 0.5" {
+		t.Fatalf("FilterRules of a legacy account: got %+v", filters)
+	}
+
+	stored := TGAccount{Rules: []string{"confidence >= 0.5"}, Filters: []FilterRule{{Expr: "stored"}}}
+	if filters := stored.FilterRules(); len(filters) != 1 || filters[0].Expr != "stored" {
+		t.Fatalf("FilterRules: want the stored rules, got %+v", filters)
+	}
+}
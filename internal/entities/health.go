@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+// BreakerState is the state of the circuit breaker around the Telegram API.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+type BreakerStatus struct {
+	State    BreakerState `json:"state"`
+	Failures int          `json:"failures"` // Failures - consecutive failed calls
+	OpenedAt time.Time    `json:"openedAt"` // OpenedAt - zero while closed
+}
+
+// Health is the state of the dependencies, the service is degraded while Telegram is unreachable.
+type Health struct {
+	Status   string        `json:"status"` // Status - ok or degraded
+	Telegram BreakerStatus `json:"telegram"`
+	Parked   int64         `json:"parked"`          // Parked - notifications waiting for Telegram
+	Fallback bool          `json:"fallback"`        // Fallback - whether alerts go to the fallback channel while Telegram is down
+	Error    string        `json:"error,omitempty"` // Error - why the health couldn't be fully checked
+}
+
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
@@ -0,0 +1,50 @@
+package entities
+
+// ImportStatus is the outcome of a single row of a bulk import.
+type ImportStatus string
+
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportUpdated   ImportStatus = "updated"
+	ImportUnchanged ImportStatus = "unchanged"
+	ImportInvalid   ImportStatus = "invalid"
+)
+
+// ImportRow is a registration read from an import file or stream, Err is set when the row can't be parsed.
+type ImportRow struct {
+	Line    int
+	Account TGAccount
+	Err     error
+}
+
+type ImportResult struct {
+	Line     int          `json:"line"`
+	ClientID string       `json:"clientID,omitempty"`
+	Status   ImportStatus `json:"status"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// ImportReport sums up a bulk import. In a dry run nothing is written and the statuses tell what would happen.
+type ImportReport struct {
+	DryRun    bool           `json:"dryRun"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Invalid   int            `json:"invalid"`
+	Rows      []ImportResult `json:"rows"`
+}
+
+func (r *ImportReport) Add(result ImportResult) {
+	switch result.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportUnchanged:
+		r.Unchanged++
+	case ImportInvalid:
+		r.Invalid++
+	}
+
+	r.Rows = append(r.Rows, result)
+}
@@ -0,0 +1,10 @@
+package entities
+
+// MediaObject references the recording or the photo of an alert kept in the object storage, the messages
+// would be too large with it embedded.
+type MediaObject struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size,omitempty"`   // Size - bytes, zero when the producer didn't tell
+	SHA256      string `json:"sha256,omitempty"` // SHA256 - hex digest of the content, empty when the producer didn't tell
+	ContentType string `json:"contentType,omitempty"`
+}
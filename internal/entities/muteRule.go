@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// MuteRule suppresses notifications matching the client and message type until the given moment.
+// Empty fields match any value, zero Until means the rule never expires.
+type MuteRule struct {
+	ClientID    string    `yaml:"clientID" json:"clientID"`
+	MessageType string    `yaml:"messageType" json:"messageType"`
+	Until       time.Time `yaml:"until" json:"until"`
+}
+
+func (m MuteRule) Matches(msg NotificationMessage, now time.Time) bool {
+	if m.ClientID != "" && m.ClientID != msg.ClientID {
+		return false
+	}
+
+	if m.MessageType != "" && m.MessageType != msg.MessageType {
+		return false
+	}
+
+	return m.Until.IsZero() || now.Before(m.Until)
+}
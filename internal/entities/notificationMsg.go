@@ -1,15 +1,63 @@
 package entities
 
 import (
-	"github.com/google/uuid"
+	"fmt"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageKind tells a new alert apart from the follow-ups revising an alert that was already sent.
+type MessageKind string
+
+const (
+	KindAlert   MessageKind = "alert"
+	KindUpdate  MessageKind = "update"  // KindUpdate - the detection was reclassified, the alert is struck through
+	KindRetract MessageKind = "retract" // KindRetract - the alert is deleted from the chats
 )
 
 type NotificationMessage struct {
 	NotificationMethods []string `json:"notificationMethods"` // NotificationMethods - for example: telegram, vk, etc
-	//Payload             []byte    `json:"payload"`
-	Timestamp   time.Time `json:"ts"`
-	RequestID   uuid.UUID `json:"requestID"`
-	ClientID    string    `json:"clientID"`
-	MessageType string    `json:"messageType"` // MessageType - audio or photo, text
+	// Payload - the recording of an audio message, WAV or raw 16-bit little-endian mono PCM
+	Payload    []byte `json:"payload,omitempty"`
+	SampleRate int    `json:"sampleRate,omitempty"` // SampleRate - Hz of a raw PCM payload, WAV carries its own
+	// Media - the recording or the photo in the object storage, sent as audio or photo by MessageType
+	Media       *MediaObject `json:"media,omitempty"`
+	Timestamp   time.Time    `json:"ts"`
+	RequestID   uuid.UUID    `json:"requestID"`
+	ClientID    string       `json:"clientID"`
+	SensorID    string       `json:"sensorID,omitempty"` // SensorID - resolved to the owning clients through the sensor registry
+	MessageType string       `json:"messageType"`        // MessageType - audio or photo, text
+	Location    *Location    `json:"location,omitempty"`
+	SensorName  string       `json:"sensorName,omitempty"`
+	SensorTags  []string     `json:"sensorTags,omitempty"` // SensorTags - filled from the sensor registry
+	Address     string       `json:"address,omitempty"`
+	Confidence  float64      `json:"confidence,omitempty"` // Confidence - of the detection from 0 to 1, zero when not reported
+	Kind        MessageKind  `json:"type,omitempty"`       // Kind - empty for a new alert, follow-ups refer to it by RequestID
+	Reason      string       `json:"reason,omitempty"`     // Reason - shown next to the struck through alert, e.g. "firework"
+	Test        bool         `json:"test,omitempty"`       // Test - a test alert requested by an installer, it is labelled as such
+	// OrganizationID - set on the copy of a site alert going to the chat of the site organization
+	OrganizationID string `json:"organizationID,omitempty"`
+	// ZoneSubscriptionID - set on the copy of an alert going to a chat subscribed to the zone of the detection
+	ZoneSubscriptionID string `json:"zoneSubscriptionID,omitempty"`
+}
+
+// IsFollowUp reports whether the message revises an earlier alert instead of raising a new one.
+func (m NotificationMessage) IsFollowUp() bool {
+	return m.Kind == KindUpdate || m.Kind == KindRetract
+}
+
+// Location is the position of the sensor that detected the event.
+type Location struct {
+	Latitude  float64 `json:"latitude" bson:"latitude"`
+	Longitude float64 `json:"longitude" bson:"longitude"`
+}
+
+func (l Location) Valid() bool {
+	return l.Latitude >= -90 && l.Latitude <= 90 && l.Longitude >= -180 && l.Longitude <= 180
+}
+
+// MapLink returns a link that opens the coordinates in a map application.
+func (l Location) MapLink() string {
+	return fmt.Sprintf("https://www.google.com/maps/search/?api=1&query=%.6f,%.6f", l.Latitude, l.Longitude)
 }
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Organization is a company owning many sites, each site is a client account. The organization chat gets
+// the alerts of all its sites, and its settings are the defaults of the sites that leave them empty.
+type Organization struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	TenantID string             `bson:"tenantID" json:"tenantID"`
+	Name     string             `bson:"name" json:"name"`
+	ChatID   int64              `bson:"chatID,omitempty" json:"chatID,omitempty"`     // ChatID - gets the alerts of every site, zero for none
+	ThreadID int                `bson:"threadID,omitempty" json:"threadID,omitempty"` // ThreadID - the forum topic of the chat, zero for none
+	BotID    string             `bson:"botID,omitempty" json:"botID,omitempty"`       // BotID - empty for the default bot
+	Region   string             `bson:"region,omitempty" json:"region,omitempty"`
+	Tags     []string           `bson:"tags,omitempty" json:"tags,omitempty"`
+	// CreatedAt - set by the service
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// Inherit returns the site with the organization defaults in place of its empty settings. A site without
+// a chat of its own posts into the organization chat, in its own topic if it has one.
+func (o Organization) Inherit(site TGAccount) TGAccount {
+	if site.ChatID == 0 {
+		site.ChatID = o.ChatID
+		if site.ThreadID == 0 {
+			site.ThreadID = o.ThreadID
+		}
+	}
+
+	if site.BotID == "" {
+		site.BotID = o.BotID
+	}
+
+	if site.Region == "" {
+		site.Region = o.Region
+	}
+
+	if len(site.Tags) == 0 {
+		site.Tags = o.Tags
+	}
+
+	return site
+}
+
+// Account returns the organization chat as an account, the alerts of the sites are copied to it.
+func (o Organization) Account() TGAccount {
+	return TGAccount{ChatID: o.ChatID, ThreadID: o.ThreadID, BotID: o.BotID, Region: o.Region, Tags: o.Tags}
+}
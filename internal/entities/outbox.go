@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Types of the outbox events, a delivery event is "delivery." followed by the delivery status.
+const (
+	EventClientCreated     = "client.created"
+	EventClientUpdated     = "client.updated"
+	EventClientDeleted     = "client.deleted"
+	EventChatMigrated      = "client.chat_migrated"
+	EventClientDeactivated = "client.deactivated"
+	EventClientReactivated = "client.reactivated"
+	EventDeliverySent      = "delivery." + string(DeliverySent)
+	EventDeliveryUpdated   = "delivery." + string(DeliveryUpdated)
+	EventDeliveryRetracted = "delivery." + string(DeliveryRetracted)
+	EventDeliveryFailed    = "delivery.failed"
+)
+
+// OutboxEvent is a change event stored with the change it describes and published to Kafka afterwards.
+// Seq is assigned by the repository in commit order, the events of a key are published in that order.
+// The Mongo repository numbers the events of every key on their own, the others number all the events.
+type OutboxEvent struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"` // ID - set by the Mongo repository
+	Seq         int64              `bson:"seq" json:"seq"`
+	Type        string             `bson:"type" json:"type"`
+	Key         string             `bson:"key" json:"key"`         // Key - the Kafka message key, the client ID
+	Payload     []byte             `bson:"payload" json:"payload"` // Payload - JSON of the changed entity
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+	PublishedAt time.Time          `bson:"publishedAt,omitempty" json:"publishedAt"` // PublishedAt - zero until the relay published it
+}
+
+func NewOutboxEvent(eventType, key string, payload interface{}) (OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return OutboxEvent{Type: eventType, Key: key, Payload: data, CreatedAt: time.Now().UTC()}, nil
+}
+
+// DeliveryFailure is the payload of EventDeliveryFailed.
+type DeliveryFailure struct {
+	RequestID string `json:"requestID"`
+	ClientID  string `json:"clientID"`
+	ChatID    int64  `json:"chatID"`
+	Error     string `json:"error"`
+}
+
+// ChatStatusChange is the payload of EventClientDeactivated and EventClientReactivated.
+type ChatStatusChange struct {
+	BotID     string    `json:"botID,omitempty"`
+	ChatID    int64     `json:"chatID"`
+	ClientIDs []string  `json:"clientIDs"`        // ClientIDs - the accounts of the chat that changed
+	Reason    string    `json:"reason,omitempty"` // Reason - empty for a reactivation
+	At        time.Time `json:"at"`
+}
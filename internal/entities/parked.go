@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ParkedNotification is a notification that couldn't be sent because Telegram was unavailable,
+// it is retried until it's sent or older than the retry max age.
+type ParkedNotification struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	Message     NotificationMessage `json:"message" bson:"message"` // Message - addressed to a single client, or a follow-up
+	Attempts    int                 `json:"attempts" bson:"attempts"`
+	LastError   string              `json:"lastError" bson:"lastError"`
+	NextAttempt time.Time           `json:"nextAttempt" bson:"nextAttempt"`
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package entities
+
+// Sensor is a detector registered by an upstream producer, alerts carrying its ID go to the owning clients.
+type Sensor struct {
+	ID        string    `bson:"_id" json:"id"`
+	Name      string    `bson:"name" json:"name"`
+	Location  *Location `bson:"location,omitempty" json:"location,omitempty"`
+	Address   string    `bson:"address,omitempty" json:"address,omitempty"`
+	ClientIDs []string  `bson:"clientIDs" json:"clientIDs"`
+	Enabled   bool      `bson:"enabled" json:"enabled"`
+	Tags      []string  `bson:"tags" json:"tags"`
+}
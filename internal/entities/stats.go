@@ -0,0 +1,158 @@
+package entities
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DeliveryStatsFilter selects the deliveries aggregated into DeliveryStats.
+type DeliveryStatsFilter struct {
+	ClientIDs  []string  // ClientIDs - the deliveries of one of the clients, empty for all of them
+	Since      time.Time // Since - inclusive, zero for no bound
+	Until      time.Time // Until - exclusive, zero for no bound
+	TopSensors int       // TopSensors - how many of the noisiest sensors to return
+}
+
+func (f DeliveryStatsFilter) Matches(delivery Delivery) bool {
+	return (len(f.ClientIDs) == 0 || containsString(f.ClientIDs, delivery.ClientID)) &&
+		(f.Since.IsZero() || !delivery.SentAt.Before(f.Since)) &&
+		(f.Until.IsZero() || delivery.SentAt.Before(f.Until))
+}
+
+// Percentiles of durations, nearest rank: a percentile is the smallest duration that many percent of all
+// are no longer than. They are zero when there are no durations.
+type Percentiles struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// NewPercentiles takes the durations sorted in ascending order.
+func NewPercentiles(sorted []time.Duration) Percentiles {
+	if len(sorted) == 0 {
+		return Percentiles{}
+	}
+
+	return Percentiles{
+		P50: sorted[percentileRank(0.5, len(sorted))],
+		P90: sorted[percentileRank(0.9, len(sorted))],
+		P99: sorted[percentileRank(0.99, len(sorted))],
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentileRank returns the index of the p percentile, 0 < p <= 1, among n > 0 sorted values.
+func percentileRank(p float64, n int) int {
+	rank := int(math.Ceil(p*float64(n))) - 1
+	if rank < 0 {
+		return 0
+	}
+
+	return rank
+}
+
+// SensorCount is the number of alerts a sensor raised.
+type SensorCount struct {
+	SensorID string `json:"sensorID"`
+	Alerts   int64  `json:"alerts"`
+}
+
+// DeliveryStats aggregates the delivery history. An alert delivered to several chats is one alert
+// but as many deliveries.
+type DeliveryStats struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+
+	Deliveries int64                    `json:"deliveries"`
+	Alerts     int64                    `json:"alerts"`
+	ByStatus   map[DeliveryStatus]int64 `json:"byStatus"` // ByStatus - the deliveries by what happened to them after sending
+	ByType     map[string]int64         `json:"byType"`   // ByType - the deliveries by message type, empty when it wasn't recorded
+	// Latency - from the detection to the sent message, of the deliveries whose alert had a timestamp
+	Latency      Percentiles `json:"latency"`
+	Acknowledged int64       `json:"acknowledged"`
+	AckTime      Percentiles `json:"ackTime"` // AckTime - from the sent message to its acknowledgment
+	// TopSensors - the sensors that raised the most alerts, most first
+	TopSensors []SensorCount `json:"topSensors"`
+}
+
+// NewDeliveryStats aggregates the deliveries matching the filter.
+func NewDeliveryStats(filter DeliveryStatsFilter, deliveries []Delivery) DeliveryStats {
+	stats := DeliveryStats{
+		Since:      filter.Since,
+		Until:      filter.Until,
+		ByStatus:   make(map[DeliveryStatus]int64),
+		ByType:     make(map[string]int64),
+		TopSensors: make([]SensorCount, 0),
+	}
+
+	var (
+		alerts    = make(map[string]bool)
+		sensors   = make(map[string]map[string]bool)
+		latencies []time.Duration
+		ackTimes  []time.Duration
+	)
+
+	for _, delivery := range deliveries {
+		if !filter.Matches(delivery) {
+			continue
+		}
+
+		stats.Deliveries++
+		stats.ByStatus[delivery.Status]++
+		stats.ByType[delivery.MessageType]++
+		alerts[delivery.RequestID] = true
+
+		if !delivery.DetectedAt.IsZero() {
+			latencies = append(latencies, delivery.SentAt.Sub(delivery.DetectedAt))
+		}
+
+		if delivery.AcknowledgedAt != nil {
+			stats.Acknowledged++
+			ackTimes = append(ackTimes, delivery.AcknowledgedAt.Sub(delivery.SentAt))
+		}
+
+		if delivery.SensorID != "" {
+			if sensors[delivery.SensorID] == nil {
+				sensors[delivery.SensorID] = make(map[string]bool)
+			}
+
+			sensors[delivery.SensorID][delivery.RequestID] = true
+		}
+	}
+
+	stats.Alerts = int64(len(alerts))
+
+	sortDurations(latencies)
+	stats.Latency = NewPercentiles(latencies)
+
+	sortDurations(ackTimes)
+	stats.AckTime = NewPercentiles(ackTimes)
+
+	for sensorID, requests := range sensors {
+		stats.TopSensors = append(stats.TopSensors, SensorCount{SensorID: sensorID, Alerts: int64(len(requests))})
+	}
+
+	sortSensorCounts(stats.TopSensors)
+	if len(stats.TopSensors) > filter.TopSensors {
+		stats.TopSensors = stats.TopSensors[:filter.TopSensors]
+	}
+
+	return stats
+}
+
+// sortSensorCounts orders the sensors by the alerts, most first, then by ID.
+func sortSensorCounts(counts []SensorCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Alerts != counts[j].Alerts {
+			return counts[i].Alerts > counts[j].Alerts
+		}
+
+		return counts[i].SensorID < counts[j].SensorID
+	})
+}
+
+func sortDurations(durations []time.Duration) {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+}
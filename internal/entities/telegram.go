@@ -0,0 +1,32 @@
+package entities
+
+import "github.com/pkg/errors"
+
+// ErrUnavailable is matched by the errors of calls that didn't reach Telegram: the breaker was open,
+// the network failed, the Bot API answered with a server error or rate limited the bot. Such a call can
+// be retried later.
+var ErrUnavailable = errors.New("error telegram is unavailable")
+
+// ErrUnreachable is matched by the errors of sends the chat will keep rejecting until somebody acts on it:
+// the bot was blocked or removed from the chat, or the chat is gone. Retrying such a send is pointless.
+var ErrUnreachable = errors.New("error chat is unreachable")
+
+// UnreachableError keeps the cause of a permanent failure while matching ErrUnreachable.
+type UnreachableError struct {
+	Reason string // Reason - why the chat rejects the bot, the Bot API description
+	Err    error
+}
+
+func (e *UnreachableError) Error() string        { return e.Err.Error() }
+func (e *UnreachableError) Unwrap() error        { return e.Err }
+func (e *UnreachableError) Is(target error) bool { return target == ErrUnreachable }
+
+// UnreachableReason returns why the chat rejects the bot when err matches ErrUnreachable, empty otherwise.
+func UnreachableReason(err error) string {
+	var unreachable *UnreachableError
+	if errors.As(err, &unreachable) {
+		return unreachable.Reason
+	}
+
+	return ""
+}
@@ -1,8 +1,119 @@
 package entities
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type TGAccount struct {
 	ClientID primitive.ObjectID `bson:"_id" json:"clientID"`
 	ChatID   int64              `bson:"chatID" json:"chatID"`
+	BotID    string             `bson:"botID,omitempty" json:"botID,omitempty"`       // BotID - empty for the default bot
+	ThreadID int                `bson:"threadID,omitempty" json:"threadID,omitempty"` // ThreadID - the forum topic of the chat, zero for none
+	Region   string             `bson:"region,omitempty" json:"region,omitempty"`
+	Tags     []string           `bson:"tags,omitempty" json:"tags,omitempty"` // Tags - free-form labels operators target broadcasts by
+	// OrganizationID - the organization the account is a site of, empty for a standalone client
+	OrganizationID string `bson:"organizationID,omitempty" json:"organizationID,omitempty"`
+	// Rules - filter rule expressions every alert must meet to be sent to the chat, see FilterRule
+	Rules []string `bson:"rules,omitempty" json:"rules,omitempty"`
+	// Filters - the parsed Rules, set with them
+	Filters []FilterRule `bson:"filters,omitempty" json:"-"`
+	// Deactivation - set while the chat rejects the bot, no alerts are sent then
+	Deactivation *Deactivation `bson:"deactivation,omitempty" json:"deactivation,omitempty"`
+}
+
+// Deactivation tells why the alerts of an account stopped, the account is active again once the bot
+// is added back to the chat.
+type Deactivation struct {
+	Reason string    `bson:"reason" json:"reason"`
+	At     time.Time `bson:"at" json:"at"`
+}
+
+func (a TGAccount) Active() bool {
+	return a.Deactivation == nil
+}
+
+// FilterRules returns the parsed filter rules of the account.
+func (a TGAccount) FilterRules() []FilterRule {
+	return storedFilters(a.Rules, a.Filters)
+}
+
+// Equal compares the accounts field by field, no tags and empty tags are the same, and so are the rules.
+func (a TGAccount) Equal(other TGAccount) bool {
+	if a.ClientID != other.ClientID || a.ChatID != other.ChatID || a.ThreadID != other.ThreadID || a.BotID != other.BotID ||
+		a.Region != other.Region || a.OrganizationID != other.OrganizationID {
+		return false
+	}
+
+	if a.Active() != other.Active() ||
+		(!a.Active() && (a.Deactivation.Reason != other.Deactivation.Reason || !a.Deactivation.At.Equal(other.Deactivation.At))) {
+		return false
+	}
+
+	return equalStrings(a.Tags, other.Tags) && equalStrings(a.Rules, other.Rules)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AccountFilter selects accounts ordered by client ID, zero fields match everything.
+type AccountFilter struct {
+	BotIDs         []string // BotIDs - the account uses one of the bots, the default bot is the empty ID
+	Region         string
+	Tags           []string // Tags - the account carries at least one of them
+	OrganizationID string   // OrganizationID - the sites of the organization
+	After          string   // After - the client ID to continue after, exclusive
+	Limit          int
+}
+
+func (f AccountFilter) Matches(account TGAccount) bool {
+	if len(f.BotIDs) != 0 && !containsString(f.BotIDs, account.BotID) {
+		return false
+	}
+
+	if f.Region != "" && account.Region != f.Region {
+		return false
+	}
+
+	if f.OrganizationID != "" && account.OrganizationID != f.OrganizationID {
+		return false
+	}
+
+	if f.After != "" && account.ClientID.Hex() <= f.After {
+		return false
+	}
+
+	if len(f.Tags) == 0 {
+		return true
+	}
+
+	for _, tag := range account.Tags {
+		if containsString(f.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,248 @@
+package entities
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	GeometryPolygon      = "Polygon"
+	GeometryMultiPolygon = "MultiPolygon"
+)
+
+// Zone is a geographic area, the chats subscribed to it get the alerts of every detection inside it
+// whichever client owns the sensor.
+type Zone struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	TenantID string             `bson:"tenantID" json:"tenantID"`
+	Name     string             `bson:"name" json:"name"`
+	Geometry Geometry           `bson:"geometry" json:"geometry"`
+	// CreatedAt - set by the service
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// Geometry is the GeoJSON area of a zone. A Polygon is accepted as well and is stored as a MultiPolygon
+// of one polygon. Positions are in longitude, latitude order, the first ring of a polygon is its
+// boundary and the others are holes.
+type Geometry struct {
+	Type        string          `bson:"type" json:"type"`
+	Coordinates [][][][]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch raw.Type {
+	case GeometryPolygon:
+		var polygon [][][]float64
+		if err := json.Unmarshal(raw.Coordinates, &polygon); err != nil {
+			return err
+		}
+
+		g.Coordinates = [][][][]float64{polygon}
+	case GeometryMultiPolygon:
+		if err := json.Unmarshal(raw.Coordinates, &g.Coordinates); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("unsupported geometry type %q, a Polygon or a MultiPolygon is expected", raw.Type)
+	}
+
+	g.Type = GeometryMultiPolygon
+
+	return nil
+}
+
+// Validate checks that the rings are closed and that the positions are valid coordinates.
+func (g Geometry) Validate() error {
+	if g.Type != GeometryMultiPolygon {
+		return errors.Errorf("unsupported geometry type %q", g.Type)
+	}
+
+	if len(g.Coordinates) == 0 {
+		return errors.New("the geometry has no polygons")
+	}
+
+	for i, polygon := range g.Coordinates {
+		if len(polygon) == 0 {
+			return errors.Errorf("polygon %d has no rings", i)
+		}
+
+		for j, ring := range polygon {
+			if len(ring) < 4 {
+				return errors.Errorf("ring %d of polygon %d has less than 4 positions", j, i)
+			}
+
+			for _, position := range ring {
+				if len(position) < 2 || !(Location{Latitude: position[1], Longitude: position[0]}).Valid() {
+					return errors.Errorf("ring %d of polygon %d has an invalid position %v", j, i, position)
+				}
+			}
+
+			first, last := ring[0], ring[len(ring)-1]
+			if first[0] != last[0] || first[1] != last[1] {
+				return errors.Errorf("ring %d of polygon %d is not closed", j, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether the location is inside one of the polygons and outside of its holes,
+// the edges are straight lines in longitude and latitude. A location on an edge is inside, as with
+// $geoIntersects of Mongo.
+func (g Geometry) Contains(loc Location) bool {
+	for _, polygon := range g.Coordinates {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		if onRing(polygon[0], loc) {
+			return true
+		}
+
+		if !ringContains(polygon[0], loc) {
+			continue
+		}
+
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if onRing(hole, loc) {
+				return true
+			}
+
+			if ringContains(hole, loc) {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ringContains casts a ray from the location along the longitude axis and counts the edges it crosses.
+func ringContains(ring [][]float64, loc Location) bool {
+	inside := false
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+
+		if (yi > loc.Latitude) != (yj > loc.Latitude) &&
+			loc.Longitude < (xj-xi)*(loc.Latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// onRing reports whether the location lies on an edge of the ring.
+func onRing(ring [][]float64, loc Location) bool {
+	for i := 1; i < len(ring); i++ {
+		xi, yi := ring[i-1][0], ring[i-1][1]
+		xj, yj := ring[i][0], ring[i][1]
+
+		cross := (xj-xi)*(loc.Latitude-yi) - (yj-yi)*(loc.Longitude-xi)
+		if math.Abs(cross) > 1e-12 {
+			continue
+		}
+
+		if loc.Longitude >= math.Min(xi, xj) && loc.Longitude <= math.Max(xi, xj) &&
+			loc.Latitude >= math.Min(yi, yj) && loc.Latitude <= math.Max(yi, yj) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Bounds returns the bounding box of the geometry.
+func (g Geometry) Bounds() BoundingBox {
+	box := BoundingBox{MinLongitude: 180, MinLatitude: 90, MaxLongitude: -180, MaxLatitude: -90}
+
+	for _, polygon := range g.Coordinates {
+		if len(polygon) == 0 {
+			continue
+		}
+
+		// The holes are inside the boundary.
+		for _, position := range polygon[0] {
+			box.MinLongitude = minFloat(box.MinLongitude, position[0])
+			box.MaxLongitude = maxFloat(box.MaxLongitude, position[0])
+			box.MinLatitude = minFloat(box.MinLatitude, position[1])
+			box.MaxLatitude = maxFloat(box.MaxLatitude, position[1])
+		}
+	}
+
+	return box
+}
+
+// BoundingBox is the rectangle enclosing a geometry, the spatial indexes store it.
+type BoundingBox struct {
+	MinLongitude float64
+	MinLatitude  float64
+	MaxLongitude float64
+	MaxLatitude  float64
+}
+
+func (b BoundingBox) Contains(loc Location) bool {
+	return loc.Longitude >= b.MinLongitude && loc.Longitude <= b.MaxLongitude &&
+		loc.Latitude >= b.MinLatitude && loc.Latitude <= b.MaxLatitude
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// ZoneSubscription makes a chat get the alerts of the detections inside the zone.
+type ZoneSubscription struct {
+	ID       primitive.ObjectID `bson:"_id" json:"id"`
+	ZoneID   string             `bson:"zoneID" json:"zoneID"`
+	ChatID   int64              `bson:"chatID" json:"chatID"`
+	ThreadID int                `bson:"threadID,omitempty" json:"threadID,omitempty"` // ThreadID - the forum topic of the chat, zero for none
+	BotID    string             `bson:"botID,omitempty" json:"botID,omitempty"`       // BotID - empty for the default bot
+	Rules    []string           `bson:"rules,omitempty" json:"rules,omitempty"`       // Rules - filter rule expressions, see FilterRule
+	Filters  []FilterRule       `bson:"filters,omitempty" json:"-"`                   // Filters - the parsed Rules, set with them
+	// CreatedAt - set by the service
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// Account returns the settings the alerts are sent to the subscribed chat with.
+func (s ZoneSubscription) Account() TGAccount {
+	return TGAccount{ChatID: s.ChatID, ThreadID: s.ThreadID, BotID: s.BotID, Rules: s.Rules, Filters: s.Filters}
+}
+
+// FilterRules returns the parsed filter rules of the subscription.
+func (s ZoneSubscription) FilterRules() []FilterRule {
+	return storedFilters(s.Rules, s.Filters)
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGeometryContains(t *testing.T) {
+	// A 10x10 square with a 2x2 hole in the middle, a diamond and a concave U shape.
+	var square, diamond, shapeU Geometry
+	for geometry, raw := range map[*Geometry]string{
+		&square:  `{"type": "Polygon", "coordinates": [[[0,0],[10,0],[10,10],[0,10],[0,0]], [[4,4],[6,4],[6,6],[4,6],[4,4]]]}`,
+		&diamond: `{"type": "Polygon", "coordinates": [[[5,0],[10,5],[5,10],[0,5],[5,0]]]}`,
+		&shapeU: `{"type": "MultiPolygon", "coordinates": [
+			[[[0,0],[9,0],[9,9],[6,9],[6,3],[3,3],[3,9],[0,9],[0,0]]],
+			[[[20,20],[21,20],[21,21],[20,21],[20,20]]]
+		]}`,
+	} {
+		if err := json.Unmarshal([]byte(raw), geometry); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := geometry.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		geometry Geometry
+		lon, lat float64
+		want     bool
+	}{
+		{name: "inside", geometry: square, lon: 2, lat: 2, want: true},
+		{name: "outside", geometry: square, lon: 12, lat: 2},
+		{name: "left edge", geometry: square, lon: 0, lat: 5, want: true},
+		{name: "right edge", geometry: square, lon: 10, lat: 5, want: true},
+		{name: "bottom edge", geometry: square, lon: 5, lat: 0, want: true},
+		{name: "top edge", geometry: square, lon: 5, lat: 10, want: true},
+		{name: "first vertex", geometry: square, lon: 0, lat: 0, want: true},
+		{name: "far vertex", geometry: square, lon: 10, lat: 10, want: true},
+		{name: "on the line of an edge beyond the vertex", geometry: square, lon: 11, lat: 10},
+		{name: "in the hole", geometry: square, lon: 5, lat: 5},
+		{name: "hole edge", geometry: square, lon: 6, lat: 5, want: true},
+		{name: "hole vertex", geometry: square, lon: 4, lat: 4, want: true},
+		{name: "level with a hole vertex", geometry: square, lon: 2, lat: 4, want: true},
+		{name: "diagonal edge", geometry: diamond, lon: 7.5, lat: 2.5, want: true},
+		{name: "just outside the diagonal edge", geometry: diamond, lon: 7.6, lat: 2.4},
+		// The ray of these locations passes through the side vertices of the diamond.
+		{name: "level with the side vertices inside", geometry: diamond, lon: 2, lat: 5, want: true},
+		{name: "level with the side vertices outside", geometry: diamond, lon: -1, lat: 5},
+		{name: "side vertex", geometry: diamond, lon: 10, lat: 5, want: true},
+		{name: "arm of a concave polygon", geometry: shapeU, lon: 1, lat: 8, want: true},
+		{name: "notch of a concave polygon", geometry: shapeU, lon: 4.5, lat: 6},
+		{name: "bottom of the notch", geometry: shapeU, lon: 4.5, lat: 3, want: true},
+		{name: "second polygon", geometry: shapeU, lon: 20.5, lat: 20.5, want: true},
+		{name: "between the polygons", geometry: shapeU, lon: 15, lat: 15},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loc := Location{Longitude: tc.lon, Latitude: tc.lat}
+			if got := tc.geometry.Contains(loc); got != tc.want {
+				t.Fatalf("Contains(%v, %v): want %v, got %v", tc.lon, tc.lat, tc.want, got)
+			}
+		})
+	}
+}
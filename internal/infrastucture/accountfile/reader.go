@@ -0,0 +1,254 @@
+// Package accountfile reads and writes client registrations as CSV or newline delimited JSON,
+// the formats of the bulk import and export.
+package accountfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+var ErrUnknownFormat = errors.New("unknown format, want csv or ndjson")
+
+// Reader yields the registrations of a file, Next returns io.EOF after the last row. A row that can't be
+// parsed is returned with Err set, an error of Next means the whole file is unreadable.
+type Reader interface {
+	Next() (entities.ImportRow, error)
+}
+
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r), nil
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// FormatByContentType returns the format of a MIME type or a file name, empty when it's neither.
+func FormatByContentType(value string) string {
+	value = strings.ToLower(value)
+
+	switch {
+	case strings.Contains(value, "csv"):
+		return FormatCSV
+	case strings.Contains(value, "ndjson"), strings.Contains(value, "jsonl"), strings.Contains(value, "json-seq"):
+		return FormatNDJSON
+	default:
+		return ""
+	}
+}
+
+type record struct {
+	ClientID string   `json:"clientID"`
+	ChatID   int64    `json:"chatID"`
+	BotID    string   `json:"botID,omitempty"`
+	ThreadID int      `json:"threadID,omitempty"`
+	Region   string   `json:"region,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+
+	OrganizationID string   `json:"organizationID,omitempty"`
+	Rules          []string `json:"rules,omitempty"`
+}
+
+func (r record) account() (entities.TGAccount, error) {
+	account := entities.TGAccount{
+		ChatID: r.ChatID, ThreadID: r.ThreadID, BotID: r.BotID, Region: r.Region, Tags: r.Tags, OrganizationID: r.OrganizationID,
+		Rules: r.Rules,
+	}
+
+	if r.OrganizationID != "" && !primitive.IsValidObjectID(r.OrganizationID) {
+		return account, fmt.Errorf("organizationID %q is not an object id", r.OrganizationID)
+	}
+
+	if r.ClientID == "" {
+		return account, nil
+	}
+
+	clientID, err := primitive.ObjectIDFromHex(r.ClientID)
+	if err != nil {
+		return account, fmt.Errorf("clientID %q is not an object id", r.ClientID)
+	}
+
+	account.ClientID = clientID
+
+	return account, nil
+}
+
+type csvReader struct {
+	csv     *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	return &csvReader{csv: reader}
+}
+
+// columnName makes clientID, client_id and ClientId the same column.
+func columnName(header string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(header), "_", ""))
+}
+
+func (c *csvReader) readHeader() error {
+	header, err := c.csv.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+
+		return errors.Wrap(err, "csv header")
+	}
+
+	c.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet applications prepend a byte order mark.
+		c.columns[columnName(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	for _, required := range []string{"clientid", "chatid"} {
+		if _, ok := c.columns[required]; !ok {
+			return errors.Errorf("csv header: missing %s column", required)
+		}
+	}
+
+	return nil
+}
+
+func (c *csvReader) field(fields []string, name string) string {
+	i, ok := c.columns[name]
+	if !ok || i >= len(fields) {
+		return ""
+	}
+
+	return strings.TrimSpace(fields[i])
+}
+
+func (c *csvReader) Next() (entities.ImportRow, error) {
+	if c.columns == nil {
+		if err := c.readHeader(); err != nil {
+			return entities.ImportRow{}, err
+		}
+	}
+
+	fields, err := c.csv.Read()
+	if err != nil {
+		return entities.ImportRow{}, err
+	}
+
+	line, _ := c.csv.FieldPos(0)
+	row := entities.ImportRow{Line: line}
+
+	rec := record{
+		ClientID: c.field(fields, "clientid"),
+		BotID:    c.field(fields, "botid"),
+		Region:   c.field(fields, "region"),
+		Tags:     splitTags(c.field(fields, "tags")),
+
+		OrganizationID: c.field(fields, "organizationid"),
+		Rules:          splitRules(c.field(fields, "rules")),
+	}
+
+	if chatID := c.field(fields, "chatid"); chatID != "" {
+		if rec.ChatID, err = strconv.ParseInt(chatID, 10, 64); err != nil {
+			row.Err = fmt.Errorf("chatID %q is not a number", chatID)
+			return row, nil
+		}
+	}
+
+	if threadID := c.field(fields, "threadid"); threadID != "" {
+		if rec.ThreadID, err = strconv.Atoi(threadID); err != nil {
+			row.Err = fmt.Errorf("threadID %q is not a number", threadID)
+			return row, nil
+		}
+	}
+
+	row.Account, row.Err = rec.account()
+
+	return row, nil
+}
+
+// splitTags reads the comma separated tags of a CSV cell.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// splitRules reads the semicolon separated rules of a CSV cell, the rules themselves have commas.
+func splitRules(value string) []string {
+	var rules []string
+	for _, rule := range strings.Split(value, ";") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (n *ndjsonReader) Next() (entities.ImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+
+		data := strings.TrimSpace(n.scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		row := entities.ImportRow{Line: n.line}
+
+		var rec record
+		if err := json.Unmarshal([]byte(data), &rec); err != nil {
+			row.Err = errors.Wrap(err, "json")
+			return row, nil
+		}
+
+		row.Account, row.Err = rec.account()
+
+		return row, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return entities.ImportRow{}, errors.Wrap(err, "scanner.Scan")
+	}
+
+	return entities.ImportRow{}, io.EOF
+}
@@ -0,0 +1,119 @@
+package accountfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// Writer streams registrations in one of the formats, Flush must be called after the last Write.
+type Writer interface {
+	Write(account entities.TGAccount) error
+	Flush() error
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{csv: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+
+	return "application/x-ndjson"
+}
+
+type csvWriter struct {
+	csv    *csv.Writer
+	header bool
+}
+
+// writeHeader writes the header once, so that even an empty export can be imported back.
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+
+	c.header = true
+
+	return c.csv.Write([]string{"clientID", "chatID", "botID", "region", "tags", "threadID", "organizationID", "rules"})
+}
+
+func (c *csvWriter) Write(account entities.TGAccount) error {
+	if err := c.writeHeader(); err != nil {
+		return errors.Wrap(err, "csv.Write")
+	}
+
+	fields := []string{
+		account.ClientID.Hex(), strconv.FormatInt(account.ChatID, 10), account.BotID, account.Region,
+		strings.Join(account.Tags, ","), threadField(account.ThreadID), account.OrganizationID,
+		strings.Join(account.Rules, ";"),
+	}
+
+	if err := c.csv.Write(fields); err != nil {
+		return errors.Wrap(err, "csv.Write")
+	}
+
+	return nil
+}
+
+// threadField leaves the cell empty for the accounts outside of forum topics.
+func threadField(threadID int) string {
+	if threadID == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(threadID)
+}
+
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return errors.Wrap(err, "csv.Write")
+	}
+
+	c.csv.Flush()
+
+	return errors.Wrap(c.csv.Error(), "csv.Flush")
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(account entities.TGAccount) error {
+	rec := record{
+		ClientID: account.ClientID.Hex(),
+		ChatID:   account.ChatID,
+		BotID:    account.BotID,
+		ThreadID: account.ThreadID,
+		Region:   account.Region,
+		Tags:     account.Tags,
+
+		OrganizationID: account.OrganizationID,
+		Rules:          account.Rules,
+	}
+	if err := n.encoder.Encode(rec); err != nil {
+		return errors.Wrap(err, "encoder.Encode")
+	}
+
+	return nil
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
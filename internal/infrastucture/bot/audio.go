@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/spectrogram"
+)
+
+const _audioMessageType = "audio"
+
+// replyAudio sends the recording of an audio alert as a reply to it, followed by its spectrogram when
+// rendering is enabled. Both are best effort, an alert is delivered without them rather than not at all.
+func (b *Bot) replyAudio(
+	ctx context.Context, span trace.Span, botAPI *tgbotapi.BotAPI, threadID, replyTo int,
+	msg entities.NotificationMessage, delivery *entities.Delivery,
+) {
+	if msg.MessageType != _audioMessageType || len(msg.Payload) == 0 {
+		return
+	}
+
+	audio, err := spectrogram.Decode(msg.Payload, msg.SampleRate)
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "spectrogram.Decode"))
+		return
+	}
+
+	// A WAV payload goes out as it came, raw PCM has to be wrapped for the players to recognize it.
+	wav := msg.Payload
+	if !spectrogram.IsWAV(wav) {
+		wav = audio.WAV()
+	}
+
+	name := msg.RequestID.String()
+
+	if err = b.limiter.Wait(ctx); err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	params := chatParams(delivery.ChatID, threadID, replyTo)
+	params["duration"] = fmt.Sprintf("%.0f", audio.Duration())
+
+	sentAudio, err := b.send(botAPI, outgoing{
+		method: "sendAudio",
+		params: params,
+		files:  []tgbotapi.RequestFile{{Name: "audio", Data: tgbotapi.FileBytes{Name: name + ".wav", Bytes: wav}}},
+	})
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "botAPI.Send audio"))
+		return
+	}
+
+	delivery.AudioMessageID = sentAudio.MessageID
+
+	if b.renderer == nil {
+		return
+	}
+
+	image, err := b.renderer.Render(audio)
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "renderer.Render"))
+		return
+	}
+
+	if err = b.limiter.Wait(ctx); err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	minFreq, maxFreq := b.renderer.FrequencyRange(audio)
+
+	params = chatParams(delivery.ChatID, threadID, replyTo)
+	params["caption"] = fmt.Sprintf("Spectrogram %.0f–%.0f Hz, %.1f s", minFreq, maxFreq, audio.Duration())
+
+	sentPhoto, err := b.send(botAPI, outgoing{
+		method: "sendPhoto",
+		params: params,
+		files:  []tgbotapi.RequestFile{{Name: "photo", Data: tgbotapi.FileBytes{Name: name + ".png", Bytes: image}}},
+	})
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "botAPI.Send spectrogram"))
+		return
+	}
+
+	delivery.SpectrogramMessageID = sentPhoto.MessageID
+}
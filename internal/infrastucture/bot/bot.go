@@ -1,45 +1,367 @@
 package bot
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
-	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/media"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/spectrogram"
 )
 
-const _messageTemplate = "Attention!\n" +
-	"The system has been triggered for message from %s"
+// ErrClosed is returned by the sends started after Close.
+var ErrClosed = errors.New("error bot is closed")
 
 type Bot struct {
-	botAPI *tgbotapi.BotAPI
-	tracer trace.Tracer
+	registry *Registry
+	tracer   trace.Tracer
+	limiter  *rate.Limiter
+	breaker  *Breaker
+	template atomic.Pointer[template.Template]
+	renderer *spectrogram.Renderer // renderer - draws the spectrogram of audio alerts, nil disables it
+	media    *media.Source         // media - where the media the alerts reference is kept, nil ignores the references
+
+	mu       sync.RWMutex
+	closed   bool
+	inFlight sync.WaitGroup
+}
+
+func NewBot(
+	cfg config.BotConfig, messageTemplate string, registry *Registry, renderer *spectrogram.Renderer,
+	mediaSource *media.Source,
+) (*Bot, error) {
+	b := &Bot{
+		registry: registry,
+		renderer: renderer,
+		media:    mediaSource,
+		tracer:   otel.GetTracerProvider().Tracer("bot"),
+		limiter:  rate.NewLimiter(rate.Limit(cfg.RateLimit), cfg.RateBurst),
+		breaker:  NewBreaker(cfg.BreakerFailures, cfg.BreakerCooldown),
+	}
+
+	if err := b.SetTemplate(messageTemplate); err != nil {
+		return nil, errors.Wrap(err, "SetTemplate")
+	}
+
+	return b, nil
 }
 
-func NewBot(cfg config.BotConfig) (*Bot, error) {
-	botAPI, err := tgbotapi.NewBotAPI(cfg.Token)
+// SetTemplate replaces the text/template used to render notifications, it is safe to call at runtime.
+func (b *Bot) SetTemplate(text string) error {
+	tmpl, err := template.New("notify").Parse(text)
 	if err != nil {
-		return nil, errors.Wrap(err, "tgbotapi.NewBotAPI")
+		return errors.Wrap(err, "template.Parse")
 	}
 
-	return &Bot{
-		botAPI: botAPI,
-		tracer: otel.GetTracerProvider().Tracer("bot"),
-	}, nil
+	b.template.Store(tmpl)
+
+	return nil
 }
 
-func (b Bot) NotifyClient(ctx context.Context, chatID int64, msg entities.NotificationMessage) error {
+// SetRateLimit changes the number of messages per second the bot is allowed to send, it is safe to call at runtime.
+func (b *Bot) SetRateLimit(limit float64, burst int) {
+	b.limiter.SetLimit(rate.Limit(limit))
+	b.limiter.SetBurst(burst)
+}
+
+// Breaker returns the circuit breaker every call to Telegram goes through.
+func (b *Bot) Breaker() *Breaker {
+	return b.breaker
+}
+
+// outgoing is a send* call with raw params, tgbotapi has no configs with message_thread_id.
+type outgoing struct {
+	method string
+	params tgbotapi.Params
+	files  []tgbotapi.RequestFile // files - uploaded as multipart form data along with the params
+}
+
+func (b *Bot) send(botAPI *tgbotapi.BotAPI, msg outgoing) (tgbotapi.Message, error) {
+	var sent tgbotapi.Message
+
+	err := b.breaker.Do(func() error {
+		var (
+			resp *tgbotapi.APIResponse
+			err  error
+		)
+
+		if len(msg.files) != 0 {
+			resp, err = botAPI.UploadFiles(msg.method, msg.params, msg.files)
+
+			// Unlike MakeRequest, UploadFiles leaves the error code out of its errors.
+			var apiErr *tgbotapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == 0 && resp != nil {
+				apiErr.Code = resp.ErrorCode
+			}
+		} else {
+			resp, err = botAPI.MakeRequest(msg.method, msg.params)
+		}
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(resp.Result, &sent)
+	})
+
+	return sent, permanent(err)
+}
+
+// migratedTo returns the new chat ID of a group that was upgraded to a supergroup, zero for other errors.
+func migratedTo(err error) int64 {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.MigrateToChatID
+	}
+
+	return 0
+}
+
+func (b *Bot) request(botAPI *tgbotapi.BotAPI, c tgbotapi.Chattable) error {
+	return permanent(b.breaker.Do(func() error {
+		_, err := botAPI.Request(c)
+		return err
+	}))
+}
+
+// Close rejects new sends and waits for the ones in flight, including those queued by the rate limit,
+// until the context is done.
+func (b *Bot) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "waiting for in-flight sends")
+	}
+}
+
+// begin registers a send, the returned func must be called when it's over.
+func (b *Bot) begin() (func(), error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	b.inFlight.Add(1)
+
+	return b.inFlight.Done, nil
+}
+
+// _testLabel heads every test alert whatever the template is, so nobody takes it for a real one.
+const _testLabel = "🧪 TEST ALERT — this is a test of the notification setup, no action is required.\n\n"
+
+func (b *Bot) render(msg entities.NotificationMessage) (string, error) {
+	var buf bytes.Buffer
+	if msg.Test {
+		buf.WriteString(_testLabel)
+	}
+
+	if err := b.template.Load().Execute(&buf, msg); err != nil {
+		return "", errors.Wrap(err, "template.Execute")
+	}
+
+	return buf.String(), nil
+}
+
+// NotifyClient sends the alert to the account chat followed by its location, recording and spectrogram
+// or media. A group upgraded to a supergroup is followed, the delivery then has the new chat ID.
+func (b *Bot) NotifyClient(
+	ctx context.Context, account entities.TGAccount, msg entities.NotificationMessage,
+) (entities.Delivery, error) {
 	ctx, span := b.tracer.Start(ctx, "bot.NotifyClient")
 	defer span.End()
 
-	message := tgbotapi.NewMessage(chatID, fmt.Sprintf(_messageTemplate, msg.Timestamp.String()))
-	if _, err := b.botAPI.Send(message); err != nil {
+	delivery := entities.Delivery{
+		RequestID:   msg.RequestID.String(),
+		ClientID:    msg.ClientID,
+		ChatID:      account.ChatID,
+		BotID:       account.BotID,
+		Status:      entities.DeliverySent,
+		SensorID:    msg.SensorID,
+		MessageType: msg.MessageType,
+		DetectedAt:  msg.Timestamp.UTC(),
+	}
+
+	done, err := b.begin()
+	if err != nil {
+		span.RecordError(err)
+		return delivery, err
+	}
+	defer done()
+
+	botAPI, err := b.registry.API(ctx, account.BotID)
+	if err != nil {
+		span.RecordError(err)
+		return delivery, errors.Wrap(err, "registry.API")
+	}
+
+	text, err := b.render(msg)
+	if err != nil {
+		span.RecordError(err)
+		return delivery, errors.Wrap(err, "render")
+	}
+
+	if err = b.limiter.Wait(ctx); err != nil {
+		span.RecordError(err)
+		return delivery, errors.Wrap(err, "limiter.Wait")
+	}
+
+	sent, err := b.send(botAPI, textMessage(account.ChatID, account.ThreadID, text))
+	if toChatID := migratedTo(err); toChatID != 0 {
+		span.AddEvent("chat migrated to a supergroup", trace.WithAttributes(attribute.Int64("chat.to", toChatID)))
+
+		delivery.ChatID = toChatID
+		if err = b.limiter.Wait(ctx); err != nil {
+			span.RecordError(err)
+			return delivery, errors.Wrap(err, "limiter.Wait")
+		}
+
+		sent, err = b.send(botAPI, textMessage(toChatID, account.ThreadID, text))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		return delivery, errors.Wrap(err, "botAPI.Send")
+	}
+
+	delivery.MessageID = sent.MessageID
+	delivery.Text = text
+	delivery.SentAt = sent.Time()
+
+	b.replyLocation(ctx, span, botAPI, account.ThreadID, sent.MessageID, msg, &delivery)
+	b.replyAudio(ctx, span, botAPI, account.ThreadID, sent.MessageID, msg, &delivery)
+	b.replyMedia(ctx, span, botAPI, account.ThreadID, sent.MessageID, msg, &delivery)
+
+	return delivery, nil
+}
+
+// replyLocation replies to the alert with the sensor position, a failure is only recorded on the span
+// since the alert is out already.
+func (b *Bot) replyLocation(
+	ctx context.Context, span trace.Span, botAPI *tgbotapi.BotAPI, threadID, replyTo int,
+	msg entities.NotificationMessage, delivery *entities.Delivery,
+) {
+	switch {
+	case msg.Location == nil:
+		return
+	case !msg.Location.Valid():
+		span.AddEvent("invalid sensor location skipped")
+		return
+	}
+
+	if err := b.limiter.Wait(ctx); err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	sentLocation, err := b.send(botAPI, locationMessage(delivery.ChatID, threadID, replyTo, msg))
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "botAPI.Send location"))
+		return
+	}
+
+	delivery.LocationMessageID = sentLocation.MessageID
+}
+
+// SendText sends an operator announcement to the account chat, it shares the rate limit with the alerts.
+func (b *Bot) SendText(ctx context.Context, account entities.TGAccount, text string) error {
+	ctx, span := b.tracer.Start(ctx, "bot.SendText")
+	defer span.End()
+
+	done, err := b.begin()
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer done()
+
+	botAPI, err := b.registry.API(ctx, account.BotID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "registry.API")
+	}
+
+	if err = b.limiter.Wait(ctx); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "limiter.Wait")
+	}
+
+	if _, err = b.send(botAPI, textMessage(account.ChatID, account.ThreadID, text)); err != nil {
 		span.RecordError(err)
 		return errors.Wrap(err, "botAPI.Send")
 	}
 
 	return nil
 }
+
+// textMessage builds a sendMessage call, posted into the forum topic when threadID isn't zero.
+func textMessage(chatID int64, threadID int, text string) outgoing {
+	params := chatParams(chatID, threadID, 0)
+	params["text"] = text
+
+	return outgoing{method: "sendMessage", params: params}
+}
+
+// locationMessage builds a reply to the alert with the sensor position, a venue when there is a name
+// or an address to show and a plain location otherwise.
+func locationMessage(
+	chatID int64, threadID, replyTo int, msg entities.NotificationMessage,
+) outgoing {
+	lat, lon := msg.Location.Latitude, msg.Location.Longitude
+
+	params := chatParams(chatID, threadID, replyTo)
+	params["latitude"] = strconv.FormatFloat(lat, 'f', 6, 64)
+	params["longitude"] = strconv.FormatFloat(lon, 'f', 6, 64)
+
+	if msg.SensorName == "" && msg.Address == "" {
+		return outgoing{method: "sendLocation", params: params}
+	}
+
+	title := msg.SensorName
+	if title == "" {
+		title = "Sensor"
+	}
+
+	address := msg.Address
+	if address == "" {
+		address = fmt.Sprintf("%.6f, %.6f", lat, lon)
+	}
+
+	params["title"] = title
+	params["address"] = address
+
+	return outgoing{method: "sendVenue", params: params}
+}
+
+func chatParams(chatID int64, threadID, replyTo int) tgbotapi.Params {
+	params := tgbotapi.Params{"chat_id": strconv.FormatInt(chatID, 10)}
+	params.AddNonZero("message_thread_id", threadID)
+	params.AddNonZero("reply_to_message_id", replyTo)
+
+	return params
+}
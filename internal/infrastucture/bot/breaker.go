@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// unavailableError keeps the cause of a failed call while matching entities.ErrUnavailable.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string        { return e.err.Error() }
+func (e *unavailableError) Unwrap() error        { return e.err }
+func (e *unavailableError) Is(target error) bool { return target == entities.ErrUnavailable }
+
+// unavailable tells the failures of Telegram itself, rate limiting included, apart from the rejections
+// of a single call, like a blocked bot or a malformed message, which say nothing about the API being up.
+// Neither does an upload failing on reading its media.
+func unavailable(err error) bool {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= http.StatusInternalServerError || apiErr.Code == http.StatusTooManyRequests
+	}
+
+	var srcErr *sourceError
+	if errors.As(err, &srcErr) {
+		return false
+	}
+
+	return true
+}
+
+// Breaker stops calling Telegram after failures consecutive failures. While open the calls fail at once,
+// after the cooldown a single trial call decides whether it closes again.
+type Breaker struct {
+	failures int
+	cooldown time.Duration
+	onChange func(entities.BreakerState)
+
+	mu       sync.Mutex
+	state    entities.BreakerState
+	failed   int
+	openedAt time.Time
+	trial    bool
+}
+
+func NewBreaker(failures int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		failures: failures,
+		cooldown: cooldown,
+		state:    entities.BreakerClosed,
+	}
+}
+
+// OnChange registers fn to be called with the new state on every transition, it must not block.
+func (b *Breaker) OnChange(fn func(entities.BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.onChange = fn
+}
+
+// Status returns the current state of the breaker.
+func (b *Breaker) Status() entities.BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return entities.BreakerStatus{State: b.state, Failures: b.failed, OpenedAt: b.openedAt}
+}
+
+// Do runs call unless the breaker is open, the error of a call that didn't reach Telegram matches
+// entities.ErrUnavailable.
+func (b *Breaker) Do(call func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := call()
+	if err != nil && unavailable(err) {
+		b.record(false)
+		return &unavailableError{err: err}
+	}
+
+	b.record(true)
+
+	return err
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case entities.BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return &unavailableError{err: errors.New("circuit breaker is open")}
+		}
+
+		b.setState(entities.BreakerHalfOpen)
+	case entities.BreakerHalfOpen:
+		if b.trial {
+			return &unavailableError{err: errors.New("circuit breaker is half-open, a trial call is running")}
+		}
+	}
+
+	if b.state == entities.BreakerHalfOpen {
+		b.trial = true
+	}
+
+	return nil
+}
+
+func (b *Breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	if ok {
+		b.failed = 0
+		if b.state != entities.BreakerClosed {
+			b.openedAt = time.Time{}
+			b.setState(entities.BreakerClosed)
+		}
+
+		return
+	}
+
+	b.failed++
+	if b.state == entities.BreakerHalfOpen || b.failed >= b.failures {
+		b.openedAt = time.Now()
+		if b.state != entities.BreakerOpen {
+			b.setState(entities.BreakerOpen)
+		}
+	}
+}
+
+func (b *Breaker) setState(state entities.BreakerState) {
+	b.state = state
+
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
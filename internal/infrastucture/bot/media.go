@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/media"
+)
+
+// The sizes Telegram accepts from bots, https://core.telegram.org/bots/api#sending-files
+const (
+	_photoUploadLimit = 10 << 20
+	_photoURLLimit    = 5 << 20
+	_fileUploadLimit  = 50 << 20
+	_fileURLLimit     = 20 << 20
+
+	_photoMessageType = "photo"
+)
+
+// sourceError is a failure of reading the media being uploaded, it says nothing about Telegram.
+type sourceError struct {
+	err error
+}
+
+func (e *sourceError) Error() string { return e.err.Error() }
+func (e *sourceError) Unwrap() error { return e.err }
+
+// sourceReader marks the read errors of the media, the upload fails with them.
+type sourceReader struct {
+	io.Reader
+}
+
+func (r sourceReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = &sourceError{err: err}
+	}
+
+	return n, err
+}
+
+// replyMedia sends the object an alert references in the media storage as a reply to it, a photo for
+// the photo alerts and an audio for the audio ones. Telegram fetches it by a presigned URL when it can,
+// otherwise it's streamed from the storage into the upload. Like the recording, it's best effort.
+func (b *Bot) replyMedia(
+	ctx context.Context, span trace.Span, botAPI *tgbotapi.BotAPI, threadID, replyTo int,
+	msg entities.NotificationMessage, delivery *entities.Delivery,
+) {
+	if msg.Media == nil {
+		return
+	}
+
+	if b.media == nil {
+		span.AddEvent("media reference skipped, no media storage is configured")
+		return
+	}
+
+	var (
+		method, field         = "sendAudio", "audio"
+		uploadLimit, urlLimit = int64(_fileUploadLimit), int64(_fileURLLimit)
+		messageID             = &delivery.AudioMessageID
+	)
+
+	switch msg.MessageType {
+	case _audioMessageType:
+		// The spectrogram is drawn from the recording itself, so it's fetched whole and sent as if embedded.
+		if b.renderer != nil && isWAV(*msg.Media) {
+			b.replyRecording(ctx, span, botAPI, threadID, replyTo, msg, delivery)
+			return
+		}
+	case _photoMessageType:
+		method, field = "sendPhoto", "photo"
+		uploadLimit, urlLimit = _photoUploadLimit, _photoURLLimit
+		messageID = &delivery.PhotoMessageID
+	default:
+		span.AddEvent("media reference skipped, the alert is neither audio nor photo")
+		return
+	}
+
+	u, err := b.media.URL(ctx, *msg.Media, urlLimit)
+	switch {
+	case err == nil:
+		sent, err := b.sendMedia(ctx, botAPI, method, chatParams(delivery.ChatID, threadID, replyTo), field,
+			tgbotapi.FileURL(u))
+		if err == nil {
+			*messageID = sent.MessageID
+			return
+		}
+
+		// Telegram couldn't fetch it, the storage may not be reachable from the internet after all.
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+			span.RecordError(errors.Wrap(err, "botAPI.Send media URL"))
+			return
+		}
+
+		span.AddEvent("Telegram couldn't fetch the media URL, uploading it")
+	case !errors.Is(err, media.ErrNoURL):
+		span.RecordError(errors.Wrap(err, "media.URL"))
+		return
+	}
+
+	body, _, err := b.media.Open(ctx, *msg.Media, uploadLimit)
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "media.Open"))
+		return
+	}
+	defer body.Close()
+
+	sent, err := b.sendMedia(ctx, botAPI, method, chatParams(delivery.ChatID, threadID, replyTo), field,
+		tgbotapi.FileReader{Name: path.Base(msg.Media.Key), Reader: sourceReader{Reader: body}})
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "botAPI.Send media"))
+		return
+	}
+
+	*messageID = sent.MessageID
+}
+
+// replyRecording fetches a WAV recording from the media storage and sends it with its spectrogram.
+func (b *Bot) replyRecording(
+	ctx context.Context, span trace.Span, botAPI *tgbotapi.BotAPI, threadID, replyTo int,
+	msg entities.NotificationMessage, delivery *entities.Delivery,
+) {
+	body, _, err := b.media.Open(ctx, *msg.Media, _fileUploadLimit)
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "media.Open"))
+		return
+	}
+	defer body.Close()
+
+	if msg.Payload, err = io.ReadAll(body); err != nil {
+		span.RecordError(errors.Wrap(err, "io.ReadAll"))
+		return
+	}
+
+	b.replyAudio(ctx, span, botAPI, threadID, replyTo, msg, delivery)
+}
+
+func (b *Bot) sendMedia(
+	ctx context.Context, botAPI *tgbotapi.BotAPI, method string, params tgbotapi.Params, field string,
+	file tgbotapi.RequestFileData,
+) (tgbotapi.Message, error) {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return tgbotapi.Message{}, errors.Wrap(err, "limiter.Wait")
+	}
+
+	return b.send(botAPI, outgoing{method: method, params: params, files: []tgbotapi.RequestFile{{Name: field, Data: file}}})
+}
+
+func isWAV(obj entities.MediaObject) bool {
+	switch strings.ToLower(obj.ContentType) {
+	case "audio/wav", "audio/x-wav", "audio/wave", "audio/vnd.wave":
+		return true
+	}
+
+	return strings.EqualFold(path.Ext(obj.Key), ".wav")
+}
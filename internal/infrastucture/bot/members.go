@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _updatesLimit = 100
+
+// ChatMemberUpdates returns the changes of the bot membership in chats after offset, along with the offset
+// to ask for the next ones. Only my_chat_member updates are requested from Telegram.
+func (b *Bot) ChatMemberUpdates(ctx context.Context, botID string, offset int) ([]entities.ChatMemberUpdate, int, error) {
+	ctx, span := b.tracer.Start(ctx, "bot.ChatMemberUpdates")
+	defer span.End()
+
+	botAPI, err := b.registry.API(ctx, botID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, offset, errors.Wrap(err, "registry.API")
+	}
+
+	var updates []tgbotapi.Update
+
+	err = b.breaker.Do(func() (err error) {
+		updates, err = botAPI.GetUpdates(tgbotapi.UpdateConfig{
+			Offset:         offset,
+			Limit:          _updatesLimit,
+			AllowedUpdates: []string{"my_chat_member"},
+		})
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, offset, errors.Wrap(err, "botAPI.GetUpdates")
+	}
+
+	changes := make([]entities.ChatMemberUpdate, 0, len(updates))
+	for _, update := range updates {
+		offset = update.UpdateID + 1
+
+		if update.MyChatMember == nil {
+			continue
+		}
+
+		changes = append(changes, chatMemberUpdate(botID, update.MyChatMember))
+	}
+
+	return changes, offset, nil
+}
+
+func chatMemberUpdate(botID string, updated *tgbotapi.ChatMemberUpdated) entities.ChatMemberUpdate {
+	change := entities.ChatMemberUpdate{
+		BotID:  botID,
+		ChatID: updated.Chat.ID,
+		At:     time.Unix(int64(updated.Date), 0).UTC(),
+	}
+
+	member := updated.NewChatMember
+	switch {
+	case member.Status == "creator" || member.Status == "administrator" || member.Status == "member":
+		change.Member = true
+	case member.Status == "restricted" && member.CanSendMessages:
+		change.Member = true
+	case updated.Chat.IsPrivate():
+		change.Reason = "bot was blocked by the user"
+	case member.Status == "restricted":
+		change.Reason = "bot is not allowed to send messages to the chat"
+	default:
+		change.Reason = "bot was removed from the chat"
+	}
+
+	return change
+}
+
+// NotifyAdmin sends the text to the admin chat of the bot, of the tenant owning it or the one configured
+// for the default bot. It does nothing when there is no admin chat.
+func (b *Bot) NotifyAdmin(ctx context.Context, botID, text string) error {
+	ctx, span := b.tracer.Start(ctx, "bot.NotifyAdmin")
+	defer span.End()
+
+	chatID, err := b.registry.AdminChatID(ctx, botID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "registry.AdminChatID")
+	}
+
+	if chatID == 0 {
+		return nil
+	}
+
+	account := entities.TGAccount{ChatID: chatID, BotID: botID}
+	if err = b.SendText(ctx, account, text); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "SendText")
+	}
+
+	return nil
+}
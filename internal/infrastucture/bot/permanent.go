@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// _unreachableChats are the descriptions of the 400 responses for chats that don't exist anymore,
+// a 400 is otherwise a problem of the single request.
+var _unreachableChats = []string{"chat not found", "group chat was deactivated", "user not found"}
+
+// permanent classifies the failures of a call, a failure the chat will keep returning is wrapped
+// to match entities.ErrUnreachable with the Bot API description as the reason.
+func permanent(err error) error {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.MigrateToChatID != 0 {
+		return err
+	}
+
+	reason := apiErr.Message
+	for _, prefix := range []string{"Forbidden: ", "Bad Request: "} {
+		reason = strings.TrimPrefix(reason, prefix)
+	}
+
+	switch apiErr.Code {
+	case http.StatusForbidden:
+		return &entities.UnreachableError{Reason: reason, Err: err}
+	case http.StatusBadRequest:
+		for _, description := range _unreachableChats {
+			if strings.Contains(reason, description) {
+				return &entities.UnreachableError{Reason: reason, Err: err}
+			}
+		}
+	}
+
+	return err
+}
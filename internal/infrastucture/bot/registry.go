@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type (
+	BotGetter interface {
+		GetBot(ctx context.Context, botID string) (entities.Bot, error)
+	}
+
+	TokenDecrypter interface {
+		Decrypt(encrypted string) (string, error)
+	}
+)
+
+// Registry keeps a BotAPI per registered bot. Bots are loaded from the repository on first use,
+// so bots registered at runtime become available without a restart. The default bot is created on
+// first use as well.
+type Registry struct {
+	endpoint     string
+	client       *http.Client
+	adminChat    int64
+	defaultToken string
+	repo         BotGetter
+	cipher       TokenDecrypter
+	mu           sync.RWMutex
+	apis         map[string]*tgbotapi.BotAPI
+}
+
+// NewRegistry creates the registry, client is used for the calls of every bot, see NewHTTPClient.
+// It doesn't call Telegram, so the service starts while Telegram is down.
+func NewRegistry(cfg config.BotConfig, client *http.Client, repo BotGetter, cipher TokenDecrypter) *Registry {
+	return &Registry{
+		endpoint:     cfg.APIEndpoint,
+		client:       client,
+		adminChat:    cfg.AdminChatID,
+		defaultToken: cfg.Token,
+		repo:         repo,
+		cipher:       cipher,
+		apis:         make(map[string]*tgbotapi.BotAPI),
+	}
+}
+
+// newAPI creates a BotAPI for the token without calling Telegram, unlike tgbotapi.NewBotAPIWithClient
+// with its getMe. The calls made with it go through the breaker, so an outage while a bot is loaded
+// parks the alerts like any other. Verify checks the token.
+func (r *Registry) newAPI(token string) *tgbotapi.BotAPI {
+	api := &tgbotapi.BotAPI{Token: token, Client: r.client, Buffer: 100}
+	api.SetAPIEndpoint(r.endpoint)
+
+	return api
+}
+
+// API returns the BotAPI for the bot, the default one for an empty botID.
+func (r *Registry) API(ctx context.Context, botID string) (*tgbotapi.BotAPI, error) {
+	r.mu.RLock()
+	api, ok := r.apis[botID]
+	r.mu.RUnlock()
+
+	if ok {
+		return api, nil
+	}
+
+	token := r.defaultToken
+	if botID != "" {
+		bot, err := r.repo.GetBot(ctx, botID)
+		if err != nil {
+			return nil, errors.Wrap(err, "repo.GetBot")
+		}
+
+		if token, err = r.cipher.Decrypt(bot.Token); err != nil {
+			return nil, errors.Wrap(err, "cipher.Decrypt")
+		}
+	}
+
+	api = r.newAPI(token)
+
+	r.mu.Lock()
+	r.apis[botID] = api
+	r.mu.Unlock()
+
+	return api, nil
+}
+
+// AdminChatID returns the admin chat of the bot, the configured one for the default bot.
+func (r *Registry) AdminChatID(ctx context.Context, botID string) (int64, error) {
+	if botID == "" {
+		return r.adminChat, nil
+	}
+
+	bot, err := r.repo.GetBot(ctx, botID)
+	if err != nil {
+		return 0, errors.Wrap(err, "repo.GetBot")
+	}
+
+	return bot.AdminChatID, nil
+}
+
+// Verify checks the token against Telegram and returns the bot username. The error matches
+// entities.ErrUnavailable when Telegram couldn't be asked.
+func (r *Registry) Verify(_ context.Context, token string) (string, error) {
+	self, err := r.newAPI(token).GetMe()
+	if err != nil {
+		if unavailable(err) {
+			err = &unavailableError{err: err}
+		}
+
+		return "", errors.Wrap(err, "botAPI.GetMe")
+	}
+
+	return self.UserName, nil
+}
+
+// Remove forgets the bot, the next use loads it from the repository again.
+func (r *Registry) Remove(botID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.apis, botID)
+}
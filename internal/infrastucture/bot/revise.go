@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _falseAlarm = "False alarm"
+
+// strikeThrough returns the text an updated alert is replaced with: the original text struck through
+// and a false alarm note with the reason, if any.
+func strikeThrough(text, reason string) (string, []tgbotapi.MessageEntity) {
+	note := _falseAlarm
+	if reason != "" {
+		note += ": " + reason
+	}
+
+	// Telegram measures entities in UTF-16 code units.
+	formatting := []tgbotapi.MessageEntity{
+		{Type: "strikethrough", Offset: 0, Length: len(utf16.Encode([]rune(text)))},
+	}
+
+	return text + "\n\n" + note, formatting
+}
+
+// Descriptions of the deleteMessage failures that leave nothing more to do with the message.
+const (
+	_messageNotFound      = "message to delete not found"
+	_messageCantBeDeleted = "message can't be deleted"
+	_messageNotModified   = "message is not modified"
+)
+
+// apiFailure tells whether err is a Bot API error with the description.
+func apiFailure(err error, description string) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, description)
+}
+
+// EditAlert strikes the delivered alert through and marks it as a false alarm.
+func (b *Bot) EditAlert(ctx context.Context, delivery entities.Delivery, reason string) error {
+	ctx, span := b.tracer.Start(ctx, "bot.EditAlert")
+	defer span.End()
+
+	done, err := b.begin()
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer done()
+
+	botAPI, err := b.registry.API(ctx, delivery.BotID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "registry.API")
+	}
+
+	if err = b.strikeThrough(ctx, botAPI, delivery, reason); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (b *Bot) strikeThrough(ctx context.Context, botAPI *tgbotapi.BotAPI, delivery entities.Delivery, reason string) error {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return errors.Wrap(err, "limiter.Wait")
+	}
+
+	edit := tgbotapi.NewEditMessageText(delivery.ChatID, delivery.MessageID, "")
+	edit.Text, edit.Entities = strikeThrough(delivery.Text, reason)
+
+	if err := b.request(botAPI, edit); err != nil {
+		return errors.Wrap(err, "botAPI.Request editMessageText")
+	}
+
+	return nil
+}
+
+// DeleteAlert deletes the delivered alert together with its location and media replies. A message that
+// is already gone counts as deleted, and an alert Telegram doesn't let the bot delete anymore is struck
+// through with the reason instead. The returned delivery has the IDs of the deleted messages zeroed, on
+// a failure it is the progress so far to save and resume from.
+func (b *Bot) DeleteAlert(ctx context.Context, delivery entities.Delivery, reason string) (entities.Delivery, error) {
+	ctx, span := b.tracer.Start(ctx, "bot.DeleteAlert")
+	defer span.End()
+
+	done, err := b.begin()
+	if err != nil {
+		span.RecordError(err)
+		return delivery, err
+	}
+	defer done()
+
+	botAPI, err := b.registry.API(ctx, delivery.BotID)
+	if err != nil {
+		span.RecordError(err)
+		return delivery, errors.Wrap(err, "registry.API")
+	}
+
+	for _, messageID := range []*int{
+		&delivery.PhotoMessageID, &delivery.SpectrogramMessageID, &delivery.AudioMessageID, &delivery.LocationMessageID,
+		&delivery.MessageID,
+	} {
+		if *messageID == 0 {
+			continue
+		}
+
+		if err = b.limiter.Wait(ctx); err != nil {
+			span.RecordError(err)
+			return delivery, errors.Wrap(err, "limiter.Wait")
+		}
+
+		err = b.request(botAPI, tgbotapi.NewDeleteMessage(delivery.ChatID, *messageID))
+		switch {
+		case err == nil || apiFailure(err, _messageNotFound):
+			*messageID = 0
+		case apiFailure(err, _messageCantBeDeleted) && messageID == &delivery.MessageID:
+			// The alert stays in the chat, struck through like an updated one.
+			err = b.strikeThrough(ctx, botAPI, delivery, reason)
+			if err != nil && !apiFailure(err, _messageNotModified) {
+				span.RecordError(err)
+				return delivery, err
+			}
+		case apiFailure(err, _messageCantBeDeleted):
+			// A reply the bot can't delete anymore stays, the alert it belongs to is dealt with last.
+			*messageID = 0
+		default:
+			span.RecordError(err)
+			return delivery, errors.Wrap(err, "botAPI.Request deleteMessage")
+		}
+	}
+
+	return delivery, nil
+}
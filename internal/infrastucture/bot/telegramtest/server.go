@@ -0,0 +1,350 @@
+// Package telegramtest provides a fake Telegram Bot API server. It records every call and can be told to
+// fail with the errors the real API returns, so the bot can be exercised without reaching Telegram.
+//
+//	srv := telegramtest.NewServer()
+//	defer srv.Close()
+//	api, _ := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.Endpoint())
+package telegramtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Call is a single request received by the server.
+type Call struct {
+	Token  string
+	Method string
+	Params map[string]string
+	Files  map[string][]byte
+}
+
+// ChatID returns the chat_id parameter of the call, zero if there is none.
+func (c Call) ChatID() int64 {
+	id, _ := strconv.ParseInt(c.Params["chat_id"], 10, 64)
+	return id
+}
+
+// Failure is an error response of the Bot API.
+type Failure struct {
+	Code        int
+	Description string
+	RetryAfter  int   // RetryAfter - seconds, for 429 responses
+	MigrateTo   int64 // MigrateTo - the new chat ID of a group upgraded to a supergroup
+}
+
+var (
+	TooManyRequests = Failure{Code: http.StatusTooManyRequests, Description: "Too Many Requests: retry after 1", RetryAfter: 1}
+	BotBlocked      = Failure{Code: http.StatusForbidden, Description: "Forbidden: bot was blocked by the user"}
+	BotKicked       = Failure{Code: http.StatusForbidden, Description: "Forbidden: bot was kicked from the group chat"}
+	BadGateway      = Failure{Code: http.StatusBadGateway, Description: "Bad Gateway"}
+	Unauthorized    = Failure{Code: http.StatusUnauthorized, Description: "Unauthorized"}
+
+	MessageNotFound      = Failure{Code: http.StatusBadRequest, Description: "Bad Request: message to delete not found"}
+	MessageCantBeDeleted = Failure{Code: http.StatusBadRequest, Description: "Bad Request: message can't be deleted"}
+)
+
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	calls         []Call
+	queued        map[string][]Failure
+	chatFailures  map[int64]Failure
+	invalidTokens map[string]bool
+	nextMessageID int
+	updates       map[string][]map[string]interface{}
+	nextUpdateID  int
+}
+
+func NewServer() *Server {
+	s := &Server{
+		queued:        make(map[string][]Failure),
+		chatFailures:  make(map[int64]Failure),
+		invalidTokens: make(map[string]bool),
+		nextMessageID: 1,
+		updates:       make(map[string][]map[string]interface{}),
+		nextUpdateID:  1,
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// Endpoint is the value for tgbotapi.NewBotAPIWithAPIEndpoint and the bot.apiEndpoint setting.
+func (s *Server) Endpoint() string {
+	return s.URL + "/bot%s/%s"
+}
+
+// Migrated is the failure of a call to a group upgraded to the supergroup toChatID.
+func Migrated(toChatID int64) Failure {
+	return Failure{
+		Code:        http.StatusBadRequest,
+		Description: "Bad Request: group chat was upgraded to a supergroup chat",
+		MigrateTo:   toChatID,
+	}
+}
+
+// FailNext makes the next calls of the method fail, one failure per call, in order.
+func (s *Server) FailNext(method string, failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queued[method] = append(s.queued[method], failures...)
+}
+
+// FailChat makes every call addressed to the chat fail until ResetChat.
+func (s *Server) FailChat(chatID int64, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chatFailures[chatID] = failure
+}
+
+func (s *Server) ResetChat(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.chatFailures, chatID)
+}
+
+// AddChatMemberUpdate queues a my_chat_member update for the bot with the token, getUpdates returns it until
+// an offset confirms it. chatType is private, group or supergroup, status is the new status of the bot.
+func (s *Server) AddChatMemberUpdate(token string, chatID int64, chatType, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updates[token] = append(s.updates[token], map[string]interface{}{
+		"update_id": s.nextUpdateID,
+		"my_chat_member": map[string]interface{}{
+			"chat":            map[string]interface{}{"id": chatID, "type": chatType},
+			"from":            map[string]interface{}{"id": 2, "is_bot": false, "first_name": "Admin"},
+			"date":            time.Now().Unix(),
+			"old_chat_member": map[string]interface{}{"user": map[string]interface{}{"id": 1, "is_bot": true}, "status": "member"},
+			"new_chat_member": map[string]interface{}{"user": map[string]interface{}{"id": 1, "is_bot": true}, "status": status},
+		},
+	})
+	s.nextUpdateID++
+}
+
+// RejectToken makes every call with the token fail as unauthorized.
+func (s *Server) RejectToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.invalidTokens[token] = true
+}
+
+// Calls returns the recorded calls of the method, all calls for an empty method.
+func (s *Server) Calls(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Call, 0, len(s.calls))
+	for _, call := range s.calls {
+		if method == "" || call.Method == method {
+			result = append(result, call)
+		}
+	}
+
+	return result
+}
+
+// WaitCalls waits until the method has been called at least n times.
+func (s *Server) WaitCalls(method string, n int, timeout time.Duration) ([]Call, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		calls := s.Calls(method)
+		if len(calls) >= n {
+			return calls, nil
+		}
+
+		if time.Now().After(deadline) {
+			return calls, fmt.Errorf("telegramtest: %d calls of %s after %s, want %d", len(calls), method, timeout, n)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		http.NotFound(w, r)
+		return
+	}
+
+	call := Call{
+		Token:  strings.TrimPrefix(parts[0], "bot"),
+		Method: parts[1],
+		Params: make(map[string]string),
+		Files:  make(map[string][]byte),
+	}
+
+	if err := readParams(r, &call); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"ok": false, "error_code": http.StatusBadRequest, "description": err.Error(),
+		})
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	failure, failed := s.failure(call)
+	messageID := s.nextMessageID
+	if !failed {
+		s.nextMessageID++
+	}
+	var updates []map[string]interface{}
+	if !failed && call.Method == "getUpdates" {
+		updates = s.pendingUpdates(call)
+	}
+	s.mu.Unlock()
+
+	if failed {
+		writeFailure(w, failure)
+		return
+	}
+
+	if call.Method == "getUpdates" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "result": updates})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "result": result(call, messageID)})
+}
+
+// failure must be called with the mutex held.
+func (s *Server) failure(call Call) (Failure, bool) {
+	if s.invalidTokens[call.Token] {
+		return Unauthorized, true
+	}
+
+	if queued := s.queued[call.Method]; len(queued) != 0 {
+		s.queued[call.Method] = queued[1:]
+		return queued[0], true
+	}
+
+	if failure, ok := s.chatFailures[call.ChatID()]; ok && call.ChatID() != 0 {
+		return failure, true
+	}
+
+	return Failure{}, false
+}
+
+// pendingUpdates drops the updates confirmed by the offset of the call and returns the rest,
+// it must be called with the mutex held.
+func (s *Server) pendingUpdates(call Call) []map[string]interface{} {
+	offset, _ := strconv.Atoi(call.Params["offset"])
+
+	pending := make([]map[string]interface{}, 0)
+	for _, update := range s.updates[call.Token] {
+		if update["update_id"].(int) >= offset {
+			pending = append(pending, update)
+		}
+	}
+
+	s.updates[call.Token] = pending
+
+	return pending
+}
+
+func readParams(r *http.Request, call *Call) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return err
+		}
+
+		for name, headers := range r.MultipartForm.File {
+			file, err := headers[0].Open()
+			if err != nil {
+				return err
+			}
+
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return err
+			}
+
+			call.Files[name] = data
+		}
+	} else if err := r.ParseForm(); err != nil {
+		return err
+	}
+
+	for name, values := range r.Form {
+		call.Params[name] = values[0]
+	}
+
+	return nil
+}
+
+func result(call Call, messageID int) interface{} {
+	switch {
+	case call.Method == "getMe":
+		return map[string]interface{}{
+			"id": 1, "is_bot": true, "first_name": "Test", "username": "test_" + strings.SplitN(call.Token, ":", 2)[0] + "_bot",
+		}
+	case call.Method == "createForumTopic":
+		return map[string]interface{}{"message_thread_id": messageID, "name": call.Params["name"], "icon_color": 7322096}
+	case strings.HasPrefix(call.Method, "send") || strings.HasPrefix(call.Method, "edit"):
+		id := messageID
+		if existing, err := strconv.Atoi(call.Params["message_id"]); err == nil {
+			id = existing
+		}
+
+		message := map[string]interface{}{
+			"message_id": id,
+			"date":       time.Now().Unix(),
+			"chat":       map[string]interface{}{"id": call.ChatID(), "type": "private"},
+		}
+		if text, ok := call.Params["text"]; ok {
+			message["text"] = text
+		}
+		if threadID, err := strconv.Atoi(call.Params["message_thread_id"]); err == nil {
+			message["message_thread_id"] = threadID
+			message["is_topic_message"] = true
+		}
+
+		return message
+	default:
+		return true
+	}
+}
+
+func writeFailure(w http.ResponseWriter, failure Failure) {
+	body := map[string]interface{}{
+		"ok":          false,
+		"error_code":  failure.Code,
+		"description": failure.Description,
+	}
+
+	parameters := map[string]interface{}{}
+	if failure.RetryAfter != 0 {
+		parameters["retry_after"] = failure.RetryAfter
+	}
+	if failure.MigrateTo != 0 {
+		parameters["migrate_to_chat_id"] = failure.MigrateTo
+	}
+	if len(parameters) != 0 {
+		body["parameters"] = parameters
+	}
+
+	writeJSON(w, failure.Code, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
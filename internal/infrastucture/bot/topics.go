@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// CreateTopic creates a forum topic in the account chat and returns its message thread ID. The chat must be
+// a forum supergroup and the bot an administrator allowed to manage topics.
+func (b *Bot) CreateTopic(ctx context.Context, account entities.TGAccount, name string) (int, error) {
+	ctx, span := b.tracer.Start(ctx, "bot.CreateTopic")
+	defer span.End()
+
+	done, err := b.begin()
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	defer done()
+
+	botAPI, err := b.registry.API(ctx, account.BotID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "registry.API")
+	}
+
+	if err = b.limiter.Wait(ctx); err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "limiter.Wait")
+	}
+
+	params := tgbotapi.Params{
+		"chat_id": strconv.FormatInt(account.ChatID, 10),
+		"name":    name,
+	}
+
+	var topic struct {
+		MessageThreadID int `json:"message_thread_id"`
+	}
+
+	err = permanent(b.breaker.Do(func() error {
+		resp, err := botAPI.MakeRequest("createForumTopic", params)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(resp.Result, &topic)
+	}))
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "botAPI.MakeRequest")
+	}
+
+	return topic.MessageThreadID, nil
+}
@@ -0,0 +1,85 @@
+package cipher
+
+import (
+	"crypto/aes"
+	stdCipher "crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNoKey         = errors.New("error encryption key is not configured")
+	ErrMalformedData = errors.New("error malformed encrypted data")
+)
+
+// TokenCipher encrypts bot tokens with AES-256-GCM before they are stored.
+type TokenCipher struct {
+	aead stdCipher.AEAD
+}
+
+// NewTokenCipher accepts a base64 encoded 32 byte key. An empty key gives a cipher that refuses to work,
+// so deployments that only use the default bot don't need one.
+func NewTokenCipher(key string) (*TokenCipher, error) {
+	if key == "" {
+		return &TokenCipher{}, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "base64.DecodeString")
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "aes.NewCipher")
+	}
+
+	aead, err := stdCipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "cipher.NewGCM")
+	}
+
+	return &TokenCipher{aead: aead}, nil
+}
+
+func (t TokenCipher) Encrypt(plain string) (string, error) {
+	if t.aead == nil {
+		return "", ErrNoKey
+	}
+
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "rand.Read")
+	}
+
+	sealed := t.aead.Seal(nonce, nonce, []byte(plain), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (t TokenCipher) Decrypt(encrypted string) (string, error) {
+	if t.aead == nil {
+		return "", ErrNoKey
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", errors.Wrap(err, "base64.DecodeString")
+	}
+
+	if len(raw) < t.aead.NonceSize() {
+		return "", ErrMalformedData
+	}
+
+	nonce, data := raw[:t.aead.NonceSize()], raw[t.aead.NonceSize():]
+
+	plain, err := t.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "aead.Open")
+	}
+
+	return string(plain), nil
+}
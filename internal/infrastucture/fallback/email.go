@@ -0,0 +1,65 @@
+package fallback
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// Email mails the alerts to the operators, the client chats have no email address to use.
+type Email struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewEmail creates the notifier, the addresses must have passed the config validation.
+func NewEmail(cfg config.FallbackConfig) *Email {
+	e := &Email{addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort)}
+
+	if cfg.SMTPUser != "" {
+		e.auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	if from, err := mail.ParseAddress(cfg.From); err == nil {
+		e.from = from.Address
+	}
+
+	if to, err := mail.ParseAddressList(cfg.To); err == nil {
+		for _, address := range to {
+			e.to = append(e.to, address.Address)
+		}
+	}
+
+	return e
+}
+
+func (e *Email) NotifyFallback(
+	_ context.Context, account entities.TGAccount, msg entities.NotificationMessage, reason string,
+) error {
+	alert := newAlert(account, msg, reason)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: Gunshot alert for client %s\r\n", alert.ClientID)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(alert.text(), "\n", "\r\n"))
+
+	if err := smtp.SendMail(e.addr, e.auth, e.from, e.to, []byte(b.String())); err != nil {
+		return errors.Wrap(err, "smtp.SendMail")
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package fallback sends the alerts through a channel other than Telegram while Telegram is unreachable.
+package fallback
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// Alert is what a fallback channel is given about an alert that couldn't be sent to the chat of the client.
+type Alert struct {
+	ClientID string                       `json:"clientID"`
+	ChatID   int64                        `json:"chatID"`
+	Message  entities.NotificationMessage `json:"message"`
+	Reason   string                       `json:"reason"` // Reason - why Telegram couldn't be used
+}
+
+func newAlert(account entities.TGAccount, msg entities.NotificationMessage, reason string) Alert {
+	return Alert{ClientID: account.ClientID.Hex(), ChatID: account.ChatID, Message: msg, Reason: reason}
+}
+
+// text renders the alert for a human reader.
+func (a Alert) text() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Gunshot alert for client %s (Telegram chat %d)\n", a.ClientID, a.ChatID)
+	fmt.Fprintf(&b, "Detected at: %s\n", a.Message.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&b, "Request: %s\n", a.Message.RequestID)
+
+	if a.Message.SensorName != "" {
+		fmt.Fprintf(&b, "Sensor: %s\n", a.Message.SensorName)
+	}
+	if a.Message.Address != "" {
+		fmt.Fprintf(&b, "Address: %s\n", a.Message.Address)
+	}
+	if a.Message.Location != nil {
+		fmt.Fprintf(&b, "Location: %s\n", a.Message.Location.MapLink())
+	}
+
+	fmt.Fprintf(&b, "\nThe alert couldn't be sent to Telegram: %s\n", a.Reason)
+
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package fallback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _webhookTimeout = 10 * time.Second
+
+// Webhook posts the alerts as JSON, any 2xx status is a success.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: _webhookTimeout},
+	}
+}
+
+func (w *Webhook) NotifyFallback(
+	ctx context.Context, account entities.TGAccount, msg entities.NotificationMessage, reason string,
+) error {
+	body, err := json.Marshal(newAlert(account, msg, reason))
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "http.NewRequestWithContext")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "client.Do")
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package media
+
+import (
+	"context"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Dir keeps the objects as files under a root directory, the keys are their slash-separated paths.
+type Dir struct {
+	root string
+}
+
+func NewDir(root string) *Dir {
+	return &Dir{root: root}
+}
+
+func (d *Dir) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(d.root, filepath.FromSlash(key)), nil
+}
+
+func (d *Dir) Stat(_ context.Context, key string) (Info, error) {
+	name, err := d.path(key)
+	if err != nil {
+		return Info{}, err
+	}
+
+	stat, err := os.Stat(name)
+	if err != nil {
+		return Info{}, fileError(err, key)
+	}
+
+	return fileInfo(key, stat)
+}
+
+func (d *Dir) Open(_ context.Context, key string) (io.ReadCloser, Info, error) {
+	name, err := d.path(key)
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, Info{}, fileError(err, key)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, Info{}, errors.Wrap(err, "file.Stat")
+	}
+
+	info, err := fileInfo(key, stat)
+	if err != nil {
+		file.Close()
+		return nil, Info{}, err
+	}
+
+	return file, info, nil
+}
+
+// Presign always fails, the files can only be uploaded.
+func (d *Dir) Presign(context.Context, string, time.Duration) (string, error) {
+	return "", ErrNoURL
+}
+
+func fileInfo(key string, stat os.FileInfo) (Info, error) {
+	if !stat.Mode().IsRegular() {
+		return Info{}, errors.Wrapf(ErrNotFound, "%q isn't a file", key)
+	}
+
+	return Info{Size: stat.Size(), ContentType: mime.TypeByExtension(path.Ext(key))}, nil
+}
+
+func fileError(err error, key string) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.Wrapf(ErrNotFound, "%q", key)
+	}
+
+	return errors.Wrap(err, "os.Open")
+}
@@ -0,0 +1,229 @@
+// Package media fetches the recordings and the photos the alerts reference by key from an object
+// storage, an S3-compatible one or a directory, checking their size and checksum on the way.
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+var (
+	ErrNotFound   = errors.New("error media object not found")
+	ErrInvalidKey = errors.New("error invalid media object key")
+	ErrTooLarge   = errors.New("error media object is too large")
+	// ErrMismatch is returned for an object whose size or checksum differs from the reference or
+	// from what the storage keeps for it.
+	ErrMismatch = errors.New("error media object doesn't match")
+	// ErrNoURL is returned when the object can't be handed out by URL and has to be uploaded.
+	ErrNoURL = errors.New("error media object has no URL")
+)
+
+// Info describes a stored object.
+type Info struct {
+	Size        int64
+	ContentType string
+	SHA256      string // SHA256 - hex digest the storage keeps for the object, empty when it keeps none
+}
+
+// Backend is an object storage.
+type Backend interface {
+	Stat(ctx context.Context, key string) (Info, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	// Presign returns a URL the object can be fetched from without credentials, ErrNoURL if there is none.
+	Presign(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Source hands out the objects of a backend within the configured limits.
+type Source struct {
+	backend       Backend
+	tracer        trace.Tracer
+	maxSize       int64
+	presign       bool
+	presignExpiry time.Duration
+}
+
+func NewSource(backend Backend, cfg config.MediaConfig) *Source {
+	return &Source{
+		backend:       backend,
+		tracer:        otel.GetTracerProvider().Tracer("media"),
+		maxSize:       cfg.MaxSize,
+		presign:       cfg.Presign,
+		presignExpiry: cfg.PresignExpiry,
+	}
+}
+
+// Open streams the object, it's refused when larger than the limit or the configured maximum. The reader
+// fails with ErrMismatch at the end of an object that isn't what the reference or the storage says.
+func (s *Source) Open(ctx context.Context, obj entities.MediaObject, limit int64) (io.ReadCloser, Info, error) {
+	ctx, span := s.tracer.Start(ctx, "media.Open", trace.WithAttributes(attribute.String("media.key", obj.Key)))
+	defer span.End()
+
+	if err := ValidateKey(obj.Key); err != nil {
+		span.RecordError(err)
+		return nil, Info{}, err
+	}
+
+	body, info, err := s.backend.Open(ctx, obj.Key)
+	if err != nil {
+		span.RecordError(err)
+		return nil, Info{}, errors.Wrap(err, "backend.Open")
+	}
+
+	if err = s.check(obj, info, limit); err != nil {
+		body.Close()
+		span.RecordError(err)
+		return nil, Info{}, err
+	}
+
+	want := obj.SHA256
+	if want == "" {
+		want = info.SHA256
+	}
+
+	v := &verifier{body: body, hash: sha256.New(), size: info.Size, max: info.Size, want: strings.ToLower(want)}
+	if info.Size < 0 {
+		v.max = s.limit(limit)
+	}
+
+	return v, info, nil
+}
+
+// URL returns a presigned URL of the object for whoever can reach the storage. It's ErrNoURL when
+// presigning is off, the object is larger than the limit or its checksum can't be verified without
+// fetching it.
+func (s *Source) URL(ctx context.Context, obj entities.MediaObject, limit int64) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "media.URL", trace.WithAttributes(attribute.String("media.key", obj.Key)))
+	defer span.End()
+
+	if !s.presign {
+		return "", ErrNoURL
+	}
+
+	if err := ValidateKey(obj.Key); err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	info, err := s.backend.Stat(ctx, obj.Key)
+	if err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "backend.Stat")
+	}
+
+	if err = s.check(obj, info, s.maxSize); err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	if info.Size > limit {
+		return "", errors.Wrapf(ErrNoURL, "%d bytes is over %d", info.Size, limit)
+	}
+
+	if obj.SHA256 != "" && info.SHA256 == "" {
+		return "", errors.Wrap(ErrNoURL, "the storage keeps no checksum to verify")
+	}
+
+	u, err := s.backend.Presign(ctx, obj.Key, s.presignExpiry)
+	if err != nil {
+		span.RecordError(err)
+		return "", errors.Wrap(err, "backend.Presign")
+	}
+
+	return u, nil
+}
+
+// check compares what the storage tells about the object with the reference and the size limits.
+func (s *Source) check(obj entities.MediaObject, info Info, limit int64) error {
+	if obj.Size != 0 && info.Size != obj.Size {
+		return errors.Wrapf(ErrMismatch, "%d bytes stored, %d referenced", info.Size, obj.Size)
+	}
+
+	if obj.SHA256 != "" && info.SHA256 != "" && !strings.EqualFold(obj.SHA256, info.SHA256) {
+		return errors.Wrapf(ErrMismatch, "checksum %s stored, %s referenced", info.SHA256, obj.SHA256)
+	}
+
+	if limit = s.limit(limit); info.Size > limit {
+		return errors.Wrapf(ErrTooLarge, "%d bytes is over %d", info.Size, limit)
+	}
+
+	return nil
+}
+
+func (s *Source) limit(limit int64) int64 {
+	if limit > s.maxSize {
+		return s.maxSize
+	}
+
+	return limit
+}
+
+// ValidateKey rejects the keys that are empty, absolute or climb out of the root with "..".
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 1024 || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return errors.Wrapf(ErrInvalidKey, "%q", key)
+	}
+
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return errors.Wrapf(ErrInvalidKey, "%q", key)
+		}
+	}
+
+	return nil
+}
+
+// verifier reads exactly size bytes, at most max when the size isn't known, and checks their digest
+// against want at the end when it's set.
+type verifier struct {
+	body io.ReadCloser
+	hash hash.Hash
+	read int64
+	size int64 // size - negative when unknown
+	max  int64
+	want string
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.body.Read(p)
+	v.hash.Write(p[:n])
+	v.read += int64(n)
+
+	if v.read > v.max {
+		if v.size < 0 {
+			return n, errors.Wrapf(ErrTooLarge, "more than %d bytes", v.max)
+		}
+
+		return n, errors.Wrapf(ErrMismatch, "more than %d bytes", v.size)
+	}
+
+	if err != io.EOF {
+		return n, err
+	}
+
+	if v.size >= 0 && v.read != v.size {
+		return n, errors.Wrapf(ErrMismatch, "%d bytes of %d", v.read, v.size)
+	}
+
+	if got := hex.EncodeToString(v.hash.Sum(nil)); v.want != "" && got != v.want {
+		return n, errors.Wrapf(ErrMismatch, "checksum %s, want %s", got, v.want)
+	}
+
+	return n, io.EOF
+}
+
+func (v *verifier) Close() error {
+	return v.body.Close()
+}
@@ -0,0 +1,139 @@
+// Package mediatest provides a stand-in of an S3-compatible object storage like a local MinIO, with the
+// bucket in the path. It checks the signatures, serves HEAD and GET of the objects put into it and
+// records the requests, so the media can be fetched without a real storage.
+//
+//	srv := mediatest.NewServer("media", mediatest.Credentials)
+//	defer srv.Close()
+//	srv.Put("alerts/1.wav", mediatest.Object{Data: wav, ContentType: "audio/wav", SHA256: mediatest.Checksum(wav)})
+package mediatest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/media/sigv4"
+)
+
+// Credentials are what MinIO starts with when nothing else is configured.
+var Credentials = sigv4.Credentials{AccessKey: "minioadmin", SecretKey: "minioadmin", Region: "us-east-1"}
+
+// Object is stored content with the metadata the server returns for it.
+type Object struct {
+	Data        []byte
+	ContentType string
+	SHA256      string // SHA256 - hex, returned as the checksum S3 keeps; empty for the objects uploaded without one
+}
+
+// Request is a single request received by the server.
+type Request struct {
+	Method    string
+	Key       string
+	Presigned bool // Presigned - authorized by the URL rather than the Authorization header
+}
+
+type Server struct {
+	*httptest.Server
+
+	bucket string
+	creds  sigv4.Credentials
+
+	mu       sync.Mutex
+	objects  map[string]Object
+	requests []Request
+}
+
+func NewServer(bucket string, creds sigv4.Credentials) *Server {
+	s := &Server{bucket: bucket, creds: creds, objects: make(map[string]Object)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// Checksum returns the hex SHA-256 of the data.
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Put stores the object, replacing the one under the key.
+func (s *Server) Put(key string, obj Object) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = obj
+}
+
+// Requests returns the requests for the objects received so far, the rejected ones included.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method, Key: key, Presigned: r.URL.Query().Get("X-Amz-Signature") != "",
+	})
+	obj, found := s.objects[key]
+	s.mu.Unlock()
+
+	if err := sigv4.Verify(r, s.creds, time.Now()); err != nil {
+		writeError(w, r, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	switch {
+	case r.Method != http.MethodGet && r.Method != http.MethodHead:
+		writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "only GET and HEAD are supported")
+		return
+	case bucket != s.bucket:
+		writeError(w, r, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	case !found:
+		writeError(w, r, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.Data)))
+	w.Header().Set("ETag", `"`+Checksum(obj.Data)[:32]+`"`)
+	if obj.ContentType != "" {
+		w.Header().Set("Content-Type", obj.ContentType)
+	}
+
+	if sum, err := hex.DecodeString(obj.SHA256); err == nil && obj.SHA256 != "" &&
+		strings.EqualFold(r.Header.Get("X-Amz-Checksum-Mode"), "ENABLED") {
+		w.Header().Set("X-Amz-Checksum-Sha256", base64.StdEncoding.EncodeToString(sum))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(obj.Data)
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: message})
+}
@@ -0,0 +1,146 @@
+package media
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/media/sigv4"
+)
+
+// S3 reads the objects of a bucket through the S3 API, it works with AWS and the compatible storages
+// such as MinIO.
+type S3 struct {
+	client    *http.Client
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	creds     sigv4.Credentials
+}
+
+func NewS3(cfg config.MediaConfig, client *http.Client) (*S3, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "url.Parse")
+	}
+
+	return &S3{
+		client:    client,
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.PathStyle,
+		creds:     sigv4.Credentials{AccessKey: cfg.AccessKey, SecretKey: cfg.SecretKey, Region: cfg.Region},
+	}, nil
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+
+	if s.pathStyle {
+		u.Path = base + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+
+	u.RawPath = sigv4.Escape(u.Path, false)
+
+	return &u
+}
+
+// do sends a signed request for the object, the checksum is asked for as S3 only returns it on demand.
+func (s *S3) do(ctx context.Context, method, key string) (*http.Response, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "http.NewRequest")
+	}
+
+	req.Header.Set("X-Amz-Checksum-Mode", "ENABLED")
+	sigv4.Sign(req, s.creds, sigv4.EmptyPayload, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "client.Do")
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.Wrapf(ErrNotFound, "%q", key)
+	}
+
+	return nil, responseError(resp)
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (Info, error) {
+	resp, err := s.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return Info{}, err
+	}
+	defer resp.Body.Close()
+
+	return responseInfo(resp), nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	resp, err := s.do(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	return resp.Body, responseInfo(resp), nil
+}
+
+func (s *S3) Presign(_ context.Context, key string, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+
+	return sigv4.Presign(http.MethodGet, s.objectURL(key), s.creds, time.Now(), expiry).String(), nil
+}
+
+// responseInfo reads the object from the headers, the checksum is the one S3 keeps for the objects
+// uploaded with a SHA-256 or, failing that, a hex digest in the sha256 user metadata.
+func responseInfo(resp *http.Response) Info {
+	info := Info{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+
+	if sum, err := base64.StdEncoding.DecodeString(resp.Header.Get("X-Amz-Checksum-Sha256")); err == nil && len(sum) == 32 {
+		info.SHA256 = hex.EncodeToString(sum)
+	} else if sum, err := hex.DecodeString(resp.Header.Get("X-Amz-Meta-Sha256")); err == nil && len(sum) == 32 {
+		info.SHA256 = hex.EncodeToString(sum)
+	}
+
+	return info
+}
+
+// responseError reads the S3 error document, HEAD responses have none and get the status only.
+func responseError(resp *http.Response) error {
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&s3Err); err != nil || s3Err.Code == "" {
+		return errors.Errorf("s3: %s", resp.Status)
+	}
+
+	return errors.Errorf("s3: %s: %s: %s", resp.Status, s3Err.Code, s3Err.Message)
+}
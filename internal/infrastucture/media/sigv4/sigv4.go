@@ -0,0 +1,255 @@
+// Package sigv4 signs S3 API requests with AWS Signature Version 4, both in the Authorization header and
+// as presigned URLs, and verifies them for the test stand-ins.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	_algorithm  = "AWS4-HMAC-SHA256"
+	_timeFormat = "20060102T150405Z"
+	_dateFormat = "20060102"
+
+	// EmptyPayload is the hash of the empty body of GET and HEAD requests.
+	EmptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// UnsignedPayload is the payload hash of presigned URLs, the body isn't known when they are made.
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// ErrInvalidSignature is returned by the verification of a request that isn't signed by the credentials.
+var ErrInvalidSignature = errors.New("error invalid signature")
+
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+	Service   string // Service - s3 when empty
+}
+
+func (c Credentials) scope(date string) string {
+	service := c.Service
+	if service == "" {
+		service = "s3"
+	}
+
+	return strings.Join([]string{date, c.Region, service, "aws4_request"}, "/")
+}
+
+func (c Credentials) key(scope string) []byte {
+	key := []byte("AWS4" + c.SecretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+
+	return key
+}
+
+// Sign signs the request with a body of the payload hash in the Authorization header. The host, range,
+// content-md5, content-type and x-amz-* headers are signed.
+func Sign(req *http.Request, creds Credentials, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(_timeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := signedHeaders(req.Header)
+	scope := creds.scope(now.Format(_dateFormat))
+	signature := creds.signature(scope, now, canonicalRequest(req, req.URL.Query(), signed, payloadHash))
+
+	req.Header.Set("Authorization", _algorithm+" Credential="+creds.AccessKey+"/"+scope+
+		", SignedHeaders="+strings.Join(signed, ";")+", Signature="+signature)
+}
+
+// Presign returns the URL signed for the method, valid for the expiry from now. Only the host is signed,
+// whoever fetches the URL needs no other headers.
+func Presign(method string, u *url.URL, creds Credentials, now time.Time, expiry time.Duration) *url.URL {
+	now = now.UTC()
+	scope := creds.scope(now.Format(_dateFormat))
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", _algorithm)
+	query.Set("X-Amz-Credential", creds.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(_timeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	req := &http.Request{Method: method, URL: u, Host: u.Host}
+	query.Set("X-Amz-Signature", creds.signature(scope, now,
+		canonicalRequest(req, query, []string{"host"}, UnsignedPayload)))
+
+	presigned := *u
+	presigned.RawQuery = canonicalQuery(query)
+
+	return &presigned
+}
+
+// Verify checks that the request was signed by the credentials, in the header or as a presigned URL
+// that hasn't expired at now.
+func Verify(req *http.Request, creds Credentials, now time.Time) error {
+	query := req.URL.Query()
+	if query.Get("X-Amz-Signature") != "" {
+		return verifyPresigned(req, query, creds, now)
+	}
+
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimPrefix(req.Header.Get("Authorization"), _algorithm+" "), ",") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(field), "="); ok {
+			fields[name] = value
+		}
+	}
+
+	signedAt, err := time.Parse(_timeFormat, req.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return errors.Wrap(ErrInvalidSignature, "X-Amz-Date")
+	}
+
+	// Signatures are accepted 15 minutes either side of the time of the server.
+	if signedAt.Sub(now) > 15*time.Minute || now.Sub(signedAt) > 15*time.Minute {
+		return errors.Wrap(ErrInvalidSignature, "request time too skewed")
+	}
+
+	scope := creds.scope(signedAt.Format(_dateFormat))
+	if fields["Credential"] != creds.AccessKey+"/"+scope {
+		return errors.Wrapf(ErrInvalidSignature, "unknown credential %q", fields["Credential"])
+	}
+
+	signature := creds.signature(scope, signedAt, canonicalRequest(req, query,
+		strings.Split(fields["SignedHeaders"], ";"), req.Header.Get("X-Amz-Content-Sha256")))
+	if !hmac.Equal([]byte(signature), []byte(fields["Signature"])) {
+		return errors.Wrap(ErrInvalidSignature, "signature mismatch")
+	}
+
+	return nil
+}
+
+func verifyPresigned(req *http.Request, query url.Values, creds Credentials, now time.Time) error {
+	signedAt, err := time.Parse(_timeFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return errors.Wrap(ErrInvalidSignature, "X-Amz-Date")
+	}
+
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || now.After(signedAt.Add(time.Duration(expires)*time.Second)) {
+		return errors.Wrap(ErrInvalidSignature, "presigned URL expired")
+	}
+
+	scope := creds.scope(signedAt.Format(_dateFormat))
+	if query.Get("X-Amz-Credential") != creds.AccessKey+"/"+scope {
+		return errors.Wrapf(ErrInvalidSignature, "unknown credential %q", query.Get("X-Amz-Credential"))
+	}
+
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+
+	expected := creds.signature(scope, signedAt, canonicalRequest(req, query,
+		strings.Split(query.Get("X-Amz-SignedHeaders"), ";"), UnsignedPayload))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.Wrap(ErrInvalidSignature, "signature mismatch")
+	}
+
+	return nil
+}
+
+func (c Credentials) signature(scope string, signedAt time.Time, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := strings.Join([]string{
+		_algorithm, signedAt.UTC().Format(_timeFormat), scope, hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	return hex.EncodeToString(hmacSHA256(c.key(scope), stringToSign))
+}
+
+func canonicalRequest(req *http.Request, query url.Values, signed []string, payloadHash string) string {
+	headers := make([]string, 0, len(signed))
+	for _, name := range signed {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		}
+
+		headers = append(headers, name+":"+strings.Join(strings.Fields(value), " ")+"\n")
+	}
+
+	path := req.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		Escape(path, false),
+		canonicalQuery(query),
+		strings.Join(headers, ""),
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func signedHeaders(header http.Header) []string {
+	signed := []string{"host"}
+	for name := range header {
+		name = strings.ToLower(name)
+		if name == "range" || name == "content-md5" || name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			signed = append(signed, name)
+		}
+	}
+
+	sort.Strings(signed)
+
+	return signed
+}
+
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, Escape(name, true)+"="+Escape(value, true))
+		}
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// Escape percent-encodes everything but the unreserved characters the way AWS does, the slashes are
+// kept unless escapeSlash is set.
+func Escape(s string, escapeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
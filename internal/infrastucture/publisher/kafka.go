@@ -0,0 +1,62 @@
+// Package publisher sends the outbox events to Kafka.
+package publisher
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// Headers of the published events, the value is the JSON payload and the key is the client ID.
+const (
+	HeaderEventType = "x-event-type"
+	HeaderEventSeq  = "x-event-seq" // HeaderEventSeq - lets consumers drop the events of a key published again after a crash
+)
+
+type KafkaPublisher struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewKafkaPublisher creates a publisher waiting for all the in-sync replicas, so an acknowledged event is not lost.
+func NewKafkaPublisher(peers, topic string) (*KafkaPublisher, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = sarama.V3_3_0_0
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+
+	producer, err := sarama.NewSyncProducer(strings.Split(peers, ","), saramaConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "sarama.NewSyncProducer")
+	}
+
+	return &KafkaPublisher{producer: producer, topic: topic}, nil
+}
+
+func (p *KafkaPublisher) Publish(_ context.Context, event entities.OutboxEvent) error {
+	message := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(event.Key),
+		Value: sarama.ByteEncoder(event.Payload),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(HeaderEventType), Value: []byte(event.Type)},
+			{Key: []byte(HeaderEventSeq), Value: []byte(strconv.FormatInt(event.Seq, 10))},
+		},
+		Timestamp: event.CreatedAt,
+	}
+
+	if _, _, err := p.producer.SendMessage(message); err != nil {
+		return errors.Wrap(err, "producer.SendMessage")
+	}
+
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.producer.Close()
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/multierr"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateBot(ctx context.Context, bot entities.Bot) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateBot")
+	defer span.End()
+
+	if _, err := r.bots.InsertOne(ctx, bot); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "bots.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetBot(ctx context.Context, botID string) (entities.Bot, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetBot")
+	defer span.End()
+
+	var bot entities.Bot
+
+	castedID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return bot, entities.ErrRecordNotFound
+	}
+
+	if err = r.bots.FindOne(ctx, bson.M{"_id": castedID}).Decode(&bot); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return bot, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return bot, errors.Wrap(err, "bots.FindOne")
+	}
+
+	return bot, nil
+}
+
+func (r Repository) ListBots(ctx context.Context, tenantID string) ([]entities.Bot, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListBots")
+	defer span.End()
+
+	filter := bson.M{}
+	if tenantID != "" {
+		filter["tenantID"] = tenantID
+	}
+
+	cursor, err := r.bots.Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "bots.Find")
+	}
+
+	bots := make([]entities.Bot, 0)
+	if err = cursor.All(ctx, &bots); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return bots, nil
+}
+
+// DeleteBot removes the bot unless an account uses it. Mongo can't make the delete depend on the accounts,
+// so they are counted again once the bot is gone and the bot is put back if one was assigned it meanwhile.
+func (r Repository) DeleteBot(ctx context.Context, botID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteBot")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return entities.ErrRecordNotFound
+	}
+
+	if err = r.botUnused(ctx, botID); err != nil {
+		return err
+	}
+
+	var bot entities.Bot
+	if err = r.bots.FindOneAndDelete(ctx, bson.M{"_id": castedID}).Decode(&bot); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "bots.FindOneAndDelete")
+	}
+
+	if err = r.botUnused(ctx, botID); err == nil {
+		return nil
+	}
+
+	if _, insertErr := r.bots.InsertOne(ctx, bot); insertErr != nil {
+		span.RecordError(insertErr)
+		return multierr.Append(err, errors.Wrap(insertErr, "bots.InsertOne"))
+	}
+
+	return err
+}
+
+// botUnused returns entities.ErrRecordInUse when an account uses the bot.
+func (r Repository) botUnused(ctx context.Context, botID string) error {
+	count, err := r.CountAccountsByBotID(ctx, botID)
+	if err != nil {
+		return err
+	}
+
+	if count != 0 {
+		return entities.ErrRecordInUse
+	}
+
+	return nil
+}
+
+func (r Repository) CountAccountsByBotID(ctx context.Context, botID string) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.CountAccountsByBotID")
+	defer span.End()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"botID": botID})
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "collection.CountDocuments")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateBroadcast(ctx context.Context, broadcast entities.Broadcast) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateBroadcast")
+	defer span.End()
+
+	if _, err := r.broadcasts.InsertOne(ctx, broadcast); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "broadcasts.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetBroadcast(ctx context.Context, broadcastID string) (entities.Broadcast, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetBroadcast")
+	defer span.End()
+
+	var broadcast entities.Broadcast
+
+	castedID, err := primitive.ObjectIDFromHex(broadcastID)
+	if err != nil {
+		return broadcast, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = r.broadcasts.FindOne(ctx, bson.M{"_id": castedID}).Decode(&broadcast); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return broadcast, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return broadcast, errors.Wrap(err, "broadcasts.FindOne")
+	}
+
+	return broadcast, nil
+}
+
+// ListBroadcasts returns the broadcasts in one of the statuses newest first, all of them when none is given.
+func (r Repository) ListBroadcasts(
+	ctx context.Context, statuses ...entities.BroadcastStatus,
+) ([]entities.Broadcast, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListBroadcasts")
+	defer span.End()
+
+	query := bson.M{}
+	if len(statuses) != 0 {
+		query["status"] = bson.M{"$in": statuses}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.broadcasts.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "broadcasts.Find")
+	}
+
+	broadcasts := make([]entities.Broadcast, 0)
+	if err = cursor.All(ctx, &broadcasts); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return broadcasts, nil
+}
+
+// UpdateBroadcast replaces a broadcast that is not finished yet. entities.ErrRecordNotFound is returned for a finished
+// one, so that the progress of a running job never overwrites a cancellation.
+func (r Repository) UpdateBroadcast(ctx context.Context, broadcast entities.Broadcast) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateBroadcast")
+	defer span.End()
+
+	filter := bson.M{
+		"_id":    broadcast.ID,
+		"status": bson.M{"$in": bson.A{entities.BroadcastPending, entities.BroadcastRunning}},
+	}
+
+	res, err := r.broadcasts.ReplaceOne(ctx, filter, broadcast)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "broadcasts.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
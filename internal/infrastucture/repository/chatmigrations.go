@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// botQuery matches the bot ID, the default bot is stored without the field and null matches a missing one.
+func botQuery(botID string) interface{} {
+	if botID == "" {
+		return nil
+	}
+
+	return botID
+}
+
+// MigrateChat moves the accounts of the bot from the old chat to the new one, it returns their client IDs.
+func (r Repository) MigrateChat(ctx context.Context, botID string, fromChatID, toChatID int64) ([]string, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.MigrateChat")
+	defer span.End()
+
+	filter := bson.M{"botID": botQuery(botID), "chatID": fromChatID}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}).SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "collection.Find")
+	}
+
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &found); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	clientIDs := make([]string, 0, len(found))
+	ids := make(bson.A, 0, len(found))
+	for _, account := range found {
+		clientIDs = append(clientIDs, account.ID.Hex())
+		ids = append(ids, account.ID)
+	}
+
+	if len(ids) == 0 {
+		return clientIDs, nil
+	}
+
+	_, err = r.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$set": bson.M{"chatID": toChatID}})
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "collection.UpdateMany")
+	}
+
+	return clientIDs, nil
+}
+
+func (r Repository) AddChatMigration(ctx context.Context, migration entities.ChatMigration) error {
+	ctx, span := r.tracer.Start(ctx, "repo.AddChatMigration")
+	defer span.End()
+
+	if _, err := r.migrations.InsertOne(ctx, migration); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "migrations.InsertOne")
+	}
+
+	return nil
+}
+
+// ListChatMigrations returns the migrations that moved the account of the client, newest first.
+func (r Repository) ListChatMigrations(ctx context.Context, clientID string) ([]entities.ChatMigration, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListChatMigrations")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "migratedAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := r.migrations.Find(ctx, bson.M{"clientIDs": clientID}, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "migrations.Find")
+	}
+
+	migrations := make([]entities.ChatMigration, 0)
+	if err = cursor.All(ctx, &migrations); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return migrations, nil
+}
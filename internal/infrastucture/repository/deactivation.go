@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// SetChatDeactivation deactivates the active accounts of the bot in the chat, or reactivates the inactive
+// ones for a nil deactivation. It returns the client IDs of the accounts it changed.
+func (r Repository) SetChatDeactivation(
+	ctx context.Context, botID string, chatID int64, deactivation *entities.Deactivation,
+) ([]string, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.SetChatDeactivation")
+	defer span.End()
+
+	filter := bson.M{"botID": botQuery(botID), "chatID": chatID, "deactivation": bson.M{"$exists": deactivation == nil}}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}).SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "collection.Find")
+	}
+
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &found); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	clientIDs := make([]string, 0, len(found))
+	ids := make(bson.A, 0, len(found))
+	for _, account := range found {
+		clientIDs = append(clientIDs, account.ID.Hex())
+		ids = append(ids, account.ID)
+	}
+
+	if len(ids) == 0 {
+		return clientIDs, nil
+	}
+
+	update := bson.M{"$unset": bson.M{"deactivation": ""}}
+	if deactivation != nil {
+		update = bson.M{"$set": bson.M{"deactivation": deactivation}}
+	}
+
+	if _, err = r.collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, update); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "collection.UpdateMany")
+	}
+
+	return clientIDs, nil
+}
+
+// GetUpdateOffset returns the offset of the next update of the bot to poll, zero when none is stored.
+func (r Repository) GetUpdateOffset(ctx context.Context, botID string) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetUpdateOffset")
+	defer span.End()
+
+	var stored struct {
+		Offset int `bson:"offset"`
+	}
+
+	err := r.offsets.FindOne(ctx, bson.M{"_id": botID}).Decode(&stored)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "offsets.FindOne")
+	}
+
+	return stored.Offset, nil
+}
+
+func (r Repository) SetUpdateOffset(ctx context.Context, botID string, offset int) error {
+	ctx, span := r.tracer.Start(ctx, "repo.SetUpdateOffset")
+	defer span.End()
+
+	_, err := r.offsets.UpdateOne(ctx,
+		bson.M{"_id": botID}, bson.M{"$set": bson.M{"offset": offset}}, options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "offsets.UpdateOne")
+	}
+
+	return nil
+}
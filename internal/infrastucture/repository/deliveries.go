@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// SaveDelivery stores the delivery, replacing the one of the same request and chat.
+func (r Repository) SaveDelivery(ctx context.Context, delivery entities.Delivery) error {
+	ctx, span := r.tracer.Start(ctx, "repo.SaveDelivery")
+	defer span.End()
+
+	filter := bson.M{"requestID": delivery.RequestID, "chatID": delivery.ChatID}
+	if _, err := r.deliveries.ReplaceOne(ctx, filter, delivery, options.Replace().SetUpsert(true)); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "deliveries.ReplaceOne")
+	}
+
+	return nil
+}
+
+func (r Repository) ListDeliveries(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDeliveries")
+	defer span.End()
+
+	query := bson.M{}
+	if filter.RequestID != "" {
+		query["requestID"] = filter.RequestID
+	}
+	if filter.ClientID != "" {
+		query["clientID"] = filter.ClientID
+	}
+
+	sentAt := bson.M{}
+	if !filter.Since.IsZero() {
+		sentAt["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		sentAt["$lt"] = filter.Until
+	}
+	if len(sentAt) != 0 {
+		query["sentAt"] = sentAt
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "sentAt", Value: -1}, {Key: "chatID", Value: 1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := r.deliveries.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "deliveries.Find")
+	}
+
+	deliveries := make([]entities.Delivery, 0)
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateDigestSchedule")
+	defer span.End()
+
+	if _, err := r.digests.InsertOne(ctx, schedule); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "digests.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetDigestSchedule(ctx context.Context, scheduleID string) (entities.DigestSchedule, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetDigestSchedule")
+	defer span.End()
+
+	var schedule entities.DigestSchedule
+
+	castedID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return schedule, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = r.digests.FindOne(ctx, bson.M{"_id": castedID}).Decode(&schedule); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return schedule, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return schedule, errors.Wrap(err, "digests.FindOne")
+	}
+
+	return schedule, nil
+}
+
+func (r Repository) ListDigestSchedules(ctx context.Context, clientID string) ([]entities.DigestSchedule, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDigestSchedules")
+	defer span.End()
+
+	return r.findDigestSchedules(ctx, bson.M{"clientID": clientID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+}
+
+// ListDueDigestSchedules returns the schedules due by now, the longest overdue first.
+func (r Repository) ListDueDigestSchedules(
+	ctx context.Context, now time.Time, limit int,
+) ([]entities.DigestSchedule, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDueDigestSchedules")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "nextRunAt", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	return r.findDigestSchedules(ctx, bson.M{"nextRunAt": bson.M{"$lte": now}}, opts)
+}
+
+func (r Repository) findDigestSchedules(
+	ctx context.Context, filter bson.M, opts *options.FindOptions,
+) ([]entities.DigestSchedule, error) {
+	span := trace.SpanFromContext(ctx)
+
+	cursor, err := r.digests.Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "digests.Find")
+	}
+
+	schedules := make([]entities.DigestSchedule, 0)
+	if err = cursor.All(ctx, &schedules); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return schedules, nil
+}
+
+func (r Repository) UpdateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateDigestSchedule")
+	defer span.End()
+
+	res, err := r.digests.ReplaceOne(ctx, bson.M{"_id": schedule.ID}, schedule)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "digests.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteDigestSchedule(ctx context.Context, scheduleID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteDigestSchedule")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	res, err := r.digests.DeleteOne(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "digests.DeleteOne")
+	}
+
+	if res.DeletedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r *Repository) CreateBroadcast(_ context.Context, broadcast entities.Broadcast) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.broadcasts[broadcast.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.broadcasts[broadcast.ID] = cloneBroadcast(broadcast)
+
+	return nil
+}
+
+func (r *Repository) GetBroadcast(_ context.Context, broadcastID string) (entities.Broadcast, error) {
+	castedID, err := primitive.ObjectIDFromHex(broadcastID)
+	if err != nil {
+		return entities.Broadcast{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	broadcast, ok := r.broadcasts[castedID]
+	if !ok {
+		return entities.Broadcast{}, entities.ErrRecordNotFound
+	}
+
+	return cloneBroadcast(broadcast), nil
+}
+
+func (r *Repository) ListBroadcasts(
+	_ context.Context, statuses ...entities.BroadcastStatus,
+) ([]entities.Broadcast, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	broadcasts := make([]entities.Broadcast, 0)
+	for _, broadcast := range r.broadcasts {
+		if len(statuses) == 0 || containsStatus(statuses, broadcast.Status) {
+			broadcasts = append(broadcasts, cloneBroadcast(broadcast))
+		}
+	}
+
+	sort.Slice(broadcasts, func(i, j int) bool {
+		if !broadcasts[i].CreatedAt.Equal(broadcasts[j].CreatedAt) {
+			return broadcasts[i].CreatedAt.After(broadcasts[j].CreatedAt)
+		}
+
+		return broadcasts[i].ID.Hex() > broadcasts[j].ID.Hex()
+	})
+
+	return broadcasts, nil
+}
+
+func (r *Repository) UpdateBroadcast(_ context.Context, broadcast entities.Broadcast) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.broadcasts[broadcast.ID]
+	if !ok || stored.Finished() {
+		return entities.ErrRecordNotFound
+	}
+
+	r.broadcasts[broadcast.ID] = cloneBroadcast(broadcast)
+
+	return nil
+}
+
+func containsStatus(statuses []entities.BroadcastStatus, status entities.BroadcastStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func cloneBroadcast(broadcast entities.Broadcast) entities.Broadcast {
+	broadcast.Target.Tags = append([]string(nil), broadcast.Target.Tags...)
+	broadcast.Failures = append([]entities.BroadcastFailure(nil), broadcast.Failures...)
+
+	return broadcast
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r *Repository) MigrateChat(_ context.Context, botID string, fromChatID, toChatID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clientIDs := make([]string, 0)
+	for id, account := range r.accounts {
+		if account.BotID == botID && account.ChatID == fromChatID {
+			account.ChatID = toChatID
+			r.accounts[id] = account
+			clientIDs = append(clientIDs, id.Hex())
+		}
+	}
+
+	sort.Strings(clientIDs)
+
+	return clientIDs, nil
+}
+
+func (r *Repository) AddChatMigration(_ context.Context, migration entities.ChatMigration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	migration.ClientIDs = append([]string(nil), migration.ClientIDs...)
+	r.migrations = append(r.migrations, migration)
+
+	return nil
+}
+
+func (r *Repository) ListChatMigrations(_ context.Context, clientID string) ([]entities.ChatMigration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	migrations := make([]entities.ChatMigration, 0)
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		if migration := r.migrations[i]; contains(migration.ClientIDs, clientID) {
+			migration.ClientIDs = append([]string(nil), migration.ClientIDs...)
+			migrations = append(migrations, migration)
+		}
+	}
+
+	return migrations, nil
+}
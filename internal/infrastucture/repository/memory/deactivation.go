@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r *Repository) SetChatDeactivation(
+	_ context.Context, botID string, chatID int64, deactivation *entities.Deactivation,
+) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clientIDs := make([]string, 0)
+	for id, account := range r.accounts {
+		if account.BotID != botID || account.ChatID != chatID || account.Active() == (deactivation == nil) {
+			continue
+		}
+
+		account.Deactivation = deactivation
+		r.accounts[id] = cloneAccount(account)
+		clientIDs = append(clientIDs, id.Hex())
+	}
+
+	sort.Strings(clientIDs)
+
+	return clientIDs, nil
+}
+
+func (r *Repository) GetUpdateOffset(_ context.Context, botID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.offsets[botID], nil
+}
+
+func (r *Repository) SetUpdateOffset(_ context.Context, botID string, offset int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.offsets[botID] = offset
+
+	return nil
+}
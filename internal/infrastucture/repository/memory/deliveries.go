@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type deliveryKey struct {
+	requestID string
+	chatID    int64
+}
+
+func (r *Repository) SaveDelivery(_ context.Context, delivery entities.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.AcknowledgedAt != nil {
+		acknowledgedAt := *delivery.AcknowledgedAt
+		delivery.AcknowledgedAt = &acknowledgedAt
+	}
+
+	r.deliveries[deliveryKey{requestID: delivery.RequestID, chatID: delivery.ChatID}] = delivery
+
+	return nil
+}
+
+func (r *Repository) ListDeliveries(_ context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]entities.Delivery, 0)
+	for _, delivery := range r.deliveries {
+		if filter.Matches(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].SentAt.Equal(deliveries[j].SentAt) {
+			return deliveries[i].SentAt.After(deliveries[j].SentAt)
+		}
+
+		return deliveries[i].ChatID < deliveries[j].ChatID
+	})
+
+	if filter.Limit > 0 && len(deliveries) > filter.Limit {
+		deliveries = deliveries[:filter.Limit]
+	}
+
+	return deliveries, nil
+}
+
+func (r *Repository) DeliveryStats(_ context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]entities.Delivery, 0, len(r.deliveries))
+	for _, delivery := range r.deliveries {
+		deliveries = append(deliveries, delivery)
+	}
+
+	return entities.NewDeliveryStats(filter, deliveries), nil
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r *Repository) CreateDigestSchedule(_ context.Context, schedule entities.DigestSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.digests[schedule.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.digests[schedule.ID] = schedule
+
+	return nil
+}
+
+func (r *Repository) GetDigestSchedule(_ context.Context, scheduleID string) (entities.DigestSchedule, error) {
+	castedID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return entities.DigestSchedule{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, ok := r.digests[castedID]
+	if !ok {
+		return entities.DigestSchedule{}, entities.ErrRecordNotFound
+	}
+
+	return schedule, nil
+}
+
+func (r *Repository) ListDigestSchedules(_ context.Context, clientID string) ([]entities.DigestSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := make([]entities.DigestSchedule, 0)
+	for _, schedule := range r.digests {
+		if schedule.ClientID == clientID {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ID.Hex() < schedules[j].ID.Hex() })
+
+	return schedules, nil
+}
+
+// ListDueDigestSchedules returns the schedules due by now, the longest overdue first.
+func (r *Repository) ListDueDigestSchedules(
+	_ context.Context, now time.Time, limit int,
+) ([]entities.DigestSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]entities.DigestSchedule, 0)
+	for _, schedule := range r.digests {
+		if !schedule.NextRunAt.After(now) {
+			due = append(due, schedule)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextRunAt.Equal(due[j].NextRunAt) {
+			return due[i].NextRunAt.Before(due[j].NextRunAt)
+		}
+
+		return due[i].ID.Hex() < due[j].ID.Hex()
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+func (r *Repository) UpdateDigestSchedule(_ context.Context, schedule entities.DigestSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.digests[schedule.ID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	r.digests[schedule.ID] = schedule
+
+	return nil
+}
+
+func (r *Repository) DeleteDigestSchedule(_ context.Context, scheduleID string) error {
+	castedID, err := primitive.ObjectIDFromHex(scheduleID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.digests[castedID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	delete(r.digests, castedID)
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r *Repository) CreateOrganization(_ context.Context, org entities.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[org.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.orgs[org.ID] = cloneOrganization(org)
+
+	return nil
+}
+
+func (r *Repository) GetOrganization(_ context.Context, orgID string) (entities.Organization, error) {
+	castedID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return entities.Organization{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	org, ok := r.orgs[castedID]
+	if !ok {
+		return entities.Organization{}, entities.ErrRecordNotFound
+	}
+
+	return cloneOrganization(org), nil
+}
+
+func (r *Repository) ListOrganizations(_ context.Context, tenantID string) ([]entities.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orgs := make([]entities.Organization, 0, len(r.orgs))
+	for _, org := range r.orgs {
+		if tenantID == "" || org.TenantID == tenantID {
+			orgs = append(orgs, cloneOrganization(org))
+		}
+	}
+
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID.Hex() < orgs[j].ID.Hex() })
+
+	return orgs, nil
+}
+
+func (r *Repository) UpdateOrganization(_ context.Context, org entities.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[org.ID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	r.orgs[org.ID] = cloneOrganization(org)
+
+	return nil
+}
+
+func (r *Repository) DeleteOrganization(_ context.Context, orgID string) error {
+	castedID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[castedID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	delete(r.orgs, castedID)
+
+	return nil
+}
+
+func cloneOrganization(org entities.Organization) entities.Organization {
+	org.Tags = append([]string(nil), org.Tags...)
+
+	return org
+}
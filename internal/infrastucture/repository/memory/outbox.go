@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// InTransaction only runs fn, the changes made before a failure of fn are not rolled back.
+func (r *Repository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *Repository) AddOutboxEvent(_ context.Context, event entities.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.outboxSeq++
+	event.Seq = r.outboxSeq
+
+	event.Payload = append([]byte(nil), event.Payload...)
+	r.outbox = append(r.outbox, event)
+
+	return nil
+}
+
+func (r *Repository) ListOutboxEvents(_ context.Context, limit int) ([]entities.OutboxEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]entities.OutboxEvent, 0)
+	for _, event := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+
+		if event.PublishedAt.IsZero() {
+			event.Payload = append([]byte(nil), event.Payload...)
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *Repository) MarkOutboxPublished(_ context.Context, events []entities.OutboxEvent, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	published := make(map[int64]bool, len(events))
+	for _, event := range events {
+		published[event.Seq] = true
+	}
+
+	for i := range r.outbox {
+		if published[r.outbox[i].Seq] && r.outbox[i].PublishedAt.IsZero() {
+			r.outbox[i].PublishedAt = at
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteOutboxEvents(_ context.Context, publishedBefore time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.outbox[:0]
+	for _, event := range r.outbox {
+		if event.PublishedAt.IsZero() || !event.PublishedAt.Before(publishedBefore) {
+			kept = append(kept, event)
+		}
+	}
+
+	deleted := int64(len(r.outbox) - len(kept))
+	r.outbox = kept
+
+	return deleted, nil
+}
+
+func (r *Repository) AcquireLease(_ context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	current, ok := r.leases[name]
+	if ok && current.owner != owner && !current.expiresAt.Before(now) {
+		return false, nil
+	}
+
+	r.leases[name] = lease{owner: owner, expiresAt: now.Add(ttl)}
+
+	return true, nil
+}
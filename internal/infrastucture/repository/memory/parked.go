@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func cloneParked(parked entities.ParkedNotification) entities.ParkedNotification {
+	parked.Message.NotificationMethods = append([]string(nil), parked.Message.NotificationMethods...)
+	if parked.Message.Location != nil {
+		location := *parked.Message.Location
+		parked.Message.Location = &location
+	}
+
+	return parked
+}
+
+func (r *Repository) ParkNotification(_ context.Context, parked entities.ParkedNotification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.parked[parked.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.parked[parked.ID] = cloneParked(parked)
+
+	return nil
+}
+
+func (r *Repository) ListDueNotifications(
+	_ context.Context, now time.Time, limit int,
+) ([]entities.ParkedNotification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]entities.ParkedNotification, 0)
+	for _, parked := range r.parked {
+		if !parked.NextAttempt.After(now) {
+			due = append(due, cloneParked(parked))
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].CreatedAt.Equal(due[j].CreatedAt) {
+			return due[i].CreatedAt.Before(due[j].CreatedAt)
+		}
+
+		return due[i].ID.Hex() < due[j].ID.Hex()
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+func (r *Repository) UpdateParkedNotification(_ context.Context, parked entities.ParkedNotification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.parked[parked.ID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	r.parked[parked.ID] = cloneParked(parked)
+
+	return nil
+}
+
+func (r *Repository) DeleteParkedNotification(_ context.Context, parkedID string) error {
+	castedID, err := primitive.ObjectIDFromHex(parkedID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.parked, castedID)
+
+	return nil
+}
+
+func (r *Repository) CountParkedNotifications(_ context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.parked)), nil
+}
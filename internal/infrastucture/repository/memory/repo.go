@@ -0,0 +1,259 @@
+// Package memory is an in-memory storage backend for tests and local runs without a database.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type Repository struct {
+	mu         sync.RWMutex
+	accounts   map[primitive.ObjectID]entities.TGAccount
+	bots       map[primitive.ObjectID]entities.Bot
+	sensors    map[string]entities.Sensor
+	deliveries map[deliveryKey]entities.Delivery
+	broadcasts map[primitive.ObjectID]entities.Broadcast
+	outbox     []entities.OutboxEvent
+	outboxSeq  int64
+	leases     map[string]lease
+	parked     map[primitive.ObjectID]entities.ParkedNotification
+	migrations []entities.ChatMigration
+	orgs       map[primitive.ObjectID]entities.Organization
+	zones      map[primitive.ObjectID]entities.Zone
+	zoneGrid   zoneGrid
+	zoneSubs   map[primitive.ObjectID]entities.ZoneSubscription
+	digests    map[primitive.ObjectID]entities.DigestSchedule
+	offsets    map[string]int
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		accounts:   make(map[primitive.ObjectID]entities.TGAccount),
+		bots:       make(map[primitive.ObjectID]entities.Bot),
+		sensors:    make(map[string]entities.Sensor),
+		deliveries: make(map[deliveryKey]entities.Delivery),
+		broadcasts: make(map[primitive.ObjectID]entities.Broadcast),
+		leases:     make(map[string]lease),
+		parked:     make(map[primitive.ObjectID]entities.ParkedNotification),
+		orgs:       make(map[primitive.ObjectID]entities.Organization),
+		zones:      make(map[primitive.ObjectID]entities.Zone),
+		zoneGrid:   make(zoneGrid),
+		zoneSubs:   make(map[primitive.ObjectID]entities.ZoneSubscription),
+		digests:    make(map[primitive.ObjectID]entities.DigestSchedule),
+		offsets:    make(map[string]int),
+	}
+}
+
+func (r *Repository) Create(_ context.Context, client entities.TGAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.accounts[client.ClientID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.accounts[client.ClientID] = cloneAccount(client)
+
+	return nil
+}
+
+func (r *Repository) Delete(_ context.Context, clientID string) error {
+	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.accounts, castedID)
+
+	return nil
+}
+
+func (r *Repository) GetAccountByClientID(_ context.Context, clientID string) (entities.TGAccount, error) {
+	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return entities.TGAccount{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	client, ok := r.accounts[castedID]
+	if !ok {
+		return entities.TGAccount{}, entities.ErrRecordNotFound
+	}
+
+	return cloneAccount(client), nil
+}
+
+func (r *Repository) UpsertAccount(_ context.Context, client entities.TGAccount) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.accounts[client.ClientID]
+	r.accounts[client.ClientID] = cloneAccount(client)
+
+	return !exists, nil
+}
+
+func (r *Repository) ForEachAccount(_ context.Context, fn func(entities.TGAccount) error) error {
+	r.mu.RLock()
+	accounts := make([]entities.TGAccount, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, cloneAccount(account))
+	}
+	r.mu.RUnlock()
+
+	// fn runs without the lock, so it may call back into the repository.
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ClientID.Hex() < accounts[j].ClientID.Hex() })
+
+	for _, account := range accounts {
+		if err := fn(account); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) ListAccounts(_ context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]entities.TGAccount, 0)
+	for _, account := range r.accounts {
+		if filter.Matches(account) {
+			accounts = append(accounts, cloneAccount(account))
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ClientID.Hex() < accounts[j].ClientID.Hex() })
+
+	if filter.Limit > 0 && len(accounts) > filter.Limit {
+		accounts = accounts[:filter.Limit]
+	}
+
+	return accounts, nil
+}
+
+// CountAccounts counts the accounts matching the filter, its After and Limit are ignored.
+func (r *Repository) CountAccounts(_ context.Context, filter entities.AccountFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	filter.After = ""
+
+	var count int64
+	for _, account := range r.accounts {
+		if filter.Matches(account) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func cloneAccount(account entities.TGAccount) entities.TGAccount {
+	account.Tags = append([]string(nil), account.Tags...)
+	account.Rules = append([]string(nil), account.Rules...)
+	account.Filters = append([]entities.FilterRule(nil), account.Filters...)
+	if account.Deactivation != nil {
+		deactivation := *account.Deactivation
+		account.Deactivation = &deactivation
+	}
+
+	return account
+}
+
+func (r *Repository) CreateBot(_ context.Context, bot entities.Bot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.bots[bot.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.bots[bot.ID] = bot
+
+	return nil
+}
+
+func (r *Repository) GetBot(_ context.Context, botID string) (entities.Bot, error) {
+	castedID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return entities.Bot{}, entities.ErrRecordNotFound
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bot, ok := r.bots[castedID]
+	if !ok {
+		return entities.Bot{}, entities.ErrRecordNotFound
+	}
+
+	return bot, nil
+}
+
+func (r *Repository) ListBots(_ context.Context, tenantID string) ([]entities.Bot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bots := make([]entities.Bot, 0, len(r.bots))
+	for _, bot := range r.bots {
+		if tenantID == "" || bot.TenantID == tenantID {
+			bots = append(bots, bot)
+		}
+	}
+
+	sort.Slice(bots, func(i, j int) bool { return bots[i].ID.Hex() < bots[j].ID.Hex() })
+
+	return bots, nil
+}
+
+// DeleteBot removes the bot unless an account uses it.
+func (r *Repository) DeleteBot(_ context.Context, botID string) error {
+	castedID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return entities.ErrRecordNotFound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.bots[castedID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	for _, account := range r.accounts {
+		if account.BotID == botID {
+			return entities.ErrRecordInUse
+		}
+	}
+
+	delete(r.bots, castedID)
+
+	return nil
+}
+
+func (r *Repository) CountAccountsByBotID(_ context.Context, botID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, client := range r.accounts {
+		if client.BotID == botID {
+			count++
+		}
+	}
+
+	return count, nil
+}
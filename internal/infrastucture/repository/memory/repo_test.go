@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/memory"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/repotest"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return memory.NewRepository()
+	})
+}
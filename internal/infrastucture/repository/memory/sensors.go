@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r *Repository) CreateSensor(_ context.Context, sensor entities.Sensor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sensors[sensor.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.sensors[sensor.ID] = cloneSensor(sensor)
+
+	return nil
+}
+
+func (r *Repository) GetSensor(_ context.Context, sensorID string) (entities.Sensor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sensor, ok := r.sensors[sensorID]
+	if !ok {
+		return entities.Sensor{}, entities.ErrRecordNotFound
+	}
+
+	return cloneSensor(sensor), nil
+}
+
+func (r *Repository) ListSensors(_ context.Context, clientID string) ([]entities.Sensor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sensors := make([]entities.Sensor, 0, len(r.sensors))
+	for _, sensor := range r.sensors {
+		if clientID == "" || contains(sensor.ClientIDs, clientID) {
+			sensors = append(sensors, cloneSensor(sensor))
+		}
+	}
+
+	sort.Slice(sensors, func(i, j int) bool { return sensors[i].ID < sensors[j].ID })
+
+	return sensors, nil
+}
+
+func (r *Repository) UpdateSensor(_ context.Context, sensor entities.Sensor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sensors[sensor.ID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	r.sensors[sensor.ID] = cloneSensor(sensor)
+
+	return nil
+}
+
+func (r *Repository) DeleteSensor(_ context.Context, sensorID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sensors[sensorID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	delete(r.sensors, sensorID)
+
+	return nil
+}
+
+// cloneSensor copies the slices and the location, so callers can't change stored sensors.
+func cloneSensor(sensor entities.Sensor) entities.Sensor {
+	sensor.ClientIDs = append([]string(nil), sensor.ClientIDs...)
+	sensor.Tags = append([]string(nil), sensor.Tags...)
+
+	if sensor.Location != nil {
+		location := *sensor.Location
+		sensor.Location = &location
+	}
+
+	return sensor
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
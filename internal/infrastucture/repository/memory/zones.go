@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// _cellDegrees is the size of the cells of the zone grid.
+const _cellDegrees = 1.0
+
+type cell struct {
+	lon, lat int
+}
+
+func cellOf(lon, lat float64) cell {
+	return cell{lon: int(math.Floor(lon / _cellDegrees)), lat: int(math.Floor(lat / _cellDegrees))}
+}
+
+// zoneGrid is the spatial index of the zones, every cell lists the zones whose bounding box overlaps it.
+type zoneGrid map[cell]map[primitive.ObjectID]bool
+
+func (g zoneGrid) add(zone entities.Zone) {
+	g.each(zone, func(c cell) {
+		if g[c] == nil {
+			g[c] = make(map[primitive.ObjectID]bool)
+		}
+
+		g[c][zone.ID] = true
+	})
+}
+
+func (g zoneGrid) remove(zone entities.Zone) {
+	g.each(zone, func(c cell) {
+		delete(g[c], zone.ID)
+		if len(g[c]) == 0 {
+			delete(g, c)
+		}
+	})
+}
+
+func (g zoneGrid) each(zone entities.Zone, fn func(cell)) {
+	box := zone.Geometry.Bounds()
+	from, to := cellOf(box.MinLongitude, box.MinLatitude), cellOf(box.MaxLongitude, box.MaxLatitude)
+
+	for lon := from.lon; lon <= to.lon; lon++ {
+		for lat := from.lat; lat <= to.lat; lat++ {
+			fn(cell{lon: lon, lat: lat})
+		}
+	}
+}
+
+func (r *Repository) CreateZone(_ context.Context, zone entities.Zone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zones[zone.ID]; ok {
+		return entities.ErrRecordExists
+	}
+
+	r.zones[zone.ID] = cloneZone(zone)
+	r.zoneGrid.add(zone)
+
+	return nil
+}
+
+func (r *Repository) GetZone(_ context.Context, zoneID string) (entities.Zone, error) {
+	castedID, err := primitive.ObjectIDFromHex(zoneID)
+	if err != nil {
+		return entities.Zone{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	zone, ok := r.zones[castedID]
+	if !ok {
+		return entities.Zone{}, entities.ErrRecordNotFound
+	}
+
+	return cloneZone(zone), nil
+}
+
+func (r *Repository) ListZones(_ context.Context, tenantID string) ([]entities.Zone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	zones := make([]entities.Zone, 0, len(r.zones))
+	for _, zone := range r.zones {
+		if tenantID == "" || zone.TenantID == tenantID {
+			zones = append(zones, cloneZone(zone))
+		}
+	}
+
+	sortZones(zones)
+
+	return zones, nil
+}
+
+func (r *Repository) UpdateZone(_ context.Context, zone entities.Zone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.zones[zone.ID]
+	if !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	r.zoneGrid.remove(existing)
+	r.zones[zone.ID] = cloneZone(zone)
+	r.zoneGrid.add(zone)
+
+	return nil
+}
+
+// DeleteZone removes the zone with its subscriptions.
+func (r *Repository) DeleteZone(_ context.Context, zoneID string) error {
+	castedID, err := primitive.ObjectIDFromHex(zoneID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zone, ok := r.zones[castedID]
+	if !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	r.zoneGrid.remove(zone)
+	delete(r.zones, castedID)
+
+	for id, sub := range r.zoneSubs {
+		if sub.ZoneID == zoneID {
+			delete(r.zoneSubs, id)
+		}
+	}
+
+	return nil
+}
+
+// ZonesContaining returns the zones the location is inside of, the grid narrows them down to the zones
+// whose bounding box overlaps the cell of the location.
+func (r *Repository) ZonesContaining(_ context.Context, loc entities.Location) ([]entities.Zone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	zones := make([]entities.Zone, 0)
+	for id := range r.zoneGrid[cellOf(loc.Longitude, loc.Latitude)] {
+		zone := r.zones[id]
+		if zone.Geometry.Bounds().Contains(loc) && zone.Geometry.Contains(loc) {
+			zones = append(zones, cloneZone(zone))
+		}
+	}
+
+	sortZones(zones)
+
+	return zones, nil
+}
+
+func sortZones(zones []entities.Zone) {
+	sort.Slice(zones, func(i, j int) bool { return zones[i].ID.Hex() < zones[j].ID.Hex() })
+}
+
+func cloneZone(zone entities.Zone) entities.Zone {
+	polygons := make([][][][]float64, len(zone.Geometry.Coordinates))
+	for i, polygon := range zone.Geometry.Coordinates {
+		polygons[i] = make([][][]float64, len(polygon))
+		for j, ring := range polygon {
+			polygons[i][j] = make([][]float64, len(ring))
+			for k, position := range ring {
+				polygons[i][j][k] = append([]float64(nil), position...)
+			}
+		}
+	}
+
+	zone.Geometry.Coordinates = polygons
+
+	return zone
+}
+
+// CreateZoneSubscription stores the subscription, a chat and topic subscribes to a zone once.
+func (r *Repository) CreateZoneSubscription(_ context.Context, sub entities.ZoneSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.zoneSubs {
+		if existing.ID == sub.ID ||
+			(existing.ZoneID == sub.ZoneID && existing.ChatID == sub.ChatID && existing.ThreadID == sub.ThreadID) {
+			return entities.ErrRecordExists
+		}
+	}
+
+	sub.Rules = append([]string(nil), sub.Rules...)
+	sub.Filters = append([]entities.FilterRule(nil), sub.Filters...)
+	r.zoneSubs[sub.ID] = sub
+
+	return nil
+}
+
+func (r *Repository) GetZoneSubscription(_ context.Context, subID string) (entities.ZoneSubscription, error) {
+	castedID, err := primitive.ObjectIDFromHex(subID)
+	if err != nil {
+		return entities.ZoneSubscription{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, ok := r.zoneSubs[castedID]
+	if !ok {
+		return entities.ZoneSubscription{}, entities.ErrRecordNotFound
+	}
+
+	return sub, nil
+}
+
+func (r *Repository) ListZoneSubscriptions(_ context.Context, zoneID string) ([]entities.ZoneSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subs := make([]entities.ZoneSubscription, 0)
+	for _, sub := range r.zoneSubs {
+		if sub.ZoneID == zoneID {
+			subs = append(subs, sub)
+		}
+	}
+
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID.Hex() < subs[j].ID.Hex() })
+
+	return subs, nil
+}
+
+func (r *Repository) UpdateZoneSubscription(_ context.Context, sub entities.ZoneSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zoneSubs[sub.ID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	for _, existing := range r.zoneSubs {
+		if existing.ID != sub.ID &&
+			existing.ZoneID == sub.ZoneID && existing.ChatID == sub.ChatID && existing.ThreadID == sub.ThreadID {
+			return entities.ErrRecordExists
+		}
+	}
+
+	sub.Rules = append([]string(nil), sub.Rules...)
+	sub.Filters = append([]entities.FilterRule(nil), sub.Filters...)
+	r.zoneSubs[sub.ID] = sub
+
+	return nil
+}
+
+func (r *Repository) DeleteZoneSubscription(_ context.Context, subID string) error {
+	castedID, err := primitive.ObjectIDFromHex(subID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zoneSubs[castedID]; !ok {
+		return entities.ErrRecordNotFound
+	}
+
+	delete(r.zoneSubs, castedID)
+
+	return nil
+}
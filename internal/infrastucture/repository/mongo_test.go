@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/repotest"
+)
+
+// _uriEnv names the server the contract runs against, the test is skipped when it isn't set.
+const _uriEnv = "TEST_MONGO_URI"
+
+func TestRepository(t *testing.T) {
+	uri := os.Getenv(_uriEnv)
+	if uri == "" {
+		t.Skipf("%s isn't set", _uriEnv)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("mongo.Connect: %v", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	if err = client.Ping(ctx, nil); err != nil {
+		t.Fatalf("client.Ping: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		// Every repository gets a database of its own, so it starts empty.
+		database := client.Database(fmt.Sprintf("repotest_%d", time.Now().UnixNano()))
+		t.Cleanup(func() {
+			if err := database.Drop(context.Background()); err != nil {
+				t.Errorf("database.Drop: %v", err)
+			}
+		})
+
+		repo := repository.NewRepository(database)
+		if err := repo.EnsureIndexes(context.Background()); err != nil {
+			t.Fatalf("EnsureIndexes: %v", err)
+		}
+
+		return repo
+	})
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateOrganization(ctx context.Context, org entities.Organization) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateOrganization")
+	defer span.End()
+
+	if _, err := r.orgs.InsertOne(ctx, org); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "orgs.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetOrganization(ctx context.Context, orgID string) (entities.Organization, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetOrganization")
+	defer span.End()
+
+	var org entities.Organization
+
+	castedID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return org, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = r.orgs.FindOne(ctx, bson.M{"_id": castedID}).Decode(&org); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return org, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return org, errors.Wrap(err, "orgs.FindOne")
+	}
+
+	return org, nil
+}
+
+func (r Repository) ListOrganizations(ctx context.Context, tenantID string) ([]entities.Organization, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListOrganizations")
+	defer span.End()
+
+	filter := bson.M{}
+	if tenantID != "" {
+		filter["tenantID"] = tenantID
+	}
+
+	cursor, err := r.orgs.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "orgs.Find")
+	}
+
+	orgs := make([]entities.Organization, 0)
+	if err = cursor.All(ctx, &orgs); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return orgs, nil
+}
+
+func (r Repository) UpdateOrganization(ctx context.Context, org entities.Organization) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateOrganization")
+	defer span.End()
+
+	res, err := r.orgs.ReplaceOne(ctx, bson.M{"_id": org.ID}, org)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "orgs.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteOrganization(ctx context.Context, orgID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteOrganization")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	res, err := r.orgs.DeleteOne(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "orgs.DeleteOne")
+	}
+
+	if res.DeletedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
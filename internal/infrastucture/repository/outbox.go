@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// _outboxCounter prefixes the key in the documents of the Counters collection holding the last outbox
+// sequence number of the key.
+const _outboxCounter = "outbox/"
+
+// InTransaction runs fn in a transaction, it joins the transaction of the context when there is one.
+// Transactions need Mongo running as a replica set. fn may be retried on a transient error.
+func (r Repository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return errors.Wrap(err, "client.StartSession")
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
+
+// AddOutboxEvent assigns the event the next sequence number of its key and stores it. The counter update
+// makes the concurrent transactions of a key conflict, so the events of a key commit in the order of their
+// numbers, the writers of the other keys don't wait.
+func (r Repository) AddOutboxEvent(ctx context.Context, event entities.OutboxEvent) error {
+	ctx, span := r.tracer.Start(ctx, "repo.AddOutboxEvent")
+	defer span.End()
+
+	err := r.InTransaction(ctx, func(ctx context.Context) error {
+		var counter struct {
+			Seq int64 `bson:"seq"`
+		}
+
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+		err := r.counters.FindOneAndUpdate(ctx,
+			bson.M{"_id": _outboxCounter + event.Key}, bson.M{"$inc": bson.M{"seq": int64(1)}}, opts,
+		).Decode(&counter)
+		if err != nil {
+			return errors.Wrap(err, "counters.FindOneAndUpdate")
+		}
+
+		event.ID, event.Seq = primitive.NewObjectID(), counter.Seq
+		if _, err = r.outbox.InsertOne(ctx, event); err != nil {
+			return errors.Wrap(err, "outbox.InsertOne")
+		}
+
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListOutboxEvents returns the oldest unpublished events, the events of a key in the order of their numbers.
+// The ids follow the commit order only roughly, so an event waits for a later batch while an earlier event
+// of its key isn't in this one.
+func (r Repository) ListOutboxEvents(ctx context.Context, limit int) ([]entities.OutboxEvent, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListOutboxEvents")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := r.outbox.Find(ctx, bson.M{"publishedAt": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "outbox.Find")
+	}
+
+	events := make([]entities.OutboxEvent, 0)
+	if err = cursor.All(ctx, &events); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	next, err := r.nextOutboxSeqs(ctx, events)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return inKeyOrder(events, next), nil
+}
+
+// nextOutboxSeqs returns the lowest unpublished sequence number of every key of the events.
+func (r Repository) nextOutboxSeqs(ctx context.Context, events []entities.OutboxEvent) (map[string]int64, error) {
+	keys := make([]string, 0, len(events))
+	for _, event := range events {
+		keys = append(keys, event.Key)
+	}
+
+	cursor, err := r.outbox.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"key": bson.M{"$in": keys}, "publishedAt": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$key", "seq": bson.M{"$min": "$seq"}}}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "outbox.Aggregate")
+	}
+
+	var lowest []struct {
+		Key string `bson:"_id"`
+		Seq int64  `bson:"seq"`
+	}
+
+	if err = cursor.All(ctx, &lowest); err != nil {
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	next := make(map[string]int64, len(lowest))
+	for _, key := range lowest {
+		next[key.Key] = key.Seq
+	}
+
+	return next, nil
+}
+
+// inKeyOrder sorts the events of every key by their numbers in the places the key takes in the batch, and
+// drops the events that would skip an unpublished number of their key, next holds the lowest one.
+func inKeyOrder(events []entities.OutboxEvent, next map[string]int64) []entities.OutboxEvent {
+	byKey := make(map[string][]entities.OutboxEvent)
+	for _, event := range events {
+		byKey[event.Key] = append(byKey[event.Key], event)
+	}
+
+	for _, keyEvents := range byKey {
+		sort.Slice(keyEvents, func(i, j int) bool { return keyEvents[i].Seq < keyEvents[j].Seq })
+	}
+
+	ordered := make([]entities.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		event, byKey[event.Key] = byKey[event.Key][0], byKey[event.Key][1:]
+		if event.Seq != next[event.Key] {
+			continue
+		}
+
+		next[event.Key]++
+		ordered = append(ordered, event)
+	}
+
+	return ordered
+}
+
+// MarkOutboxPublished marks the events as published.
+func (r Repository) MarkOutboxPublished(ctx context.Context, events []entities.OutboxEvent, at time.Time) error {
+	ctx, span := r.tracer.Start(ctx, "repo.MarkOutboxPublished")
+	defer span.End()
+
+	ids := make([]primitive.ObjectID, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	_, err := r.outbox.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}, "publishedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"publishedAt": at}},
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "outbox.UpdateMany")
+	}
+
+	return nil
+}
+
+// DeleteOutboxEvents deletes the events published before the time, it returns how many were deleted.
+func (r Repository) DeleteOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteOutboxEvents")
+	defer span.End()
+
+	res, err := r.outbox.DeleteMany(ctx, bson.M{"publishedAt": bson.M{"$lt": publishedBefore}})
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "outbox.DeleteMany")
+	}
+
+	return res.DeletedCount, nil
+}
+
+// AcquireLease takes the lease for ttl when it's free or expired, or renews it for its owner.
+func (r Repository) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.AcquireLease")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	// A lease held by another owner doesn't match, the upsert then collides with it on _id.
+	_, err := r.leases.UpdateOne(ctx,
+		bson.M{"_id": name, "$or": bson.A{bson.M{"owner": owner}, bson.M{"expiresAt": bson.M{"$lt": now}}}},
+		bson.M{"$set": bson.M{"owner": owner, "expiresAt": now.Add(ttl)}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		span.RecordError(err)
+		return false, errors.Wrap(err, "leases.UpdateOne")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func TestInKeyOrder(t *testing.T) {
+	event := func(key string, seq int64) entities.OutboxEvent {
+		return entities.OutboxEvent{Key: key, Seq: seq}
+	}
+
+	tests := []struct {
+		name   string
+		events []entities.OutboxEvent
+		next   map[string]int64
+		want   []entities.OutboxEvent
+	}{
+		{
+			name:   "in order",
+			events: []entities.OutboxEvent{event("a", 1), event("b", 1), event("a", 2)},
+			next:   map[string]int64{"a": 1, "b": 1},
+			want:   []entities.OutboxEvent{event("a", 1), event("b", 1), event("a", 2)},
+		},
+		{
+			name:   "ids out of the order of a key",
+			events: []entities.OutboxEvent{event("a", 2), event("b", 1), event("a", 1)},
+			next:   map[string]int64{"a": 1, "b": 1},
+			want:   []entities.OutboxEvent{event("a", 1), event("b", 1), event("a", 2)},
+		},
+		{
+			name:   "earlier event of a key outside the batch",
+			events: []entities.OutboxEvent{event("a", 3), event("b", 4), event("a", 4)},
+			next:   map[string]int64{"a": 2, "b": 4},
+			want:   []entities.OutboxEvent{event("b", 4)},
+		},
+		{
+			name:   "gap in the batch",
+			events: []entities.OutboxEvent{event("a", 1), event("a", 3)},
+			next:   map[string]int64{"a": 1},
+			want:   []entities.OutboxEvent{event("a", 1)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := inKeyOrder(tc.events, tc.next); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("inKeyOrder: want %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) ParkNotification(ctx context.Context, parked entities.ParkedNotification) error {
+	ctx, span := r.tracer.Start(ctx, "repo.ParkNotification")
+	defer span.End()
+
+	if _, err := r.parked.InsertOne(ctx, parked); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "parked.InsertOne")
+	}
+
+	return nil
+}
+
+// ListDueNotifications returns the parked notifications due by now, oldest first.
+func (r Repository) ListDueNotifications(
+	ctx context.Context, now time.Time, limit int,
+) ([]entities.ParkedNotification, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDueNotifications")
+	defer span.End()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.parked.Find(ctx, bson.M{"nextAttempt": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "parked.Find")
+	}
+
+	due := make([]entities.ParkedNotification, 0)
+	if err = cursor.All(ctx, &due); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return due, nil
+}
+
+func (r Repository) UpdateParkedNotification(ctx context.Context, parked entities.ParkedNotification) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateParkedNotification")
+	defer span.End()
+
+	res, err := r.parked.ReplaceOne(ctx, bson.M{"_id": parked.ID}, parked)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "parked.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteParkedNotification(ctx context.Context, parkedID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteParkedNotification")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(parkedID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if _, err = r.parked.DeleteOne(ctx, bson.M{"_id": castedID}); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "parked.DeleteOne")
+	}
+
+	return nil
+}
+
+func (r Repository) CountParkedNotifications(ctx context.Context) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.CountParkedNotifications")
+	defer span.End()
+
+	count, err := r.parked.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "parked.CountDocuments")
+	}
+
+	return count, nil
+}
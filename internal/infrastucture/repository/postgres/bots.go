@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateBot(ctx context.Context, bot entities.Bot) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateBot")
+	defer span.End()
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO bots (id, tenant_id, username, token, admin_chat_id, poll_updates) VALUES ($1, $2, $3, $4, $5, $6)",
+		bot.ID.Hex(), bot.TenantID, bot.Username, bot.Token, bot.AdminChatID, bot.PollUpdates,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetBot(ctx context.Context, botID string) (entities.Bot, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetBot")
+	defer span.End()
+
+	var bot entities.Bot
+
+	castedID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return bot, entities.ErrRecordNotFound
+	}
+
+	err = r.conn(ctx).QueryRowContext(ctx,
+		"SELECT tenant_id, username, token, admin_chat_id, poll_updates FROM bots WHERE id = $1", botID,
+	).Scan(&bot.TenantID, &bot.Username, &bot.Token, &bot.AdminChatID, &bot.PollUpdates)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return bot, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return bot, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	bot.ID = castedID
+
+	return bot, nil
+}
+
+func (r Repository) ListBots(ctx context.Context, tenantID string) ([]entities.Bot, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListBots")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT id, tenant_id, username, token, admin_chat_id, poll_updates FROM bots WHERE $1 = '' OR tenant_id = $1 ORDER BY id", tenantID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	bots := make([]entities.Bot, 0)
+	for rows.Next() {
+		var (
+			bot entities.Bot
+			id  string
+		)
+
+		if err = rows.Scan(&id, &bot.TenantID, &bot.Username, &bot.Token, &bot.AdminChatID, &bot.PollUpdates); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+
+		if bot.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return nil, errors.Wrap(err, "primitive.ObjectIDFromHex")
+		}
+
+		bots = append(bots, bot)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return bots, nil
+}
+
+// DeleteBot removes the bot unless an account uses it.
+func (r Repository) DeleteBot(ctx context.Context, botID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteBot")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(botID); err != nil {
+		return entities.ErrRecordNotFound
+	}
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		"DELETE FROM bots WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM telegram_accounts WHERE bot_id = $1)", botID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		// Either there is no such bot or an account uses it.
+		if _, err = r.GetBot(ctx, botID); err != nil {
+			return err
+		}
+
+		return entities.ErrRecordInUse
+	}
+
+	return nil
+}
+
+func (r Repository) CountAccountsByBotID(ctx context.Context, botID string) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.CountAccountsByBotID")
+	defer span.End()
+
+	var count int64
+	if err := r.conn(ctx).QueryRowContext(ctx,
+		"SELECT count(*) FROM telegram_accounts WHERE bot_id = $1", botID,
+	).Scan(&count); err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return count, nil
+}
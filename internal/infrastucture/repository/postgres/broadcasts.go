@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _broadcastColumns = "id, text, target, status, total, sent, failed, failures, cursor, error, " +
+	"created_at, updated_at, finished_at"
+
+func scanBroadcast(row rowScanner) (entities.Broadcast, error) {
+	var (
+		broadcast        entities.Broadcast
+		id               string
+		target, failures []byte
+		finishedAt       sql.NullTime
+	)
+
+	err := row.Scan(&id, &broadcast.Text, &target, &broadcast.Status, &broadcast.Total, &broadcast.Sent,
+		&broadcast.Failed, &failures, &broadcast.Cursor, &broadcast.Error, &broadcast.CreatedAt, &broadcast.UpdatedAt,
+		&finishedAt)
+	if err != nil {
+		return broadcast, err
+	}
+
+	if broadcast.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return broadcast, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = json.Unmarshal(target, &broadcast.Target); err != nil {
+		return broadcast, errors.Wrap(err, "json.Unmarshal target")
+	}
+
+	if err = json.Unmarshal(failures, &broadcast.Failures); err != nil {
+		return broadcast, errors.Wrap(err, "json.Unmarshal failures")
+	}
+
+	broadcast.FinishedAt = finishedAt.Time
+
+	return broadcast, nil
+}
+
+// broadcastValues returns the columns of the broadcast after the ID, in _broadcastColumns order.
+func broadcastValues(broadcast entities.Broadcast) ([]interface{}, error) {
+	target, err := json.Marshal(broadcast.Target)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal target")
+	}
+
+	failures := broadcast.Failures
+	if failures == nil {
+		failures = []entities.BroadcastFailure{}
+	}
+
+	failuresJSON, err := json.Marshal(failures)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal failures")
+	}
+
+	finishedAt := sql.NullTime{Time: broadcast.FinishedAt, Valid: !broadcast.FinishedAt.IsZero()}
+
+	return []interface{}{
+		broadcast.Text, target, broadcast.Status, broadcast.Total, broadcast.Sent, broadcast.Failed, failuresJSON,
+		broadcast.Cursor, broadcast.Error, broadcast.CreatedAt, broadcast.UpdatedAt, finishedAt,
+	}, nil
+}
+
+func (r Repository) CreateBroadcast(ctx context.Context, broadcast entities.Broadcast) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateBroadcast")
+	defer span.End()
+
+	values, err := broadcastValues(broadcast)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO broadcasts ("+_broadcastColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)",
+		append([]interface{}{broadcast.ID.Hex()}, values...)...,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetBroadcast(ctx context.Context, broadcastID string) (entities.Broadcast, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetBroadcast")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(broadcastID); err != nil {
+		return entities.Broadcast{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	broadcast, err := scanBroadcast(r.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+_broadcastColumns+" FROM broadcasts WHERE id = $1", broadcastID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return broadcast, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return broadcast, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return broadcast, nil
+}
+
+// ListBroadcasts returns the broadcasts in one of the statuses newest first, all of them when none is given.
+func (r Repository) ListBroadcasts(
+	ctx context.Context, statuses ...entities.BroadcastStatus,
+) ([]entities.Broadcast, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListBroadcasts")
+	defer span.End()
+
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, string(status))
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		`SELECT `+_broadcastColumns+` FROM broadcasts WHERE cardinality($1::text[]) = 0 OR status = ANY($1)
+		ORDER BY created_at DESC, id DESC`,
+		pq.Array(names),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	broadcasts := make([]entities.Broadcast, 0)
+	for rows.Next() {
+		broadcast, err := scanBroadcast(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanBroadcast")
+		}
+
+		broadcasts = append(broadcasts, broadcast)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return broadcasts, nil
+}
+
+// UpdateBroadcast replaces a broadcast that is not finished yet. ErrRecordNotFound is returned for a finished
+// one, so that the progress of a running job never overwrites a cancellation.
+func (r Repository) UpdateBroadcast(ctx context.Context, broadcast entities.Broadcast) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateBroadcast")
+	defer span.End()
+
+	values, err := broadcastValues(broadcast)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE broadcasts SET text = $2, target = $3, status = $4, total = $5, sent = $6, failed = $7,
+			failures = $8, cursor = $9, error = $10, created_at = $11, updated_at = $12, finished_at = $13
+		WHERE id = $1 AND status IN ('pending', 'running')`,
+		append([]interface{}{broadcast.ID.Hex()}, values...)...,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// MigrateChat moves the accounts of the bot from the old chat to the new one, it returns their client IDs.
+func (r Repository) MigrateChat(ctx context.Context, botID string, fromChatID, toChatID int64) ([]string, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.MigrateChat")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"UPDATE telegram_accounts SET chat_id = $3 WHERE bot_id = $1 AND chat_id = $2 RETURNING client_id",
+		botID, fromChatID, toChatID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	clientIDs := make([]string, 0)
+	for rows.Next() {
+		var clientID string
+		if err = rows.Scan(&clientID); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+
+		clientIDs = append(clientIDs, clientID)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	sort.Strings(clientIDs)
+
+	return clientIDs, nil
+}
+
+func (r Repository) AddChatMigration(ctx context.Context, migration entities.ChatMigration) error {
+	ctx, span := r.tracer.Start(ctx, "repo.AddChatMigration")
+	defer span.End()
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO chat_migrations (id, bot_id, from_chat_id, to_chat_id, client_ids, migrated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		migration.ID.Hex(), migration.BotID, migration.FromChatID, migration.ToChatID,
+		stringArray(migration.ClientIDs), migration.MigratedAt,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// ListChatMigrations returns the migrations that moved the account of the client, newest first.
+func (r Repository) ListChatMigrations(ctx context.Context, clientID string) ([]entities.ChatMigration, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListChatMigrations")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		`SELECT id, bot_id, from_chat_id, to_chat_id, client_ids, migrated_at FROM chat_migrations
+		WHERE client_ids @> ARRAY[$1::text] ORDER BY migrated_at DESC, id DESC`,
+		clientID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	migrations := make([]entities.ChatMigration, 0)
+	for rows.Next() {
+		var (
+			migration entities.ChatMigration
+			id        string
+		)
+
+		err = rows.Scan(&id, &migration.BotID, &migration.FromChatID, &migration.ToChatID,
+			pq.Array(&migration.ClientIDs), &migration.MigratedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+
+		if migration.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return nil, errors.Wrap(err, "primitive.ObjectIDFromHex")
+		}
+
+		migration.MigratedAt = migration.MigratedAt.UTC()
+		migrations = append(migrations, migration)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return migrations, nil
+}
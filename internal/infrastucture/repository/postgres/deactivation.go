@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// SetChatDeactivation deactivates the active accounts of the bot in the chat, or reactivates the inactive
+// ones for a nil deactivation. It returns the client IDs of the accounts it changed.
+func (r Repository) SetChatDeactivation(
+	ctx context.Context, botID string, chatID int64, deactivation *entities.Deactivation,
+) ([]string, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.SetChatDeactivation")
+	defer span.End()
+
+	var reason, at interface{}
+	if deactivation != nil {
+		reason, at = deactivation.Reason, deactivation.At
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		`UPDATE telegram_accounts SET deactivation_reason = $3, deactivated_at = $4
+		WHERE bot_id = $1 AND chat_id = $2 AND (deactivated_at IS NULL) = ($4::timestamptz IS NOT NULL)
+		RETURNING client_id`,
+		botID, chatID, reason, at,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	clientIDs := make([]string, 0)
+	for rows.Next() {
+		var clientID string
+		if err = rows.Scan(&clientID); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+
+		clientIDs = append(clientIDs, clientID)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	sort.Strings(clientIDs)
+
+	return clientIDs, nil
+}
+
+// GetUpdateOffset returns the offset of the next update of the bot to poll, zero when none is stored.
+func (r Repository) GetUpdateOffset(ctx context.Context, botID string) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetUpdateOffset")
+	defer span.End()
+
+	var offset int
+
+	err := r.conn(ctx).QueryRowContext(ctx,
+		"SELECT update_offset FROM update_offsets WHERE bot_id = $1", botID,
+	).Scan(&offset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return offset, nil
+}
+
+func (r Repository) SetUpdateOffset(ctx context.Context, botID string, offset int) error {
+	ctx, span := r.tracer.Start(ctx, "repo.SetUpdateOffset")
+	defer span.End()
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO update_offsets (bot_id, update_offset) VALUES ($1, $2)
+		ON CONFLICT (bot_id) DO UPDATE SET update_offset = EXCLUDED.update_offset`,
+		botID, offset,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _deliveryColumns = "request_id, client_id, chat_id, bot_id, message_id, location_message_id, text, status, sent_at, sensor_id, acknowledged_at, message_type, detected_at, audio_message_id, spectrogram_message_id, photo_message_id"
+
+func scanDelivery(row rowScanner) (entities.Delivery, error) {
+	var (
+		delivery       entities.Delivery
+		acknowledgedAt sql.NullTime
+		detectedAt     sql.NullTime
+	)
+
+	err := row.Scan(&delivery.RequestID, &delivery.ClientID, &delivery.ChatID, &delivery.BotID, &delivery.MessageID,
+		&delivery.LocationMessageID, &delivery.Text, &delivery.Status, &delivery.SentAt, &delivery.SensorID, &acknowledgedAt,
+		&delivery.MessageType, &detectedAt, &delivery.AudioMessageID, &delivery.SpectrogramMessageID,
+		&delivery.PhotoMessageID)
+
+	if acknowledgedAt.Valid {
+		at := acknowledgedAt.Time.UTC()
+		delivery.AcknowledgedAt = &at
+	}
+
+	if detectedAt.Valid {
+		delivery.DetectedAt = detectedAt.Time.UTC()
+	}
+
+	return delivery, err
+}
+
+// SaveDelivery stores the delivery, replacing the one of the same request and chat.
+func (r Repository) SaveDelivery(ctx context.Context, delivery entities.Delivery) error {
+	ctx, span := r.tracer.Start(ctx, "repo.SaveDelivery")
+	defer span.End()
+
+	var detectedAt interface{}
+	if !delivery.DetectedAt.IsZero() {
+		detectedAt = delivery.DetectedAt
+	}
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO deliveries (`+_deliveryColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (request_id, chat_id) DO UPDATE SET client_id = $2, bot_id = $4, message_id = $5,
+			location_message_id = $6, text = $7, status = $8, sent_at = $9, sensor_id = $10, acknowledged_at = $11,
+			message_type = $12, detected_at = $13, audio_message_id = $14, spectrogram_message_id = $15,
+			photo_message_id = $16`,
+		delivery.RequestID, delivery.ClientID, delivery.ChatID, delivery.BotID, delivery.MessageID,
+		delivery.LocationMessageID, delivery.Text, delivery.Status, delivery.SentAt, delivery.SensorID,
+		delivery.AcknowledgedAt, delivery.MessageType, detectedAt, delivery.AudioMessageID, delivery.SpectrogramMessageID,
+		delivery.PhotoMessageID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) ListDeliveries(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDeliveries")
+	defer span.End()
+
+	var since, until, limit interface{}
+	if !filter.Since.IsZero() {
+		since = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		until = filter.Until
+	}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		`SELECT `+_deliveryColumns+` FROM deliveries
+		WHERE ($1 = '' OR request_id = $1) AND ($2 = '' OR client_id = $2)
+			AND ($3::timestamptz IS NULL OR sent_at >= $3) AND ($4::timestamptz IS NULL OR sent_at < $4)
+		ORDER BY sent_at DESC, chat_id LIMIT $5`,
+		filter.RequestID, filter.ClientID, since, until, limit,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	deliveries := make([]entities.Delivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanDelivery")
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return deliveries, nil
+}
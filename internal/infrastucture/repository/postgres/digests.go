@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _digestColumns = "id, client_id, cron, timezone, period, next_run_at, last_run_at, created_at"
+
+func scanDigestSchedule(row rowScanner) (entities.DigestSchedule, error) {
+	var (
+		schedule  entities.DigestSchedule
+		id        string
+		lastRunAt sql.NullTime
+	)
+
+	err := row.Scan(&id, &schedule.ClientID, &schedule.Cron, &schedule.Timezone, &schedule.Period,
+		&schedule.NextRunAt, &lastRunAt, &schedule.CreatedAt)
+	if err != nil {
+		return schedule, err
+	}
+
+	if schedule.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return schedule, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	schedule.NextRunAt = schedule.NextRunAt.UTC()
+	schedule.CreatedAt = schedule.CreatedAt.UTC()
+	if lastRunAt.Valid {
+		schedule.LastRunAt = lastRunAt.Time.UTC()
+	}
+
+	return schedule, nil
+}
+
+// digestArgs are the values of _digestColumns.
+func digestArgs(schedule entities.DigestSchedule) []interface{} {
+	var lastRunAt interface{}
+	if !schedule.LastRunAt.IsZero() {
+		lastRunAt = schedule.LastRunAt
+	}
+
+	return []interface{}{
+		schedule.ID.Hex(), schedule.ClientID, schedule.Cron, schedule.Timezone, schedule.Period,
+		schedule.NextRunAt, lastRunAt, schedule.CreatedAt,
+	}
+}
+
+func (r Repository) CreateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateDigestSchedule")
+	defer span.End()
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO digest_schedules (`+_digestColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		digestArgs(schedule)...,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetDigestSchedule(ctx context.Context, scheduleID string) (entities.DigestSchedule, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetDigestSchedule")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(scheduleID); err != nil {
+		return entities.DigestSchedule{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	schedule, err := scanDigestSchedule(r.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+_digestColumns+" FROM digest_schedules WHERE id = $1", scheduleID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return schedule, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return schedule, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return schedule, nil
+}
+
+func (r Repository) ListDigestSchedules(ctx context.Context, clientID string) ([]entities.DigestSchedule, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDigestSchedules")
+	defer span.End()
+
+	return r.queryDigestSchedules(ctx,
+		"SELECT "+_digestColumns+" FROM digest_schedules WHERE client_id = $1 ORDER BY id", clientID,
+	)
+}
+
+// ListDueDigestSchedules returns the schedules due by now, the longest overdue first.
+func (r Repository) ListDueDigestSchedules(
+	ctx context.Context, now time.Time, limit int,
+) ([]entities.DigestSchedule, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDueDigestSchedules")
+	defer span.End()
+
+	query := `SELECT ` + _digestColumns + ` FROM digest_schedules WHERE next_run_at <= $1 ORDER BY next_run_at, id`
+	args := []interface{}{now}
+
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+
+	return r.queryDigestSchedules(ctx, query, args...)
+}
+
+func (r Repository) queryDigestSchedules(
+	ctx context.Context, query string, args ...interface{},
+) ([]entities.DigestSchedule, error) {
+	span := trace.SpanFromContext(ctx)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	schedules := make([]entities.DigestSchedule, 0)
+	for rows.Next() {
+		schedule, err := scanDigestSchedule(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanDigestSchedule")
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return schedules, nil
+}
+
+func (r Repository) UpdateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateDigestSchedule")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE digest_schedules SET client_id = $2, cron = $3, timezone = $4, period = $5, next_run_at = $6,
+		last_run_at = $7, created_at = $8 WHERE id = $1`,
+		digestArgs(schedule)...,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteDigestSchedule(ctx context.Context, scheduleID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteDigestSchedule")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(scheduleID); err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	res, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM digest_schedules WHERE id = $1", scheduleID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
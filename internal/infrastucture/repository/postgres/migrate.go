@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed migrations/*.sql
+var _migrations embed.FS
+
+// _migrationLockID serializes migrations between replicas starting at the same time.
+const _migrationLockID = 7076
+
+// Migrate applies the embedded migrations that haven't been applied yet, each one in its own transaction.
+// Files are named <version>_<description>.sql and applied in version order.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "db.Conn")
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", _migrationLockID); err != nil {
+		return errors.Wrap(err, "pg_advisory_lock")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", _migrationLockID)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return errors.Wrap(err, "creating schema_migrations")
+	}
+
+	files, err := fs.Glob(_migrations, "migrations/*.sql")
+	if err != nil {
+		return errors.Wrap(err, "fs.Glob")
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err = applyMigration(ctx, conn, file); err != nil {
+			return errors.Wrapf(err, "migration %s", file)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, file string) error {
+	name := strings.TrimPrefix(file, "migrations/")
+
+	version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+	if err != nil {
+		return errors.Wrap(err, "parsing version")
+	}
+
+	var applied bool
+	err = conn.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", version,
+	).Scan(&applied)
+	if err != nil {
+		return errors.Wrap(err, "checking schema_migrations")
+	}
+
+	if applied {
+		return nil
+	}
+
+	query, err := _migrations.ReadFile(file)
+	if err != nil {
+		return errors.Wrap(err, "ReadFile")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "conn.BeginTx")
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, string(query)); err != nil {
+		return errors.Wrap(err, "tx.ExecContext")
+	}
+
+	if _, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		return errors.Wrap(err, "recording version")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "tx.Commit")
+	}
+
+	return nil
+}
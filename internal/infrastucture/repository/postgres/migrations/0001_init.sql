@@ -0,0 +1,16 @@
+CREATE TABLE telegram_accounts (
+    client_id TEXT PRIMARY KEY,
+    chat_id   BIGINT NOT NULL,
+    bot_id    TEXT   NOT NULL DEFAULT ''
+);
+
+CREATE INDEX telegram_accounts_bot_id_idx ON telegram_accounts (bot_id);
+
+CREATE TABLE bots (
+    id        TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    username  TEXT NOT NULL,
+    token     TEXT NOT NULL
+);
+
+CREATE INDEX bots_tenant_id_idx ON bots (tenant_id);
@@ -0,0 +1,12 @@
+CREATE TABLE sensors (
+    id         TEXT PRIMARY KEY,
+    name       TEXT             NOT NULL,
+    latitude   DOUBLE PRECISION,
+    longitude  DOUBLE PRECISION,
+    address    TEXT             NOT NULL DEFAULT '',
+    client_ids TEXT[]           NOT NULL DEFAULT '{}',
+    enabled    BOOLEAN          NOT NULL DEFAULT TRUE,
+    tags       TEXT[]           NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX sensors_client_ids_idx ON sensors USING GIN (client_ids);
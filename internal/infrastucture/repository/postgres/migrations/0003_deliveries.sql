@@ -0,0 +1,12 @@
+CREATE TABLE deliveries (
+    request_id          TEXT        NOT NULL,
+    client_id           TEXT        NOT NULL,
+    chat_id             BIGINT      NOT NULL,
+    bot_id              TEXT        NOT NULL DEFAULT '',
+    message_id          INTEGER     NOT NULL,
+    location_message_id INTEGER     NOT NULL DEFAULT 0,
+    text                TEXT        NOT NULL,
+    status              TEXT        NOT NULL,
+    sent_at             TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (request_id, chat_id)
+);
@@ -0,0 +1,23 @@
+ALTER TABLE telegram_accounts
+    ADD COLUMN region TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN tags   TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX telegram_accounts_tags_idx ON telegram_accounts USING GIN (tags);
+
+CREATE TABLE broadcasts (
+    id          TEXT PRIMARY KEY,
+    text        TEXT        NOT NULL,
+    target      JSONB       NOT NULL,
+    status      TEXT        NOT NULL,
+    total       INTEGER     NOT NULL DEFAULT 0,
+    sent        INTEGER     NOT NULL DEFAULT 0,
+    failed      INTEGER     NOT NULL DEFAULT 0,
+    failures    JSONB       NOT NULL DEFAULT '[]',
+    cursor      TEXT        NOT NULL DEFAULT '',
+    error       TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX broadcasts_status_idx ON broadcasts (status);
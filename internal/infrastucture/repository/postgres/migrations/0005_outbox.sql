@@ -0,0 +1,17 @@
+CREATE TABLE outbox (
+    seq          BIGSERIAL PRIMARY KEY,
+    type         TEXT        NOT NULL,
+    key          TEXT        NOT NULL,
+    payload      BYTEA       NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_unpublished_idx ON outbox (seq) WHERE published_at IS NULL;
+CREATE INDEX outbox_published_at_idx ON outbox (published_at);
+
+CREATE TABLE leases (
+    name       TEXT PRIMARY KEY,
+    owner      TEXT        NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
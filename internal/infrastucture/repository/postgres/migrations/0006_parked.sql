@@ -0,0 +1,10 @@
+CREATE TABLE parked_notifications (
+    id           TEXT PRIMARY KEY,
+    message      JSONB       NOT NULL,
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    last_error   TEXT        NOT NULL DEFAULT '',
+    next_attempt TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX parked_notifications_next_attempt_idx ON parked_notifications (next_attempt);
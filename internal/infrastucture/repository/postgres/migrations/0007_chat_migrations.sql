@@ -0,0 +1,11 @@
+CREATE TABLE chat_migrations (
+    id           TEXT PRIMARY KEY,
+    bot_id       TEXT        NOT NULL DEFAULT '',
+    from_chat_id BIGINT      NOT NULL,
+    to_chat_id   BIGINT      NOT NULL,
+    client_ids   TEXT[]      NOT NULL DEFAULT '{}',
+    migrated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX chat_migrations_client_ids_idx ON chat_migrations USING GIN (client_ids);
+CREATE INDEX telegram_accounts_chat_id_idx ON telegram_accounts (chat_id);
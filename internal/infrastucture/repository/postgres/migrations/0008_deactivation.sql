@@ -0,0 +1,6 @@
+ALTER TABLE telegram_accounts
+    ADD COLUMN deactivation_reason TEXT,
+    ADD COLUMN deactivated_at      TIMESTAMPTZ;
+
+ALTER TABLE bots
+    ADD COLUMN admin_chat_id BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE telegram_accounts
+    ADD COLUMN thread_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,17 @@
+CREATE TABLE organizations (
+    id         TEXT PRIMARY KEY,
+    tenant_id  TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    chat_id    BIGINT      NOT NULL DEFAULT 0,
+    thread_id  INTEGER     NOT NULL DEFAULT 0,
+    bot_id     TEXT        NOT NULL DEFAULT '',
+    region     TEXT        NOT NULL DEFAULT '',
+    tags       TEXT[]      NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX organizations_tenant_id_idx ON organizations (tenant_id);
+
+ALTER TABLE telegram_accounts ADD COLUMN organization_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX telegram_accounts_organization_id_idx ON telegram_accounts (organization_id);
@@ -0,0 +1,23 @@
+CREATE TABLE zones (
+    id         TEXT PRIMARY KEY,
+    tenant_id  TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    geometry   JSONB       NOT NULL,
+    bbox       BOX         NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX zones_tenant_id_idx ON zones (tenant_id);
+
+-- The bounding boxes narrow the zones down to the few the point-in-polygon test runs on.
+CREATE INDEX zones_bbox_idx ON zones USING gist (bbox);
+
+CREATE TABLE zone_subscriptions (
+    id         TEXT PRIMARY KEY,
+    zone_id    TEXT        NOT NULL REFERENCES zones (id) ON DELETE CASCADE,
+    chat_id    BIGINT      NOT NULL,
+    thread_id  INTEGER     NOT NULL DEFAULT 0,
+    bot_id     TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (zone_id, chat_id, thread_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE telegram_accounts ADD COLUMN rules TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE zone_subscriptions ADD COLUMN rules TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,18 @@
+ALTER TABLE deliveries ADD COLUMN sensor_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE deliveries ADD COLUMN acknowledged_at TIMESTAMPTZ;
+
+CREATE INDEX deliveries_client_id_sent_at_idx ON deliveries (client_id, sent_at);
+
+CREATE TABLE digest_schedules (
+    id          TEXT PRIMARY KEY,
+    client_id   TEXT        NOT NULL,
+    cron        TEXT        NOT NULL,
+    timezone    TEXT        NOT NULL DEFAULT '',
+    period      TEXT        NOT NULL,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX digest_schedules_client_id_idx ON digest_schedules (client_id);
+CREATE INDEX digest_schedules_next_run_at_idx ON digest_schedules (next_run_at);
@@ -0,0 +1,4 @@
+ALTER TABLE deliveries ADD COLUMN message_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE deliveries ADD COLUMN detected_at TIMESTAMPTZ;
+
+CREATE INDEX deliveries_sent_at_idx ON deliveries (sent_at);
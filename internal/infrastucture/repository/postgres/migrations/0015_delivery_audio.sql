@@ -0,0 +1,2 @@
+ALTER TABLE deliveries ADD COLUMN audio_message_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE deliveries ADD COLUMN spectrogram_message_id INTEGER NOT NULL DEFAULT 0;
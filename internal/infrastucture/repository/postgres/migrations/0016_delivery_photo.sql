@@ -0,0 +1 @@
+ALTER TABLE deliveries ADD COLUMN photo_message_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE bots
+    ADD COLUMN poll_updates BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE update_offsets (
+    bot_id        TEXT PRIMARY KEY,
+    update_offset BIGINT NOT NULL
+);
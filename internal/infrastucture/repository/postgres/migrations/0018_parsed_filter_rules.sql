@@ -0,0 +1,3 @@
+ALTER TABLE telegram_accounts ADD COLUMN filters JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE zone_subscriptions ADD COLUMN filters JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _organizationColumns = "id, tenant_id, name, chat_id, thread_id, bot_id, region, tags, created_at"
+
+func scanOrganization(row rowScanner) (entities.Organization, error) {
+	var (
+		org   entities.Organization
+		orgID string
+	)
+
+	err := row.Scan(&orgID, &org.TenantID, &org.Name, &org.ChatID, &org.ThreadID, &org.BotID, &org.Region,
+		pq.Array(&org.Tags), &org.CreatedAt)
+	if err != nil {
+		return org, err
+	}
+
+	castedID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return org, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	org.ID = castedID
+	org.CreatedAt = org.CreatedAt.UTC()
+	if len(org.Tags) == 0 {
+		org.Tags = nil
+	}
+
+	return org, nil
+}
+
+func (r Repository) CreateOrganization(ctx context.Context, org entities.Organization) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateOrganization")
+	defer span.End()
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO organizations ("+_organizationColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		org.ID.Hex(), org.TenantID, org.Name, org.ChatID, org.ThreadID, org.BotID, org.Region, stringArray(org.Tags),
+		org.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetOrganization(ctx context.Context, orgID string) (entities.Organization, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetOrganization")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(orgID); err != nil {
+		return entities.Organization{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	org, err := scanOrganization(r.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+_organizationColumns+" FROM organizations WHERE id = $1", orgID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return org, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return org, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return org, nil
+}
+
+func (r Repository) ListOrganizations(ctx context.Context, tenantID string) ([]entities.Organization, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListOrganizations")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT "+_organizationColumns+" FROM organizations WHERE $1 = '' OR tenant_id = $1 ORDER BY id", tenantID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	orgs := make([]entities.Organization, 0)
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanOrganization")
+		}
+
+		orgs = append(orgs, org)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return orgs, nil
+}
+
+func (r Repository) UpdateOrganization(ctx context.Context, org entities.Organization) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateOrganization")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE organizations SET tenant_id = $2, name = $3, chat_id = $4, thread_id = $5, bot_id = $6, region = $7,
+			tags = $8, created_at = $9
+		WHERE id = $1`,
+		org.ID.Hex(), org.TenantID, org.Name, org.ChatID, org.ThreadID, org.BotID, org.Region, stringArray(org.Tags),
+		org.CreatedAt,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteOrganization(ctx context.Context, orgID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteOrganization")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM organizations WHERE id = $1", orgID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
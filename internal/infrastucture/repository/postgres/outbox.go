@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// _outboxLockID serializes the outbox writers, so the events commit in the order of their sequence numbers
+// and the relay never skips an event committed after a later one.
+const _outboxLockID = 7077
+
+type txKey struct{}
+
+// querier is the part of sql.DB and sql.Tx the repository queries with.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction started by InTransaction, the database outside of one.
+func (r Repository) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return r.db
+}
+
+// InTransaction runs fn in a transaction, it joins the transaction of the context when there is one.
+func (r Repository) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "db.BeginTx")
+	}
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return multierr.Append(err, tx.Rollback())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "tx.Commit")
+	}
+
+	return nil
+}
+
+func (r Repository) AddOutboxEvent(ctx context.Context, event entities.OutboxEvent) error {
+	ctx, span := r.tracer.Start(ctx, "repo.AddOutboxEvent")
+	defer span.End()
+
+	err := r.InTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.conn(ctx).ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", _outboxLockID); err != nil {
+			return errors.Wrap(err, "pg_advisory_xact_lock")
+		}
+
+		_, err := r.conn(ctx).ExecContext(ctx,
+			"INSERT INTO outbox (type, key, payload, created_at) VALUES ($1, $2, $3, $4)",
+			event.Type, event.Key, event.Payload, event.CreatedAt,
+		)
+
+		return errors.Wrap(err, "db.ExecContext")
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListOutboxEvents returns the oldest unpublished events.
+func (r Repository) ListOutboxEvents(ctx context.Context, limit int) ([]entities.OutboxEvent, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListOutboxEvents")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		`SELECT seq, type, key, payload, created_at FROM outbox
+		WHERE published_at IS NULL ORDER BY seq LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	events := make([]entities.OutboxEvent, 0)
+	for rows.Next() {
+		var event entities.OutboxEvent
+		if err = rows.Scan(&event.Seq, &event.Type, &event.Key, &event.Payload, &event.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan")
+		}
+
+		event.CreatedAt = event.CreatedAt.UTC()
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return events, nil
+}
+
+// MarkOutboxPublished marks the events as published.
+func (r Repository) MarkOutboxPublished(ctx context.Context, events []entities.OutboxEvent, at time.Time) error {
+	ctx, span := r.tracer.Start(ctx, "repo.MarkOutboxPublished")
+	defer span.End()
+
+	seqs := make([]int64, 0, len(events))
+	for _, event := range events {
+		seqs = append(seqs, event.Seq)
+	}
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"UPDATE outbox SET published_at = $2 WHERE seq = ANY($1) AND published_at IS NULL",
+		pq.Array(seqs), at,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// DeleteOutboxEvents deletes the events published before the time, it returns how many were deleted.
+func (r Repository) DeleteOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteOutboxEvents")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM outbox WHERE published_at < $1", publishedBefore)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "db.ExecContext")
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "res.RowsAffected")
+	}
+
+	return deleted, nil
+}
+
+// AcquireLease takes the lease for ttl when it's free or expired, or renews it for its owner.
+func (r Repository) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.AcquireLease")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO leases (name, owner, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at
+		WHERE leases.owner = EXCLUDED.owner OR leases.expires_at < $4`,
+		name, owner, now.Add(ttl), now,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "res.RowsAffected")
+	}
+
+	return affected != 0, nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _parkedColumns = "id, message, attempts, last_error, next_attempt, created_at"
+
+func scanParked(row rowScanner) (entities.ParkedNotification, error) {
+	var (
+		parked  entities.ParkedNotification
+		id      string
+		message []byte
+	)
+
+	err := row.Scan(&id, &message, &parked.Attempts, &parked.LastError, &parked.NextAttempt, &parked.CreatedAt)
+	if err != nil {
+		return parked, err
+	}
+
+	if parked.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return parked, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = json.Unmarshal(message, &parked.Message); err != nil {
+		return parked, errors.Wrap(err, "json.Unmarshal message")
+	}
+
+	return parked, nil
+}
+
+func (r Repository) ParkNotification(ctx context.Context, parked entities.ParkedNotification) error {
+	ctx, span := r.tracer.Start(ctx, "repo.ParkNotification")
+	defer span.End()
+
+	message, err := json.Marshal(parked.Message)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal message")
+	}
+
+	_, err = r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO parked_notifications (`+_parkedColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		parked.ID.Hex(), message, parked.Attempts, parked.LastError, parked.NextAttempt, parked.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+// ListDueNotifications returns the parked notifications due by now, oldest first.
+func (r Repository) ListDueNotifications(
+	ctx context.Context, now time.Time, limit int,
+) ([]entities.ParkedNotification, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListDueNotifications")
+	defer span.End()
+
+	query := `SELECT ` + _parkedColumns + ` FROM parked_notifications WHERE next_attempt <= $1
+		ORDER BY created_at, id`
+	args := []interface{}{now}
+
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	due := make([]entities.ParkedNotification, 0)
+	for rows.Next() {
+		parked, err := scanParked(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanParked")
+		}
+
+		due = append(due, parked)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return due, nil
+}
+
+func (r Repository) UpdateParkedNotification(ctx context.Context, parked entities.ParkedNotification) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateParkedNotification")
+	defer span.End()
+
+	message, err := json.Marshal(parked.Message)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal message")
+	}
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE parked_notifications SET message = $2, attempts = $3, last_error = $4, next_attempt = $5,
+		created_at = $6 WHERE id = $1`,
+		parked.ID.Hex(), message, parked.Attempts, parked.LastError, parked.NextAttempt, parked.CreatedAt,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteParkedNotification(ctx context.Context, parkedID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteParkedNotification")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(parkedID); err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM parked_notifications WHERE id = $1", parkedID); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) CountParkedNotifications(ctx context.Context) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.CountParkedNotifications")
+	defer span.End()
+
+	var count int64
+	if err := r.conn(ctx).QueryRowContext(ctx, "SELECT count(*) FROM parked_notifications").Scan(&count); err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,77 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/postgres"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/repotest"
+)
+
+// _dsnEnv names the database the contract runs against, the test is skipped when it isn't set.
+const _dsnEnv = "TEST_POSTGRES_DSN"
+
+func TestRepository(t *testing.T) {
+	dsn := os.Getenv(_dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s isn't set", _dsnEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer admin.Close()
+
+	repotest.Run(t, func(t *testing.T) repotest.Repository {
+		return newRepository(t, admin, dsn)
+	})
+}
+
+// newRepository migrates a schema of its own, so every repository starts empty.
+func newRepository(t *testing.T, admin *sql.DB, dsn string) *postgres.Repository {
+	ctx := context.Background()
+	schema := fmt.Sprintf("repotest_%d", time.Now().UnixNano())
+
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("dropping schema: %v", err)
+		}
+	})
+
+	db, err := sql.Open("postgres", withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err = postgres.Migrate(ctx, db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	return postgres.NewRepository(db)
+}
+
+// withSearchPath adds the search_path parameter to a URL or a key=value DSN.
+func withSearchPath(dsn, schema string) string {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + schema
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+
+	return dsn + "?search_path=" + schema
+}
@@ -0,0 +1,317 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// _uniqueViolation is the SQLSTATE returned when an insert hits a primary key or unique index.
+const _uniqueViolation = "23505"
+
+// Repository is the PostgreSQL implementation of the storage, it returns the same errors
+// as the Mongo one so callers don't depend on the backend.
+type Repository struct {
+	db     *sql.DB
+	tracer trace.Tracer
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{
+		db:     db,
+		tracer: otel.GetTracerProvider().Tracer("repo"),
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == _uniqueViolation
+}
+
+// stringArray is a text[] parameter that is empty rather than NULL for a nil slice.
+func stringArray(values []string) interface{} {
+	if values == nil {
+		values = []string{}
+	}
+
+	return pq.Array(values)
+}
+
+// filterRules is a jsonb column of the parsed filter rules, an empty array for none.
+type filterRules struct {
+	rules *[]entities.FilterRule
+}
+
+func (f filterRules) Value() (driver.Value, error) {
+	if len(*f.rules) == 0 {
+		return []byte("[]"), nil
+	}
+
+	value, err := json.Marshal(*f.rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal filters")
+	}
+
+	return value, nil
+}
+
+func (f filterRules) Scan(src interface{}) error {
+	value, ok := src.([]byte)
+	if !ok {
+		return errors.Errorf("filters: unexpected %T", src)
+	}
+
+	if err := json.Unmarshal(value, f.rules); err != nil {
+		return errors.Wrap(err, "json.Unmarshal filters")
+	}
+
+	if len(*f.rules) == 0 {
+		*f.rules = nil
+	}
+
+	return nil
+}
+
+const _accountColumns = "client_id, chat_id, bot_id, region, tags, deactivation_reason, deactivated_at, thread_id, organization_id, rules, filters"
+
+// accountArgs are the values of _accountColumns.
+func accountArgs(client entities.TGAccount) []interface{} {
+	var reason, at interface{}
+	if client.Deactivation != nil {
+		reason, at = client.Deactivation.Reason, client.Deactivation.At
+	}
+
+	return []interface{}{
+		client.ClientID.Hex(), client.ChatID, client.BotID, client.Region, stringArray(client.Tags), reason, at, client.ThreadID,
+		client.OrganizationID, stringArray(client.Rules), filterRules{&client.Filters},
+	}
+}
+
+func scanAccount(row rowScanner) (entities.TGAccount, error) {
+	var (
+		client        entities.TGAccount
+		clientID      string
+		reason        sql.NullString
+		deactivatedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&clientID, &client.ChatID, &client.BotID, &client.Region, pq.Array(&client.Tags), &reason, &deactivatedAt, &client.ThreadID,
+		&client.OrganizationID, pq.Array(&client.Rules), filterRules{&client.Filters},
+	)
+	if err != nil {
+		return client, err
+	}
+
+	if deactivatedAt.Valid {
+		client.Deactivation = &entities.Deactivation{Reason: reason.String, At: deactivatedAt.Time.UTC()}
+	}
+
+	castedID, err := primitive.ObjectIDFromHex(clientID)
+	if err != nil {
+		return client, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	client.ClientID = castedID
+	if len(client.Tags) == 0 {
+		client.Tags = nil
+	}
+
+	if len(client.Rules) == 0 {
+		client.Rules = nil
+	}
+
+	return client, nil
+}
+
+func (r Repository) Create(ctx context.Context, client entities.TGAccount) error {
+	ctx, span := r.tracer.Start(ctx, "repo.Create")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO telegram_accounts (`+_accountColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (client_id) DO NOTHING`,
+		accountArgs(client)...,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordExists
+	}
+
+	return nil
+}
+
+func (r Repository) Delete(ctx context.Context, clientID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.Delete")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(clientID); err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if _, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM telegram_accounts WHERE client_id = $1", clientID); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetAccountByClientID(ctx context.Context, clientID string) (entities.TGAccount, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetAccountByClientID")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(clientID); err != nil {
+		return entities.TGAccount{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	client, err := scanAccount(r.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+_accountColumns+" FROM telegram_accounts WHERE client_id = $1", clientID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return client, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return client, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return client, nil
+}
+
+// UpsertAccount creates the account or replaces the existing one, it reports whether the account was created.
+func (r Repository) UpsertAccount(ctx context.Context, client entities.TGAccount) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.UpsertAccount")
+	defer span.End()
+
+	var created bool
+
+	// xmax is zero only for a freshly inserted row.
+	err := r.conn(ctx).QueryRowContext(ctx,
+		`INSERT INTO telegram_accounts (`+_accountColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (client_id) DO UPDATE SET chat_id = EXCLUDED.chat_id, bot_id = EXCLUDED.bot_id,
+			region = EXCLUDED.region, tags = EXCLUDED.tags,
+			deactivation_reason = EXCLUDED.deactivation_reason, deactivated_at = EXCLUDED.deactivated_at,
+			thread_id = EXCLUDED.thread_id, organization_id = EXCLUDED.organization_id, rules = EXCLUDED.rules,
+			filters = EXCLUDED.filters
+		RETURNING xmax = 0`,
+		accountArgs(client)...,
+	).Scan(&created)
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return created, nil
+}
+
+// ForEachAccount calls fn for every account ordered by client ID, it stops at the first error of fn.
+func (r Repository) ForEachAccount(ctx context.Context, fn func(entities.TGAccount) error) error {
+	ctx, span := r.tracer.Start(ctx, "repo.ForEachAccount")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT "+_accountColumns+" FROM telegram_accounts ORDER BY client_id")
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		client, err := scanAccount(rows)
+		if err != nil {
+			return errors.Wrap(err, "scanAccount")
+		}
+
+		if err = fn(client); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "rows.Err")
+	}
+
+	return nil
+}
+
+// _accountWhere is the condition of the account filter over the parameters $1 to $5.
+const _accountWhere = `(cardinality($1::text[]) = 0 OR bot_id = ANY($1)) AND ($2 = '' OR region = $2)
+	AND (cardinality($3::text[]) = 0 OR tags && $3) AND ($4 = '' OR client_id > $4)
+	AND ($5 = '' OR organization_id = $5)`
+
+func (r Repository) ListAccounts(ctx context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListAccounts")
+	defer span.End()
+
+	var limit interface{}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT "+_accountColumns+" FROM telegram_accounts WHERE "+_accountWhere+" ORDER BY client_id LIMIT $6",
+		stringArray(filter.BotIDs), filter.Region, stringArray(filter.Tags), filter.After, filter.OrganizationID, limit,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	accounts := make([]entities.TGAccount, 0)
+	for rows.Next() {
+		client, err := scanAccount(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanAccount")
+		}
+
+		accounts = append(accounts, client)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return accounts, nil
+}
+
+// CountAccounts counts the accounts matching the filter, its After and Limit are ignored.
+func (r Repository) CountAccounts(ctx context.Context, filter entities.AccountFilter) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.CountAccounts")
+	defer span.End()
+
+	var count int64
+
+	err := r.conn(ctx).QueryRowContext(ctx,
+		"SELECT count(*) FROM telegram_accounts WHERE "+_accountWhere,
+		stringArray(filter.BotIDs), filter.Region, stringArray(filter.Tags), "", filter.OrganizationID,
+	).Scan(&count)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return count, nil
+}
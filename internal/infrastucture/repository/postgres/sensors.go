@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _sensorColumns = "id, name, latitude, longitude, address, client_ids, enabled, tags"
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSensor(row rowScanner) (entities.Sensor, error) {
+	var (
+		sensor   entities.Sensor
+		lat, lon sql.NullFloat64
+	)
+
+	err := row.Scan(&sensor.ID, &sensor.Name, &lat, &lon, &sensor.Address,
+		pq.Array(&sensor.ClientIDs), &sensor.Enabled, pq.Array(&sensor.Tags))
+	if err != nil {
+		return sensor, err
+	}
+
+	if lat.Valid && lon.Valid {
+		sensor.Location = &entities.Location{Latitude: lat.Float64, Longitude: lon.Float64}
+	}
+
+	return sensor, nil
+}
+
+func locationColumns(location *entities.Location) (sql.NullFloat64, sql.NullFloat64) {
+	if location == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{Float64: location.Latitude, Valid: true}, sql.NullFloat64{Float64: location.Longitude, Valid: true}
+}
+
+func (r Repository) CreateSensor(ctx context.Context, sensor entities.Sensor) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateSensor")
+	defer span.End()
+
+	lat, lon := locationColumns(sensor.Location)
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO sensors ("+_sensorColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		sensor.ID, sensor.Name, lat, lon, sensor.Address, pq.Array(sensor.ClientIDs), sensor.Enabled, pq.Array(sensor.Tags),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetSensor(ctx context.Context, sensorID string) (entities.Sensor, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetSensor")
+	defer span.End()
+
+	sensor, err := scanSensor(r.conn(ctx).QueryRowContext(ctx, "SELECT "+_sensorColumns+" FROM sensors WHERE id = $1", sensorID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sensor, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return sensor, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return sensor, nil
+}
+
+func (r Repository) ListSensors(ctx context.Context, clientID string) ([]entities.Sensor, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListSensors")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT "+_sensorColumns+" FROM sensors WHERE $1 = '' OR $1 = ANY(client_ids) ORDER BY id", clientID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	sensors := make([]entities.Sensor, 0)
+	for rows.Next() {
+		sensor, err := scanSensor(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanSensor")
+		}
+
+		sensors = append(sensors, sensor)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return sensors, nil
+}
+
+func (r Repository) UpdateSensor(ctx context.Context, sensor entities.Sensor) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateSensor")
+	defer span.End()
+
+	lat, lon := locationColumns(sensor.Location)
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE sensors SET name = $2, latitude = $3, longitude = $4, address = $5, client_ids = $6, enabled = $7, tags = $8
+		WHERE id = $1`,
+		sensor.ID, sensor.Name, lat, lon, sensor.Address, pq.Array(sensor.ClientIDs), sensor.Enabled, pq.Array(sensor.Tags),
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteSensor(ctx context.Context, sensorID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteSensor")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM sensors WHERE id = $1", sensorID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// _statsWhere selects the deliveries of the DeliveryStatsFilter: $1 client IDs, $2 since, $3 until.
+const _statsWhere = `(coalesce(cardinality($1::text[]), 0) = 0 OR client_id = ANY($1::text[]))
+	AND ($2::timestamptz IS NULL OR sent_at >= $2) AND ($3::timestamptz IS NULL OR sent_at < $3)`
+
+// DeliveryStats aggregates the deliveries, percentile_disc picks the same nearest rank percentiles
+// as entities.Percentiles.
+func (r Repository) DeliveryStats(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.DeliveryStats")
+	defer span.End()
+
+	stats := entities.DeliveryStats{
+		Since:      filter.Since,
+		Until:      filter.Until,
+		ByStatus:   make(map[entities.DeliveryStatus]int64),
+		ByType:     make(map[string]int64),
+		TopSensors: make([]entities.SensorCount, 0),
+	}
+
+	var since, until interface{}
+	if !filter.Since.IsZero() {
+		since = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		until = filter.Until
+	}
+
+	args := []interface{}{pq.Array(filter.ClientIDs), since, until}
+
+	err := r.conn(ctx).QueryRowContext(ctx,
+		`SELECT count(*), count(DISTINCT request_id) FROM deliveries WHERE `+_statsWhere, args...,
+	).Scan(&stats.Deliveries, &stats.Alerts)
+	if err != nil {
+		span.RecordError(err)
+		return stats, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	err = r.queryCounts(ctx, `SELECT status, count(*) FROM deliveries WHERE `+_statsWhere+` GROUP BY status`, args,
+		func(key string, n int64) { stats.ByStatus[entities.DeliveryStatus(key)] = n })
+	if err != nil {
+		return stats, err
+	}
+
+	err = r.queryCounts(ctx, `SELECT message_type, count(*) FROM deliveries WHERE `+_statsWhere+` GROUP BY message_type`, args,
+		func(key string, n int64) { stats.ByType[key] = n })
+	if err != nil {
+		return stats, err
+	}
+
+	if stats.Latency, _, err = r.queryPercentiles(ctx, "sent_at - detected_at", "detected_at IS NOT NULL", args); err != nil {
+		return stats, err
+	}
+
+	stats.AckTime, stats.Acknowledged, err = r.queryPercentiles(ctx, "acknowledged_at - sent_at", "acknowledged_at IS NOT NULL", args)
+	if err != nil {
+		return stats, err
+	}
+
+	if filter.TopSensors <= 0 {
+		return stats, nil
+	}
+
+	err = r.queryCounts(ctx,
+		`SELECT sensor_id, count(DISTINCT request_id) AS alerts FROM deliveries WHERE `+_statsWhere+` AND sensor_id <> ''
+		GROUP BY sensor_id ORDER BY alerts DESC, sensor_id LIMIT $4`, append(args, filter.TopSensors),
+		func(key string, n int64) {
+			stats.TopSensors = append(stats.TopSensors, entities.SensorCount{SensorID: key, Alerts: n})
+		})
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// queryCounts runs a query of key and count rows.
+func (r Repository) queryCounts(ctx context.Context, query string, args []interface{}, fn func(key string, n int64)) error {
+	span := trace.SpanFromContext(ctx)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key string
+			n   int64
+		)
+
+		if err = rows.Scan(&key, &n); err != nil {
+			return errors.Wrap(err, "rows.Scan")
+		}
+
+		fn(key, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "rows.Err")
+	}
+
+	return nil
+}
+
+// queryPercentiles returns the percentiles of the interval over the deliveries meeting the condition
+// and their number.
+func (r Repository) queryPercentiles(
+	ctx context.Context, interval, condition string, args []interface{},
+) (entities.Percentiles, int64, error) {
+	var (
+		n                  int64
+		p50, p90, p99, max sql.NullFloat64
+	)
+
+	seconds := "EXTRACT(EPOCH FROM " + interval + ")"
+
+	err := r.conn(ctx).QueryRowContext(ctx,
+		`SELECT count(*), percentile_disc(0.5) WITHIN GROUP (ORDER BY `+seconds+`),
+			percentile_disc(0.9) WITHIN GROUP (ORDER BY `+seconds+`),
+			percentile_disc(0.99) WITHIN GROUP (ORDER BY `+seconds+`), max(`+seconds+`)
+		FROM deliveries WHERE `+_statsWhere+` AND `+condition, args...,
+	).Scan(&n, &p50, &p90, &p99, &max)
+	if err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+		return entities.Percentiles{}, 0, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	duration := func(seconds sql.NullFloat64) time.Duration {
+		return time.Duration(seconds.Float64 * float64(time.Second)).Round(time.Microsecond)
+	}
+
+	return entities.Percentiles{P50: duration(p50), P90: duration(p90), P99: duration(p99), Max: duration(max)}, n, nil
+}
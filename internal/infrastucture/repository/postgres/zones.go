@@ -0,0 +1,373 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const _zoneColumns = "id, tenant_id, name, geometry, created_at"
+
+func scanZone(row rowScanner) (entities.Zone, error) {
+	var (
+		zone     entities.Zone
+		zoneID   string
+		geometry []byte
+	)
+
+	if err := row.Scan(&zoneID, &zone.TenantID, &zone.Name, &geometry, &zone.CreatedAt); err != nil {
+		return zone, err
+	}
+
+	castedID, err := primitive.ObjectIDFromHex(zoneID)
+	if err != nil {
+		return zone, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = json.Unmarshal(geometry, &zone.Geometry); err != nil {
+		return zone, errors.Wrap(err, "json.Unmarshal geometry")
+	}
+
+	zone.ID = castedID
+	zone.CreatedAt = zone.CreatedAt.UTC()
+
+	return zone, nil
+}
+
+// zoneArgs are the values of the zone columns followed by the corners of its bounding box.
+func zoneArgs(zone entities.Zone) ([]interface{}, error) {
+	geometry, err := json.Marshal(zone.Geometry)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal geometry")
+	}
+
+	box := zone.Geometry.Bounds()
+
+	return []interface{}{
+		zone.ID.Hex(), zone.TenantID, zone.Name, geometry, zone.CreatedAt,
+		box.MinLongitude, box.MinLatitude, box.MaxLongitude, box.MaxLatitude,
+	}, nil
+}
+
+func (r Repository) CreateZone(ctx context.Context, zone entities.Zone) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateZone")
+	defer span.End()
+
+	args, err := zoneArgs(zone)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn(ctx).ExecContext(ctx,
+		`INSERT INTO zones (`+_zoneColumns+`, bbox) VALUES ($1, $2, $3, $4, $5, box(point($6, $7), point($8, $9)))`,
+		args...,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetZone(ctx context.Context, zoneID string) (entities.Zone, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetZone")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(zoneID); err != nil {
+		return entities.Zone{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	zone, err := scanZone(r.conn(ctx).QueryRowContext(ctx, "SELECT "+_zoneColumns+" FROM zones WHERE id = $1", zoneID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return zone, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return zone, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return zone, nil
+}
+
+func (r Repository) ListZones(ctx context.Context, tenantID string) ([]entities.Zone, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListZones")
+	defer span.End()
+
+	return r.queryZones(ctx, "SELECT "+_zoneColumns+" FROM zones WHERE $1 = '' OR tenant_id = $1 ORDER BY id", tenantID)
+}
+
+func (r Repository) UpdateZone(ctx context.Context, zone entities.Zone) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateZone")
+	defer span.End()
+
+	args, err := zoneArgs(zone)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE zones SET tenant_id = $2, name = $3, geometry = $4, created_at = $5,
+			bbox = box(point($6, $7), point($8, $9))
+		WHERE id = $1`,
+		args...,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteZone removes the zone, its subscriptions are removed by the foreign key.
+func (r Repository) DeleteZone(ctx context.Context, zoneID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteZone")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM zones WHERE id = $1", zoneID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ZonesContaining returns the zones the location is inside of. The GiST index of the bounding boxes finds
+// the candidates, the exact test against the polygons runs on them only.
+func (r Repository) ZonesContaining(ctx context.Context, loc entities.Location) ([]entities.Zone, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ZonesContaining")
+	defer span.End()
+
+	candidates, err := r.queryZones(ctx,
+		"SELECT "+_zoneColumns+" FROM zones WHERE bbox && box(point($1, $2), point($1, $2)) ORDER BY id",
+		loc.Longitude, loc.Latitude,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := candidates[:0]
+	for _, zone := range candidates {
+		if zone.Geometry.Contains(loc) {
+			zones = append(zones, zone)
+		}
+	}
+
+	return zones, nil
+}
+
+func (r Repository) queryZones(ctx context.Context, query string, args ...interface{}) ([]entities.Zone, error) {
+	span := trace.SpanFromContext(ctx)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	zones := make([]entities.Zone, 0)
+	for rows.Next() {
+		zone, err := scanZone(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanZone")
+		}
+
+		zones = append(zones, zone)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return zones, nil
+}
+
+const _zoneSubscriptionColumns = "id, zone_id, chat_id, thread_id, bot_id, created_at, rules, filters"
+
+func scanZoneSubscription(row rowScanner) (entities.ZoneSubscription, error) {
+	var (
+		sub   entities.ZoneSubscription
+		subID string
+	)
+
+	if err := row.Scan(
+		&subID, &sub.ZoneID, &sub.ChatID, &sub.ThreadID, &sub.BotID, &sub.CreatedAt, pq.Array(&sub.Rules), filterRules{&sub.Filters},
+	); err != nil {
+		return sub, err
+	}
+
+	castedID, err := primitive.ObjectIDFromHex(subID)
+	if err != nil {
+		return sub, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	sub.ID = castedID
+	sub.CreatedAt = sub.CreatedAt.UTC()
+	if len(sub.Rules) == 0 {
+		sub.Rules = nil
+	}
+
+	return sub, nil
+}
+
+// CreateZoneSubscription stores the subscription, a chat and topic subscribes to a zone once.
+func (r Repository) CreateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateZoneSubscription")
+	defer span.End()
+
+	_, err := r.conn(ctx).ExecContext(ctx,
+		"INSERT INTO zone_subscriptions ("+_zoneSubscriptionColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		sub.ID.Hex(), sub.ZoneID, sub.ChatID, sub.ThreadID, sub.BotID, sub.CreatedAt, stringArray(sub.Rules), filterRules{&sub.Filters},
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	return nil
+}
+
+func (r Repository) GetZoneSubscription(ctx context.Context, subID string) (entities.ZoneSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetZoneSubscription")
+	defer span.End()
+
+	if _, err := primitive.ObjectIDFromHex(subID); err != nil {
+		return entities.ZoneSubscription{}, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	sub, err := scanZoneSubscription(r.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+_zoneSubscriptionColumns+" FROM zone_subscriptions WHERE id = $1", subID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sub, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return sub, errors.Wrap(err, "db.QueryRowContext")
+	}
+
+	return sub, nil
+}
+
+func (r Repository) ListZoneSubscriptions(ctx context.Context, zoneID string) ([]entities.ZoneSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListZoneSubscriptions")
+	defer span.End()
+
+	rows, err := r.conn(ctx).QueryContext(ctx,
+		"SELECT "+_zoneSubscriptionColumns+" FROM zone_subscriptions WHERE zone_id = $1 ORDER BY id", zoneID,
+	)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "db.QueryContext")
+	}
+	defer rows.Close()
+
+	subs := make([]entities.ZoneSubscription, 0)
+	for rows.Next() {
+		sub, err := scanZoneSubscription(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanZoneSubscription")
+		}
+
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "rows.Err")
+	}
+
+	return subs, nil
+}
+
+func (r Repository) UpdateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateZoneSubscription")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx,
+		`UPDATE zone_subscriptions SET zone_id = $2, chat_id = $3, thread_id = $4, bot_id = $5, created_at = $6,
+			rules = $7, filters = $8
+		WHERE id = $1`,
+		sub.ID.Hex(), sub.ZoneID, sub.ChatID, sub.ThreadID, sub.BotID, sub.CreatedAt, stringArray(sub.Rules), filterRules{&sub.Filters},
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteZoneSubscription(ctx context.Context, subID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteZoneSubscription")
+	defer span.End()
+
+	res, err := r.conn(ctx).ExecContext(ctx, "DELETE FROM zone_subscriptions WHERE id = $1", subID)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "db.ExecContext")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "res.RowsAffected")
+	}
+
+	if affected == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
@@ -15,35 +16,122 @@ import (
 
 type Repository struct {
 	collection *mongo.Collection
+	bots       *mongo.Collection
+	sensors    *mongo.Collection
+	deliveries *mongo.Collection
+	broadcasts *mongo.Collection
+	outbox     *mongo.Collection
+	leases     *mongo.Collection
+	counters   *mongo.Collection
+	parked     *mongo.Collection
+	migrations *mongo.Collection
+	orgs       *mongo.Collection
+	zones      *mongo.Collection
+	zoneSubs   *mongo.Collection
+	digests    *mongo.Collection
+	offsets    *mongo.Collection
 	tracer     trace.Tracer
 }
 
-const _telegramCollectionName = "Telegram"
-
-var ErrRecordExists = errors.New("error record exists")
+const (
+	_telegramCollectionName   = "Telegram"
+	_botsCollectionName       = "Bots"
+	_sensorsCollectionName    = "Sensors"
+	_deliveriesCollectionName = "Deliveries"
+	_broadcastsCollectionName = "Broadcasts"
+	_outboxCollectionName     = "Outbox"
+	_leasesCollectionName     = "Leases"
+	_countersCollectionName   = "Counters"
+	_parkedCollectionName     = "Parked"
+	_migrationsCollectionName = "ChatMigrations"
+	_orgsCollectionName       = "Organizations"
+	_zonesCollectionName      = "Zones"
+	_zoneSubsCollectionName   = "ZoneSubscriptions"
+	_digestsCollectionName    = "DigestSchedules"
+	_offsetsCollectionName    = "UpdateOffsets"
+)
 
 func NewRepository(database *mongo.Database) *Repository {
 	return &Repository{
 		collection: database.Collection(_telegramCollectionName),
+		bots:       database.Collection(_botsCollectionName),
+		sensors:    database.Collection(_sensorsCollectionName),
+		deliveries: database.Collection(_deliveriesCollectionName),
+		broadcasts: database.Collection(_broadcastsCollectionName),
+		outbox:     database.Collection(_outboxCollectionName),
+		leases:     database.Collection(_leasesCollectionName),
+		counters:   database.Collection(_countersCollectionName),
+		parked:     database.Collection(_parkedCollectionName),
+		migrations: database.Collection(_migrationsCollectionName),
+		orgs:       database.Collection(_orgsCollectionName),
+		zones:      database.Collection(_zonesCollectionName),
+		zoneSubs:   database.Collection(_zoneSubsCollectionName),
+		digests:    database.Collection(_digestsCollectionName),
+		offsets:    database.Collection(_offsetsCollectionName),
 		tracer:     otel.GetTracerProvider().Tracer("repo"),
 	}
 }
 
-func (r Repository) Create(ctx context.Context, client entities.TGAccount) error {
-	ctx, span := r.tracer.Start(ctx, "repo.Create")
-	defer span.End()
+// EnsureIndexes creates the indexes the queries rely on: the unique index of the deliveries per chat,
+// the index of the due parked notifications, the unique index of the outbox events per key, the 2dsphere index of the zone geometries with the unique
+// index of the zone subscriptions, and the indexes of the due digest schedules.
+func (r Repository) EnsureIndexes(ctx context.Context) error {
+	// SaveDelivery upserts on the pair, the index keeps concurrent upserts from adding it twice.
+	_, err := r.deliveries.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "requestID", Value: 1}, {Key: "chatID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "deliveries.CreateOne")
+	}
 
-	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": client.ClientID})
+	_, err = r.parked.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "nextAttempt", Value: 1}}})
 	if err != nil {
-		span.RecordError(err)
-		return errors.Wrap(err, "collection.CountDocuments")
+		return errors.Wrap(err, "parked.CreateOne")
+	}
+
+	// ListOutboxEvents looks up the lowest unpublished number of the keys.
+	_, err = r.outbox.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}, {Key: "seq", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "outbox.CreateOne")
 	}
 
-	if count != 0 {
-		return ErrRecordExists
+	_, err = r.zones.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "geometry", Value: "2dsphere"}}})
+	if err != nil {
+		return errors.Wrap(err, "zones.CreateOne")
 	}
 
-	if _, err = r.collection.InsertOne(ctx, client); err != nil {
+	_, err = r.zoneSubs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "zoneID", Value: 1}, {Key: "chatID", Value: 1}, {Key: "threadID", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "zoneSubs.CreateOne")
+	}
+
+	_, err = r.digests.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "nextRunAt", Value: 1}}},
+		{Keys: bson.D{{Key: "clientID", Value: 1}}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "digests.CreateMany")
+	}
+
+	return nil
+}
+
+func (r Repository) Create(ctx context.Context, client entities.TGAccount) error {
+	ctx, span := r.tracer.Start(ctx, "repo.Create")
+	defer span.End()
+
+	if _, err := r.collection.InsertOne(ctx, client); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
 		span.RecordError(err)
 		return errors.Wrap(err, "collection.InsertOne")
 	}
@@ -72,23 +160,166 @@ func (r Repository) Delete(ctx context.Context, clientID string) error {
 	return nil
 }
 
-func (r Repository) GetChatIDByClientID(ctx context.Context, clientID string) (int64, error) {
-	ctx, span := r.tracer.Start(ctx, "repo.GetChatIDByClientID")
+func (r Repository) GetAccountByClientID(ctx context.Context, clientID string) (entities.TGAccount, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetAccountByClientID")
 	defer span.End()
 
+	var client entities.TGAccount
+
 	castedID, err := primitive.ObjectIDFromHex(clientID)
 	if err != nil {
-		return 0, err
+		return client, errors.Wrap(err, "primitive.ObjectIDFromHex")
 	}
 
 	filter := bson.M{
 		"_id": castedID,
 	}
 
-	var client entities.TGAccount
 	if err = r.collection.FindOne(ctx, filter).Decode(&client); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return client, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return client, errors.Wrap(err, "collection.FindOne")
+	}
+
+	return client, nil
+}
+
+// UpsertAccount creates the account or replaces the existing one, it reports whether the account was created.
+func (r Repository) UpsertAccount(ctx context.Context, client entities.TGAccount) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.UpsertAccount")
+	defer span.End()
+
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": client.ClientID}, client, options.Replace().SetUpsert(true))
+	if err != nil {
+		span.RecordError(err)
+		return false, errors.Wrap(err, "collection.ReplaceOne")
+	}
+
+	return res.UpsertedCount != 0, nil
+}
+
+// ForEachAccount calls fn for every account ordered by client ID, it stops at the first error of fn.
+func (r Repository) ForEachAccount(ctx context.Context, fn func(entities.TGAccount) error) error {
+	ctx, span := r.tracer.Start(ctx, "repo.ForEachAccount")
+	defer span.End()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "collection.Find")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var client entities.TGAccount
+		if err = cursor.Decode(&client); err != nil {
+			span.RecordError(err)
+			return errors.Wrap(err, "cursor.Decode")
+		}
+
+		if err = fn(client); err != nil {
+			return err
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "cursor.Err")
+	}
+
+	return nil
+}
+
+func accountQuery(filter entities.AccountFilter) (bson.M, error) {
+	query := bson.M{}
+
+	if len(filter.BotIDs) != 0 {
+		botIDs := make(bson.A, 0, len(filter.BotIDs))
+		for _, botID := range filter.BotIDs {
+			// The default bot is stored without the field, null matches a missing one.
+			if botID == "" {
+				botIDs = append(botIDs, nil)
+			} else {
+				botIDs = append(botIDs, botID)
+			}
+		}
+
+		query["botID"] = bson.M{"$in": botIDs}
+	}
+
+	if filter.Region != "" {
+		query["region"] = filter.Region
+	}
+
+	if len(filter.Tags) != 0 {
+		query["tags"] = bson.M{"$in": filter.Tags}
+	}
+
+	if filter.OrganizationID != "" {
+		query["organizationID"] = filter.OrganizationID
+	}
+
+	if filter.After != "" {
+		after, err := primitive.ObjectIDFromHex(filter.After)
+		if err != nil {
+			return nil, errors.Wrap(err, "primitive.ObjectIDFromHex")
+		}
+
+		query["_id"] = bson.M{"$gt": after}
+	}
+
+	return query, nil
+}
+
+func (r Repository) ListAccounts(ctx context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListAccounts")
+	defer span.End()
+
+	query, err := accountQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "collection.Find")
+	}
+
+	accounts := make([]entities.TGAccount, 0)
+	if err = cursor.All(ctx, &accounts); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return accounts, nil
+}
+
+// CountAccounts counts the accounts matching the filter, its After and Limit are ignored.
+func (r Repository) CountAccounts(ctx context.Context, filter entities.AccountFilter) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.CountAccounts")
+	defer span.End()
+
+	filter.After = ""
+
+	query, err := accountQuery(filter)
+	if err != nil {
 		return 0, err
 	}
 
-	return client.ChatID, nil
+	count, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "collection.CountDocuments")
+	}
+
+	return count, nil
 }
@@ -0,0 +1,1480 @@
+// Package repotest is the contract every storage backend must satisfy. A backend runs it from its own tests:
+//
+//	repotest.Run(t, func(t *testing.T) repotest.Repository { return newEmptyRepo(t) })
+package repotest
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type Repository interface {
+	Create(ctx context.Context, account entities.TGAccount) error
+	Delete(ctx context.Context, clientID string) error
+	GetAccountByClientID(ctx context.Context, clientID string) (entities.TGAccount, error)
+	UpsertAccount(ctx context.Context, account entities.TGAccount) (bool, error)
+	ForEachAccount(ctx context.Context, fn func(entities.TGAccount) error) error
+	ListAccounts(ctx context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error)
+	CountAccounts(ctx context.Context, filter entities.AccountFilter) (int64, error)
+
+	CreateBot(ctx context.Context, bot entities.Bot) error
+	GetBot(ctx context.Context, botID string) (entities.Bot, error)
+	ListBots(ctx context.Context, tenantID string) ([]entities.Bot, error)
+	DeleteBot(ctx context.Context, botID string) error
+	CountAccountsByBotID(ctx context.Context, botID string) (int64, error)
+
+	CreateSensor(ctx context.Context, sensor entities.Sensor) error
+	GetSensor(ctx context.Context, sensorID string) (entities.Sensor, error)
+	ListSensors(ctx context.Context, clientID string) ([]entities.Sensor, error)
+	UpdateSensor(ctx context.Context, sensor entities.Sensor) error
+	DeleteSensor(ctx context.Context, sensorID string) error
+
+	SaveDelivery(ctx context.Context, delivery entities.Delivery) error
+	ListDeliveries(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error)
+	DeliveryStats(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error)
+
+	CreateBroadcast(ctx context.Context, broadcast entities.Broadcast) error
+	GetBroadcast(ctx context.Context, broadcastID string) (entities.Broadcast, error)
+	ListBroadcasts(ctx context.Context, statuses ...entities.BroadcastStatus) ([]entities.Broadcast, error)
+	UpdateBroadcast(ctx context.Context, broadcast entities.Broadcast) error
+
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	AddOutboxEvent(ctx context.Context, event entities.OutboxEvent) error
+	ListOutboxEvents(ctx context.Context, limit int) ([]entities.OutboxEvent, error)
+	MarkOutboxPublished(ctx context.Context, events []entities.OutboxEvent, at time.Time) error
+	DeleteOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error)
+	AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+
+	ParkNotification(ctx context.Context, parked entities.ParkedNotification) error
+	ListDueNotifications(ctx context.Context, now time.Time, limit int) ([]entities.ParkedNotification, error)
+	UpdateParkedNotification(ctx context.Context, parked entities.ParkedNotification) error
+	DeleteParkedNotification(ctx context.Context, parkedID string) error
+	CountParkedNotifications(ctx context.Context) (int64, error)
+
+	MigrateChat(ctx context.Context, botID string, fromChatID, toChatID int64) ([]string, error)
+	AddChatMigration(ctx context.Context, migration entities.ChatMigration) error
+	ListChatMigrations(ctx context.Context, clientID string) ([]entities.ChatMigration, error)
+
+	SetChatDeactivation(ctx context.Context, botID string, chatID int64, deactivation *entities.Deactivation) ([]string, error)
+	GetUpdateOffset(ctx context.Context, botID string) (int, error)
+	SetUpdateOffset(ctx context.Context, botID string, offset int) error
+
+	CreateOrganization(ctx context.Context, org entities.Organization) error
+	GetOrganization(ctx context.Context, orgID string) (entities.Organization, error)
+	ListOrganizations(ctx context.Context, tenantID string) ([]entities.Organization, error)
+	UpdateOrganization(ctx context.Context, org entities.Organization) error
+	DeleteOrganization(ctx context.Context, orgID string) error
+
+	CreateZone(ctx context.Context, zone entities.Zone) error
+	GetZone(ctx context.Context, zoneID string) (entities.Zone, error)
+	ListZones(ctx context.Context, tenantID string) ([]entities.Zone, error)
+	UpdateZone(ctx context.Context, zone entities.Zone) error
+	DeleteZone(ctx context.Context, zoneID string) error
+	ZonesContaining(ctx context.Context, loc entities.Location) ([]entities.Zone, error)
+	CreateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error
+	GetZoneSubscription(ctx context.Context, subID string) (entities.ZoneSubscription, error)
+	ListZoneSubscriptions(ctx context.Context, zoneID string) ([]entities.ZoneSubscription, error)
+	UpdateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error
+	DeleteZoneSubscription(ctx context.Context, subID string) error
+
+	CreateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error
+	GetDigestSchedule(ctx context.Context, scheduleID string) (entities.DigestSchedule, error)
+	ListDigestSchedules(ctx context.Context, clientID string) ([]entities.DigestSchedule, error)
+	ListDueDigestSchedules(ctx context.Context, now time.Time, limit int) ([]entities.DigestSchedule, error)
+	UpdateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error
+	DeleteDigestSchedule(ctx context.Context, scheduleID string) error
+}
+
+// Run executes the contract, newRepo must return an empty repository for every call.
+func Run(t *testing.T, newRepo func(t *testing.T) Repository) {
+	t.Run("Accounts", func(t *testing.T) { testAccounts(t, newRepo(t)) })
+	t.Run("AccountsBulk", func(t *testing.T) { testAccountsBulk(t, newRepo(t)) })
+	t.Run("AccountFilter", func(t *testing.T) { testAccountFilter(t, newRepo(t)) })
+	t.Run("Bots", func(t *testing.T) { testBots(t, newRepo(t)) })
+	t.Run("Sensors", func(t *testing.T) { testSensors(t, newRepo(t)) })
+	t.Run("Deliveries", func(t *testing.T) { testDeliveries(t, newRepo(t)) })
+	t.Run("DeliveryStats", func(t *testing.T) { testDeliveryStats(t, newRepo(t)) })
+	t.Run("Broadcasts", func(t *testing.T) { testBroadcasts(t, newRepo(t)) })
+	t.Run("Outbox", func(t *testing.T) { testOutbox(t, newRepo(t)) })
+	t.Run("Leases", func(t *testing.T) { testLeases(t, newRepo(t)) })
+	t.Run("Parked", func(t *testing.T) { testParked(t, newRepo(t)) })
+	t.Run("ChatMigrations", func(t *testing.T) { testChatMigrations(t, newRepo(t)) })
+	t.Run("ChatDeactivation", func(t *testing.T) { testChatDeactivation(t, newRepo(t)) })
+	t.Run("UpdateOffsets", func(t *testing.T) { testUpdateOffsets(t, newRepo(t)) })
+	t.Run("Organizations", func(t *testing.T) { testOrganizations(t, newRepo(t)) })
+	t.Run("Zones", func(t *testing.T) { testZones(t, newRepo(t)) })
+	t.Run("DigestSchedules", func(t *testing.T) { testDigestSchedules(t, newRepo(t)) })
+}
+
+func testAccounts(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	account := entities.TGAccount{
+		ClientID: primitive.NewObjectID(), ChatID: -100123, BotID: primitive.NewObjectID().Hex(),
+		Region: "north", Tags: []string{"school", "city"}, ThreadID: 7,
+		Rules: []string{"confidence >= 0.8", "messageType in (audio, photo)"},
+	}
+	account.Filters, _ = entities.ParseFilterRules(account.Rules)
+
+	if _, err := repo.GetAccountByClientID(ctx, account.ClientID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetAccountByClientID on empty repository: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err := repo.Create(ctx, account); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Create(ctx, account); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("Create duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetAccountByClientID(ctx, account.ClientID.Hex())
+	if err != nil {
+		t.Fatalf("GetAccountByClientID: %v", err)
+	}
+
+	if !got.Equal(account) || !reflect.DeepEqual(got.Filters, account.Filters) {
+		t.Fatalf("GetAccountByClientID: want %+v, got %+v", account, got)
+	}
+
+	if _, err = repo.GetAccountByClientID(ctx, "not-an-id"); err == nil {
+		t.Fatal("GetAccountByClientID with malformed id: want error")
+	}
+
+	if err = repo.Delete(ctx, account.ClientID.Hex()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err = repo.GetAccountByClientID(ctx, account.ClientID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetAccountByClientID after Delete: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.Delete(ctx, account.ClientID.Hex()); err != nil {
+		t.Fatalf("Delete of a missing account: want nil, got %v", err)
+	}
+}
+
+func testAccountsBulk(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	first := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: 1}
+	second := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: 2, BotID: primitive.NewObjectID().Hex()}
+
+	for _, account := range []entities.TGAccount{second, first} {
+		created, err := repo.UpsertAccount(ctx, account)
+		if err != nil {
+			t.Fatalf("UpsertAccount: %v", err)
+		}
+
+		if !created {
+			t.Fatalf("UpsertAccount of a new account: want created")
+		}
+	}
+
+	first.ChatID = -100
+	first.BotID = primitive.NewObjectID().Hex()
+
+	created, err := repo.UpsertAccount(ctx, first)
+	if err != nil {
+		t.Fatalf("UpsertAccount of an existing account: %v", err)
+	}
+
+	if created {
+		t.Fatal("UpsertAccount of an existing account: want updated, got created")
+	}
+
+	var got []entities.TGAccount
+	if err = repo.ForEachAccount(ctx, func(account entities.TGAccount) error {
+		got = append(got, account)
+		return nil
+	}); err != nil {
+		t.Fatalf("ForEachAccount: %v", err)
+	}
+
+	// ObjectIDs created in sequence sort the same as their hex form.
+	want := []entities.TGAccount{first, second}
+	if len(got) != len(want) || !got[0].Equal(want[0]) || !got[1].Equal(want[1]) {
+		t.Fatalf("ForEachAccount: want %+v, got %+v", want, got)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.ForEachAccount(ctx, func(entities.TGAccount) error {
+		calls++
+		return stop
+	})
+
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("ForEachAccount: want the callback error after one call, got %v after %d calls", err, calls)
+	}
+}
+
+func testAccountFilter(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	botID := primitive.NewObjectID().Hex()
+
+	// ObjectIDs created in sequence sort the same as their hex form.
+	school := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: 1, Region: "north", Tags: []string{"school"}}
+	mall := entities.TGAccount{
+		ClientID: primitive.NewObjectID(), ChatID: 2, BotID: botID, Region: "north", Tags: []string{"mall", "city"},
+	}
+	park := entities.TGAccount{
+		ClientID: primitive.NewObjectID(), ChatID: 3, BotID: botID, Region: "south", OrganizationID: primitive.NewObjectID().Hex(),
+	}
+	plain := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: 4}
+
+	for _, account := range []entities.TGAccount{plain, park, mall, school} {
+		if err := repo.Create(ctx, account); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter entities.AccountFilter
+		want   []entities.TGAccount
+	}{
+		{"all", entities.AccountFilter{}, []entities.TGAccount{school, mall, park, plain}},
+		{"by bot", entities.AccountFilter{BotIDs: []string{botID}}, []entities.TGAccount{mall, park}},
+		{"by default bot", entities.AccountFilter{BotIDs: []string{""}}, []entities.TGAccount{school, plain}},
+		{"by region", entities.AccountFilter{Region: "north"}, []entities.TGAccount{school, mall}},
+		{"by any tag", entities.AccountFilter{Tags: []string{"school", "city"}}, []entities.TGAccount{school, mall}},
+		{"combined", entities.AccountFilter{BotIDs: []string{botID}, Region: "north"}, []entities.TGAccount{mall}},
+		{"by organization", entities.AccountFilter{OrganizationID: park.OrganizationID}, []entities.TGAccount{park}},
+		{"page", entities.AccountFilter{After: school.ClientID.Hex(), Limit: 2}, []entities.TGAccount{mall, park}},
+	}
+
+	for _, tc := range cases {
+		got, err := repo.ListAccounts(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("ListAccounts %s: %v", tc.name, err)
+		}
+
+		if len(got) != len(tc.want) {
+			t.Fatalf("ListAccounts %s: want %+v, got %+v", tc.name, tc.want, got)
+		}
+
+		for i := range tc.want {
+			if !got[i].Equal(tc.want[i]) {
+				t.Fatalf("ListAccounts %s[%d]: want %+v, got %+v", tc.name, i, tc.want[i], got[i])
+			}
+		}
+	}
+
+	count, err := repo.CountAccounts(ctx, entities.AccountFilter{Region: "north", After: mall.ClientID.Hex(), Limit: 1})
+	if err != nil {
+		t.Fatalf("CountAccounts: %v", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("CountAccounts: want 2 ignoring After and Limit, got %d", count)
+	}
+}
+
+func testBots(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	first := entities.Bot{
+		ID: primitive.NewObjectID(), TenantID: "acme", Username: "acme_alerts_bot", Token: "encrypted-1", AdminChatID: -42,
+		PollUpdates: true,
+	}
+	second := entities.Bot{ID: primitive.NewObjectID(), TenantID: "globex", Username: "globex_bot", Token: "encrypted-2"}
+
+	for _, bot := range []entities.Bot{first, second} {
+		if err := repo.CreateBot(ctx, bot); err != nil {
+			t.Fatalf("CreateBot: %v", err)
+		}
+	}
+
+	if err := repo.CreateBot(ctx, first); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateBot duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetBot(ctx, first.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetBot: %v", err)
+	}
+
+	if got != first {
+		t.Fatalf("GetBot: want %+v, got %+v", first, got)
+	}
+
+	all, err := repo.ListBots(ctx, "")
+	if err != nil {
+		t.Fatalf("ListBots: %v", err)
+	}
+
+	if len(all) != 2 {
+		t.Fatalf("ListBots without tenant: want 2 bots, got %d", len(all))
+	}
+
+	tenant, err := repo.ListBots(ctx, "acme")
+	if err != nil {
+		t.Fatalf("ListBots: %v", err)
+	}
+
+	if len(tenant) != 1 || tenant[0] != first {
+		t.Fatalf("ListBots for tenant: want [%+v], got %+v", first, tenant)
+	}
+
+	account := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: 42, BotID: first.ID.Hex()}
+	if err = repo.Create(ctx, account); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	count, err := repo.CountAccountsByBotID(ctx, first.ID.Hex())
+	if err != nil {
+		t.Fatalf("CountAccountsByBotID: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("CountAccountsByBotID: want 1, got %d", count)
+	}
+
+	if err = repo.DeleteBot(ctx, first.ID.Hex()); !errors.Is(err, entities.ErrRecordInUse) {
+		t.Fatalf("DeleteBot of a used bot: want ErrRecordInUse, got %v", err)
+	}
+
+	if _, err = repo.GetBot(ctx, first.ID.Hex()); err != nil {
+		t.Fatalf("GetBot after refused DeleteBot: %v", err)
+	}
+
+	if err = repo.DeleteBot(ctx, second.ID.Hex()); err != nil {
+		t.Fatalf("DeleteBot: %v", err)
+	}
+
+	if err = repo.DeleteBot(ctx, second.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteBot of a missing bot: want ErrRecordNotFound, got %v", err)
+	}
+
+	if _, err = repo.GetBot(ctx, second.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetBot after DeleteBot: want ErrRecordNotFound, got %v", err)
+	}
+
+	if _, err = repo.GetBot(ctx, "not-an-id"); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetBot with an invalid ID: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.DeleteBot(ctx, "not-an-id"); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteBot with an invalid ID: want ErrRecordNotFound, got %v", err)
+	}
+}
+
+func testSensors(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	clientID := primitive.NewObjectID().Hex()
+	gate := entities.Sensor{
+		ID:        "gate-1",
+		Name:      "North gate",
+		Location:  &entities.Location{Latitude: 55.75, Longitude: 37.61},
+		Address:   "1 Main st",
+		ClientIDs: []string{clientID},
+		Enabled:   true,
+		Tags:      []string{"outdoor"},
+	}
+	hall := entities.Sensor{ID: "hall-1", Name: "Hall", ClientIDs: []string{primitive.NewObjectID().Hex()}}
+
+	for _, sensor := range []entities.Sensor{gate, hall} {
+		if err := repo.CreateSensor(ctx, sensor); err != nil {
+			t.Fatalf("CreateSensor: %v", err)
+		}
+	}
+
+	if err := repo.CreateSensor(ctx, gate); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateSensor duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetSensor(ctx, gate.ID)
+	if err != nil {
+		t.Fatalf("GetSensor: %v", err)
+	}
+
+	assertSensor(t, "GetSensor", gate, got)
+
+	got, err = repo.GetSensor(ctx, hall.ID)
+	if err != nil {
+		t.Fatalf("GetSensor: %v", err)
+	}
+
+	assertSensor(t, "GetSensor without location", hall, got)
+
+	owned, err := repo.ListSensors(ctx, clientID)
+	if err != nil {
+		t.Fatalf("ListSensors: %v", err)
+	}
+
+	if len(owned) != 1 || owned[0].ID != gate.ID {
+		t.Fatalf("ListSensors for client: want [%s], got %+v", gate.ID, owned)
+	}
+
+	all, err := repo.ListSensors(ctx, "")
+	if err != nil {
+		t.Fatalf("ListSensors: %v", err)
+	}
+
+	if len(all) != 2 {
+		t.Fatalf("ListSensors without client: want 2 sensors, got %d", len(all))
+	}
+
+	gate.Enabled = false
+	gate.Location = nil
+	gate.Tags = []string{"outdoor", "parking"}
+	if err = repo.UpdateSensor(ctx, gate); err != nil {
+		t.Fatalf("UpdateSensor: %v", err)
+	}
+
+	got, err = repo.GetSensor(ctx, gate.ID)
+	if err != nil {
+		t.Fatalf("GetSensor: %v", err)
+	}
+
+	assertSensor(t, "GetSensor after UpdateSensor", gate, got)
+
+	if err = repo.UpdateSensor(ctx, entities.Sensor{ID: "missing"}); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateSensor of a missing sensor: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.DeleteSensor(ctx, gate.ID); err != nil {
+		t.Fatalf("DeleteSensor: %v", err)
+	}
+
+	if err = repo.DeleteSensor(ctx, gate.ID); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteSensor of a missing sensor: want ErrRecordNotFound, got %v", err)
+	}
+
+	if _, err = repo.GetSensor(ctx, gate.ID); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetSensor after DeleteSensor: want ErrRecordNotFound, got %v", err)
+	}
+}
+
+func testDeliveries(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	requestID := uuid.NewString()
+	// Mongo keeps milliseconds only.
+	sentAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	group := entities.Delivery{
+		RequestID: requestID, ClientID: primitive.NewObjectID().Hex(), ChatID: -100123, MessageID: 7,
+		LocationMessageID: 8, Text: "Gunshot", Status: entities.DeliverySent, SentAt: sentAt,
+	}
+	private := entities.Delivery{
+		RequestID: requestID, ClientID: primitive.NewObjectID().Hex(), ChatID: 42, BotID: primitive.NewObjectID().Hex(),
+		MessageID: 3, Text: "Gunshot", Status: entities.DeliverySent, SentAt: sentAt, SensorID: "sensor-1",
+		MessageType: "audio", DetectedAt: sentAt.Add(-2 * time.Second), AudioMessageID: 4, SpectrogramMessageID: 5,
+		PhotoMessageID: 6,
+	}
+	other := entities.Delivery{
+		RequestID: uuid.NewString(), ClientID: group.ClientID, ChatID: group.ChatID, MessageID: 9,
+		Text: "Gunshot", Status: entities.DeliverySent, SentAt: sentAt.Add(time.Minute),
+	}
+
+	if got, err := repo.ListDeliveries(ctx, entities.DeliveryFilter{RequestID: requestID}); err != nil || len(got) != 0 {
+		t.Fatalf("ListDeliveries on empty repository: want no deliveries, got %+v, %v", got, err)
+	}
+
+	for _, delivery := range []entities.Delivery{private, group, other} {
+		if err := repo.SaveDelivery(ctx, delivery); err != nil {
+			t.Fatalf("SaveDelivery: %v", err)
+		}
+	}
+
+	acknowledgedAt := sentAt.Add(30 * time.Second)
+	group.Status = entities.DeliveryUpdated
+	group.Text = "Gunshot (false alarm)"
+	group.AcknowledgedAt = &acknowledgedAt
+	if err := repo.SaveDelivery(ctx, group); err != nil {
+		t.Fatalf("SaveDelivery of an existing delivery: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		filter entities.DeliveryFilter
+		want   []entities.Delivery
+	}{
+		{"by request", entities.DeliveryFilter{RequestID: requestID}, []entities.Delivery{group, private}},
+		{"by client, newest first", entities.DeliveryFilter{ClientID: group.ClientID}, []entities.Delivery{other, group}},
+		{"since", entities.DeliveryFilter{Since: other.SentAt}, []entities.Delivery{other}},
+		{"until", entities.DeliveryFilter{Until: other.SentAt}, []entities.Delivery{group, private}},
+		{"limit", entities.DeliveryFilter{Limit: 2}, []entities.Delivery{other, group}},
+	}
+
+	for _, tc := range cases {
+		got, err := repo.ListDeliveries(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("ListDeliveries %s: %v", tc.name, err)
+		}
+
+		assertDeliveries(t, "ListDeliveries "+tc.name, tc.want, got)
+	}
+}
+
+func testBroadcasts(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	// Mongo keeps milliseconds only.
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	older := entities.Broadcast{
+		ID: primitive.NewObjectID(), Text: "Maintenance tonight", Status: entities.BroadcastDone,
+		Failures: []entities.BroadcastFailure{}, CreatedAt: now.Add(-time.Hour), UpdatedAt: now, FinishedAt: now,
+	}
+	broadcast := entities.Broadcast{
+		ID: primitive.NewObjectID(), Text: "Storm warning",
+		Target: entities.BroadcastTarget{TenantID: "acme", Region: "north", Tags: []string{"school"}},
+		Status: entities.BroadcastPending, Failures: []entities.BroadcastFailure{}, CreatedAt: now, UpdatedAt: now,
+	}
+
+	if _, err := repo.GetBroadcast(ctx, broadcast.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetBroadcast on empty repository: want ErrRecordNotFound, got %v", err)
+	}
+
+	for _, b := range []entities.Broadcast{older, broadcast} {
+		if err := repo.CreateBroadcast(ctx, b); err != nil {
+			t.Fatalf("CreateBroadcast: %v", err)
+		}
+	}
+
+	if err := repo.CreateBroadcast(ctx, broadcast); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateBroadcast duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetBroadcast(ctx, broadcast.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetBroadcast: %v", err)
+	}
+
+	assertBroadcast(t, "GetBroadcast", broadcast, got)
+
+	if _, err = repo.GetBroadcast(ctx, "not-an-id"); err == nil {
+		t.Fatal("GetBroadcast with malformed id: want error")
+	}
+
+	broadcast.Status = entities.BroadcastRunning
+	broadcast.Total, broadcast.Sent, broadcast.Failed = 3, 1, 1
+	broadcast.Cursor = primitive.NewObjectID().Hex()
+	broadcast.Failures = []entities.BroadcastFailure{
+		{ClientID: primitive.NewObjectID().Hex(), ChatID: 42, Error: "Forbidden: bot was blocked by the user", At: now},
+	}
+
+	if err = repo.UpdateBroadcast(ctx, broadcast); err != nil {
+		t.Fatalf("UpdateBroadcast: %v", err)
+	}
+
+	got, err = repo.GetBroadcast(ctx, broadcast.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetBroadcast after UpdateBroadcast: %v", err)
+	}
+
+	assertBroadcast(t, "GetBroadcast after UpdateBroadcast", broadcast, got)
+
+	list, err := repo.ListBroadcasts(ctx)
+	if err != nil {
+		t.Fatalf("ListBroadcasts: %v", err)
+	}
+
+	if len(list) != 2 || list[0].ID != broadcast.ID || list[1].ID != older.ID {
+		t.Fatalf("ListBroadcasts: want newest first, got %+v", list)
+	}
+
+	list, err = repo.ListBroadcasts(ctx, entities.BroadcastPending, entities.BroadcastRunning)
+	if err != nil {
+		t.Fatalf("ListBroadcasts by status: %v", err)
+	}
+
+	if len(list) != 1 || list[0].ID != broadcast.ID {
+		t.Fatalf("ListBroadcasts by status: want the running one, got %+v", list)
+	}
+
+	if err = repo.UpdateBroadcast(ctx, older); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateBroadcast of a finished broadcast: want ErrRecordNotFound, got %v", err)
+	}
+
+	missing := broadcast
+	missing.ID = primitive.NewObjectID()
+	if err = repo.UpdateBroadcast(ctx, missing); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateBroadcast of a missing broadcast: want ErrRecordNotFound, got %v", err)
+	}
+}
+
+func testOutbox(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	types := []string{entities.EventClientCreated, entities.EventDeliverySent, entities.EventClientDeleted}
+	for i, eventType := range types {
+		event := entities.OutboxEvent{Type: eventType, Key: "client", Payload: []byte(`{"n":` + strconv.Itoa(i) + `}`)}
+		event.CreatedAt = now
+
+		err := repo.InTransaction(ctx, func(ctx context.Context) error {
+			return repo.AddOutboxEvent(ctx, event)
+		})
+		if err != nil {
+			t.Fatalf("AddOutboxEvent in transaction: %v", err)
+		}
+	}
+
+	events, err := repo.ListOutboxEvents(ctx, 2)
+	if err != nil {
+		t.Fatalf("ListOutboxEvents: %v", err)
+	}
+
+	if len(events) != 2 || events[0].Type != types[0] || events[1].Type != types[1] ||
+		events[0].Seq >= events[1].Seq || string(events[1].Payload) != `{"n":1}` || !events[0].CreatedAt.Equal(now) {
+		t.Fatalf("ListOutboxEvents: want the first two events in order, got %+v", events)
+	}
+
+	if err = repo.MarkOutboxPublished(ctx, events, now); err != nil {
+		t.Fatalf("MarkOutboxPublished: %v", err)
+	}
+
+	events, err = repo.ListOutboxEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListOutboxEvents after MarkOutboxPublished: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Type != types[2] {
+		t.Fatalf("ListOutboxEvents after MarkOutboxPublished: want the last event, got %+v", events)
+	}
+
+	deleted, err := repo.DeleteOutboxEvents(ctx, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("DeleteOutboxEvents: %v", err)
+	}
+
+	if deleted != 2 {
+		t.Fatalf("DeleteOutboxEvents: want 2 published events deleted, got %d", deleted)
+	}
+
+	events, err = repo.ListOutboxEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListOutboxEvents after DeleteOutboxEvents: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Type != types[2] {
+		t.Fatalf("ListOutboxEvents after DeleteOutboxEvents: want the unpublished event kept, got %+v", events)
+	}
+}
+
+func testLeases(t *testing.T, repo Repository) {
+	ctx := context.Background()
+
+	acquire := func(owner string, ttl time.Duration, want bool) {
+		t.Helper()
+
+		held, err := repo.AcquireLease(ctx, "relay", owner, ttl)
+		if err != nil {
+			t.Fatalf("AcquireLease by %s: %v", owner, err)
+		}
+
+		if held != want {
+			t.Fatalf("AcquireLease by %s: want %v, got %v", owner, want, held)
+		}
+	}
+
+	acquire("a", time.Minute, true)
+	acquire("b", time.Minute, false)
+	acquire("a", -time.Second, true)
+	acquire("b", time.Minute, true)
+	acquire("a", time.Minute, false)
+}
+
+func testParked(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	msg := entities.NotificationMessage{
+		NotificationMethods: []string{"telegram"},
+		Timestamp:           now,
+		RequestID:           uuid.New(),
+		ClientID:            primitive.NewObjectID().Hex(),
+		MessageType:         "text",
+		Location:            &entities.Location{Latitude: 55.75, Longitude: 37.62},
+	}
+
+	due := entities.ParkedNotification{
+		ID: primitive.NewObjectID(), Message: msg, Attempts: 1, LastError: "Bad Gateway",
+		NextAttempt: now.Add(-time.Second), CreatedAt: now.Add(-time.Minute),
+	}
+	later := entities.ParkedNotification{
+		ID: primitive.NewObjectID(), Message: msg, Attempts: 1, NextAttempt: now.Add(time.Hour), CreatedAt: now,
+	}
+
+	for _, parked := range []entities.ParkedNotification{due, later} {
+		if err := repo.ParkNotification(ctx, parked); err != nil {
+			t.Fatalf("ParkNotification: %v", err)
+		}
+	}
+
+	if err := repo.ParkNotification(ctx, due); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("ParkNotification duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	count, err := repo.CountParkedNotifications(ctx)
+	if err != nil || count != 2 {
+		t.Fatalf("CountParkedNotifications: want 2, got %d, %v", count, err)
+	}
+
+	list, err := repo.ListDueNotifications(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("ListDueNotifications: %v", err)
+	}
+
+	if len(list) != 1 || list[0].ID != due.ID || list[0].LastError != due.LastError ||
+		list[0].Message.RequestID != msg.RequestID || list[0].Message.ClientID != msg.ClientID ||
+		list[0].Message.Location == nil || *list[0].Message.Location != *msg.Location ||
+		!list[0].Message.Timestamp.Equal(msg.Timestamp) || !list[0].NextAttempt.Equal(due.NextAttempt) {
+		t.Fatalf("ListDueNotifications: want the due notification, got %+v", list)
+	}
+
+	due.Attempts, due.LastError, due.NextAttempt = 2, "circuit breaker is open", now.Add(time.Minute)
+	if err = repo.UpdateParkedNotification(ctx, due); err != nil {
+		t.Fatalf("UpdateParkedNotification: %v", err)
+	}
+
+	list, err = repo.ListDueNotifications(ctx, now.Add(2*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("ListDueNotifications after UpdateParkedNotification: %v", err)
+	}
+
+	if len(list) != 2 || list[0].ID != due.ID || list[0].Attempts != 2 || list[1].ID != later.ID {
+		t.Fatalf("ListDueNotifications after UpdateParkedNotification: want both oldest first, got %+v", list)
+	}
+
+	if err = repo.DeleteParkedNotification(ctx, due.ID.Hex()); err != nil {
+		t.Fatalf("DeleteParkedNotification: %v", err)
+	}
+
+	if err = repo.UpdateParkedNotification(ctx, due); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateParkedNotification of a deleted notification: want ErrRecordNotFound, got %v", err)
+	}
+
+	if count, err = repo.CountParkedNotifications(ctx); err != nil || count != 1 {
+		t.Fatalf("CountParkedNotifications after DeleteParkedNotification: want 1, got %d, %v", count, err)
+	}
+}
+
+func testChatMigrations(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	first := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -100}
+	second := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -100}
+	otherBot := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -100, BotID: primitive.NewObjectID().Hex()}
+	otherChat := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -200}
+
+	for _, account := range []entities.TGAccount{first, second, otherBot, otherChat} {
+		if err := repo.Create(ctx, account); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	clientIDs, err := repo.MigrateChat(ctx, "", -100, -1000100)
+	if err != nil {
+		t.Fatalf("MigrateChat: %v", err)
+	}
+
+	want := []string{first.ClientID.Hex(), second.ClientID.Hex()}
+	if want[0] > want[1] {
+		want[0], want[1] = want[1], want[0]
+	}
+
+	if len(clientIDs) != 2 || clientIDs[0] != want[0] || clientIDs[1] != want[1] {
+		t.Fatalf("MigrateChat: want %v, got %v", want, clientIDs)
+	}
+
+	for _, check := range []struct {
+		account entities.TGAccount
+		chatID  int64
+	}{{first, -1000100}, {second, -1000100}, {otherBot, -100}, {otherChat, -200}} {
+		got, err := repo.GetAccountByClientID(ctx, check.account.ClientID.Hex())
+		if err != nil || got.ChatID != check.chatID {
+			t.Fatalf("GetAccountByClientID after MigrateChat: want chat %d, got %d, %v", check.chatID, got.ChatID, err)
+		}
+	}
+
+	if clientIDs, err = repo.MigrateChat(ctx, "", -100, -1000100); err != nil || len(clientIDs) != 0 {
+		t.Fatalf("MigrateChat repeated: want no accounts, got %v, %v", clientIDs, err)
+	}
+
+	older := entities.ChatMigration{
+		ID: primitive.NewObjectID(), FromChatID: -50, ToChatID: -100, ClientIDs: []string{want[0]},
+		MigratedAt: now.Add(-time.Hour),
+	}
+	newer := entities.ChatMigration{
+		ID: primitive.NewObjectID(), FromChatID: -100, ToChatID: -1000100, ClientIDs: want, MigratedAt: now,
+	}
+
+	for _, migration := range []entities.ChatMigration{older, newer} {
+		if err = repo.AddChatMigration(ctx, migration); err != nil {
+			t.Fatalf("AddChatMigration: %v", err)
+		}
+	}
+
+	list, err := repo.ListChatMigrations(ctx, want[0])
+	if err != nil {
+		t.Fatalf("ListChatMigrations: %v", err)
+	}
+
+	if len(list) != 2 || list[0].ID != newer.ID || list[1].ID != older.ID || list[0].FromChatID != -100 ||
+		list[0].ToChatID != -1000100 || len(list[0].ClientIDs) != 2 || !list[0].MigratedAt.Equal(now) {
+		t.Fatalf("ListChatMigrations: want both newest first, got %+v", list)
+	}
+
+	if list, err = repo.ListChatMigrations(ctx, want[1]); err != nil || len(list) != 1 || list[0].ID != newer.ID {
+		t.Fatalf("ListChatMigrations of the second client: want the newer one, got %+v, %v", list, err)
+	}
+
+	if list, err = repo.ListChatMigrations(ctx, otherChat.ClientID.Hex()); err != nil || len(list) != 0 {
+		t.Fatalf("ListChatMigrations of an unmoved client: want none, got %+v, %v", list, err)
+	}
+}
+
+func testChatDeactivation(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	botID := primitive.NewObjectID().Hex()
+
+	first := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -100, BotID: botID}
+	second := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -100, BotID: botID}
+	defaultBot := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: -100}
+
+	for _, account := range []entities.TGAccount{first, second, defaultBot} {
+		if err := repo.Create(ctx, account); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	deactivation := &entities.Deactivation{Reason: "bot was kicked from the group chat", At: now}
+
+	clientIDs, err := repo.SetChatDeactivation(ctx, botID, -100, deactivation)
+	if err != nil || len(clientIDs) != 2 {
+		t.Fatalf("SetChatDeactivation: want both accounts of the bot, got %v, %v", clientIDs, err)
+	}
+
+	got, err := repo.GetAccountByClientID(ctx, first.ClientID.Hex())
+	if err != nil || got.Active() || got.Deactivation.Reason != deactivation.Reason || !got.Deactivation.At.Equal(now) {
+		t.Fatalf("GetAccountByClientID after SetChatDeactivation: want deactivated, got %+v, %v", got.Deactivation, err)
+	}
+
+	if got, err = repo.GetAccountByClientID(ctx, defaultBot.ClientID.Hex()); err != nil || !got.Active() {
+		t.Fatalf("GetAccountByClientID of another bot: want active, got %+v, %v", got.Deactivation, err)
+	}
+
+	if clientIDs, err = repo.SetChatDeactivation(ctx, botID, -100, deactivation); err != nil || len(clientIDs) != 0 {
+		t.Fatalf("SetChatDeactivation repeated: want no accounts, got %v, %v", clientIDs, err)
+	}
+
+	clientIDs, err = repo.SetChatDeactivation(ctx, "", -100, deactivation)
+	if err != nil || len(clientIDs) != 1 || clientIDs[0] != defaultBot.ClientID.Hex() {
+		t.Fatalf("SetChatDeactivation of the default bot: want %s, got %v, %v", defaultBot.ClientID.Hex(), clientIDs, err)
+	}
+
+	if clientIDs, err = repo.SetChatDeactivation(ctx, botID, -100, nil); err != nil || len(clientIDs) != 2 {
+		t.Fatalf("SetChatDeactivation reactivating: want both accounts of the bot, got %v, %v", clientIDs, err)
+	}
+
+	if got, err = repo.GetAccountByClientID(ctx, second.ClientID.Hex()); err != nil || !got.Active() {
+		t.Fatalf("GetAccountByClientID after reactivation: want active, got %+v, %v", got.Deactivation, err)
+	}
+}
+
+func testUpdateOffsets(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	botID := primitive.NewObjectID().Hex()
+
+	for _, id := range []string{"", botID} {
+		if offset, err := repo.GetUpdateOffset(ctx, id); err != nil || offset != 0 {
+			t.Fatalf("GetUpdateOffset of %q before SetUpdateOffset: want 0, got %d, %v", id, offset, err)
+		}
+	}
+
+	for _, offset := range []int{100, 1 << 40} {
+		if err := repo.SetUpdateOffset(ctx, botID, offset); err != nil {
+			t.Fatalf("SetUpdateOffset: %v", err)
+		}
+
+		if got, err := repo.GetUpdateOffset(ctx, botID); err != nil || got != offset {
+			t.Fatalf("GetUpdateOffset: want %d, got %d, %v", offset, got, err)
+		}
+	}
+
+	if offset, err := repo.GetUpdateOffset(ctx, ""); err != nil || offset != 0 {
+		t.Fatalf("GetUpdateOffset of the default bot: want 0, got %d, %v", offset, err)
+	}
+}
+
+// assertBroadcast compares the fields a backend must keep, times to the millisecond.
+func assertBroadcast(t *testing.T, op string, want, got entities.Broadcast) {
+	t.Helper()
+
+	if got.ID != want.ID || got.Text != want.Text || got.Status != want.Status || got.Total != want.Total ||
+		got.Sent != want.Sent || got.Failed != want.Failed || got.Cursor != want.Cursor || got.Error != want.Error ||
+		got.Target.TenantID != want.Target.TenantID || got.Target.Region != want.Target.Region ||
+		len(got.Target.Tags) != len(want.Target.Tags) || len(got.Failures) != len(want.Failures) ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) ||
+		!got.FinishedAt.Equal(want.FinishedAt) {
+		t.Fatalf("%s: want %+v, got %+v", op, want, got)
+	}
+
+	for i := range want.Failures {
+		w, g := want.Failures[i], got.Failures[i]
+		if g.ClientID != w.ClientID || g.ChatID != w.ChatID || g.Error != w.Error || !g.At.Equal(w.At) {
+			t.Fatalf("%s.Failures[%d]: want %+v, got %+v", op, i, w, g)
+		}
+	}
+}
+
+func testDeliveryStats(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	at := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	acknowledgedAt := at.Add(10 * time.Second)
+	clientA, clientB := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	r1, r2, r3, r4 := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+
+	deliveries := []entities.Delivery{
+		{
+			RequestID: r1, ClientID: clientA, ChatID: 1, MessageID: 1, Status: entities.DeliverySent, SentAt: at,
+			SensorID: "s1", MessageType: "audio", DetectedAt: at.Add(-2 * time.Second), AcknowledgedAt: &acknowledgedAt,
+		},
+		{
+			RequestID: r1, ClientID: clientB, ChatID: 2, MessageID: 1, Status: entities.DeliverySent, SentAt: at,
+			SensorID: "s1", MessageType: "audio", DetectedAt: at.Add(-4 * time.Second),
+		},
+		{
+			RequestID: r2, ClientID: clientA, ChatID: 1, MessageID: 2, Status: entities.DeliveryUpdated,
+			SentAt: at.Add(time.Hour), SensorID: "s2", MessageType: "text", DetectedAt: at.Add(time.Hour - time.Second),
+		},
+		{RequestID: r3, ClientID: clientA, ChatID: 1, MessageID: 3, Status: entities.DeliverySent, SentAt: at.Add(2 * time.Hour)},
+		{
+			RequestID: r4, ClientID: clientB, ChatID: 2, MessageID: 2, Status: entities.DeliveryRetracted,
+			SentAt: at.Add(-time.Hour), SensorID: "s2", MessageType: "text", DetectedAt: at.Add(-time.Hour - 3*time.Second),
+		},
+	}
+
+	for _, delivery := range deliveries {
+		if err := repo.SaveDelivery(ctx, delivery); err != nil {
+			t.Fatalf("SaveDelivery: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter entities.DeliveryStatsFilter
+		want   entities.DeliveryStats
+	}{
+		{
+			name:   "of a client in a period",
+			filter: entities.DeliveryStatsFilter{ClientIDs: []string{clientA}, Since: at, Until: at.Add(3 * time.Hour), TopSensors: 5},
+			want: entities.DeliveryStats{
+				Since: at, Until: at.Add(3 * time.Hour), Deliveries: 3, Alerts: 3,
+				ByStatus:     map[entities.DeliveryStatus]int64{entities.DeliverySent: 2, entities.DeliveryUpdated: 1},
+				ByType:       map[string]int64{"audio": 1, "text": 1, "": 1},
+				Latency:      entities.Percentiles{P50: time.Second, P90: 2 * time.Second, P99: 2 * time.Second, Max: 2 * time.Second},
+				Acknowledged: 1,
+				AckTime:      entities.Percentiles{P50: 10 * time.Second, P90: 10 * time.Second, P99: 10 * time.Second, Max: 10 * time.Second},
+				TopSensors:   []entities.SensorCount{{SensorID: "s1", Alerts: 1}, {SensorID: "s2", Alerts: 1}},
+			},
+		},
+		{
+			name:   "of all clients, an alert in several chats is one",
+			filter: entities.DeliveryStatsFilter{TopSensors: 1},
+			want: entities.DeliveryStats{
+				Deliveries: 5, Alerts: 4,
+				ByStatus: map[entities.DeliveryStatus]int64{
+					entities.DeliverySent: 3, entities.DeliveryUpdated: 1, entities.DeliveryRetracted: 1,
+				},
+				ByType:       map[string]int64{"audio": 2, "text": 2, "": 1},
+				Latency:      entities.Percentiles{P50: 2 * time.Second, P90: 4 * time.Second, P99: 4 * time.Second, Max: 4 * time.Second},
+				Acknowledged: 1,
+				AckTime:      entities.Percentiles{P50: 10 * time.Second, P90: 10 * time.Second, P99: 10 * time.Second, Max: 10 * time.Second},
+				TopSensors:   []entities.SensorCount{{SensorID: "s2", Alerts: 2}},
+			},
+		},
+		{
+			name:   "of a client without deliveries",
+			filter: entities.DeliveryStatsFilter{ClientIDs: []string{primitive.NewObjectID().Hex()}, TopSensors: 5},
+			want: entities.DeliveryStats{
+				ByStatus: map[entities.DeliveryStatus]int64{}, ByType: map[string]int64{}, TopSensors: []entities.SensorCount{},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := repo.DeliveryStats(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("DeliveryStats %s: %v", tc.name, err)
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("DeliveryStats %s: want %+v, got %+v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func assertDeliveries(t *testing.T, op string, want, got []entities.Delivery) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: want %+v, got %+v", op, want, got)
+	}
+
+	for i := range want {
+		if !got[i].SentAt.Equal(want[i].SentAt) {
+			t.Fatalf("%s[%d].SentAt: want %s, got %s", op, i, want[i].SentAt, got[i].SentAt)
+		}
+
+		if (got[i].AcknowledgedAt == nil) != (want[i].AcknowledgedAt == nil) ||
+			got[i].AcknowledgedAt != nil && !got[i].AcknowledgedAt.Equal(*want[i].AcknowledgedAt) {
+			t.Fatalf("%s[%d].AcknowledgedAt: want %v, got %v", op, i, want[i].AcknowledgedAt, got[i].AcknowledgedAt)
+		}
+
+		if !got[i].DetectedAt.Equal(want[i].DetectedAt) {
+			t.Fatalf("%s[%d].DetectedAt: want %s, got %s", op, i, want[i].DetectedAt, got[i].DetectedAt)
+		}
+
+		got[i].SentAt = want[i].SentAt
+		got[i].AcknowledgedAt = want[i].AcknowledgedAt
+		got[i].DetectedAt = want[i].DetectedAt
+		if got[i] != want[i] {
+			t.Fatalf("%s[%d]: want %+v, got %+v", op, i, want[i], got[i])
+		}
+	}
+}
+
+// assertSensor compares sensors treating nil and empty slices as equal, backends differ in that.
+func assertSensor(t *testing.T, op string, want, got entities.Sensor) {
+	t.Helper()
+
+	equalStrings := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	equalLocations := (want.Location == nil && got.Location == nil) ||
+		(want.Location != nil && got.Location != nil && *want.Location == *got.Location)
+
+	if want.ID != got.ID || want.Name != got.Name || want.Address != got.Address || want.Enabled != got.Enabled ||
+		!equalLocations || !equalStrings(want.ClientIDs, got.ClientIDs) || !equalStrings(want.Tags, got.Tags) {
+		t.Fatalf("%s: want %+v, got %+v", op, want, got)
+	}
+}
+
+func testOrganizations(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	createdAt := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	acme := entities.Organization{
+		ID: primitive.NewObjectID(), TenantID: "acme", Name: "Acme", ChatID: -100500, ThreadID: 3,
+		BotID: primitive.NewObjectID().Hex(), Region: "north", Tags: []string{"retail"}, CreatedAt: createdAt,
+	}
+	globex := entities.Organization{ID: primitive.NewObjectID(), TenantID: "globex", Name: "Globex", CreatedAt: createdAt}
+
+	for _, org := range []entities.Organization{acme, globex} {
+		if err := repo.CreateOrganization(ctx, org); err != nil {
+			t.Fatalf("CreateOrganization: %v", err)
+		}
+	}
+
+	if err := repo.CreateOrganization(ctx, acme); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateOrganization duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetOrganization(ctx, acme.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetOrganization: %v", err)
+	}
+
+	if !sameOrganization(got, acme) {
+		t.Fatalf("GetOrganization: want %+v, got %+v", acme, got)
+	}
+
+	all, err := repo.ListOrganizations(ctx, "")
+	if err != nil {
+		t.Fatalf("ListOrganizations: %v", err)
+	}
+
+	if len(all) != 2 || !sameOrganization(all[0], acme) || !sameOrganization(all[1], globex) {
+		t.Fatalf("ListOrganizations without tenant: want [%+v %+v], got %+v", acme, globex, all)
+	}
+
+	tenant, err := repo.ListOrganizations(ctx, "globex")
+	if err != nil {
+		t.Fatalf("ListOrganizations: %v", err)
+	}
+
+	if len(tenant) != 1 || !sameOrganization(tenant[0], globex) {
+		t.Fatalf("ListOrganizations for tenant: want [%+v], got %+v", globex, tenant)
+	}
+
+	acme.ChatID, acme.ThreadID, acme.Tags = -100600, 0, nil
+	if err = repo.UpdateOrganization(ctx, acme); err != nil {
+		t.Fatalf("UpdateOrganization: %v", err)
+	}
+
+	if got, err = repo.GetOrganization(ctx, acme.ID.Hex()); err != nil || !sameOrganization(got, acme) {
+		t.Fatalf("GetOrganization after update: want %+v, got %+v, %v", acme, got, err)
+	}
+
+	missing := entities.Organization{ID: primitive.NewObjectID(), TenantID: "acme", Name: "Missing"}
+	if err = repo.UpdateOrganization(ctx, missing); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateOrganization of a missing organization: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.DeleteOrganization(ctx, globex.ID.Hex()); err != nil {
+		t.Fatalf("DeleteOrganization: %v", err)
+	}
+
+	if err = repo.DeleteOrganization(ctx, globex.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteOrganization of a missing organization: want ErrRecordNotFound, got %v", err)
+	}
+
+	if _, err = repo.GetOrganization(ctx, globex.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetOrganization after DeleteOrganization: want ErrRecordNotFound, got %v", err)
+	}
+}
+
+// sameOrganization compares the organizations field by field, no tags and empty tags are the same.
+func sameOrganization(a, b entities.Organization) bool {
+	if a.ID != b.ID || a.TenantID != b.TenantID || a.Name != b.Name || a.ChatID != b.ChatID || a.ThreadID != b.ThreadID ||
+		a.BotID != b.BotID || a.Region != b.Region || !a.CreatedAt.Equal(b.CreatedAt) || len(a.Tags) != len(b.Tags) {
+		return false
+	}
+
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// square is a closed ring around the point, size degrees wide.
+func square(lon, lat, size float64) [][]float64 {
+	half := size / 2
+
+	return [][]float64{
+		{lon - half, lat - half}, {lon + half, lat - half}, {lon + half, lat + half}, {lon - half, lat + half},
+		{lon - half, lat - half},
+	}
+}
+
+func testZones(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	createdAt := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// The district is a square with a hole in the middle, the park is a second polygon next to it.
+	district := entities.Zone{
+		ID: primitive.NewObjectID(), TenantID: "police", Name: "District", CreatedAt: createdAt,
+		Geometry: entities.Geometry{Type: entities.GeometryMultiPolygon, Coordinates: [][][][]float64{
+			{square(37.6, 55.75, 0.2), square(37.6, 55.75, 0.02)},
+			{square(37.9, 55.75, 0.1)},
+		}},
+	}
+	city := entities.Zone{
+		ID: primitive.NewObjectID(), TenantID: "city", Name: "City", CreatedAt: createdAt,
+		Geometry: entities.Geometry{Type: entities.GeometryMultiPolygon, Coordinates: [][][][]float64{
+			{square(37.7, 55.75, 1)},
+		}},
+	}
+
+	for _, zone := range []entities.Zone{district, city} {
+		if err := repo.CreateZone(ctx, zone); err != nil {
+			t.Fatalf("CreateZone: %v", err)
+		}
+	}
+
+	if err := repo.CreateZone(ctx, district); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateZone duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetZone(ctx, district.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetZone: %v", err)
+	}
+
+	if !sameZone(got, district) {
+		t.Fatalf("GetZone: want %+v, got %+v", district, got)
+	}
+
+	tenant, err := repo.ListZones(ctx, "city")
+	if err != nil {
+		t.Fatalf("ListZones: %v", err)
+	}
+
+	if len(tenant) != 1 || !sameZone(tenant[0], city) {
+		t.Fatalf("ListZones for tenant: want [%+v], got %+v", city, tenant)
+	}
+
+	for _, tc := range []struct {
+		name string
+		loc  entities.Location
+		want []entities.Zone
+	}{
+		{"inside both", entities.Location{Latitude: 55.70, Longitude: 37.55}, []entities.Zone{district, city}},
+		{"in the hole", entities.Location{Latitude: 55.75, Longitude: 37.60}, []entities.Zone{city}},
+		{"in the second polygon", entities.Location{Latitude: 55.76, Longitude: 37.91}, []entities.Zone{district, city}},
+		{"inside the city only", entities.Location{Latitude: 56.1, Longitude: 37.4}, []entities.Zone{city}},
+		{"outside", entities.Location{Latitude: 59.93, Longitude: 30.31}, nil},
+	} {
+		zones, err := repo.ZonesContaining(ctx, tc.loc)
+		if err != nil {
+			t.Fatalf("ZonesContaining %s: %v", tc.name, err)
+		}
+
+		if len(zones) != len(tc.want) {
+			t.Fatalf("ZonesContaining %s: want %d zones, got %+v", tc.name, len(tc.want), zones)
+		}
+
+		for i := range zones {
+			if zones[i].ID != tc.want[i].ID {
+				t.Fatalf("ZonesContaining %s: want %s at %d, got %s", tc.name, tc.want[i].ID.Hex(), i, zones[i].ID.Hex())
+			}
+		}
+	}
+
+	// Moving the city away drops it from the spatial lookups.
+	city.Geometry.Coordinates = [][][][]float64{{square(30.3, 59.9, 1)}}
+	if err = repo.UpdateZone(ctx, city); err != nil {
+		t.Fatalf("UpdateZone: %v", err)
+	}
+
+	zones, err := repo.ZonesContaining(ctx, entities.Location{Latitude: 56.1, Longitude: 37.4})
+	if err != nil || len(zones) != 0 {
+		t.Fatalf("ZonesContaining after UpdateZone: want none, got %+v, %v", zones, err)
+	}
+
+	missing := entities.Zone{ID: primitive.NewObjectID(), TenantID: "city", Name: "Missing", Geometry: city.Geometry}
+	if err = repo.UpdateZone(ctx, missing); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateZone of a missing zone: want ErrRecordNotFound, got %v", err)
+	}
+
+	precinct := entities.ZoneSubscription{
+		ID: primitive.NewObjectID(), ZoneID: district.ID.Hex(), ChatID: -100700, ThreadID: 4,
+		BotID: primitive.NewObjectID().Hex(), Rules: []string{"time between 22:00 and 06:00"}, CreatedAt: createdAt,
+	}
+	patrol := entities.ZoneSubscription{ID: primitive.NewObjectID(), ZoneID: district.ID.Hex(), ChatID: -100800, CreatedAt: createdAt}
+
+	for _, sub := range []entities.ZoneSubscription{precinct, patrol} {
+		if err = repo.CreateZoneSubscription(ctx, sub); err != nil {
+			t.Fatalf("CreateZoneSubscription: %v", err)
+		}
+	}
+
+	again := precinct
+	again.ID = primitive.NewObjectID()
+	if err = repo.CreateZoneSubscription(ctx, again); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateZoneSubscription of a subscribed chat: want ErrRecordExists, got %v", err)
+	}
+
+	sub, err := repo.GetZoneSubscription(ctx, precinct.ID.Hex())
+	if err != nil || !sameZoneSubscription(sub, precinct) {
+		t.Fatalf("GetZoneSubscription: want %+v, got %+v, %v", precinct, sub, err)
+	}
+
+	patrol.ChatID = -100900
+	patrol.Rules = []string{"tags not in (indoor)", "time between 22:00 and 06:00 Europe/Moscow"}
+	patrol.Filters, _ = entities.ParseFilterRules(patrol.Rules)
+	if err = repo.UpdateZoneSubscription(ctx, patrol); err != nil {
+		t.Fatalf("UpdateZoneSubscription: %v", err)
+	}
+
+	subs, err := repo.ListZoneSubscriptions(ctx, district.ID.Hex())
+	if err != nil {
+		t.Fatalf("ListZoneSubscriptions: %v", err)
+	}
+
+	if len(subs) != 2 || !sameZoneSubscription(subs[0], precinct) || !sameZoneSubscription(subs[1], patrol) {
+		t.Fatalf("ListZoneSubscriptions: want [%+v %+v], got %+v", precinct, patrol, subs)
+	}
+
+	if err = repo.DeleteZoneSubscription(ctx, patrol.ID.Hex()); err != nil {
+		t.Fatalf("DeleteZoneSubscription: %v", err)
+	}
+
+	if err = repo.DeleteZoneSubscription(ctx, patrol.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteZoneSubscription of a missing subscription: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.DeleteZone(ctx, district.ID.Hex()); err != nil {
+		t.Fatalf("DeleteZone: %v", err)
+	}
+
+	if _, err = repo.GetZoneSubscription(ctx, precinct.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetZoneSubscription after DeleteZone: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.DeleteZone(ctx, district.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteZone of a missing zone: want ErrRecordNotFound, got %v", err)
+	}
+
+	zones, err = repo.ZonesContaining(ctx, entities.Location{Latitude: 55.70, Longitude: 37.55})
+	if err != nil || len(zones) != 0 {
+		t.Fatalf("ZonesContaining after DeleteZone: want none, got %+v, %v", zones, err)
+	}
+}
+
+func sameZone(a, b entities.Zone) bool {
+	if a.ID != b.ID || a.TenantID != b.TenantID || a.Name != b.Name || !a.CreatedAt.Equal(b.CreatedAt) ||
+		a.Geometry.Type != b.Geometry.Type || len(a.Geometry.Coordinates) != len(b.Geometry.Coordinates) {
+		return false
+	}
+
+	for i, polygon := range a.Geometry.Coordinates {
+		if len(polygon) != len(b.Geometry.Coordinates[i]) {
+			return false
+		}
+
+		for j, ring := range polygon {
+			if len(ring) != len(b.Geometry.Coordinates[i][j]) {
+				return false
+			}
+
+			for k, position := range ring {
+				other := b.Geometry.Coordinates[i][j][k]
+				if position[0] != other[0] || position[1] != other[1] {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+func sameZoneSubscription(a, b entities.ZoneSubscription) bool {
+	return a.ID == b.ID && a.ZoneID == b.ZoneID && a.ChatID == b.ChatID && a.ThreadID == b.ThreadID &&
+		a.BotID == b.BotID && a.CreatedAt.Equal(b.CreatedAt) && strings.Join(a.Rules, ";") == strings.Join(b.Rules, ";") &&
+		reflect.DeepEqual(a.Filters, b.Filters)
+}
+
+func testDigestSchedules(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+	clientID := primitive.NewObjectID().Hex()
+
+	daily := entities.DigestSchedule{
+		ID: primitive.NewObjectID(), ClientID: clientID, Cron: "0 8 * * *", Timezone: "Europe/Moscow",
+		Period: entities.DigestDaily, NextRunAt: now.Add(-time.Hour), CreatedAt: now.Add(-48 * time.Hour),
+	}
+	weekly := entities.DigestSchedule{
+		ID: primitive.NewObjectID(), ClientID: clientID, Cron: "0 9 * * 1", Period: entities.DigestWeekly,
+		NextRunAt: now.Add(-2 * time.Hour), CreatedAt: now.Add(-48 * time.Hour),
+	}
+	other := entities.DigestSchedule{
+		ID: primitive.NewObjectID(), ClientID: primitive.NewObjectID().Hex(), Cron: "@daily",
+		Period: entities.DigestDaily, NextRunAt: now.Add(time.Hour), CreatedAt: now,
+	}
+
+	for _, schedule := range []entities.DigestSchedule{daily, weekly, other} {
+		if err := repo.CreateDigestSchedule(ctx, schedule); err != nil {
+			t.Fatalf("CreateDigestSchedule: %v", err)
+		}
+	}
+
+	if err := repo.CreateDigestSchedule(ctx, daily); !errors.Is(err, entities.ErrRecordExists) {
+		t.Fatalf("CreateDigestSchedule duplicate: want ErrRecordExists, got %v", err)
+	}
+
+	got, err := repo.GetDigestSchedule(ctx, daily.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetDigestSchedule: %v", err)
+	}
+
+	assertDigestSchedules(t, "GetDigestSchedule", []entities.DigestSchedule{daily}, []entities.DigestSchedule{got})
+
+	if _, err = repo.GetDigestSchedule(ctx, primitive.NewObjectID().Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("GetDigestSchedule of a missing schedule: want ErrRecordNotFound, got %v", err)
+	}
+
+	list, err := repo.ListDigestSchedules(ctx, clientID)
+	if err != nil {
+		t.Fatalf("ListDigestSchedules: %v", err)
+	}
+
+	assertDigestSchedules(t, "ListDigestSchedules", []entities.DigestSchedule{daily, weekly}, list)
+
+	due, err := repo.ListDueDigestSchedules(ctx, now, 0)
+	if err != nil {
+		t.Fatalf("ListDueDigestSchedules: %v", err)
+	}
+
+	assertDigestSchedules(t, "ListDueDigestSchedules, the longest overdue first", []entities.DigestSchedule{weekly, daily}, due)
+
+	if due, err = repo.ListDueDigestSchedules(ctx, now, 1); err != nil || len(due) != 1 {
+		t.Fatalf("ListDueDigestSchedules with limit: want 1 schedule, got %+v, %v", due, err)
+	}
+
+	weekly.LastRunAt = now
+	weekly.NextRunAt = now.Add(7 * 24 * time.Hour)
+	if err = repo.UpdateDigestSchedule(ctx, weekly); err != nil {
+		t.Fatalf("UpdateDigestSchedule: %v", err)
+	}
+
+	if got, err = repo.GetDigestSchedule(ctx, weekly.ID.Hex()); err != nil {
+		t.Fatalf("GetDigestSchedule after update: %v", err)
+	}
+
+	assertDigestSchedules(t, "GetDigestSchedule after update", []entities.DigestSchedule{weekly}, []entities.DigestSchedule{got})
+
+	if due, err = repo.ListDueDigestSchedules(ctx, now, 0); err != nil {
+		t.Fatalf("ListDueDigestSchedules after update: %v", err)
+	}
+
+	assertDigestSchedules(t, "ListDueDigestSchedules after update", []entities.DigestSchedule{daily}, due)
+
+	missing := other
+	missing.ID = primitive.NewObjectID()
+	if err = repo.UpdateDigestSchedule(ctx, missing); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("UpdateDigestSchedule of a missing schedule: want ErrRecordNotFound, got %v", err)
+	}
+
+	if err = repo.DeleteDigestSchedule(ctx, daily.ID.Hex()); err != nil {
+		t.Fatalf("DeleteDigestSchedule: %v", err)
+	}
+
+	if err = repo.DeleteDigestSchedule(ctx, daily.ID.Hex()); !errors.Is(err, entities.ErrRecordNotFound) {
+		t.Fatalf("DeleteDigestSchedule twice: want ErrRecordNotFound, got %v", err)
+	}
+
+	if list, err = repo.ListDigestSchedules(ctx, clientID); err != nil {
+		t.Fatalf("ListDigestSchedules after delete: %v", err)
+	}
+
+	assertDigestSchedules(t, "ListDigestSchedules after delete", []entities.DigestSchedule{weekly}, list)
+}
+
+// assertDigestSchedules compares the schedules with their times as instants, backends differ in the location.
+func assertDigestSchedules(t *testing.T, op string, want, got []entities.DigestSchedule) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: want %+v, got %+v", op, want, got)
+	}
+
+	for i := range want {
+		if !got[i].NextRunAt.Equal(want[i].NextRunAt) || !got[i].LastRunAt.Equal(want[i].LastRunAt) ||
+			!got[i].CreatedAt.Equal(want[i].CreatedAt) {
+			t.Fatalf("%s[%d]: want %+v, got %+v", op, i, want[i], got[i])
+		}
+
+		got[i].NextRunAt, got[i].LastRunAt, got[i].CreatedAt = want[i].NextRunAt, want[i].LastRunAt, want[i].CreatedAt
+		if got[i] != want[i] {
+			t.Fatalf("%s[%d]: want %+v, got %+v", op, i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateSensor(ctx context.Context, sensor entities.Sensor) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateSensor")
+	defer span.End()
+
+	if _, err := r.sensors.InsertOne(ctx, sensor); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "sensors.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetSensor(ctx context.Context, sensorID string) (entities.Sensor, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetSensor")
+	defer span.End()
+
+	var sensor entities.Sensor
+	if err := r.sensors.FindOne(ctx, bson.M{"_id": sensorID}).Decode(&sensor); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return sensor, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return sensor, errors.Wrap(err, "sensors.FindOne")
+	}
+
+	return sensor, nil
+}
+
+func (r Repository) ListSensors(ctx context.Context, clientID string) ([]entities.Sensor, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListSensors")
+	defer span.End()
+
+	filter := bson.M{}
+	if clientID != "" {
+		filter["clientIDs"] = clientID
+	}
+
+	cursor, err := r.sensors.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "sensors.Find")
+	}
+
+	sensors := make([]entities.Sensor, 0)
+	if err = cursor.All(ctx, &sensors); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return sensors, nil
+}
+
+func (r Repository) UpdateSensor(ctx context.Context, sensor entities.Sensor) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateSensor")
+	defer span.End()
+
+	res, err := r.sensors.ReplaceOne(ctx, bson.M{"_id": sensor.ID}, sensor)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "sensors.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteSensor(ctx context.Context, sensorID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteSensor")
+	defer span.End()
+
+	res, err := r.sensors.DeleteOne(ctx, bson.M{"_id": sensorID})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "sensors.DeleteOne")
+	}
+
+	if res.DeletedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type (
+	statsCount struct {
+		ID string `bson:"_id"`
+		N  int64  `bson:"n"`
+	}
+
+	// statsPercentiles are in milliseconds, the unit of the date differences.
+	statsPercentiles struct {
+		N   int64 `bson:"n"`
+		P50 int64 `bson:"p50"`
+		P90 int64 `bson:"p90"`
+		P99 int64 `bson:"p99"`
+		Max int64 `bson:"max"`
+	}
+
+	statsFacets struct {
+		Deliveries []statsCount       `bson:"deliveries"`
+		Alerts     []statsCount       `bson:"alerts"`
+		ByStatus   []statsCount       `bson:"byStatus"`
+		ByType     []statsCount       `bson:"byType"`
+		Latency    []statsPercentiles `bson:"latency"`
+		AckTime    []statsPercentiles `bson:"ackTime"`
+		TopSensors []statsCount       `bson:"topSensors"`
+	}
+)
+
+func (p statsPercentiles) percentiles() entities.Percentiles {
+	return entities.Percentiles{
+		P50: time.Duration(p.P50) * time.Millisecond,
+		P90: time.Duration(p.P90) * time.Millisecond,
+		P99: time.Duration(p.P99) * time.Millisecond,
+		Max: time.Duration(p.Max) * time.Millisecond,
+	}
+}
+
+// percentileStages sort the values of the deliveries having the field and pick the nearest rank percentiles
+// out of them, see entities.Percentiles. The values are pushed into a single document, which bounds them
+// to about a million.
+func percentileStages(field string, value bson.M) bson.A {
+	at := func(p float64) bson.M {
+		rank := bson.M{"$subtract": bson.A{bson.M{"$ceil": bson.M{"$multiply": bson.A{p, bson.M{"$size": "$values"}}}}, 1}}
+		return bson.M{"$arrayElemAt": bson.A{"$values", bson.M{"$toInt": bson.M{"$max": bson.A{0, rank}}}}}
+	}
+
+	return bson.A{
+		bson.M{"$match": bson.M{field: bson.M{"$exists": true, "$ne": nil}}},
+		bson.M{"$project": bson.M{"value": value}},
+		bson.M{"$sort": bson.M{"value": 1}},
+		bson.M{"$group": bson.M{"_id": nil, "values": bson.M{"$push": "$value"}}},
+		bson.M{"$project": bson.M{
+			"n":   bson.M{"$size": "$values"},
+			"p50": at(0.5),
+			"p90": at(0.9),
+			"p99": at(0.99),
+			"max": bson.M{"$arrayElemAt": bson.A{"$values", -1}},
+		}},
+	}
+}
+
+// DeliveryStats aggregates the deliveries in a single pipeline, each statistic is a facet of it.
+func (r Repository) DeliveryStats(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.DeliveryStats")
+	defer span.End()
+
+	match := bson.M{}
+	if len(filter.ClientIDs) != 0 {
+		match["clientID"] = bson.M{"$in": filter.ClientIDs}
+	}
+
+	sentAt := bson.M{}
+	if !filter.Since.IsZero() {
+		sentAt["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		sentAt["$lt"] = filter.Until
+	}
+	if len(sentAt) != 0 {
+		match["sentAt"] = sentAt
+	}
+
+	facets := bson.M{
+		"deliveries": bson.A{bson.M{"$count": "n"}},
+		"alerts":     bson.A{bson.M{"$group": bson.M{"_id": "$requestID"}}, bson.M{"$count": "n"}},
+		"byStatus":   bson.A{bson.M{"$group": bson.M{"_id": "$status", "n": bson.M{"$sum": 1}}}},
+		"byType": bson.A{
+			bson.M{"$group": bson.M{"_id": bson.M{"$ifNull": bson.A{"$messageType", ""}}, "n": bson.M{"$sum": 1}}},
+		},
+		"latency": percentileStages("detectedAt", bson.M{"$subtract": bson.A{"$sentAt", "$detectedAt"}}),
+		"ackTime": percentileStages("acknowledgedAt", bson.M{"$subtract": bson.A{"$acknowledgedAt", "$sentAt"}}),
+	}
+
+	if filter.TopSensors > 0 {
+		facets["topSensors"] = bson.A{
+			bson.M{"$match": bson.M{"sensorID": bson.M{"$exists": true, "$ne": ""}}},
+			bson.M{"$group": bson.M{"_id": bson.M{"sensorID": "$sensorID", "requestID": "$requestID"}}},
+			bson.M{"$group": bson.M{"_id": "$_id.sensorID", "n": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "n", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": filter.TopSensors},
+		}
+	}
+
+	pipeline := bson.A{bson.M{"$match": match}, bson.M{"$facet": facets}}
+
+	cursor, err := r.deliveries.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		span.RecordError(err)
+		return entities.DeliveryStats{}, errors.Wrap(err, "deliveries.Aggregate")
+	}
+
+	var results []statsFacets
+	if err = cursor.All(ctx, &results); err != nil {
+		span.RecordError(err)
+		return entities.DeliveryStats{}, errors.Wrap(err, "cursor.All")
+	}
+
+	stats := entities.DeliveryStats{
+		Since:      filter.Since,
+		Until:      filter.Until,
+		ByStatus:   make(map[entities.DeliveryStatus]int64),
+		ByType:     make(map[string]int64),
+		TopSensors: make([]entities.SensorCount, 0),
+	}
+
+	if len(results) == 0 {
+		return stats, nil
+	}
+
+	result := results[0]
+	if len(result.Deliveries) != 0 {
+		stats.Deliveries = result.Deliveries[0].N
+	}
+	if len(result.Alerts) != 0 {
+		stats.Alerts = result.Alerts[0].N
+	}
+	for _, count := range result.ByStatus {
+		stats.ByStatus[entities.DeliveryStatus(count.ID)] = count.N
+	}
+	for _, count := range result.ByType {
+		stats.ByType[count.ID] = count.N
+	}
+	if len(result.Latency) != 0 {
+		stats.Latency = result.Latency[0].percentiles()
+	}
+	if len(result.AckTime) != 0 {
+		stats.Acknowledged = result.AckTime[0].N
+		stats.AckTime = result.AckTime[0].percentiles()
+	}
+	for _, count := range result.TopSensors {
+		stats.TopSensors = append(stats.TopSensors, entities.SensorCount{SensorID: count.ID, Alerts: count.N})
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+func (r Repository) CreateZone(ctx context.Context, zone entities.Zone) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateZone")
+	defer span.End()
+
+	if _, err := r.zones.InsertOne(ctx, zone); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "zones.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetZone(ctx context.Context, zoneID string) (entities.Zone, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetZone")
+	defer span.End()
+
+	var zone entities.Zone
+
+	castedID, err := primitive.ObjectIDFromHex(zoneID)
+	if err != nil {
+		return zone, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = r.zones.FindOne(ctx, bson.M{"_id": castedID}).Decode(&zone); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return zone, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return zone, errors.Wrap(err, "zones.FindOne")
+	}
+
+	return zone, nil
+}
+
+func (r Repository) ListZones(ctx context.Context, tenantID string) ([]entities.Zone, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListZones")
+	defer span.End()
+
+	filter := bson.M{}
+	if tenantID != "" {
+		filter["tenantID"] = tenantID
+	}
+
+	return r.findZones(ctx, filter)
+}
+
+func (r Repository) UpdateZone(ctx context.Context, zone entities.Zone) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateZone")
+	defer span.End()
+
+	res, err := r.zones.ReplaceOne(ctx, bson.M{"_id": zone.ID}, zone)
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "zones.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteZone removes the zone with its subscriptions.
+func (r Repository) DeleteZone(ctx context.Context, zoneID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteZone")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(zoneID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	res, err := r.zones.DeleteOne(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "zones.DeleteOne")
+	}
+
+	if res.DeletedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	if _, err = r.zoneSubs.DeleteMany(ctx, bson.M{"zoneID": zoneID}); err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "zoneSubs.DeleteMany")
+	}
+
+	return nil
+}
+
+// ZonesContaining returns the zones the location is inside of, it is answered by the 2dsphere index.
+func (r Repository) ZonesContaining(ctx context.Context, loc entities.Location) ([]entities.Zone, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ZonesContaining")
+	defer span.End()
+
+	filter := bson.M{"geometry": bson.M{"$geoIntersects": bson.M{"$geometry": bson.M{
+		"type":        "Point",
+		"coordinates": bson.A{loc.Longitude, loc.Latitude},
+	}}}}
+
+	return r.findZones(ctx, filter)
+}
+
+func (r Repository) findZones(ctx context.Context, filter bson.M) ([]entities.Zone, error) {
+	span := trace.SpanFromContext(ctx)
+
+	cursor, err := r.zones.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "zones.Find")
+	}
+
+	zones := make([]entities.Zone, 0)
+	if err = cursor.All(ctx, &zones); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return zones, nil
+}
+
+// CreateZoneSubscription stores the subscription, a chat and topic subscribes to a zone once.
+func (r Repository) CreateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error {
+	ctx, span := r.tracer.Start(ctx, "repo.CreateZoneSubscription")
+	defer span.End()
+
+	if _, err := r.zoneSubs.InsertOne(ctx, sub); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "zoneSubs.InsertOne")
+	}
+
+	return nil
+}
+
+func (r Repository) GetZoneSubscription(ctx context.Context, subID string) (entities.ZoneSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.GetZoneSubscription")
+	defer span.End()
+
+	var sub entities.ZoneSubscription
+
+	castedID, err := primitive.ObjectIDFromHex(subID)
+	if err != nil {
+		return sub, errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	if err = r.zoneSubs.FindOne(ctx, bson.M{"_id": castedID}).Decode(&sub); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return sub, entities.ErrRecordNotFound
+		}
+
+		span.RecordError(err)
+		return sub, errors.Wrap(err, "zoneSubs.FindOne")
+	}
+
+	return sub, nil
+}
+
+func (r Repository) ListZoneSubscriptions(ctx context.Context, zoneID string) ([]entities.ZoneSubscription, error) {
+	ctx, span := r.tracer.Start(ctx, "repo.ListZoneSubscriptions")
+	defer span.End()
+
+	cursor, err := r.zoneSubs.Find(ctx, bson.M{"zoneID": zoneID}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "zoneSubs.Find")
+	}
+
+	subs := make([]entities.ZoneSubscription, 0)
+	if err = cursor.All(ctx, &subs); err != nil {
+		span.RecordError(err)
+		return nil, errors.Wrap(err, "cursor.All")
+	}
+
+	return subs, nil
+}
+
+func (r Repository) UpdateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error {
+	ctx, span := r.tracer.Start(ctx, "repo.UpdateZoneSubscription")
+	defer span.End()
+
+	res, err := r.zoneSubs.ReplaceOne(ctx, bson.M{"_id": sub.ID}, sub)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return entities.ErrRecordExists
+		}
+
+		span.RecordError(err)
+		return errors.Wrap(err, "zoneSubs.ReplaceOne")
+	}
+
+	if res.MatchedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r Repository) DeleteZoneSubscription(ctx context.Context, subID string) error {
+	ctx, span := r.tracer.Start(ctx, "repo.DeleteZoneSubscription")
+	defer span.End()
+
+	castedID, err := primitive.ObjectIDFromHex(subID)
+	if err != nil {
+		return errors.Wrap(err, "primitive.ObjectIDFromHex")
+	}
+
+	res, err := r.zoneSubs.DeleteOne(ctx, bson.M{"_id": castedID})
+	if err != nil {
+		span.RecordError(err)
+		return errors.Wrap(err, "zoneSubs.DeleteOne")
+	}
+
+	if res.DeletedCount == 0 {
+		return entities.ErrRecordNotFound
+	}
+
+	return nil
+}
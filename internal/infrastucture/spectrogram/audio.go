@@ -0,0 +1,169 @@
+package spectrogram
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedAudio is returned for a payload that is neither a PCM WAV file nor raw PCM with a sample rate.
+var ErrUnsupportedAudio = errors.New("error unsupported audio")
+
+const (
+	_wavPCM   = 1
+	_wavFloat = 3
+	// _wavExtensible carries the format in its extension, the first two bytes of the sub format GUID.
+	_wavExtensible = 0xFFFE
+)
+
+// Audio is a mono signal with the samples in [-1, 1].
+type Audio struct {
+	Samples    []float64
+	SampleRate int
+}
+
+func (a Audio) Duration() float64 {
+	return float64(len(a.Samples)) / float64(a.SampleRate)
+}
+
+// IsWAV reports whether the payload is a RIFF WAVE file.
+func IsWAV(payload []byte) bool {
+	return len(payload) >= 12 && string(payload[0:4]) == "RIFF" && string(payload[8:12]) == "WAVE"
+}
+
+// Decode reads a WAV file of 8, 16, 24 or 32-bit integer or 32-bit float PCM, or raw 16-bit
+// little-endian mono PCM at the sample rate. The channels are mixed down to mono.
+func Decode(payload []byte, sampleRate int) (Audio, error) {
+	if !IsWAV(payload) {
+		if sampleRate <= 0 {
+			return Audio{}, errors.Wrap(ErrUnsupportedAudio, "raw PCM needs a sample rate")
+		}
+
+		return decodePCM(payload, _wavPCM, 1, 16, sampleRate)
+	}
+
+	var (
+		format, channels, bits uint16
+		rate                   uint32
+		data                   []byte
+		haveFormat             bool
+	)
+
+	for chunks := payload[12:]; len(chunks) >= 8; {
+		id, size := string(chunks[0:4]), binary.LittleEndian.Uint32(chunks[4:8])
+		chunks = chunks[8:]
+		if uint64(size) > uint64(len(chunks)) {
+			// A truncated recording still has the samples up to the cut.
+			size = uint32(len(chunks))
+		}
+
+		body := chunks[:size]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return Audio{}, errors.Wrap(ErrUnsupportedAudio, "short fmt chunk")
+			}
+
+			format = binary.LittleEndian.Uint16(body[0:2])
+			channels = binary.LittleEndian.Uint16(body[2:4])
+			rate = binary.LittleEndian.Uint32(body[4:8])
+			bits = binary.LittleEndian.Uint16(body[14:16])
+			if format == _wavExtensible && len(body) >= 26 {
+				format = binary.LittleEndian.Uint16(body[24:26])
+			}
+
+			haveFormat = true
+		case "data":
+			data = body
+		}
+
+		// Chunks are padded to an even size.
+		size += size & 1
+		if uint64(size) > uint64(len(chunks)) {
+			break
+		}
+
+		chunks = chunks[size:]
+	}
+
+	if !haveFormat || data == nil {
+		return Audio{}, errors.Wrap(ErrUnsupportedAudio, "no fmt or data chunk")
+	}
+
+	return decodePCM(data, format, int(channels), int(bits), int(rate))
+}
+
+func decodePCM(data []byte, format uint16, channels, bits, sampleRate int) (Audio, error) {
+	switch {
+	case channels < 1:
+		return Audio{}, errors.Wrapf(ErrUnsupportedAudio, "%d channels", channels)
+	case sampleRate <= 0:
+		return Audio{}, errors.Wrapf(ErrUnsupportedAudio, "sample rate %d", sampleRate)
+	case format == _wavPCM && (bits == 8 || bits == 16 || bits == 24 || bits == 32):
+	case format == _wavFloat && bits == 32:
+	default:
+		return Audio{}, errors.Wrapf(ErrUnsupportedAudio, "format %d with %d bits", format, bits)
+	}
+
+	width := bits / 8
+	frames := len(data) / (width * channels)
+	if frames == 0 {
+		return Audio{}, errors.Wrap(ErrUnsupportedAudio, "no samples")
+	}
+
+	audio := Audio{Samples: make([]float64, frames), SampleRate: sampleRate}
+	for i := range audio.Samples {
+		var sum float64
+		for c := 0; c < channels; c++ {
+			sum += sample(data[(i*channels+c)*width:], format, bits)
+		}
+
+		audio.Samples[i] = sum / float64(channels)
+	}
+
+	return audio, nil
+}
+
+// sample reads one little-endian sample scaled to [-1, 1].
+func sample(b []byte, format uint16, bits int) float64 {
+	switch {
+	case format == _wavFloat:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case bits == 8:
+		// 8-bit WAV is unsigned.
+		return (float64(b[0]) - 128) / 128
+	case bits == 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case bits == 24:
+		v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	}
+}
+
+// WAV encodes the audio as a 16-bit PCM mono WAV file.
+func (a Audio) WAV() []byte {
+	var buf bytes.Buffer
+
+	size := uint32(len(a.Samples) * 2)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+size)
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []interface{}{
+		uint32(16), uint16(_wavPCM), uint16(1), uint32(a.SampleRate), uint32(a.SampleRate * 2), uint16(2), uint16(16),
+	} {
+		_ = binary.Write(&buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, size)
+
+	for _, s := range a.Samples {
+		_ = binary.Write(&buf, binary.LittleEndian, int16(math.Max(-1, math.Min(1, s))*math.MaxInt16))
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,204 @@
+// Package spectrogram renders the audio of an alert as an image responders can glance at: the waveform
+// on top and the spectrogram, time along and frequency up, below it.
+package spectrogram
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+)
+
+const (
+	_fftSize = 1024
+	// _dynamicRange - dB below the loudest point that are still told apart, the rest is background
+	_dynamicRange = 80
+)
+
+var (
+	_background = color.RGBA{R: 12, G: 12, B: 20, A: 255}
+	_waveform   = color.RGBA{R: 120, G: 200, B: 255, A: 255}
+
+	// _palette runs from silence to the loudest point.
+	_palette = []color.RGBA{
+		{R: 0, G: 0, B: 4, A: 255},
+		{R: 87, G: 16, B: 110, A: 255},
+		{R: 188, G: 55, B: 84, A: 255},
+		{R: 249, G: 142, B: 9, A: 255},
+		{R: 252, G: 255, B: 164, A: 255},
+	}
+)
+
+type Renderer struct {
+	cfg config.SpectrogramConfig
+}
+
+func NewRenderer(cfg config.SpectrogramConfig) *Renderer {
+	return &Renderer{cfg: cfg}
+}
+
+// FrequencyRange returns the frequencies shown for the audio, the configured maximum is capped by half
+// the sample rate.
+func (r *Renderer) FrequencyRange(audio Audio) (min, max float64) {
+	nyquist := float64(audio.SampleRate) / 2
+
+	max = r.cfg.MaxFrequency
+	if max == 0 || max > nyquist {
+		max = nyquist
+	}
+
+	min = r.cfg.MinFrequency
+	if min >= max {
+		min = 0
+	}
+
+	return min, max
+}
+
+// Render draws the audio as a PNG image of the configured size.
+func (r *Renderer) Render(audio Audio) ([]byte, error) {
+	if len(audio.Samples) == 0 || audio.SampleRate <= 0 {
+		return nil, errors.Wrap(ErrUnsupportedAudio, "no samples")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, r.cfg.Width, r.cfg.Height))
+	waveHeight := r.cfg.Height / 4
+
+	r.drawWaveform(img, audio, waveHeight)
+	r.drawSpectrogram(img, audio, waveHeight)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, errors.Wrap(err, "png.Encode")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// drawWaveform draws the peaks of every column in the top rows of the image.
+func (r *Renderer) drawWaveform(img *image.RGBA, audio Audio, height int) {
+	var peak float64
+	for _, s := range audio.Samples {
+		peak = math.Max(peak, math.Abs(s))
+	}
+	if peak == 0 {
+		peak = 1
+	}
+
+	middle := float64(height-1) / 2
+	for x := 0; x < r.cfg.Width; x++ {
+		from := x * len(audio.Samples) / r.cfg.Width
+		to := (x + 1) * len(audio.Samples) / r.cfg.Width
+		if to <= from {
+			to = from + 1
+		}
+
+		low, high := 1.0, -1.0
+		for _, s := range audio.Samples[from:to] {
+			low, high = math.Min(low, s/peak), math.Max(high, s/peak)
+		}
+
+		top, bottom := int(middle-high*middle), int(middle-low*middle)
+		for y := 0; y < height; y++ {
+			c := _background
+			if y >= top && y <= bottom {
+				c = _waveform
+			}
+
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawSpectrogram draws the magnitudes of a windowed FFT per column below the waveform, the rows are
+// spread linearly over the frequency range.
+func (r *Renderer) drawSpectrogram(img *image.RGBA, audio Audio, top int) {
+	size := _fftSize
+	for size > 64 && size > len(audio.Samples) {
+		size /= 2
+	}
+
+	window := hann(size)
+	height := r.cfg.Height - top
+	minFreq, maxFreq := r.FrequencyRange(audio)
+	binWidth := float64(audio.SampleRate) / float64(size)
+
+	columns := make([][]float64, r.cfg.Width)
+	loudest := math.Inf(-1)
+	frame := make([]complex128, size)
+
+	for x := range columns {
+		start := 0
+		if len(audio.Samples) > size && r.cfg.Width > 1 {
+			start = x * (len(audio.Samples) - size) / (r.cfg.Width - 1)
+		}
+
+		for i := range frame {
+			var s float64
+			if start+i < len(audio.Samples) {
+				s = audio.Samples[start+i]
+			}
+
+			frame[i] = complex(s*window[i], 0)
+		}
+
+		fft(frame)
+
+		column := make([]float64, height)
+		for y := range column {
+			freq := maxFreq - (float64(y)+0.5)/float64(height)*(maxFreq-minFreq)
+			bin := freq / binWidth
+			low := int(bin)
+			if low >= size/2 {
+				low = size/2 - 1
+			}
+
+			frac := bin - float64(low)
+			magnitude := (1-frac)*magnitudeAt(frame, low) + frac*magnitudeAt(frame, low+1)
+
+			column[y] = 20 * math.Log10(magnitude+1e-12)
+			loudest = math.Max(loudest, column[y])
+		}
+
+		columns[x] = column
+	}
+
+	for x, column := range columns {
+		for y, db := range column {
+			level := 1 - (loudest-db)/_dynamicRange
+			img.SetRGBA(x, top+y, shade(level))
+		}
+	}
+}
+
+func magnitudeAt(frame []complex128, bin int) float64 {
+	if bin >= len(frame)/2 {
+		bin = len(frame)/2 - 1
+	}
+
+	re, im := real(frame[bin]), imag(frame[bin])
+
+	return math.Sqrt(re*re + im*im)
+}
+
+// shade maps a level in [0, 1] onto the palette, the levels out of the range are clamped.
+func shade(level float64) color.RGBA {
+	level = math.Max(0, math.Min(1, level))
+
+	pos := level * float64(len(_palette)-1)
+	i := int(pos)
+	if i >= len(_palette)-1 {
+		return _palette[len(_palette)-1]
+	}
+
+	frac := pos - float64(i)
+	a, b := _palette[i], _palette[i+1]
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + frac*(float64(y)-float64(x))) }
+
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
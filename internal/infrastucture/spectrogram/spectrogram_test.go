@@ -0,0 +1,232 @@
+package spectrogram
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/png"
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+)
+
+// sine returns n samples of a tone of the frequency and amplitude.
+func sine(frequency, amplitude float64, sampleRate, n int) Audio {
+	audio := Audio{Samples: make([]float64, n), SampleRate: sampleRate}
+	for i := range audio.Samples {
+		audio.Samples[i] = amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate))
+	}
+
+	return audio
+}
+
+// wavFile builds a RIFF WAVE file of the fmt chunk, the chunks and the data.
+func wavFile(format uint16, channels, bits, rate int, data []byte, chunks ...[]byte) []byte {
+	var body bytes.Buffer
+
+	body.WriteString("WAVEfmt ")
+	for _, field := range []interface{}{
+		uint32(16), format, uint16(channels), uint32(rate), uint32(rate * channels * bits / 8),
+		uint16(channels * bits / 8), uint16(bits),
+	} {
+		_ = binary.Write(&body, binary.LittleEndian, field)
+	}
+
+	for _, chunk := range chunks {
+		body.Write(chunk)
+	}
+
+	body.WriteString("data")
+	_ = binary.Write(&body, binary.LittleEndian, uint32(len(data)))
+	body.Write(data)
+
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	_ = binary.Write(&file, binary.LittleEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+
+	return file.Bytes()
+}
+
+func TestWAVHeader(t *testing.T) {
+	audio := sine(1000, 0.5, 8000, 800)
+	wav := audio.WAV()
+
+	if len(wav) != 44+2*800 {
+		t.Fatalf("length: want %d, got %d", 44+2*800, len(wav))
+	}
+
+	le := binary.LittleEndian
+	for _, field := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"RIFF", string(wav[0:4]), "RIFF"},
+		{"RIFF size", le.Uint32(wav[4:8]), uint32(36 + 2*800)},
+		{"WAVE", string(wav[8:16]), "WAVEfmt "},
+		{"fmt size", le.Uint32(wav[16:20]), uint32(16)},
+		{"format", le.Uint16(wav[20:22]), uint16(_wavPCM)},
+		{"channels", le.Uint16(wav[22:24]), uint16(1)},
+		{"sample rate", le.Uint32(wav[24:28]), uint32(8000)},
+		{"byte rate", le.Uint32(wav[28:32]), uint32(16000)},
+		{"block align", le.Uint16(wav[32:34]), uint16(2)},
+		{"bits", le.Uint16(wav[34:36]), uint16(16)},
+		{"data", string(wav[36:40]), "data"},
+		{"data size", le.Uint32(wav[40:44]), uint32(2 * 800)},
+	} {
+		if field.got != field.want {
+			t.Errorf("%s: want %v, got %v", field.name, field.want, field.got)
+		}
+	}
+
+	decoded, err := Decode(wav, 0)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if decoded.SampleRate != 8000 || len(decoded.Samples) != 800 {
+		t.Fatalf("Decode: got %d samples at %d Hz", len(decoded.Samples), decoded.SampleRate)
+	}
+
+	for i, s := range decoded.Samples {
+		if math.Abs(s-audio.Samples[i]) > 1.0/(1<<14) {
+			t.Fatalf("sample %d: want %v, got %v", i, audio.Samples[i], s)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	le := binary.LittleEndian
+	float := func(values ...float32) []byte {
+		data := make([]byte, 4*len(values))
+		for i, v := range values {
+			le.PutUint32(data[4*i:], math.Float32bits(v))
+		}
+
+		return data
+	}
+
+	// An extensible fmt chunk is 40 bytes, the sub format GUID at its end starts with the format.
+	extension := make([]byte, 24)
+	le.PutUint16(extension[0:2], 22)
+	le.PutUint16(extension[8:10], _wavPCM)
+
+	extensible := wavFile(_wavExtensible, 1, 16, 8000, []byte{0x00, 0x40})
+	le.PutUint32(extensible[16:20], 40)
+	extensible = append(extensible[:36:36], append(extension, extensible[36:]...)...)
+
+	tests := []struct {
+		name       string
+		payload    []byte
+		sampleRate int
+		want       []float64
+		wantErr    bool
+	}{
+		{name: "unsigned 8-bit", payload: wavFile(_wavPCM, 1, 8, 8000, []byte{0, 128, 192}), want: []float64{-1, 0, 0.5}},
+		{name: "16-bit stereo mixed down", payload: wavFile(_wavPCM, 2, 16, 8000, []byte{0x00, 0x40, 0x00, 0x00}), want: []float64{0.25}},
+		{name: "24-bit negative", payload: wavFile(_wavPCM, 1, 24, 8000, []byte{0x00, 0x00, 0xC0}), want: []float64{-0.5}},
+		{name: "32-bit float", payload: wavFile(_wavFloat, 1, 32, 8000, float(0.75, -0.25)), want: []float64{0.75, -0.25}},
+		{name: "extensible", payload: extensible, want: []float64{0.5}},
+		{
+			name:    "odd chunk before the data",
+			payload: wavFile(_wavPCM, 1, 16, 8000, []byte{0x00, 0x40}, []byte("LIST\x03\x00\x00\x00abc\x00")),
+			want:    []float64{0.5},
+		},
+		{name: "truncated data", payload: wavFile(_wavPCM, 1, 16, 8000, []byte{0x00, 0x40, 0x00, 0xC0})[:46], want: []float64{0.5}},
+		{name: "raw PCM", payload: []byte{0x00, 0xC0}, sampleRate: 8000, want: []float64{-0.5}},
+		{name: "raw PCM without a rate", payload: []byte{0x00, 0xC0}, wantErr: true},
+		{name: "12-bit", payload: wavFile(_wavPCM, 1, 12, 8000, []byte{0, 0}), wantErr: true},
+		{name: "no samples", payload: wavFile(_wavPCM, 1, 16, 8000, nil), wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			audio, err := Decode(tc.payload, tc.sampleRate)
+			if tc.wantErr {
+				if !errors.Is(err, ErrUnsupportedAudio) {
+					t.Fatalf("Decode: want ErrUnsupportedAudio, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			if len(audio.Samples) != len(tc.want) || audio.SampleRate != 8000 {
+				t.Fatalf("Decode: want %v at 8000 Hz, got %v at %d Hz", tc.want, audio.Samples, audio.SampleRate)
+			}
+
+			for i, want := range tc.want {
+				if math.Abs(audio.Samples[i]-want) > 1e-9 {
+					t.Fatalf("Decode: want %v, got %v", tc.want, audio.Samples)
+				}
+			}
+		})
+	}
+}
+
+func TestFFTSine(t *testing.T) {
+	// 1024 Hz at 8192 Hz falls exactly on bin 128 of a 1024 point transform.
+	audio := sine(1024, 0.5, 8192, 1024)
+
+	frame := make([]complex128, len(audio.Samples))
+	for i, s := range audio.Samples {
+		frame[i] = complex(s, 0)
+	}
+
+	fft(frame)
+
+	for bin := 0; bin < len(frame)/2; bin++ {
+		magnitude := cmplx.Abs(frame[bin])
+
+		want := 0.0
+		if bin == 128 {
+			want = 0.5 * float64(len(frame)) / 2
+		}
+
+		if math.Abs(magnitude-want) > 1e-6 {
+			t.Fatalf("bin %d: want magnitude %v, got %v", bin, want, magnitude)
+		}
+	}
+}
+
+func TestRenderSine(t *testing.T) {
+	cfg := config.SpectrogramConfig{Width: 32, Height: 128}
+	renderer := NewRenderer(cfg)
+
+	// 2 kHz is half way up the 0-4 kHz range of 8 kHz audio.
+	payload, err := renderer.Render(sine(2000, 0.8, 8000, 8000))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+
+	if bounds := img.Bounds(); bounds.Dx() != cfg.Width || bounds.Dy() != cfg.Height {
+		t.Fatalf("size: want %dx%d, got %v", cfg.Width, cfg.Height, bounds)
+	}
+
+	top := cfg.Height / 4
+	middle := top + (cfg.Height-top)/2
+
+	for x := 0; x < cfg.Width; x++ {
+		brightest, brightness := 0, uint32(0)
+		for y := top; y < cfg.Height; y++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if r+g+b > brightness {
+				brightest, brightness = y, r+g+b
+			}
+		}
+
+		if brightest < middle-1 || brightest > middle {
+			t.Fatalf("column %d: the tone is at row %d, want %d or %d", x, brightest, middle-1, middle)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ucase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+var ErrBotInUse = errors.New("error bot is used by clients")
+
+type (
+	BotRepo interface {
+		CreateBot(ctx context.Context, bot entities.Bot) error
+		GetBot(ctx context.Context, botID string) (entities.Bot, error)
+		ListBots(ctx context.Context, tenantID string) ([]entities.Bot, error)
+		DeleteBot(ctx context.Context, botID string) error
+	}
+
+	BotRegistry interface {
+		Verify(ctx context.Context, token string) (string, error)
+		Remove(botID string)
+	}
+
+	TokenEncrypter interface {
+		Encrypt(plain string) (string, error)
+	}
+)
+
+type Bot struct {
+	repo     BotRepo
+	registry BotRegistry
+	cipher   TokenEncrypter
+}
+
+func NewBotUCase(repo BotRepo, registry BotRegistry, cipher TokenEncrypter) *Bot {
+	return &Bot{
+		repo:     repo,
+		registry: registry,
+		cipher:   cipher,
+	}
+}
+
+// Create checks the token against Telegram and stores the bot with the token encrypted. The admin chat
+// is told about the client chats that stop accepting the alerts, zero for none, pollUpdates opts the bot
+// in to the membership polling. A tenant scoped call creates the bot for its own tenant.
+func (b Bot) Create(
+	ctx context.Context, tenantID, token string, adminChatID int64, pollUpdates bool,
+) (entities.Bot, error) {
+	if scope, ok := TenantFromContext(ctx); ok {
+		tenantID = scope
+	}
+
+	username, err := b.registry.Verify(ctx, token)
+	if err != nil {
+		return entities.Bot{}, errors.Wrap(err, "registry.Verify")
+	}
+
+	encrypted, err := b.cipher.Encrypt(token)
+	if err != nil {
+		return entities.Bot{}, errors.Wrap(err, "cipher.Encrypt")
+	}
+
+	bot := entities.Bot{
+		ID:          primitive.NewObjectID(),
+		TenantID:    tenantID,
+		Username:    username,
+		Token:       encrypted,
+		AdminChatID: adminChatID,
+		PollUpdates: pollUpdates,
+	}
+
+	if err = b.repo.CreateBot(ctx, bot); err != nil {
+		return entities.Bot{}, errors.Wrap(err, "repo.CreateBot")
+	}
+
+	return bot, nil
+}
+
+// List returns the bots of the tenant, all of them for an empty one. A tenant scoped call lists its own
+// tenant only.
+func (b Bot) List(ctx context.Context, tenantID string) ([]entities.Bot, error) {
+	if scope, ok := TenantFromContext(ctx); ok {
+		tenantID = scope
+	}
+
+	bots, err := b.repo.ListBots(ctx, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListBots")
+	}
+
+	return bots, nil
+}
+
+// Delete removes a bot that no client references anymore.
+func (b Bot) Delete(ctx context.Context, botID string) error {
+	if _, scoped := TenantFromContext(ctx); scoped {
+		bot, err := b.repo.GetBot(ctx, botID)
+		if err != nil {
+			return errors.Wrap(err, "repo.GetBot")
+		}
+
+		if !visible(ctx, bot.TenantID) {
+			return errors.Wrapf(entities.ErrRecordNotFound, "bot %s", botID)
+		}
+	}
+
+	if err := b.repo.DeleteBot(ctx, botID); err != nil {
+		if errors.Is(err, entities.ErrRecordInUse) {
+			return ErrBotInUse
+		}
+
+		return errors.Wrap(err, "repo.DeleteBot")
+	}
+
+	b.registry.Remove(botID)
+
+	return nil
+}
@@ -0,0 +1,347 @@
+package ucase
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+var (
+	ErrInvalidBroadcast  = errors.New("error invalid broadcast")
+	ErrBroadcastFinished = errors.New("error broadcast is finished")
+)
+
+const (
+	_maxBroadcastLength    = 4096 // _maxBroadcastLength - characters in a Telegram text message
+	_maxBroadcastFailures  = 1000
+	_broadcastPageSize     = 100
+	_broadcastPollInterval = 30 * time.Second
+)
+
+type (
+	BroadcastRepo interface {
+		CreateBroadcast(ctx context.Context, broadcast entities.Broadcast) error
+		GetBroadcast(ctx context.Context, broadcastID string) (entities.Broadcast, error)
+		ListBroadcasts(ctx context.Context, statuses ...entities.BroadcastStatus) ([]entities.Broadcast, error)
+		UpdateBroadcast(ctx context.Context, broadcast entities.Broadcast) error
+		ListAccounts(ctx context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error)
+		CountAccounts(ctx context.Context, filter entities.AccountFilter) (int64, error)
+		ListBots(ctx context.Context, tenantID string) ([]entities.Bot, error)
+		OrganizationGetter
+	}
+
+	TextSender interface {
+		SendText(ctx context.Context, account entities.TGAccount, text string) error
+	}
+)
+
+// Broadcast sends operator announcements to all the clients or to a part of them. The jobs are stored and
+// run one at a time by Run, a job interrupted by a restart resumes after the last client it handled.
+type Broadcast struct {
+	repo   BroadcastRepo
+	sender TextSender
+	tracer trace.Tracer
+	wake   chan struct{}
+}
+
+func NewBroadcastUCase(repo BroadcastRepo, sender TextSender) *Broadcast {
+	return &Broadcast{
+		repo:   repo,
+		sender: sender,
+		tracer: otel.Tracer("broadcastUCase"),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+func (b *Broadcast) Create(
+	ctx context.Context, text string, target entities.BroadcastTarget,
+) (entities.Broadcast, error) {
+	if strings.TrimSpace(text) == "" {
+		return entities.Broadcast{}, errors.Wrap(ErrInvalidBroadcast, "text is required")
+	}
+
+	if utf8.RuneCountInString(text) > _maxBroadcastLength {
+		return entities.Broadcast{}, errors.Wrapf(ErrInvalidBroadcast, "text is longer than %d characters", _maxBroadcastLength)
+	}
+
+	if target.TenantID != "" {
+		bots, err := b.repo.ListBots(ctx, target.TenantID)
+		if err != nil {
+			return entities.Broadcast{}, errors.Wrap(err, "repo.ListBots")
+		}
+
+		if len(bots) == 0 {
+			return entities.Broadcast{}, errors.Wrapf(ErrInvalidBroadcast, "tenant %s has no bots", target.TenantID)
+		}
+	}
+
+	now := time.Now().UTC()
+	broadcast := entities.Broadcast{
+		ID:        primitive.NewObjectID(),
+		Text:      text,
+		Target:    target,
+		Status:    entities.BroadcastPending,
+		Failures:  []entities.BroadcastFailure{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := b.repo.CreateBroadcast(ctx, broadcast); err != nil {
+		return entities.Broadcast{}, errors.Wrap(err, "repo.CreateBroadcast")
+	}
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+
+	return broadcast, nil
+}
+
+func (b *Broadcast) Get(ctx context.Context, broadcastID string) (entities.Broadcast, error) {
+	broadcast, err := b.repo.GetBroadcast(ctx, broadcastID)
+	if err != nil {
+		return broadcast, errors.Wrap(err, "repo.GetBroadcast")
+	}
+
+	return broadcast, nil
+}
+
+func (b *Broadcast) List(ctx context.Context) ([]entities.Broadcast, error) {
+	broadcasts, err := b.repo.ListBroadcasts(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListBroadcasts")
+	}
+
+	return broadcasts, nil
+}
+
+// Cancel stops a pending or running broadcast, the chats already sent to keep the message.
+func (b *Broadcast) Cancel(ctx context.Context, broadcastID string) (entities.Broadcast, error) {
+	broadcast, err := b.repo.GetBroadcast(ctx, broadcastID)
+	if err != nil {
+		return broadcast, errors.Wrap(err, "repo.GetBroadcast")
+	}
+
+	if broadcast.Finished() {
+		return broadcast, errors.Wrapf(ErrBroadcastFinished, "broadcast is %s", broadcast.Status)
+	}
+
+	now := time.Now().UTC()
+	broadcast.Status = entities.BroadcastCanceled
+	broadcast.UpdatedAt = now
+	broadcast.FinishedAt = now
+
+	if err = b.repo.UpdateBroadcast(ctx, broadcast); err != nil {
+		if errors.Is(err, entities.ErrRecordNotFound) {
+			return broadcast, errors.Wrap(ErrBroadcastFinished, "broadcast finished meanwhile")
+		}
+
+		return broadcast, errors.Wrap(err, "repo.UpdateBroadcast")
+	}
+
+	return broadcast, nil
+}
+
+// Run executes the pending broadcasts until the context is canceled. It picks up new jobs as they are
+// created and retries the interrupted ones every _broadcastPollInterval.
+func (b *Broadcast) Run(ctx context.Context) {
+	ticker := time.NewTicker(_broadcastPollInterval)
+	defer ticker.Stop()
+
+	for {
+		b.runPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Broadcast) runPending(ctx context.Context) {
+	broadcasts, err := b.repo.ListBroadcasts(ctx, entities.BroadcastRunning, entities.BroadcastPending)
+	if err != nil {
+		return
+	}
+
+	// Oldest first, the list is newest first.
+	for i := len(broadcasts) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.run(ctx, broadcasts[i])
+	}
+}
+
+// run sends the broadcast to the clients after its cursor. The progress is saved after every chat, so a resumed
+// job sends again at most the message that was in flight. It returns when the job is done, canceled or
+// when the repository fails, leaving the job to be resumed.
+func (b *Broadcast) run(ctx context.Context, broadcast entities.Broadcast) {
+	ctx, span := b.tracer.Start(ctx, "broadcastUCase.run", trace.WithAttributes(
+		attribute.String("broadcast.id", broadcast.ID.Hex()),
+	))
+	defer span.End()
+
+	filter, ok, err := b.accountFilter(ctx, broadcast.Target)
+	if err != nil {
+		b.fail(ctx, broadcast, err)
+		return
+	}
+
+	if broadcast.Status == entities.BroadcastPending {
+		if ok {
+			total, err := b.repo.CountAccounts(ctx, filter)
+			if err != nil {
+				b.fail(ctx, broadcast, errors.Wrap(err, "repo.CountAccounts"))
+				return
+			}
+
+			broadcast.Total = int(total)
+		}
+
+		broadcast.Status = entities.BroadcastRunning
+		if err = b.save(ctx, &broadcast); err != nil {
+			return
+		}
+	}
+
+	filter.Limit = _broadcastPageSize
+	orgs := make(map[string]entities.Organization)
+
+	for ok {
+		filter.After = broadcast.Cursor
+
+		accounts, err := b.repo.ListAccounts(ctx, filter)
+		if err != nil {
+			b.fail(ctx, broadcast, errors.Wrap(err, "repo.ListAccounts"))
+			return
+		}
+
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, account := range accounts {
+			account, err = b.inherit(ctx, account, orgs)
+			switch {
+			case err != nil:
+			case !account.Active():
+				err = errors.Wrapf(ErrInactiveAccount, "chat rejects the bot: %s", account.Deactivation.Reason)
+			default:
+				err = b.sender.SendText(ctx, account, broadcast.Text)
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			// The chat isn't counted, the job resumes with it on a later poll.
+			if errors.Is(err, entities.ErrUnavailable) {
+				b.fail(ctx, broadcast, err)
+				return
+			}
+
+			if err != nil {
+				span.RecordError(err)
+
+				broadcast.Failed++
+				if len(broadcast.Failures) < _maxBroadcastFailures {
+					broadcast.Failures = append(broadcast.Failures, entities.BroadcastFailure{
+						ClientID: account.ClientID.Hex(),
+						ChatID:   account.ChatID,
+						Error:    err.Error(),
+						At:       time.Now().UTC(),
+					})
+				}
+			} else {
+				broadcast.Sent++
+			}
+
+			broadcast.Cursor = account.ClientID.Hex()
+			broadcast.Error = ""
+
+			if err = b.save(ctx, &broadcast); err != nil {
+				return
+			}
+		}
+	}
+
+	broadcast.Status = entities.BroadcastDone
+	broadcast.FinishedAt = time.Now().UTC()
+	_ = b.save(ctx, &broadcast)
+}
+
+// inherit fills the settings the site leaves empty from its organization, the organizations are looked up
+// once per job.
+func (b *Broadcast) inherit(
+	ctx context.Context, account entities.TGAccount, orgs map[string]entities.Organization,
+) (entities.TGAccount, error) {
+	if account.OrganizationID == "" {
+		return account, nil
+	}
+
+	org, ok := orgs[account.OrganizationID]
+	if !ok {
+		var err error
+		if org, err = b.repo.GetOrganization(ctx, account.OrganizationID); err != nil {
+			return account, errors.Wrap(err, "repo.GetOrganization")
+		}
+
+		orgs[account.OrganizationID] = org
+	}
+
+	return org.Inherit(account), nil
+}
+
+// accountFilter resolves the target to a filter, ok is false when the target can't match any client.
+func (b *Broadcast) accountFilter(
+	ctx context.Context, target entities.BroadcastTarget,
+) (filter entities.AccountFilter, ok bool, err error) {
+	filter = entities.AccountFilter{Region: target.Region, Tags: target.Tags}
+
+	if target.TenantID == "" {
+		return filter, true, nil
+	}
+
+	bots, err := b.repo.ListBots(ctx, target.TenantID)
+	if err != nil {
+		return filter, false, errors.Wrap(err, "repo.ListBots")
+	}
+
+	for _, bot := range bots {
+		filter.BotIDs = append(filter.BotIDs, bot.ID.Hex())
+	}
+
+	return filter, len(filter.BotIDs) != 0, nil
+}
+
+func (b *Broadcast) save(ctx context.Context, broadcast *entities.Broadcast) error {
+	broadcast.UpdatedAt = time.Now().UTC()
+
+	return b.repo.UpdateBroadcast(ctx, *broadcast)
+}
+
+// fail records the error of the job, it stays as it is and is retried on the next poll.
+func (b *Broadcast) fail(ctx context.Context, broadcast entities.Broadcast, err error) {
+	trace.SpanFromContext(ctx).RecordError(err)
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	broadcast.Error = err.Error()
+	_ = b.save(ctx, &broadcast)
+}
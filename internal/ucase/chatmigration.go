@@ -0,0 +1,56 @@
+package ucase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type ChatMigrationRepo interface {
+	// MigrateChat moves the accounts of the bot from the old chat to the new one, it returns their client IDs.
+	MigrateChat(ctx context.Context, botID string, fromChatID, toChatID int64) ([]string, error)
+	AddChatMigration(ctx context.Context, migration entities.ChatMigration) error
+}
+
+// migrateChat follows the upgrade of the account chat to a supergroup: every account registered with the old
+// chat moves to the new one and the migration is recorded along with its outbox event.
+func (n *Notify) migrateChat(ctx context.Context, account entities.TGAccount, toChatID int64) error {
+	migration := entities.ChatMigration{
+		ID:         primitive.NewObjectID(),
+		BotID:      account.BotID,
+		FromChatID: account.ChatID,
+		ToChatID:   toChatID,
+		MigratedAt: time.Now().UTC(),
+	}
+
+	err := n.outbox.Transaction(ctx, func(ctx context.Context) error {
+		clientIDs, err := n.repo.MigrateChat(ctx, migration.BotID, migration.FromChatID, migration.ToChatID)
+		if err != nil {
+			return errors.Wrap(err, "repo.MigrateChat")
+		}
+
+		migration.ClientIDs = clientIDs
+		if err = n.repo.AddChatMigration(ctx, migration); err != nil {
+			return errors.Wrap(err, "repo.AddChatMigration")
+		}
+
+		return n.outbox.Record(ctx, entities.EventChatMigrated, account.ClientID.Hex(), migration)
+	})
+	if err != nil {
+		return err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("chat migrated", trace.WithAttributes(
+		attribute.Int64("chat.from", migration.FromChatID),
+		attribute.Int64("chat.to", migration.ToChatID),
+		attribute.Int("chat.accounts", len(migration.ClientIDs)),
+	))
+
+	return nil
+}
@@ -2,37 +2,176 @@ package ucase
 
 import (
 	"context"
+
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrTopicNotCreated is returned when Telegram refuses to create the forum topic of a new client.
+	ErrTopicNotCreated = errors.New("error forum topic is not created")
+	ErrInvalidAccount  = errors.New("error invalid account")
 )
 
+type TopicCreator interface {
+	CreateTopic(ctx context.Context, account entities.TGAccount, name string) (int, error)
+}
+
 type ClientRepo interface {
+	OrganizationGetter
 	Create(ctx context.Context, account entities.TGAccount) error
 	Delete(ctx context.Context, clientID string) error
+	GetAccountByClientID(ctx context.Context, clientID string) (entities.TGAccount, error)
+	UpsertAccount(ctx context.Context, account entities.TGAccount) (bool, error)
+	ForEachAccount(ctx context.Context, fn func(entities.TGAccount) error) error
+	GetBot(ctx context.Context, botID string) (entities.Bot, error)
+	ListChatMigrations(ctx context.Context, clientID string) ([]entities.ChatMigration, error)
 }
 
 type Client struct {
-	repo ClientRepo
+	repo   ClientRepo
+	outbox *Outbox
+	topics TopicCreator
 }
 
-func NewClientUCase(repo ClientRepo) *Client {
+// NewClientUCase creates the use case, the registration changes are recorded in the outbox unless it's nil.
+func NewClientUCase(repo ClientRepo, outbox *Outbox, topics TopicCreator) *Client {
 	return &Client{
-		repo: repo,
+		repo:   repo,
+		outbox: outbox,
+		topics: topics,
 	}
 }
 
-func (c Client) Create(ctx context.Context, account entities.TGAccount) error {
-	if err := c.repo.Create(ctx, account); err != nil {
-		return errors.Wrap(err, "repo.Create")
+// Create registers the account. With a topic name and no thread ID the client gets its own forum topic
+// in the chat, the alerts are then posted into it. A site without a chat of its own uses the chat of its
+// organization, a tenant scoped call can register sites of its own organizations only.
+func (c Client) Create(ctx context.Context, account entities.TGAccount, topicName string) error {
+	account.Deactivation = nil
+
+	if _, scoped := TenantFromContext(ctx); scoped && account.OrganizationID == "" {
+		return errors.Wrap(ErrInvalidAccount, "organizationID is required")
+	}
+
+	if account.OrganizationID != "" && !primitive.IsValidObjectID(account.OrganizationID) {
+		return errors.Wrapf(ErrInvalidAccount, "organizationID %q is not an object id", account.OrganizationID)
+	}
+
+	filters, invalid := parseRules(account.Rules)
+	if invalid != "" {
+		return errors.Wrap(ErrInvalidAccount, invalid)
+	}
+
+	account.Filters = filters
+
+	org, err := siteOrganization(ctx, c.repo, account)
+	if err != nil {
+		return err
+	}
+
+	if account.ChatID == 0 && org.ChatID == 0 {
+		return errors.Wrap(ErrInvalidAccount, "chatID is required without a chat of the organization")
 	}
 
-	return nil
+	if account.BotID != "" {
+		bot, err := c.repo.GetBot(ctx, account.BotID)
+		if err != nil {
+			return errors.Wrap(err, "repo.GetBot")
+		}
+
+		if !visible(ctx, bot.TenantID) {
+			return errors.Wrapf(entities.ErrRecordNotFound, "bot %s", account.BotID)
+		}
+	}
+
+	if topicName != "" && account.ThreadID == 0 {
+		// Checked first, a topic of a rejected registration would stay in the chat.
+		_, err = c.repo.GetAccountByClientID(ctx, account.ClientID.Hex())
+		if err == nil {
+			return entities.ErrRecordExists
+		}
+		if !errors.Is(err, entities.ErrRecordNotFound) {
+			return errors.Wrap(err, "repo.GetAccountByClientID")
+		}
+
+		if account.ThreadID, err = c.topics.CreateTopic(ctx, org.Inherit(account), topicName); err != nil {
+			return errors.Wrapf(ErrTopicNotCreated, "topics.CreateTopic: %s", err)
+		}
+	}
+
+	return c.outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := c.repo.Create(ctx, account); err != nil {
+			return errors.Wrap(err, "repo.Create")
+		}
+
+		return c.outbox.Record(ctx, entities.EventClientCreated, account.ClientID.Hex(), account)
+	})
+}
+
+// Get returns the account as it was registered, without the settings inherited from the organization.
+func (c Client) Get(ctx context.Context, clientID string) (entities.TGAccount, error) {
+	account, err := c.repo.GetAccountByClientID(ctx, clientID)
+	if err != nil {
+		return account, errors.Wrap(err, "repo.GetAccountByClientID")
+	}
+
+	if _, err = siteOrganization(ctx, c.repo, account); err != nil {
+		return entities.TGAccount{}, err
+	}
+
+	return account, nil
+}
+
+// ListMigrations returns the chat migrations that moved the account of the client, newest first.
+func (c Client) ListMigrations(ctx context.Context, clientID string) ([]entities.ChatMigration, error) {
+	if _, err := c.Get(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	migrations, err := c.repo.ListChatMigrations(ctx, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListChatMigrations")
+	}
+
+	return migrations, nil
+}
+
+// SetRules replaces the filter rules of the client, no rules let every alert through.
+func (c Client) SetRules(ctx context.Context, clientID string, rules []string) error {
+	filters, invalid := parseRules(rules)
+	if invalid != "" {
+		return errors.Wrap(ErrInvalidAccount, invalid)
+	}
+
+	account, err := c.Get(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	account.Rules, account.Filters = rules, filters
+
+	return c.outbox.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := c.repo.UpsertAccount(ctx, account); err != nil {
+			return errors.Wrap(err, "repo.UpsertAccount")
+		}
+
+		return c.outbox.Record(ctx, entities.EventClientUpdated, clientID, account)
+	})
 }
 
 func (c Client) Delete(ctx context.Context, clientID string) error {
-	if err := c.repo.Delete(ctx, clientID); err != nil {
-		return errors.Wrap(err, "repo.Delete")
+	if _, scoped := TenantFromContext(ctx); scoped {
+		if _, err := c.Get(ctx, clientID); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return c.outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := c.repo.Delete(ctx, clientID); err != nil {
+			return errors.Wrap(err, "repo.Delete")
+		}
+
+		return c.outbox.Record(ctx, entities.EventClientDeleted, clientID, map[string]string{"clientID": clientID})
+	})
 }
@@ -0,0 +1,75 @@
+package ucase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	_defaultDeliveryLimit = 100
+	_maxDeliveryLimit     = 1000
+)
+
+type DeliveryLister interface {
+	ListDeliveries(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error)
+}
+
+// Delivery is the history of the delivered alerts.
+type Delivery struct {
+	repo DeliveryRepo
+}
+
+func NewDeliveryUCase(repo DeliveryRepo) *Delivery {
+	return &Delivery{
+		repo: repo,
+	}
+}
+
+// List returns the newest deliveries matching the filter, at most _maxDeliveryLimit of them.
+func (d Delivery) List(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = _defaultDeliveryLimit
+	}
+	if filter.Limit > _maxDeliveryLimit {
+		filter.Limit = _maxDeliveryLimit
+	}
+
+	deliveries, err := d.repo.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListDeliveries")
+	}
+
+	return deliveries, nil
+}
+
+// Acknowledge records that an operator has seen the alert of the request, in every chat it was delivered to
+// or only in the chats of the client when clientID isn't empty. An alert acknowledged already keeps the time
+// of the first acknowledgment.
+func (d Delivery) Acknowledge(ctx context.Context, requestID, clientID string) ([]entities.Delivery, error) {
+	deliveries, err := d.repo.ListDeliveries(ctx, entities.DeliveryFilter{RequestID: requestID, ClientID: clientID})
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListDeliveries")
+	}
+
+	if len(deliveries) == 0 {
+		return nil, errors.Wrapf(ErrNoDelivery, "request %s", requestID)
+	}
+
+	now := time.Now().UTC()
+	for i := range deliveries {
+		if deliveries[i].AcknowledgedAt != nil {
+			continue
+		}
+
+		deliveries[i].AcknowledgedAt = &now
+		if err = d.repo.SaveDelivery(ctx, deliveries[i]); err != nil {
+			return nil, errors.Wrap(err, "repo.SaveDelivery")
+		}
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,342 @@
+package ucase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	_digestBatchSize    = 50
+	_digestPollInterval = time.Minute
+	_digestLeaseTTL     = 2 * time.Minute
+	_digestLease        = "digests"
+)
+
+// ErrInvalidDigest is returned for a digest schedule that can't be stored.
+var ErrInvalidDigest = errors.New("error invalid digest schedule")
+
+type (
+	DigestScheduleRepo interface {
+		CreateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error
+		GetDigestSchedule(ctx context.Context, scheduleID string) (entities.DigestSchedule, error)
+		ListDigestSchedules(ctx context.Context, clientID string) ([]entities.DigestSchedule, error)
+		ListDueDigestSchedules(ctx context.Context, now time.Time, limit int) ([]entities.DigestSchedule, error)
+		UpdateDigestSchedule(ctx context.Context, schedule entities.DigestSchedule) error
+		DeleteDigestSchedule(ctx context.Context, scheduleID string) error
+	}
+
+	DigestRepo interface {
+		DigestScheduleRepo
+		AccountGetter
+		OrganizationGetter
+		SensorGetter
+		DeliveryLister
+		AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	}
+
+	DigestSender interface {
+		SendText(ctx context.Context, account entities.TGAccount, text string) error
+	}
+)
+
+// Digest sends the clients scheduled summaries of the alerts delivered to them.
+type Digest struct {
+	repo   DigestRepo
+	sender DigestSender
+	tracer trace.Tracer
+}
+
+func NewDigestUCase(repo DigestRepo, sender DigestSender) *Digest {
+	return &Digest{
+		repo:   repo,
+		sender: sender,
+		tracer: otel.Tracer("digestUCase"),
+	}
+}
+
+// client returns the account of the client with its organization, it must be visible to the caller.
+func (d *Digest) client(ctx context.Context, clientID string) (entities.TGAccount, entities.Organization, error) {
+	account, err := d.repo.GetAccountByClientID(ctx, clientID)
+	if err != nil {
+		return account, entities.Organization{}, errors.Wrap(err, "repo.GetAccountByClientID")
+	}
+
+	org, err := siteOrganization(ctx, d.repo, account)
+	if err != nil {
+		return account, org, err
+	}
+
+	return account, org, nil
+}
+
+// Schedule stores a digest schedule of the client, a daily digest unless the period says otherwise.
+func (d *Digest) Schedule(ctx context.Context, schedule entities.DigestSchedule) (entities.DigestSchedule, error) {
+	if _, _, err := d.client(ctx, schedule.ClientID); err != nil {
+		return entities.DigestSchedule{}, err
+	}
+
+	if schedule.Period == "" {
+		schedule.Period = entities.DigestDaily
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return entities.DigestSchedule{}, errors.Wrap(ErrInvalidDigest, err.Error())
+	}
+
+	now := time.Now().UTC()
+
+	next, err := schedule.Next(now)
+	if err != nil {
+		return entities.DigestSchedule{}, errors.Wrap(ErrInvalidDigest, err.Error())
+	}
+
+	schedule.ID = primitive.NewObjectID()
+	schedule.NextRunAt = next
+	schedule.LastRunAt = time.Time{}
+	schedule.CreatedAt = now
+
+	if err = d.repo.CreateDigestSchedule(ctx, schedule); err != nil {
+		return entities.DigestSchedule{}, errors.Wrap(err, "repo.CreateDigestSchedule")
+	}
+
+	return schedule, nil
+}
+
+func (d *Digest) ListSchedules(ctx context.Context, clientID string) ([]entities.DigestSchedule, error) {
+	if _, _, err := d.client(ctx, clientID); err != nil {
+		return nil, err
+	}
+
+	schedules, err := d.repo.ListDigestSchedules(ctx, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListDigestSchedules")
+	}
+
+	return schedules, nil
+}
+
+func (d *Digest) Unschedule(ctx context.Context, clientID, scheduleID string) error {
+	if _, _, err := d.client(ctx, clientID); err != nil {
+		return err
+	}
+
+	schedule, err := d.repo.GetDigestSchedule(ctx, scheduleID)
+	if err != nil {
+		return errors.Wrap(err, "repo.GetDigestSchedule")
+	}
+
+	if schedule.ClientID != clientID {
+		return errors.Wrapf(entities.ErrRecordNotFound, "digest schedule %s", scheduleID)
+	}
+
+	if err = d.repo.DeleteDigestSchedule(ctx, scheduleID); err != nil {
+		return errors.Wrap(err, "repo.DeleteDigestSchedule")
+	}
+
+	return nil
+}
+
+// Compose summarizes the alerts delivered to the client in the period ending now, the hours are taken
+// in the time zone, UTC when it's empty.
+func (d *Digest) Compose(
+	ctx context.Context, clientID string, period entities.DigestPeriod, timezone string,
+) (entities.Digest, error) {
+	if _, _, err := d.client(ctx, clientID); err != nil {
+		return entities.Digest{}, err
+	}
+
+	if period.Duration() == 0 {
+		return entities.Digest{}, errors.Wrapf(ErrInvalidDigest, "unknown period %q", period)
+	}
+
+	schedule := entities.DigestSchedule{ClientID: clientID, Period: period, Timezone: timezone}
+
+	return d.compose(ctx, schedule, time.Now().UTC())
+}
+
+func (d *Digest) compose(ctx context.Context, schedule entities.DigestSchedule, until time.Time) (entities.Digest, error) {
+	location := time.UTC
+	if schedule.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(schedule.Timezone); err != nil {
+			return entities.Digest{}, errors.Wrapf(ErrInvalidDigest, "unknown time zone %q", schedule.Timezone)
+		}
+	}
+
+	from := until.Add(-schedule.Period.Duration())
+
+	deliveries, err := d.repo.ListDeliveries(ctx, entities.DeliveryFilter{ClientID: schedule.ClientID, Since: from, Until: until})
+	if err != nil {
+		return entities.Digest{}, errors.Wrap(err, "repo.ListDeliveries")
+	}
+
+	return entities.NewDigest(schedule.ClientID, from, until, location, deliveries), nil
+}
+
+// Run sends the due digests until the context is canceled. Only the replica holding the lease sends them,
+// owner identifies it.
+func (d *Digest) Run(ctx context.Context, owner string) {
+	ticker := time.NewTicker(_digestPollInterval)
+	defer ticker.Stop()
+
+	for {
+		held, err := d.repo.AcquireLease(ctx, _digestLease, owner, _digestLeaseTTL)
+		if err == nil && held {
+			d.sendDue(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Digest) sendDue(ctx context.Context) {
+	ctx, span := d.tracer.Start(ctx, "uCase.sendDue")
+	defer span.End()
+
+	due, err := d.repo.ListDueDigestSchedules(ctx, time.Now().UTC(), _digestBatchSize)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("digests.due", len(due)))
+
+	for _, schedule := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err = d.send(ctx, schedule)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		// Telegram is down, the digests stay due until it's back.
+		if errors.Is(err, entities.ErrUnavailable) {
+			return
+		}
+	}
+}
+
+// send delivers the digest of the schedule and moves the schedule to its next run. The digest covers
+// the period ending at the scheduled time, the runs missed while the service was down are skipped.
+// A digest for a deleted client removes its schedule.
+func (d *Digest) send(ctx context.Context, schedule entities.DigestSchedule) error {
+	site, org, err := d.client(ctx, schedule.ClientID)
+	if errors.Is(err, entities.ErrRecordNotFound) {
+		return errors.Wrap(d.repo.DeleteDigestSchedule(ctx, schedule.ID.Hex()), "repo.DeleteDigestSchedule")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	digest, err := d.compose(ctx, schedule, schedule.NextRunAt)
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	if account := org.Inherit(site); account.Active() {
+		sendErr = d.sender.SendText(ctx, account, d.text(ctx, digest, schedule.Period))
+		if errors.Is(sendErr, entities.ErrUnavailable) {
+			return errors.Wrap(sendErr, "sender.SendText")
+		}
+	}
+
+	now := time.Now().UTC()
+	if schedule.NextRunAt, err = schedule.Next(now); err != nil {
+		return err
+	}
+
+	if sendErr == nil {
+		schedule.LastRunAt = now
+	}
+
+	if err = d.repo.UpdateDigestSchedule(ctx, schedule); err != nil {
+		return errors.Wrap(err, "repo.UpdateDigestSchedule")
+	}
+
+	return errors.Wrap(sendErr, "sender.SendText")
+}
+
+// text renders the digest, the sensors are named as they are registered.
+func (d *Digest) text(ctx context.Context, digest entities.Digest, period entities.DigestPeriod) string {
+	location := time.UTC
+	if digest.Timezone != "" {
+		location, _ = time.LoadLocation(digest.Timezone)
+	}
+
+	title := "Daily digest"
+	if period == entities.DigestWeekly {
+		title = "Weekly digest"
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s, %s – %s %s\n", title,
+		digest.From.In(location).Format("02 Jan 15:04"), digest.Until.In(location).Format("02 Jan 15:04"), location)
+
+	if digest.Alerts == 0 {
+		b.WriteString("No detections.")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Alerts: %d\n", digest.Alerts)
+
+	fmt.Fprintf(&b, "Acknowledged: %d of %d", digest.Acknowledged, digest.Alerts)
+	if digest.Acknowledged != 0 {
+		fmt.Fprintf(&b, ", median %s after the alert", digest.AckDelay.Round(time.Second))
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "False alarms: %d (%.0f%%), %d reclassified, %d retracted\n",
+		digest.Reclassified+digest.Retracted, digest.FalseAlarmRatio*100, digest.Reclassified, digest.Retracted)
+
+	hours := make([]string, 0, len(digest.ByHour))
+	for hour, count := range digest.ByHour {
+		if count != 0 {
+			hours = append(hours, fmt.Sprintf("%02d:00 %d", hour, count))
+		}
+	}
+
+	fmt.Fprintf(&b, "By hour: %s\n", strings.Join(hours, ", "))
+
+	sensors := make([]string, 0, len(digest.BySensor))
+	for sensorID, count := range digest.BySensor {
+		sensors = append(sensors, fmt.Sprintf("%s %d", d.sensorName(ctx, sensorID), count))
+	}
+
+	sort.Strings(sensors)
+	fmt.Fprintf(&b, "By sensor: %s", strings.Join(sensors, ", "))
+
+	return b.String()
+}
+
+func (d *Digest) sensorName(ctx context.Context, sensorID string) string {
+	if sensorID == "" {
+		return "no sensor"
+	}
+
+	sensor, err := d.repo.GetSensor(ctx, sensorID)
+	if err != nil || sensor.Name == "" {
+		return sensorID
+	}
+
+	return sensor.Name
+}
@@ -0,0 +1,56 @@
+package ucase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type (
+	BreakerStater interface {
+		Status() entities.BreakerStatus
+	}
+
+	ParkedCounter interface {
+		CountParkedNotifications(ctx context.Context) (int64, error)
+	}
+)
+
+// Health reports whether Telegram is reachable and how many notifications wait for it.
+type Health struct {
+	breaker  BreakerStater
+	repo     ParkedCounter
+	fallback bool
+}
+
+func NewHealthUCase(breaker BreakerStater, repo ParkedCounter, fallback bool) *Health {
+	return &Health{
+		breaker:  breaker,
+		repo:     repo,
+		fallback: fallback,
+	}
+}
+
+// Check returns the health, it is degraded while the breaker isn't closed or the storage fails.
+func (h *Health) Check(ctx context.Context) entities.Health {
+	health := entities.Health{
+		Status:   entities.HealthOK,
+		Telegram: h.breaker.Status(),
+		Fallback: h.fallback,
+	}
+
+	parked, err := h.repo.CountParkedNotifications(ctx)
+	if err != nil {
+		health.Error = errors.Wrap(err, "repo.CountParkedNotifications").Error()
+	}
+
+	health.Parked = parked
+
+	if health.Telegram.State != entities.BreakerClosed || health.Error != "" {
+		health.Status = entities.HealthDegraded
+	}
+
+	return health
+}
@@ -0,0 +1,194 @@
+package ucase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// ImportSource yields the rows of a bulk import, Next returns io.EOF after the last row.
+// Any other error aborts the import.
+type ImportSource interface {
+	Next() (entities.ImportRow, error)
+}
+
+// ErrUnreadableImport is returned when the import source fails, for example on a malformed file header.
+var ErrUnreadableImport = errors.New("unreadable import")
+
+// Import registers or updates every valid row of the source. Invalid rows are reported and skipped,
+// the import is aborted only when the source or the storage fails.
+func (c Client) Import(ctx context.Context, src ImportSource, dryRun bool) (entities.ImportReport, error) {
+	report := entities.ImportReport{DryRun: dryRun, Rows: make([]entities.ImportResult, 0)}
+	seen := make(map[primitive.ObjectID]int)
+	bots := make(map[string]bool)
+	orgs := make(map[string]*entities.Organization)
+
+	for {
+		row, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+
+		if err != nil {
+			return report, fmt.Errorf("%w: %v", ErrUnreadableImport, err)
+		}
+
+		result, err := c.importRow(ctx, row, dryRun, seen, bots, orgs)
+		if err != nil {
+			return report, errors.Wrapf(err, "line %d", row.Line)
+		}
+
+		report.Add(result)
+	}
+}
+
+func (c Client) importRow(
+	ctx context.Context, row entities.ImportRow, dryRun bool, seen map[primitive.ObjectID]int, bots map[string]bool,
+	orgs map[string]*entities.Organization,
+) (entities.ImportResult, error) {
+	result := entities.ImportResult{Line: row.Line, Status: entities.ImportInvalid}
+	account := row.Account
+
+	if !account.ClientID.IsZero() {
+		result.ClientID = account.ClientID.Hex()
+	}
+
+	invalid, err := c.validateRow(ctx, row, seen, bots, orgs)
+	if err != nil {
+		return result, err
+	}
+
+	if invalid != "" {
+		result.Error = invalid
+		return result, nil
+	}
+
+	seen[account.ClientID] = row.Line
+	account.Filters, _ = parseRules(account.Rules)
+
+	existing, err := c.repo.GetAccountByClientID(ctx, account.ClientID.Hex())
+	if err == nil && existing.ChatID == account.ChatID && existing.BotID == account.BotID {
+		// The import doesn't carry the deactivation, the same chat stays inactive until the bot is added back.
+		account.Deactivation = existing.Deactivation
+	}
+
+	switch {
+	case errors.Is(err, entities.ErrRecordNotFound):
+		result.Status = entities.ImportCreated
+	case err != nil:
+		return result, errors.Wrap(err, "repo.GetAccountByClientID")
+	case existing.Equal(account):
+		result.Status = entities.ImportUnchanged
+		return result, nil
+	default:
+		result.Status = entities.ImportUpdated
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	err = c.outbox.Transaction(ctx, func(ctx context.Context) error {
+		created, err := c.repo.UpsertAccount(ctx, account)
+		if err != nil {
+			return errors.Wrap(err, "repo.UpsertAccount")
+		}
+
+		// Another writer may have won the race since the lookup above.
+		eventType := entities.EventClientUpdated
+		result.Status = entities.ImportUpdated
+		if created {
+			eventType = entities.EventClientCreated
+			result.Status = entities.ImportCreated
+		}
+
+		return c.outbox.Record(ctx, eventType, account.ClientID.Hex(), account)
+	})
+
+	return result, err
+}
+
+// validateRow returns why the row can't be imported, empty for a valid row. The bots and the organizations
+// are looked up once per import, a missing organization is cached as nil.
+func (c Client) validateRow(
+	ctx context.Context, row entities.ImportRow, seen map[primitive.ObjectID]int, bots map[string]bool,
+	orgs map[string]*entities.Organization,
+) (string, error) {
+	account := row.Account
+
+	switch {
+	case row.Err != nil:
+		return row.Err.Error(), nil
+	case account.ClientID.IsZero():
+		return "clientID is required", nil
+	case account.ChatID == 0 && account.OrganizationID == "":
+		return "chatID is required", nil
+	case account.OrganizationID != "" && !primitive.IsValidObjectID(account.OrganizationID):
+		return "organizationID is invalid", nil
+	}
+
+	if _, invalid := parseRules(account.Rules); invalid != "" {
+		return invalid, nil
+	}
+
+	if line, ok := seen[account.ClientID]; ok {
+		return fmt.Sprintf("duplicate of line %d", line), nil
+	}
+
+	if account.OrganizationID != "" {
+		org, checked := orgs[account.OrganizationID]
+		if !checked {
+			found, err := c.repo.GetOrganization(ctx, account.OrganizationID)
+			if err != nil && !errors.Is(err, entities.ErrRecordNotFound) {
+				return "", errors.Wrap(err, "repo.GetOrganization")
+			}
+
+			if err == nil {
+				org = &found
+			}
+			orgs[account.OrganizationID] = org
+		}
+
+		switch {
+		case org == nil:
+			return "the organization not found", nil
+		case account.ChatID == 0 && org.ChatID == 0:
+			return "chatID is required, the organization has no chat", nil
+		}
+	}
+
+	if account.BotID == "" {
+		return "", nil
+	}
+
+	exists, checked := bots[account.BotID]
+	if !checked {
+		_, err := c.repo.GetBot(ctx, account.BotID)
+		if err != nil && !errors.Is(err, entities.ErrRecordNotFound) {
+			return "", errors.Wrap(err, "repo.GetBot")
+		}
+
+		exists = err == nil
+		bots[account.BotID] = exists
+	}
+
+	if !exists {
+		return "the bot not found", nil
+	}
+
+	return "", nil
+}
+
+// Export calls fn for every registration, it stops at the first error of fn.
+func (c Client) Export(ctx context.Context, fn func(entities.TGAccount) error) error {
+	if err := c.repo.ForEachAccount(ctx, fn); err != nil {
+		return errors.Wrap(err, "repo.ForEachAccount")
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package ucase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	_membersLeaseTTL = time.Minute
+	_membersLease    = "chat-members"
+)
+
+// ErrInactiveAccount is returned for an alert to an account deactivated because its chat rejects the bot.
+var ErrInactiveAccount = errors.New("error account is inactive")
+
+type (
+	ChatStatusRepo interface {
+		// SetChatDeactivation deactivates the active accounts of the bot in the chat, or reactivates
+		// the inactive ones for a nil deactivation. It returns the client IDs of the changed accounts.
+		SetChatDeactivation(ctx context.Context, botID string, chatID int64, deactivation *entities.Deactivation) ([]string, error)
+		ListBots(ctx context.Context, tenantID string) ([]entities.Bot, error)
+		// GetUpdateOffset returns the offset of the next update of the bot to poll, zero when it's unknown.
+		GetUpdateOffset(ctx context.Context, botID string) (int, error)
+		SetUpdateOffset(ctx context.Context, botID string, offset int) error
+	}
+
+	// MembershipNotifier reports the changes of the bot membership in chats and tells the admins about them.
+	MembershipNotifier interface {
+		ChatMemberUpdates(ctx context.Context, botID string, offset int) ([]entities.ChatMemberUpdate, int, error)
+		NotifyAdmin(ctx context.Context, botID, text string) error
+	}
+)
+
+// unreachable deactivates the accounts of the chat that rejected the alert for good.
+func (n *Notify) unreachable(ctx context.Context, account entities.TGAccount, chatID int64, cause error) {
+	update := entities.ChatMemberUpdate{
+		BotID:  account.BotID,
+		ChatID: chatID,
+		Reason: entities.UnreachableReason(cause),
+		At:     time.Now().UTC(),
+	}
+
+	if err := n.setChatStatus(ctx, update); err != nil {
+		trace.SpanFromContext(ctx).RecordError(errors.Wrap(err, "setChatStatus"))
+	}
+}
+
+// setChatStatus deactivates or reactivates the accounts of the chat with an outbox event for each changed
+// account, then tells the admin of the bot which clients are affected.
+func (n *Notify) setChatStatus(ctx context.Context, update entities.ChatMemberUpdate) error {
+	change := entities.ChatStatusChange{BotID: update.BotID, ChatID: update.ChatID, At: update.At}
+
+	var deactivation *entities.Deactivation
+	eventType := entities.EventClientReactivated
+	if !update.Member {
+		deactivation = &entities.Deactivation{Reason: update.Reason, At: update.At}
+		change.Reason = update.Reason
+		eventType = entities.EventClientDeactivated
+	}
+
+	err := n.outbox.Transaction(ctx, func(ctx context.Context) error {
+		clientIDs, err := n.repo.SetChatDeactivation(ctx, update.BotID, update.ChatID, deactivation)
+		if err != nil {
+			return errors.Wrap(err, "repo.SetChatDeactivation")
+		}
+
+		change.ClientIDs = clientIDs
+		for _, clientID := range clientIDs {
+			if err = n.outbox.Record(ctx, eventType, clientID, change); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil || len(change.ClientIDs) == 0 {
+		return err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent(eventType, trace.WithAttributes(
+		attribute.Int64("chat.id", change.ChatID),
+		attribute.Int("chat.accounts", len(change.ClientIDs)),
+	))
+
+	if err = n.notifier.NotifyAdmin(ctx, update.BotID, adminText(change)); err != nil {
+		return errors.Wrap(err, "notifier.NotifyAdmin")
+	}
+
+	return nil
+}
+
+func adminText(change entities.ChatStatusChange) string {
+	clients := strings.Join(change.ClientIDs, ", ")
+
+	if change.Reason == "" {
+		return fmt.Sprintf("The bot is back in chat %d, alerts resume for the clients: %s.", change.ChatID, clients)
+	}
+
+	return fmt.Sprintf(
+		"Alerts to chat %d stopped: %s. The clients %s stay inactive until the bot is added back to the chat.",
+		change.ChatID, change.Reason, clients,
+	)
+}
+
+// RunMemberUpdates follows the membership of the bots in chats until the context is canceled, the chats
+// the bot is added back to are reactivated and the ones it is removed from deactivated. Only the bots
+// that opt in are polled, pollDefault opts in the default one. Only the replica holding the lease polls
+// Telegram, owner identifies it, and the offsets are kept in the repository for the next holder.
+func (n *Notify) RunMemberUpdates(ctx context.Context, owner string, interval time.Duration, pollDefault bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		held, err := n.repo.AcquireLease(ctx, _membersLease, owner, _membersLeaseTTL)
+		if err == nil && held {
+			n.pollMembers(ctx, pollDefault)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Notify) pollMembers(ctx context.Context, pollDefault bool) {
+	ctx, span := n.tracer.Start(ctx, "uCase.pollMembers")
+	defer span.End()
+
+	bots, err := n.repo.ListBots(ctx, "")
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "repo.ListBots"))
+		return
+	}
+
+	botIDs := make([]string, 0, len(bots)+1)
+	if pollDefault {
+		botIDs = append(botIDs, "")
+	}
+
+	for _, registered := range bots {
+		if registered.PollUpdates {
+			botIDs = append(botIDs, registered.ID.Hex())
+		}
+	}
+
+	for _, botID := range botIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err = n.pollBotMembers(ctx, botID); err != nil {
+			span.RecordError(err)
+		}
+	}
+}
+
+// pollBotMembers applies the membership changes of the bot after the stored offset, the offset moves on
+// once they are applied.
+func (n *Notify) pollBotMembers(ctx context.Context, botID string) error {
+	offset, err := n.repo.GetUpdateOffset(ctx, botID)
+	if err != nil {
+		return errors.Wrap(err, "repo.GetUpdateOffset")
+	}
+
+	updates, next, err := n.notifier.ChatMemberUpdates(ctx, botID, offset)
+	if err != nil {
+		return errors.Wrap(err, "notifier.ChatMemberUpdates")
+	}
+
+	var applyErr error
+	for _, update := range updates {
+		applyErr = multierr.Append(applyErr, n.setChatStatus(ctx, update))
+	}
+
+	if next != offset {
+		if err = n.repo.SetUpdateOffset(ctx, botID, next); err != nil {
+			applyErr = multierr.Append(applyErr, errors.Wrap(err, "repo.SetUpdateOffset"))
+		}
+	}
+
+	return applyErr
+}
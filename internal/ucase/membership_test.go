@@ -0,0 +1,131 @@
+package ucase_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/config"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/bot"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/bot/telegramtest"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/infrastucture/repository/memory"
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/ucase"
+)
+
+func newMembership(t *testing.T) (*ucase.Notify, *memory.Repository, *telegramtest.Server) {
+	t.Helper()
+
+	telegram := telegramtest.NewServer()
+	t.Cleanup(telegram.Close)
+
+	cfg := config.BotConfig{
+		Token:           "token",
+		APIEndpoint:     telegram.Endpoint(),
+		RateLimit:       100,
+		RateBurst:       10,
+		BreakerFailures: 5,
+		BreakerCooldown: time.Minute,
+	}
+
+	repo := memory.NewRepository()
+
+	registry := bot.NewRegistry(cfg, http.DefaultClient, repo, nil)
+
+	tgBot, err := bot.NewBot(cfg, "Shot near {{.ClientID}}", registry, nil, nil)
+	if err != nil {
+		t.Fatalf("NewBot: %v", err)
+	}
+
+	return ucase.NewNotifyUCase(repo, tgBot, nil, nil, time.Hour), repo, telegram
+}
+
+func createAccount(t *testing.T, repo *memory.Repository, chatID int64) entities.TGAccount {
+	t.Helper()
+
+	account := entities.TGAccount{ClientID: primitive.NewObjectID(), ChatID: chatID}
+	if err := repo.Create(context.Background(), account); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	return account
+}
+
+func active(t *testing.T, repo *memory.Repository, account entities.TGAccount) bool {
+	t.Helper()
+
+	got, err := repo.GetAccountByClientID(context.Background(), account.ClientID.Hex())
+	if err != nil {
+		t.Fatalf("GetAccountByClientID: %v", err)
+	}
+
+	return got.Active()
+}
+
+func TestMemberUpdatesOptIn(t *testing.T) {
+	notify, repo, telegram := newMembership(t)
+	account := createAccount(t, repo, -100)
+	telegram.AddChatMemberUpdate("token", -100, "supergroup", "kicked")
+
+	// A bot registered without PollUpdates isn't polled either.
+	err := repo.CreateBot(context.Background(), entities.Bot{ID: primitive.NewObjectID(), Username: "other_bot", Token: "other"})
+	if err != nil {
+		t.Fatalf("CreateBot: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	notify.RunMemberUpdates(ctx, "replica", 10*time.Millisecond, false)
+
+	if calls := telegram.Calls("getUpdates"); len(calls) != 0 {
+		t.Fatalf("%d getUpdates calls without an opted in bot, want none", len(calls))
+	}
+
+	if !active(t, repo, account) {
+		t.Fatal("account deactivated without polling")
+	}
+}
+
+func TestMemberUpdatesStoredOffset(t *testing.T) {
+	notify, repo, telegram := newMembership(t)
+	skipped := createAccount(t, repo, -100)
+	removed := createAccount(t, repo, -200)
+	telegram.AddChatMemberUpdate("token", -100, "supergroup", "kicked")
+	telegram.AddChatMemberUpdate("token", -200, "supergroup", "kicked")
+
+	// Another replica applied the first update before it lost the lease.
+	if err := repo.SetUpdateOffset(context.Background(), "", 2); err != nil {
+		t.Fatalf("SetUpdateOffset: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		notify.RunMemberUpdates(ctx, "replica", 10*time.Millisecond, true)
+	}()
+
+	calls, err := telegram.WaitCalls("getUpdates", 2, 5*time.Second)
+	cancel()
+	<-done
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls[0].Params["offset"] != "2" {
+		t.Fatalf("first getUpdates with offset %q, want the stored 2", calls[0].Params["offset"])
+	}
+
+	if !active(t, repo, skipped) || active(t, repo, removed) {
+		t.Fatal("want only the chat of the update after the stored offset deactivated")
+	}
+
+	if offset, err := repo.GetUpdateOffset(context.Background(), ""); err != nil || offset != 3 {
+		t.Fatalf("GetUpdateOffset: want 3, got %d, %v", offset, err)
+	}
+}
@@ -2,52 +2,481 @@ package ucase
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
 
 	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
 )
 
 type (
-	ChatIDGetter interface {
-		GetChatIDByClientID(ctx context.Context, clientID string) (int64, error)
+	AccountGetter interface {
+		GetAccountByClientID(ctx context.Context, clientID string) (entities.TGAccount, error)
+	}
+
+	SensorGetter interface {
+		GetSensor(ctx context.Context, sensorID string) (entities.Sensor, error)
+	}
+
+	DeliveryRepo interface {
+		SaveDelivery(ctx context.Context, delivery entities.Delivery) error
+		ListDeliveries(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error)
+	}
+
+	NotifyRepo interface {
+		AccountGetter
+		SensorGetter
+		DeliveryRepo
+		ParkedRepo
+		ChatMigrationRepo
+		ChatStatusRepo
+		SiteRepo
+		ZoneLookup
 	}
 
 	ClientNotifier interface {
-		NotifyClient(ctx context.Context, chatID int64, msg entities.NotificationMessage) error
+		NotifyClient(ctx context.Context, account entities.TGAccount, msg entities.NotificationMessage) (entities.Delivery, error)
+		EditAlert(ctx context.Context, delivery entities.Delivery, reason string) error
+		// DeleteAlert returns the delivery with the IDs of the deleted messages zeroed, the progress so far
+		// on a failure.
+		DeleteAlert(ctx context.Context, delivery entities.Delivery, reason string) (entities.Delivery, error)
+		MembershipNotifier
 	}
 )
 
+// DeliveryError is a failure of Telegram to deliver an alert, its message is the one of the Bot API.
+type DeliveryError struct {
+	Err error
+}
+
+func (e *DeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoDelivery is returned for a follow-up whose alert was never delivered, or not yet.
+var ErrNoDelivery = errors.New("error no delivered alert for the request")
+
 type Notify struct {
-	repo     ChatIDGetter
-	notifier ClientNotifier
-	tracer   trace.Tracer
+	repo      NotifyRepo
+	notifier  ClientNotifier
+	outbox    *Outbox
+	fallback  FallbackNotifier
+	maxAge    time.Duration
+	tracer    trace.Tracer
+	muteRules atomic.Pointer[[]entities.MuteRule]
 }
 
-func NewNotifyUCase(repo ChatIDGetter, notifier ClientNotifier) *Notify {
+// NewNotifyUCase creates the use case, the delivery outcomes are recorded in the outbox unless it's nil.
+// The notifications Telegram is unavailable for are parked and retried until they are older than maxAge,
+// the alerts among them also go to the fallback unless it's nil.
+func NewNotifyUCase(
+	repo NotifyRepo, notifier ClientNotifier, outbox *Outbox, fallback FallbackNotifier, maxAge time.Duration,
+) *Notify {
 	return &Notify{
 		repo:     repo,
 		notifier: notifier,
+		outbox:   outbox,
+		fallback: fallback,
+		maxAge:   maxAge,
 		tracer:   otel.Tracer("notifyUCase"),
 	}
 }
 
-func (n Notify) Notify(ctx context.Context, msg entities.NotificationMessage) error {
+// SetMuteRules replaces the rules used to suppress notifications, it is safe to call at runtime.
+func (n *Notify) SetMuteRules(rules []entities.MuteRule) {
+	n.muteRules.Store(&rules)
+}
+
+func (n *Notify) muted(msg entities.NotificationMessage) bool {
+	rules := n.muteRules.Load()
+	if rules == nil {
+		return false
+	}
+
+	now := time.Now()
+	for _, rule := range *rules {
+		if rule.Matches(msg, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve returns the clients the message is addressed to. A message carrying a sensor ID goes to the owners
+// of the sensor and is enriched with the sensor metadata it doesn't have yet, a test alert only goes to its
+// client and the sensor must belong to it.
+func (n *Notify) resolve(ctx context.Context, msg entities.NotificationMessage) ([]string, entities.NotificationMessage, error) {
+	if msg.SensorID == "" {
+		return []string{msg.ClientID}, msg, nil
+	}
+
+	sensor, err := n.repo.GetSensor(ctx, msg.SensorID)
+	if err != nil {
+		return nil, msg, errors.Wrap(err, "repo.GetSensor")
+	}
+
+	if msg.Test {
+		if !containsString(sensor.ClientIDs, msg.ClientID) {
+			return nil, msg, errors.Wrapf(ErrInvalidSensor, "sensor %s doesn't belong to the client", msg.SensorID)
+		}
+
+		return []string{msg.ClientID}, withSensor(msg, sensor), nil
+	}
+
+	if !sensor.Enabled {
+		return nil, msg, nil
+	}
+
+	msg = withSensor(msg, sensor)
+
+	recipients := sensor.ClientIDs
+	if msg.ClientID != "" && !containsString(recipients, msg.ClientID) {
+		recipients = append([]string{msg.ClientID}, recipients...)
+	}
+
+	return recipients, msg, nil
+}
+
+// withSensor fills the sensor metadata the message doesn't have yet.
+func withSensor(msg entities.NotificationMessage, sensor entities.Sensor) entities.NotificationMessage {
+	if msg.SensorName == "" {
+		msg.SensorName = sensor.Name
+	}
+	if msg.Address == "" {
+		msg.Address = sensor.Address
+	}
+	if msg.Location == nil {
+		msg.Location = sensor.Location
+	}
+	if len(msg.SensorTags) == 0 {
+		msg.SensorTags = sensor.Tags
+	}
+
+	return msg
+}
+
+func (n *Notify) Notify(ctx context.Context, msg entities.NotificationMessage) error {
 	ctx, span := n.tracer.Start(ctx, "uCase.Notify")
 	defer span.End()
 
-	chatID, err := n.repo.GetChatIDByClientID(ctx, msg.ClientID)
-	if err != nil {
+	if _, err := n.notifyMessage(ctx, msg); err != nil {
 		span.RecordError(err)
-		return errors.Wrap(err, "repo.GetChatIDByClientID")
+		return err
+	}
+
+	return nil
+}
+
+// notifyMessage sends the message to its recipients and to the organizations and zones they are part of,
+// it returns the deliveries to the recipients. A test alert goes no further than its client.
+func (n *Notify) notifyMessage(ctx context.Context, msg entities.NotificationMessage) ([]entities.Delivery, error) {
+	span := trace.SpanFromContext(ctx)
+
+	if msg.IsFollowUp() {
+		err := n.revise(ctx, msg)
+		if errors.Is(err, entities.ErrUnavailable) || errors.Is(err, ErrNoDelivery) {
+			// The chats already revised are skipped when the follow-up is retried, an alert still in flight
+			// or parked is waited for.
+			err = n.park(ctx, msg, err)
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "revise")
+		}
+
+		return nil, nil
+	}
+
+	recipients, msg, err := n.resolve(ctx, msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolve")
+	}
+
+	span.SetAttributes(attribute.Int("notify.recipients", len(recipients)))
+
+	var (
+		deliveries []entities.Delivery
+		notifyErr  error
+	)
+	for _, clientID := range recipients {
+		clientMsg := msg
+		clientMsg.ClientID = clientID
+
+		delivery, err := n.notify(ctx, clientMsg)
+		if err != nil {
+			notifyErr = multierr.Append(notifyErr, errors.Wrapf(err, "client %s", clientID))
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if msg.Test {
+		return deliveries, notifyErr
+	}
+
+	chats := make(map[chat]bool)
+
+	copies, err := n.organizationCopies(ctx, msg, recipients, chats)
+	if err != nil {
+		notifyErr = multierr.Append(notifyErr, err)
+	}
+
+	span.SetAttributes(attribute.Int("notify.organizations", len(copies)))
+
+	for _, orgMsg := range copies {
+		if _, err = n.notify(ctx, orgMsg); err != nil {
+			notifyErr = multierr.Append(notifyErr, errors.Wrapf(err, "organization %s", orgMsg.OrganizationID))
+		}
+	}
+
+	zoneCopies, err := n.zoneCopies(ctx, msg, chats)
+	if err != nil {
+		notifyErr = multierr.Append(notifyErr, err)
+	}
+
+	span.SetAttributes(attribute.Int("notify.zones", len(zoneCopies)))
+
+	for _, zoneMsg := range zoneCopies {
+		if _, err = n.notify(ctx, zoneMsg); err != nil {
+			notifyErr = multierr.Append(notifyErr, errors.Wrapf(err, "zone subscription %s", zoneMsg.ZoneSubscriptionID))
+		}
 	}
 
-	if err = n.notifier.NotifyClient(ctx, chatID, msg); err != nil {
+	return deliveries, notifyErr
+}
+
+// notify sends the message to a single chat. The outcome of a test alert is returned as it is, mute rules
+// don't apply to it and it isn't parked.
+func (n *Notify) notify(ctx context.Context, msg entities.NotificationMessage) (entities.Delivery, error) {
+	if !msg.Test && n.muted(msg) {
+		trace.SpanFromContext(ctx).AddEvent("muted", trace.WithAttributes(attribute.String("clientID", msg.ClientID)))
+		return entities.Delivery{}, nil
+	}
+
+	delivery, unsent, err := n.send(ctx, msg)
+	if msg.Test {
+		return delivery, err
+	}
+
+	if errors.Is(err, ErrInactiveAccount) {
+		trace.SpanFromContext(ctx).AddEvent("inactive", trace.WithAttributes(attribute.String("clientID", msg.ClientID)))
+		return delivery, nil
+	}
+
+	if suppressed(ctx, msg, err) {
+		return delivery, nil
+	}
+
+	if !unsent {
+		return delivery, err
+	}
+
+	if parkErr := n.park(ctx, msg, err); parkErr != nil {
+		return delivery, parkErr
+	}
+
+	n.notifyFallback(ctx, msg, err)
+
+	return delivery, nil
+}
+
+// send delivers the message and records the outcome, the outcome of a test alert isn't recorded. unsent is
+// true when nothing reached the chat because Telegram was unavailable, the message can be sent again then.
+func (n *Notify) send(
+	ctx context.Context, msg entities.NotificationMessage,
+) (delivery entities.Delivery, unsent bool, err error) {
+	delivery, deliverErr := n.deliver(ctx, msg)
+	if delivery.MessageID == 0 && errors.Is(deliverErr, entities.ErrUnavailable) {
+		return delivery, true, deliverErr
+	}
+
+	if msg.Test {
+		return delivery, false, deliverErr
+	}
+
+	if delivery.MessageID != 0 {
+		if err := n.saveDelivery(ctx, delivery); err != nil {
+			return delivery, false, err
+		}
+	}
+
+	var failure *DeliveryError
+	if errors.As(deliverErr, &failure) {
+		event := entities.DeliveryFailure{
+			RequestID: delivery.RequestID,
+			ClientID:  delivery.ClientID,
+			ChatID:    delivery.ChatID,
+			Error:     failure.Error(),
+		}
+
+		if err := n.outbox.Record(ctx, entities.EventDeliveryFailed, delivery.ClientID, event); err != nil {
+			return delivery, false, multierr.Append(deliverErr, err)
+		}
+	}
+
+	return delivery, false, deliverErr
+}
+
+// saveDelivery stores the delivery with the outbox event of its status.
+func (n *Notify) saveDelivery(ctx context.Context, delivery entities.Delivery) error {
+	return n.outbox.Transaction(ctx, func(ctx context.Context) error {
+		if err := n.repo.SaveDelivery(ctx, delivery); err != nil {
+			return errors.Wrap(err, "repo.SaveDelivery")
+		}
+
+		return n.outbox.Record(ctx, "delivery."+string(delivery.Status), delivery.ClientID, delivery)
+	})
+}
+
+// deliver sends the message to the chat of the client, a failure of Telegram is returned as a DeliveryError.
+// A site gets the settings it leaves empty from its organization, the copy for the organization goes to
+// the organization chat and the copy for a zone subscription to the subscribed chat. A delivery to another
+// chat than the registered one means the chat was migrated, the accounts or the organization follow it.
+// A chat of a client rejecting the bot for good is deactivated, nothing is sent to an inactive account
+// nor when the alert doesn't meet the filter rules of the client.
+func (n *Notify) deliver(ctx context.Context, msg entities.NotificationMessage) (entities.Delivery, error) {
+	if msg.ZoneSubscriptionID != "" {
+		return n.deliverSubscription(ctx, msg)
+	}
+
+	site, org, err := n.recipient(ctx, msg)
+	if err != nil {
+		return entities.Delivery{}, err
+	}
+
+	// Only a chat and a bot of the site itself are matched by the stored accounts on a migration
+	// or a deactivation, a site using the bot of its organization is left as it is.
+	account := org.Inherit(site)
+	orgChat, own := site.ChatID == 0, site.ChatID != 0 && site.BotID == account.BotID
+
+	if !account.Active() {
+		return entities.Delivery{}, errors.Wrapf(ErrInactiveAccount, "chat rejects the bot: %s", account.Deactivation.Reason)
+	}
+
+	if err = suppress(account.FilterRules(), msg); err != nil {
+		return entities.Delivery{}, err
+	}
+
+	delivery, err := n.notifier.NotifyClient(ctx, account, msg)
+	if delivery.MessageID != 0 && delivery.ChatID != account.ChatID {
+		// The alert is out already, a failure to store the new chat is retried by the next alert.
+		if orgChat {
+			if migrateErr := n.migrateOrganization(ctx, org, delivery.ChatID); migrateErr != nil {
+				trace.SpanFromContext(ctx).RecordError(errors.Wrap(migrateErr, "migrateOrganization"))
+			}
+		} else if own {
+			if migrateErr := n.migrateChat(ctx, account, delivery.ChatID); migrateErr != nil {
+				trace.SpanFromContext(ctx).RecordError(errors.Wrap(migrateErr, "migrateChat"))
+			}
+		}
+	}
+
+	if delivery.MessageID == 0 && errors.Is(err, entities.ErrUnreachable) && own {
+		n.unreachable(ctx, account, delivery.ChatID, err)
+	}
+
+	if err != nil {
+		return delivery, &DeliveryError{Err: errors.Wrap(err, "notifier.NotifyClient")}
+	}
+
+	return delivery, nil
+}
+
+// Test sends a labelled test alert to the client through the path of Notify. Mute rules don't apply, the
+// delivery isn't recorded and the alert isn't parked nor copied to organizations and zones. With a sensor
+// the alert carries its metadata and location, the sensor must belong to the client.
+func (n *Notify) Test(ctx context.Context, clientID, sensorID string) (entities.Delivery, error) {
+	ctx, span := n.tracer.Start(ctx, "uCase.Test")
+	defer span.End()
+
+	msg := entities.NotificationMessage{
+		NotificationMethods: []string{"telegram"},
+		Timestamp:           time.Now(),
+		RequestID:           uuid.New(),
+		ClientID:            clientID,
+		SensorID:            sensorID,
+		MessageType:         "text",
+		Test:                true,
+	}
+
+	deliveries, err := n.notifyMessage(ctx, msg)
+
+	var delivery entities.Delivery
+	if len(deliveries) != 0 {
+		delivery = deliveries[0]
+	}
+
+	if err != nil {
 		span.RecordError(err)
-		return errors.Wrap(err, "notifier.NotifyClient")
+		return delivery, err
 	}
 
-	return nil
+	return delivery, nil
+}
+
+// revise applies an update or a retraction to every chat the alert of the request was delivered to.
+func (n *Notify) revise(ctx context.Context, msg entities.NotificationMessage) error {
+	deliveries, err := n.repo.ListDeliveries(ctx, entities.DeliveryFilter{RequestID: msg.RequestID.String()})
+	if err != nil {
+		return errors.Wrap(err, "repo.ListDeliveries")
+	}
+
+	if len(deliveries) == 0 {
+		return ErrNoDelivery
+	}
+
+	var reviseErr error
+	for _, delivery := range deliveries {
+		// Telegram rejects an edit that doesn't change the message, so repeated follow-ups are skipped.
+		if delivery.Status == entities.DeliveryRetracted ||
+			(delivery.Status == entities.DeliveryUpdated && msg.Kind == entities.KindUpdate) {
+			continue
+		}
+
+		revised := delivery
+		switch msg.Kind {
+		case entities.KindUpdate:
+			err = n.notifier.EditAlert(ctx, delivery, msg.Reason)
+			revised.Status = entities.DeliveryUpdated
+		case entities.KindRetract:
+			revised, err = n.notifier.DeleteAlert(ctx, delivery, msg.Reason)
+			revised.Status = entities.DeliveryRetracted
+		}
+
+		switch {
+		case err == nil:
+			err = n.saveDelivery(ctx, revised)
+		case msg.Kind == entities.KindRetract && revised != delivery:
+			// The replies deleted so far aren't deleted again when the retraction is retried.
+			revised.Status = delivery.Status
+			err = multierr.Append(err, n.saveDelivery(ctx, revised))
+		}
+
+		if err != nil {
+			reviseErr = multierr.Append(reviseErr, errors.Wrapf(err, "chat %d", delivery.ChatID))
+		}
+	}
+
+	return reviseErr
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,175 @@
+package ucase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+var (
+	ErrInvalidOrganization = errors.New("error invalid organization")
+	ErrOrganizationInUse   = errors.New("error organization has sites")
+)
+
+type OrganizationRepo interface {
+	OrganizationGetter
+	CreateOrganization(ctx context.Context, org entities.Organization) error
+	ListOrganizations(ctx context.Context, tenantID string) ([]entities.Organization, error)
+	UpdateOrganization(ctx context.Context, org entities.Organization) error
+	DeleteOrganization(ctx context.Context, orgID string) error
+	ListAccounts(ctx context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error)
+	CountAccounts(ctx context.Context, filter entities.AccountFilter) (int64, error)
+	GetBot(ctx context.Context, botID string) (entities.Bot, error)
+}
+
+// Organization manages the companies owning many sites, the sites are the clients registered with
+// the organization ID.
+type Organization struct {
+	repo OrganizationRepo
+}
+
+func NewOrganizationUCase(repo OrganizationRepo) *Organization {
+	return &Organization{
+		repo: repo,
+	}
+}
+
+// validate checks the organization, its bot must belong to the same tenant.
+func (o Organization) validate(ctx context.Context, org entities.Organization) error {
+	if strings.TrimSpace(org.TenantID) == "" {
+		return errors.Wrap(ErrInvalidOrganization, "tenantID is required")
+	}
+
+	if strings.TrimSpace(org.Name) == "" {
+		return errors.Wrap(ErrInvalidOrganization, "name is required")
+	}
+
+	if org.ThreadID != 0 && org.ChatID == 0 {
+		return errors.Wrap(ErrInvalidOrganization, "threadID needs a chatID")
+	}
+
+	if org.BotID == "" {
+		return nil
+	}
+
+	bot, err := o.repo.GetBot(ctx, org.BotID)
+	if err != nil {
+		return errors.Wrap(err, "repo.GetBot")
+	}
+
+	if bot.TenantID != org.TenantID {
+		return errors.Wrapf(ErrInvalidOrganization, "bot %s belongs to another tenant", org.BotID)
+	}
+
+	return nil
+}
+
+// Create stores a new organization, a tenant scoped call creates it for its own tenant.
+func (o Organization) Create(ctx context.Context, org entities.Organization) (entities.Organization, error) {
+	if tenantID, ok := TenantFromContext(ctx); ok {
+		org.TenantID = tenantID
+	}
+
+	if err := o.validate(ctx, org); err != nil {
+		return entities.Organization{}, err
+	}
+
+	org.ID = primitive.NewObjectID()
+	org.CreatedAt = time.Now().UTC()
+
+	if err := o.repo.CreateOrganization(ctx, org); err != nil {
+		return entities.Organization{}, errors.Wrap(err, "repo.CreateOrganization")
+	}
+
+	return org, nil
+}
+
+func (o Organization) Get(ctx context.Context, orgID string) (entities.Organization, error) {
+	org, err := o.repo.GetOrganization(ctx, orgID)
+	if err != nil {
+		return org, errors.Wrap(err, "repo.GetOrganization")
+	}
+
+	if !visible(ctx, org.TenantID) {
+		return entities.Organization{}, errors.Wrapf(entities.ErrRecordNotFound, "organization %s", orgID)
+	}
+
+	return org, nil
+}
+
+// List returns the organizations of the tenant, all of them for an empty one. A tenant scoped call
+// lists its own tenant only.
+func (o Organization) List(ctx context.Context, tenantID string) ([]entities.Organization, error) {
+	if scope, ok := TenantFromContext(ctx); ok {
+		tenantID = scope
+	}
+
+	orgs, err := o.repo.ListOrganizations(ctx, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListOrganizations")
+	}
+
+	return orgs, nil
+}
+
+// Update replaces the settings of the organization, it stays with its tenant.
+func (o Organization) Update(ctx context.Context, org entities.Organization) error {
+	existing, err := o.Get(ctx, org.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	org.TenantID = existing.TenantID
+	org.CreatedAt = existing.CreatedAt
+
+	if err = o.validate(ctx, org); err != nil {
+		return err
+	}
+
+	if err = o.repo.UpdateOrganization(ctx, org); err != nil {
+		return errors.Wrap(err, "repo.UpdateOrganization")
+	}
+
+	return nil
+}
+
+// Delete removes an organization that has no sites anymore.
+func (o Organization) Delete(ctx context.Context, orgID string) error {
+	if _, err := o.Get(ctx, orgID); err != nil {
+		return err
+	}
+
+	count, err := o.repo.CountAccounts(ctx, entities.AccountFilter{OrganizationID: orgID})
+	if err != nil {
+		return errors.Wrap(err, "repo.CountAccounts")
+	}
+
+	if count != 0 {
+		return errors.Wrapf(ErrOrganizationInUse, "%d sites", count)
+	}
+
+	if err = o.repo.DeleteOrganization(ctx, orgID); err != nil {
+		return errors.Wrap(err, "repo.DeleteOrganization")
+	}
+
+	return nil
+}
+
+// ListSites returns the clients registered as sites of the organization, ordered by client ID.
+func (o Organization) ListSites(ctx context.Context, orgID string) ([]entities.TGAccount, error) {
+	if _, err := o.Get(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	sites, err := o.repo.ListAccounts(ctx, entities.AccountFilter{OrganizationID: orgID})
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListAccounts")
+	}
+
+	return sites, nil
+}
@@ -0,0 +1,194 @@
+package ucase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	_outboxBatchSize       = 100
+	_outboxPollInterval    = time.Second
+	_outboxLeaseTTL        = 30 * time.Second
+	_outboxCleanupInterval = time.Hour
+	_outboxLease           = "outbox-relay"
+)
+
+type (
+	OutboxRepo interface {
+		// InTransaction runs fn in a transaction, the repository calls made with the context fn gets join it.
+		InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+		AddOutboxEvent(ctx context.Context, event entities.OutboxEvent) error
+	}
+
+	OutboxRelayRepo interface {
+		ListOutboxEvents(ctx context.Context, limit int) ([]entities.OutboxEvent, error)
+		MarkOutboxPublished(ctx context.Context, events []entities.OutboxEvent, at time.Time) error
+		DeleteOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error)
+		AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	}
+
+	EventPublisher interface {
+		Publish(ctx context.Context, event entities.OutboxEvent) error
+	}
+)
+
+// Outbox records change events in the transaction of the change they describe, so that an event is stored
+// if and only if the change is. A nil Outbox is disabled: Transaction only runs fn and Record does nothing.
+type Outbox struct {
+	repo OutboxRepo
+}
+
+func NewOutbox(repo OutboxRepo) *Outbox {
+	return &Outbox{
+		repo: repo,
+	}
+}
+
+func (o *Outbox) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if o == nil {
+		return fn(ctx)
+	}
+
+	return o.repo.InTransaction(ctx, fn)
+}
+
+// Record stores the event, ctx must be the one of the transaction to make it part of it.
+func (o *Outbox) Record(ctx context.Context, eventType, key string, payload interface{}) error {
+	if o == nil {
+		return nil
+	}
+
+	event, err := entities.NewOutboxEvent(eventType, key, payload)
+	if err != nil {
+		return errors.Wrap(err, "entities.NewOutboxEvent")
+	}
+
+	if err = o.repo.AddOutboxEvent(ctx, event); err != nil {
+		return errors.Wrap(err, "repo.AddOutboxEvent")
+	}
+
+	return nil
+}
+
+// OutboxRelay publishes the recorded events in order. An event is marked published only after Kafka
+// acknowledged it, a crash in between publishes it again, so the delivery is at least once. Only the replica
+// holding the lease relays, the others wait for it to expire.
+type OutboxRelay struct {
+	repo      OutboxRelayRepo
+	publisher EventPublisher
+	retention time.Duration
+	owner     string
+	tracer    trace.Tracer
+
+	leaseUntil  time.Time
+	lastCleanup time.Time
+}
+
+// NewOutboxRelay creates the relay, owner identifies the replica holding the lease.
+func NewOutboxRelay(repo OutboxRelayRepo, publisher EventPublisher, retention time.Duration, owner string) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		retention: retention,
+		owner:     owner,
+		tracer:    otel.Tracer("outboxRelay"),
+	}
+}
+
+// Run relays the events until the context is canceled, a failed batch is retried after _outboxPollInterval.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	for {
+		published, err := r.relay(ctx)
+		if err == nil && published == _outboxBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(_outboxPollInterval):
+		}
+	}
+}
+
+// relay publishes one batch of events and cleans up the old ones, it returns the number of published events.
+func (r *OutboxRelay) relay(ctx context.Context) (int, error) {
+	held, err := r.lease(ctx)
+	if err != nil || !held {
+		return 0, err
+	}
+
+	ctx, span := r.tracer.Start(ctx, "outboxRelay.relay")
+	defer span.End()
+
+	events, err := r.repo.ListOutboxEvents(ctx, _outboxBatchSize)
+	if err != nil {
+		span.RecordError(err)
+		return 0, errors.Wrap(err, "repo.ListOutboxEvents")
+	}
+
+	published := 0
+	for _, event := range events {
+		if err = r.publisher.Publish(ctx, event); err != nil {
+			// The later events wait, publishing them now would break the order.
+			err = errors.Wrapf(err, "publisher.Publish %d", event.Seq)
+			break
+		}
+
+		published++
+	}
+
+	span.SetAttributes(attribute.Int("outbox.published", published))
+
+	if published != 0 {
+		if markErr := r.repo.MarkOutboxPublished(ctx, events[:published], time.Now().UTC()); markErr != nil {
+			err = errors.Wrap(markErr, "repo.MarkOutboxPublished")
+		}
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		return published, err
+	}
+
+	if time.Since(r.lastCleanup) >= _outboxCleanupInterval {
+		if _, err = r.repo.DeleteOutboxEvents(ctx, time.Now().UTC().Add(-r.retention)); err != nil {
+			span.RecordError(err)
+			return published, errors.Wrap(err, "repo.DeleteOutboxEvents")
+		}
+
+		r.lastCleanup = time.Now()
+	}
+
+	return published, nil
+}
+
+// lease acquires or renews the lease once a third of it has passed.
+func (r *OutboxRelay) lease(ctx context.Context) (bool, error) {
+	if time.Until(r.leaseUntil) > _outboxLeaseTTL*2/3 {
+		return true, nil
+	}
+
+	// The lease is counted from before the call, it can't last longer in the repository than here.
+	start := time.Now()
+
+	held, err := r.repo.AcquireLease(ctx, _outboxLease, r.owner, _outboxLeaseTTL)
+	if err != nil {
+		return false, errors.Wrap(err, "repo.AcquireLease")
+	}
+
+	if held {
+		r.leaseUntil = start.Add(_outboxLeaseTTL)
+	} else {
+		r.leaseUntil = time.Time{}
+	}
+
+	return held, nil
+}
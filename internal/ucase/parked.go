@@ -0,0 +1,209 @@
+package ucase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	_parkedBatchSize    = 50
+	_parkedPollInterval = 5 * time.Second
+	_parkedFirstDelay   = 10 * time.Second
+	_parkedMaxDelay     = 5 * time.Minute
+	_parkedLeaseTTL     = 30 * time.Second
+	_parkedLease        = "parked-retry"
+)
+
+type (
+	ParkedRepo interface {
+		ParkNotification(ctx context.Context, parked entities.ParkedNotification) error
+		ListDueNotifications(ctx context.Context, now time.Time, limit int) ([]entities.ParkedNotification, error)
+		UpdateParkedNotification(ctx context.Context, parked entities.ParkedNotification) error
+		DeleteParkedNotification(ctx context.Context, parkedID string) error
+		CountParkedNotifications(ctx context.Context) (int64, error)
+		AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	}
+
+	// FallbackNotifier is another channel for the alerts, used while Telegram is unavailable.
+	FallbackNotifier interface {
+		NotifyFallback(ctx context.Context, account entities.TGAccount, msg entities.NotificationMessage, reason string) error
+	}
+)
+
+// retryDelay doubles the wait after every failed attempt up to _parkedMaxDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := _parkedFirstDelay
+	for i := 1; i < attempts && delay < _parkedMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > _parkedMaxDelay {
+		return _parkedMaxDelay
+	}
+
+	return delay
+}
+
+// park stores the message to be sent once Telegram is back.
+func (n *Notify) park(ctx context.Context, msg entities.NotificationMessage, cause error) error {
+	now := time.Now().UTC()
+	parked := entities.ParkedNotification{
+		ID:          primitive.NewObjectID(),
+		Message:     msg,
+		Attempts:    1,
+		LastError:   cause.Error(),
+		NextAttempt: now.Add(retryDelay(1)),
+		CreatedAt:   now,
+	}
+
+	if err := n.repo.ParkNotification(ctx, parked); err != nil {
+		return multierr.Append(cause, errors.Wrap(err, "repo.ParkNotification"))
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("parked", trace.WithAttributes(
+		attribute.String("clientID", msg.ClientID),
+		attribute.String("parked.id", parked.ID.Hex()),
+	))
+
+	return nil
+}
+
+// notifyFallback sends the alert to the fallback channel, a failure only leaves the alert parked.
+func (n *Notify) notifyFallback(ctx context.Context, msg entities.NotificationMessage, cause error) {
+	if n.fallback == nil || msg.Test {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	account, err := n.repo.GetAccountByClientID(ctx, msg.ClientID)
+	if err != nil {
+		span.RecordError(errors.Wrap(err, "repo.GetAccountByClientID"))
+		return
+	}
+
+	if err = n.fallback.NotifyFallback(ctx, account, msg, cause.Error()); err != nil {
+		span.RecordError(errors.Wrap(err, "fallback.NotifyFallback"))
+		return
+	}
+
+	span.AddEvent("fallback sent", trace.WithAttributes(attribute.String("clientID", msg.ClientID)))
+}
+
+// RunRetries sends the parked notifications again until the context is canceled. Only the replica holding
+// the lease retries, owner identifies it.
+func (n *Notify) RunRetries(ctx context.Context, owner string) {
+	ticker := time.NewTicker(_parkedPollInterval)
+	defer ticker.Stop()
+
+	for {
+		held, err := n.repo.AcquireLease(ctx, _parkedLease, owner, _parkedLeaseTTL)
+		if err == nil && held {
+			n.retryDue(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Notify) retryDue(ctx context.Context) {
+	ctx, span := n.tracer.Start(ctx, "uCase.retryDue")
+	defer span.End()
+
+	due, err := n.repo.ListDueNotifications(ctx, time.Now().UTC(), _parkedBatchSize)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	span.SetAttributes(attribute.Int("parked.due", len(due)))
+
+	for _, parked := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		unavailable, err := n.retry(ctx, parked)
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		// Telegram is still down, the rest of the batch would fail the same way.
+		if unavailable {
+			return
+		}
+	}
+}
+
+// retry sends the parked notification once more. It is rescheduled while Telegram is unavailable and
+// removed once it is sent, rejected or too old.
+func (n *Notify) retry(ctx context.Context, parked entities.ParkedNotification) (unavailable bool, err error) {
+	msg := parked.Message
+
+	if time.Since(parked.CreatedAt) > n.maxAge {
+		if !msg.IsFollowUp() {
+			event := entities.DeliveryFailure{
+				RequestID: msg.RequestID.String(),
+				ClientID:  msg.ClientID,
+				Error:     "expired in the retry queue: " + parked.LastError,
+			}
+
+			if err = n.outbox.Record(ctx, entities.EventDeliveryFailed, msg.ClientID, event); err != nil {
+				return false, err
+			}
+		}
+
+		return false, n.deleteParked(ctx, parked)
+	}
+
+	if msg.IsFollowUp() {
+		err = n.revise(ctx, msg)
+		if errors.Is(err, ErrNoDelivery) {
+			// The alert isn't delivered yet, the follow-up waits for it until it's too old.
+			return false, n.reschedule(ctx, parked, err)
+		}
+
+		unavailable = errors.Is(err, entities.ErrUnavailable)
+	} else {
+		_, unavailable, err = n.send(ctx, msg)
+	}
+
+	if !unavailable {
+		return false, multierr.Append(err, n.deleteParked(ctx, parked))
+	}
+
+	return true, n.reschedule(ctx, parked, err)
+}
+
+// reschedule puts the next attempt of the parked notification off, cause is the error of the last one.
+func (n *Notify) reschedule(ctx context.Context, parked entities.ParkedNotification, cause error) error {
+	parked.Attempts++
+	parked.LastError = cause.Error()
+	parked.NextAttempt = time.Now().UTC().Add(retryDelay(parked.Attempts))
+
+	if err := n.repo.UpdateParkedNotification(ctx, parked); err != nil {
+		return errors.Wrap(err, "repo.UpdateParkedNotification")
+	}
+
+	return nil
+}
+
+func (n *Notify) deleteParked(ctx context.Context, parked entities.ParkedNotification) error {
+	if err := n.repo.DeleteParkedNotification(ctx, parked.ID.Hex()); err != nil {
+		return errors.Wrap(err, "repo.DeleteParkedNotification")
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package ucase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// ErrSuppressed is matched by a SuppressedError.
+var ErrSuppressed = errors.New("error suppressed by a filter rule")
+
+// SuppressedError is an alert that doesn't meet a filter rule of its subscription, nothing is sent then.
+type SuppressedError struct {
+	Rule string
+}
+
+func (e *SuppressedError) Error() string {
+	return ErrSuppressed.Error() + ": " + e.Rule
+}
+
+func (e *SuppressedError) Is(target error) bool {
+	return target == ErrSuppressed
+}
+
+// parseRules parses the rules to be stored with them, invalid describes the first rule that doesn't parse.
+func parseRules(rules []string) (filters []entities.FilterRule, invalid string) {
+	filters, ruleErrors := entities.ParseFilterRules(rules)
+	if len(ruleErrors) == 0 {
+		return filters, ""
+	}
+
+	return nil, fmt.Sprintf("rule %q is invalid: %s", ruleErrors[0].Rule, ruleErrors[0].Error)
+}
+
+// suppress returns a SuppressedError for an alert that doesn't meet the rules, the rules don't apply to
+// test alerts.
+func suppress(rules []entities.FilterRule, msg entities.NotificationMessage) error {
+	if msg.Test {
+		return nil
+	}
+
+	if rule, ok := entities.SuppressingRule(rules, msg); ok {
+		return &SuppressedError{Rule: rule}
+	}
+
+	return nil
+}
+
+// suppressed records the rule that suppressed the alert on the span.
+func suppressed(ctx context.Context, msg entities.NotificationMessage, err error) bool {
+	var rule *SuppressedError
+	if !errors.As(err, &rule) {
+		return false
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("suppressed", trace.WithAttributes(
+		attribute.String("clientID", msg.ClientID),
+		attribute.String("notify.suppressed_by", rule.Rule),
+	))
+
+	return true
+}
+
+// CheckRules validates the rule expressions, and tells which of them suppresses the sample message
+// when one is given. A sample without a timestamp is checked as detected now.
+func (n *Notify) CheckRules(rules []string, msg *entities.NotificationMessage) entities.RuleCheck {
+	filters, ruleErrors := entities.ParseFilterRules(rules)
+	check := entities.RuleCheck{Errors: ruleErrors}
+	check.Valid = len(check.Errors) == 0
+
+	if msg != nil && check.Valid {
+		sample := *msg
+		if sample.Timestamp.IsZero() {
+			sample.Timestamp = time.Now()
+		}
+
+		check.SuppressedBy, _ = entities.SuppressingRule(filters, sample)
+	}
+
+	return check
+}
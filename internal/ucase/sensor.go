@@ -0,0 +1,107 @@
+package ucase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+var ErrInvalidSensor = errors.New("error invalid sensor")
+
+type SensorRepo interface {
+	CreateSensor(ctx context.Context, sensor entities.Sensor) error
+	GetSensor(ctx context.Context, sensorID string) (entities.Sensor, error)
+	ListSensors(ctx context.Context, clientID string) ([]entities.Sensor, error)
+	UpdateSensor(ctx context.Context, sensor entities.Sensor) error
+	DeleteSensor(ctx context.Context, sensorID string) error
+	GetAccountByClientID(ctx context.Context, clientID string) (entities.TGAccount, error)
+}
+
+type Sensor struct {
+	repo SensorRepo
+}
+
+func NewSensorUCase(repo SensorRepo) *Sensor {
+	return &Sensor{
+		repo: repo,
+	}
+}
+
+// validate checks the sensor and that every owning client is registered.
+func (s Sensor) validate(ctx context.Context, sensor entities.Sensor) error {
+	if sensor.ID == "" {
+		return errors.Wrap(ErrInvalidSensor, "id is required")
+	}
+
+	if sensor.Name == "" {
+		return errors.Wrap(ErrInvalidSensor, "name is required")
+	}
+
+	if sensor.Location != nil && !sensor.Location.Valid() {
+		return errors.Wrap(ErrInvalidSensor, "location is out of range")
+	}
+
+	if len(sensor.ClientIDs) == 0 {
+		return errors.Wrap(ErrInvalidSensor, "at least one client is required")
+	}
+
+	for _, clientID := range sensor.ClientIDs {
+		if _, err := s.repo.GetAccountByClientID(ctx, clientID); err != nil {
+			return errors.Wrapf(ErrInvalidSensor, "client %s: %s", clientID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s Sensor) Create(ctx context.Context, sensor entities.Sensor) error {
+	if err := s.validate(ctx, sensor); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateSensor(ctx, sensor); err != nil {
+		return errors.Wrap(err, "repo.CreateSensor")
+	}
+
+	return nil
+}
+
+func (s Sensor) Get(ctx context.Context, sensorID string) (entities.Sensor, error) {
+	sensor, err := s.repo.GetSensor(ctx, sensorID)
+	if err != nil {
+		return sensor, errors.Wrap(err, "repo.GetSensor")
+	}
+
+	return sensor, nil
+}
+
+func (s Sensor) List(ctx context.Context, clientID string) ([]entities.Sensor, error) {
+	sensors, err := s.repo.ListSensors(ctx, clientID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListSensors")
+	}
+
+	return sensors, nil
+}
+
+func (s Sensor) Update(ctx context.Context, sensor entities.Sensor) error {
+	if err := s.validate(ctx, sensor); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateSensor(ctx, sensor); err != nil {
+		return errors.Wrap(err, "repo.UpdateSensor")
+	}
+
+	return nil
+}
+
+func (s Sensor) Delete(ctx context.Context, sensorID string) error {
+	if err := s.repo.DeleteSensor(ctx, sensorID); err != nil {
+		return errors.Wrap(err, "repo.DeleteSensor")
+	}
+
+	return nil
+}
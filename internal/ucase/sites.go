@@ -0,0 +1,129 @@
+package ucase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type SiteRepo interface {
+	OrganizationGetter
+	UpdateOrganization(ctx context.Context, org entities.Organization) error
+}
+
+// recipient returns the account of the message as it is stored and the organization it is a site of.
+// The account is zero for the copy of a site alert going to the organization chat.
+func (n *Notify) recipient(
+	ctx context.Context, msg entities.NotificationMessage,
+) (entities.TGAccount, entities.Organization, error) {
+	if msg.OrganizationID != "" {
+		org, err := n.repo.GetOrganization(ctx, msg.OrganizationID)
+		if err != nil {
+			return entities.TGAccount{}, org, errors.Wrap(err, "repo.GetOrganization")
+		}
+
+		return entities.TGAccount{}, org, nil
+	}
+
+	account, err := n.repo.GetAccountByClientID(ctx, msg.ClientID)
+	if err != nil {
+		return account, entities.Organization{}, errors.Wrap(err, "repo.GetAccountByClientID")
+	}
+
+	org, err := siteOrganization(ctx, n.repo, account)
+	if err != nil {
+		return account, org, err
+	}
+
+	return account, org, nil
+}
+
+// chat is a chat as seen by a bot, the copies of an alert skip the chats that get it already.
+type chat struct {
+	botID  string
+	chatID int64
+}
+
+// organizationCopies returns a copy of the message for the chat of every organization the recipients are
+// sites of. An organization chat that gets the alert as the chat of one of the sites is skipped, and every
+// organization gets a single copy however many of its sites the alert is for. The chats of the recipients
+// and of the copies are added to chats.
+func (n *Notify) organizationCopies(
+	ctx context.Context, msg entities.NotificationMessage, recipients []string, chats map[chat]bool,
+) ([]entities.NotificationMessage, error) {
+	var (
+		copiesErr error
+		orgIDs    []string
+	)
+
+	orgs := make(map[string]entities.Organization)
+	sites := make(map[string]string) // sites - the first recipient of every organization
+
+	for _, clientID := range recipients {
+		account, err := n.repo.GetAccountByClientID(ctx, clientID)
+		if err != nil {
+			// The site alert reports it already.
+			continue
+		}
+
+		if account.OrganizationID == "" {
+			chats[chat{account.BotID, account.ChatID}] = true
+			continue
+		}
+
+		org, ok := orgs[account.OrganizationID]
+		if !ok {
+			if org, err = n.repo.GetOrganization(ctx, account.OrganizationID); err != nil {
+				copiesErr = multierr.Append(copiesErr, errors.Wrapf(err, "organization %s", account.OrganizationID))
+				continue
+			}
+
+			orgs[org.ID.Hex()] = org
+			sites[org.ID.Hex()] = clientID
+			orgIDs = append(orgIDs, org.ID.Hex())
+		}
+
+		site := org.Inherit(account)
+		chats[chat{site.BotID, site.ChatID}] = true
+	}
+
+	copies := make([]entities.NotificationMessage, 0, len(orgIDs))
+	for _, orgID := range orgIDs {
+		org := orgs[orgID]
+		if org.ChatID == 0 || chats[chat{org.BotID, org.ChatID}] {
+			continue
+		}
+
+		chats[chat{org.BotID, org.ChatID}] = true
+
+		orgMsg := msg
+		orgMsg.ClientID = sites[orgID]
+		orgMsg.OrganizationID = orgID
+		copies = append(copies, orgMsg)
+	}
+
+	return copies, copiesErr
+}
+
+// migrateOrganization follows the upgrade of the organization chat to a supergroup.
+func (n *Notify) migrateOrganization(ctx context.Context, org entities.Organization, toChatID int64) error {
+	fromChatID := org.ChatID
+	org.ChatID = toChatID
+
+	if err := n.repo.UpdateOrganization(ctx, org); err != nil {
+		return errors.Wrap(err, "repo.UpdateOrganization")
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("organization chat migrated", trace.WithAttributes(
+		attribute.String("organization.id", org.ID.Hex()),
+		attribute.Int64("chat.from", fromChatID),
+		attribute.Int64("chat.to", toChatID),
+	))
+
+	return nil
+}
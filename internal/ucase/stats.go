@@ -0,0 +1,152 @@
+package ucase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+const (
+	_statsPeriod        = 30 * 24 * time.Hour
+	_statsTopSensors    = 10
+	_statsMaxTopSensors = 100
+)
+
+// ErrInvalidStats is returned for a statistics request with an impossible period.
+var ErrInvalidStats = errors.New("error invalid statistics request")
+
+type StatsRepo interface {
+	AccountGetter
+	OrganizationGetter
+	ListAccounts(ctx context.Context, filter entities.AccountFilter) ([]entities.TGAccount, error)
+	DeliveryStats(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error)
+}
+
+// Stats aggregates the delivery history of a client, the sites of an organization or all the clients.
+type Stats struct {
+	repo   StatsRepo
+	tracer trace.Tracer
+}
+
+func NewStatsUCase(repo StatsRepo) *Stats {
+	return &Stats{
+		repo:   repo,
+		tracer: otel.Tracer("statsUCase"),
+	}
+}
+
+// period fills in the defaults of the filter: the 30 days until now and the top 10 sensors.
+func (s *Stats) period(filter entities.DeliveryStatsFilter) (entities.DeliveryStatsFilter, error) {
+	if filter.Until.IsZero() {
+		filter.Until = time.Now().UTC()
+	}
+
+	if filter.Since.IsZero() {
+		filter.Since = filter.Until.Add(-_statsPeriod)
+	}
+
+	if !filter.Since.Before(filter.Until) {
+		return filter, errors.Wrap(ErrInvalidStats, "since must be before until")
+	}
+
+	switch {
+	case filter.TopSensors <= 0:
+		filter.TopSensors = _statsTopSensors
+	case filter.TopSensors > _statsMaxTopSensors:
+		filter.TopSensors = _statsMaxTopSensors
+	}
+
+	return filter, nil
+}
+
+func (s *Stats) stats(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	stats, err := s.repo.DeliveryStats(ctx, filter)
+	if err != nil {
+		return stats, errors.Wrap(err, "repo.DeliveryStats")
+	}
+
+	return stats, nil
+}
+
+// Client aggregates the deliveries of the client, the copies sent to its organization included.
+func (s *Stats) Client(ctx context.Context, clientID string, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	ctx, span := s.tracer.Start(ctx, "uCase.ClientStats")
+	defer span.End()
+
+	filter, err := s.period(filter)
+	if err != nil {
+		return entities.DeliveryStats{}, err
+	}
+
+	account, err := s.repo.GetAccountByClientID(ctx, clientID)
+	if err != nil {
+		return entities.DeliveryStats{}, errors.Wrap(err, "repo.GetAccountByClientID")
+	}
+
+	if _, err = siteOrganization(ctx, s.repo, account); err != nil {
+		return entities.DeliveryStats{}, err
+	}
+
+	filter.ClientIDs = []string{clientID}
+
+	return s.stats(ctx, filter)
+}
+
+// Organization aggregates the deliveries of all the sites of the organization.
+func (s *Stats) Organization(ctx context.Context, orgID string, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	ctx, span := s.tracer.Start(ctx, "uCase.OrganizationStats")
+	defer span.End()
+
+	filter, err := s.period(filter)
+	if err != nil {
+		return entities.DeliveryStats{}, err
+	}
+
+	org, err := s.repo.GetOrganization(ctx, orgID)
+	if err != nil {
+		return entities.DeliveryStats{}, errors.Wrap(err, "repo.GetOrganization")
+	}
+
+	if !visible(ctx, org.TenantID) {
+		return entities.DeliveryStats{}, errors.Wrapf(entities.ErrRecordNotFound, "organization %s", orgID)
+	}
+
+	sites, err := s.repo.ListAccounts(ctx, entities.AccountFilter{OrganizationID: orgID})
+	if err != nil {
+		return entities.DeliveryStats{}, errors.Wrap(err, "repo.ListAccounts")
+	}
+
+	span.SetAttributes(attribute.Int("stats.sites", len(sites)))
+
+	// No client IDs would mean all the clients.
+	if len(sites) == 0 {
+		return entities.NewDeliveryStats(filter, nil), nil
+	}
+
+	for _, site := range sites {
+		filter.ClientIDs = append(filter.ClientIDs, site.ClientID.Hex())
+	}
+
+	return s.stats(ctx, filter)
+}
+
+// Global aggregates the deliveries of all the clients, it spans the tenants.
+func (s *Stats) Global(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error) {
+	ctx, span := s.tracer.Start(ctx, "uCase.GlobalStats")
+	defer span.End()
+
+	filter, err := s.period(filter)
+	if err != nil {
+		return entities.DeliveryStats{}, err
+	}
+
+	filter.ClientIDs = nil
+
+	return s.stats(ctx, filter)
+}
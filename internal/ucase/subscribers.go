@@ -0,0 +1,96 @@
+package ucase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// zoneCopies returns a copy of the message for every chat subscribed to a zone the detection is inside of,
+// whichever client owns the sensor. A chat that gets the alert already, as a recipient or through another
+// zone, is skipped. A message without a location is in no zone.
+func (n *Notify) zoneCopies(
+	ctx context.Context, msg entities.NotificationMessage, chats map[chat]bool,
+) ([]entities.NotificationMessage, error) {
+	if msg.Location == nil {
+		return nil, nil
+	}
+
+	zones, err := n.repo.ZonesContaining(ctx, *msg.Location)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ZonesContaining")
+	}
+
+	var copiesErr error
+
+	copies := make([]entities.NotificationMessage, 0)
+	for _, zone := range zones {
+		subs, err := n.repo.ListZoneSubscriptions(ctx, zone.ID.Hex())
+		if err != nil {
+			copiesErr = multierr.Append(copiesErr, errors.Wrapf(err, "zone %s", zone.ID.Hex()))
+			continue
+		}
+
+		for _, sub := range subs {
+			if chats[chat{sub.BotID, sub.ChatID}] {
+				continue
+			}
+
+			chats[chat{sub.BotID, sub.ChatID}] = true
+
+			zoneMsg := msg
+			zoneMsg.ZoneSubscriptionID = sub.ID.Hex()
+			copies = append(copies, zoneMsg)
+		}
+	}
+
+	return copies, copiesErr
+}
+
+// deliverSubscription sends the copy of an alert to the chat subscribed to the zone unless it doesn't meet
+// the filter rules of the subscription, the subscription follows the chat when it was migrated.
+func (n *Notify) deliverSubscription(ctx context.Context, msg entities.NotificationMessage) (entities.Delivery, error) {
+	sub, err := n.repo.GetZoneSubscription(ctx, msg.ZoneSubscriptionID)
+	if err != nil {
+		return entities.Delivery{}, errors.Wrap(err, "repo.GetZoneSubscription")
+	}
+
+	if err = suppress(sub.FilterRules(), msg); err != nil {
+		return entities.Delivery{}, err
+	}
+
+	delivery, err := n.notifier.NotifyClient(ctx, sub.Account(), msg)
+	if delivery.MessageID != 0 && delivery.ChatID != sub.ChatID {
+		if migrateErr := n.migrateSubscription(ctx, sub, delivery.ChatID); migrateErr != nil {
+			trace.SpanFromContext(ctx).RecordError(errors.Wrap(migrateErr, "migrateSubscription"))
+		}
+	}
+
+	if err != nil {
+		return delivery, &DeliveryError{Err: errors.Wrap(err, "notifier.NotifyClient")}
+	}
+
+	return delivery, nil
+}
+
+func (n *Notify) migrateSubscription(ctx context.Context, sub entities.ZoneSubscription, toChatID int64) error {
+	fromChatID := sub.ChatID
+	sub.ChatID = toChatID
+
+	if err := n.repo.UpdateZoneSubscription(ctx, sub); err != nil {
+		return errors.Wrap(err, "repo.UpdateZoneSubscription")
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("zone subscription chat migrated", trace.WithAttributes(
+		attribute.String("subscription.id", sub.ID.Hex()),
+		attribute.Int64("chat.from", fromChatID),
+		attribute.Int64("chat.to", toChatID),
+	))
+
+	return nil
+}
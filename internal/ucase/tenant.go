@@ -0,0 +1,56 @@
+package ucase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+type tenantKey struct{}
+
+// WithTenant scopes the calls made with the context to the tenant, the organizations, sites and bots
+// of other tenants look missing to them.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant the calls are scoped to, ok is false for unscoped calls.
+func TenantFromContext(ctx context.Context) (tenantID string, ok bool) {
+	tenantID, ok = ctx.Value(tenantKey{}).(string)
+	return tenantID, ok
+}
+
+// visible reports whether a record of the tenant can be seen with the context.
+func visible(ctx context.Context, tenantID string) bool {
+	scope, ok := TenantFromContext(ctx)
+	return !ok || scope == tenantID
+}
+
+type OrganizationGetter interface {
+	GetOrganization(ctx context.Context, orgID string) (entities.Organization, error)
+}
+
+// siteOrganization returns the organization of the site, zero for a standalone client. A tenant scoped
+// context sees only the sites of its own organizations, the others are not found.
+func siteOrganization(ctx context.Context, repo OrganizationGetter, account entities.TGAccount) (entities.Organization, error) {
+	if account.OrganizationID == "" {
+		if _, scoped := TenantFromContext(ctx); scoped {
+			return entities.Organization{}, errors.Wrapf(entities.ErrRecordNotFound, "client %s", account.ClientID.Hex())
+		}
+
+		return entities.Organization{}, nil
+	}
+
+	org, err := repo.GetOrganization(ctx, account.OrganizationID)
+	if err != nil {
+		return org, errors.Wrap(err, "repo.GetOrganization")
+	}
+
+	if !visible(ctx, org.TenantID) {
+		return org, errors.Wrapf(entities.ErrRecordNotFound, "client %s", account.ClientID.Hex())
+	}
+
+	return org, nil
+}
@@ -8,23 +8,116 @@ import (
 
 type (
 	ClientUseCase interface {
-		Create(ctx context.Context, account entities.TGAccount) error
+		Create(ctx context.Context, account entities.TGAccount, topicName string) error
+		Get(ctx context.Context, clientID string) (entities.TGAccount, error)
 		Delete(ctx context.Context, clientID string) error
+		ListMigrations(ctx context.Context, clientID string) ([]entities.ChatMigration, error)
+		SetRules(ctx context.Context, clientID string, rules []string) error
+		Import(ctx context.Context, src ImportSource, dryRun bool) (entities.ImportReport, error)
+		Export(ctx context.Context, fn func(entities.TGAccount) error) error
 	}
 
 	NotificationUseCase interface {
 		Notify(ctx context.Context, message entities.NotificationMessage) error
+		Test(ctx context.Context, clientID, sensorID string) (entities.Delivery, error)
+		CheckRules(rules []string, msg *entities.NotificationMessage) entities.RuleCheck
+	}
+
+	SensorUseCase interface {
+		Create(ctx context.Context, sensor entities.Sensor) error
+		Get(ctx context.Context, sensorID string) (entities.Sensor, error)
+		List(ctx context.Context, clientID string) ([]entities.Sensor, error)
+		Update(ctx context.Context, sensor entities.Sensor) error
+		Delete(ctx context.Context, sensorID string) error
+	}
+
+	BotUseCase interface {
+		Create(ctx context.Context, tenantID, token string, adminChatID int64, pollUpdates bool) (entities.Bot, error)
+		List(ctx context.Context, tenantID string) ([]entities.Bot, error)
+		Delete(ctx context.Context, botID string) error
+	}
+
+	DeliveryUseCase interface {
+		List(ctx context.Context, filter entities.DeliveryFilter) ([]entities.Delivery, error)
+		Acknowledge(ctx context.Context, requestID, clientID string) ([]entities.Delivery, error)
+	}
+
+	BroadcastUseCase interface {
+		Create(ctx context.Context, text string, target entities.BroadcastTarget) (entities.Broadcast, error)
+		Get(ctx context.Context, broadcastID string) (entities.Broadcast, error)
+		List(ctx context.Context) ([]entities.Broadcast, error)
+		Cancel(ctx context.Context, broadcastID string) (entities.Broadcast, error)
+	}
+
+	OrganizationUseCase interface {
+		Create(ctx context.Context, org entities.Organization) (entities.Organization, error)
+		Get(ctx context.Context, orgID string) (entities.Organization, error)
+		List(ctx context.Context, tenantID string) ([]entities.Organization, error)
+		Update(ctx context.Context, org entities.Organization) error
+		Delete(ctx context.Context, orgID string) error
+		ListSites(ctx context.Context, orgID string) ([]entities.TGAccount, error)
+	}
+
+	ZoneUseCase interface {
+		Create(ctx context.Context, zone entities.Zone) (entities.Zone, error)
+		Get(ctx context.Context, zoneID string) (entities.Zone, error)
+		List(ctx context.Context, tenantID string) ([]entities.Zone, error)
+		Update(ctx context.Context, zone entities.Zone) error
+		Delete(ctx context.Context, zoneID string) error
+		Subscribe(ctx context.Context, sub entities.ZoneSubscription) (entities.ZoneSubscription, error)
+		ListSubscriptions(ctx context.Context, zoneID string) ([]entities.ZoneSubscription, error)
+		SetSubscriptionRules(ctx context.Context, zoneID, subID string, rules []string) error
+		Unsubscribe(ctx context.Context, zoneID, subID string) error
+	}
+
+	DigestUseCase interface {
+		Schedule(ctx context.Context, schedule entities.DigestSchedule) (entities.DigestSchedule, error)
+		ListSchedules(ctx context.Context, clientID string) ([]entities.DigestSchedule, error)
+		Unschedule(ctx context.Context, clientID, scheduleID string) error
+		Compose(ctx context.Context, clientID string, period entities.DigestPeriod, timezone string) (entities.Digest, error)
+	}
+
+	StatsUseCase interface {
+		Client(ctx context.Context, clientID string, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error)
+		Organization(ctx context.Context, orgID string, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error)
+		Global(ctx context.Context, filter entities.DeliveryStatsFilter) (entities.DeliveryStats, error)
+	}
+
+	HealthUseCase interface {
+		Check(ctx context.Context) entities.Health
 	}
 )
 
 type UCase struct {
 	ClientUCase       ClientUseCase
 	NotificationUCase NotificationUseCase
+	BotUCase          BotUseCase
+	SensorUCase       SensorUseCase
+	DeliveryUCase     DeliveryUseCase
+	BroadcastUCase    BroadcastUseCase
+	OrganizationUCase OrganizationUseCase
+	ZoneUCase         ZoneUseCase
+	DigestUCase       DigestUseCase
+	StatsUCase        StatsUseCase
+	HealthUCase       HealthUseCase
 }
 
-func NewUCase(client ClientUseCase, notification NotificationUseCase) *UCase {
+func NewUCase(
+	client ClientUseCase, notification NotificationUseCase, bot BotUseCase, sensor SensorUseCase,
+	delivery DeliveryUseCase, broadcast BroadcastUseCase, organization OrganizationUseCase, zone ZoneUseCase,
+	digest DigestUseCase, stats StatsUseCase, health HealthUseCase,
+) *UCase {
 	return &UCase{
 		ClientUCase:       client,
 		NotificationUCase: notification,
+		BotUCase:          bot,
+		SensorUCase:       sensor,
+		DeliveryUCase:     delivery,
+		BroadcastUCase:    broadcast,
+		OrganizationUCase: organization,
+		ZoneUCase:         zone,
+		DigestUCase:       digest,
+		StatsUCase:        stats,
+		HealthUCase:       health,
 	}
 }
@@ -0,0 +1,250 @@
+package ucase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Imm0bilize/gunshot-telegram-notifier/internal/entities"
+)
+
+// ErrInvalidZone is returned for a zone or a zone subscription that can't be stored.
+var ErrInvalidZone = errors.New("error invalid zone")
+
+type (
+	// ZoneLookup finds the chats subscribed to the zones of a detection.
+	ZoneLookup interface {
+		ZonesContaining(ctx context.Context, loc entities.Location) ([]entities.Zone, error)
+		ListZoneSubscriptions(ctx context.Context, zoneID string) ([]entities.ZoneSubscription, error)
+		GetZoneSubscription(ctx context.Context, subID string) (entities.ZoneSubscription, error)
+		UpdateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error
+	}
+
+	ZoneRepo interface {
+		ZoneLookup
+		CreateZone(ctx context.Context, zone entities.Zone) error
+		GetZone(ctx context.Context, zoneID string) (entities.Zone, error)
+		ListZones(ctx context.Context, tenantID string) ([]entities.Zone, error)
+		UpdateZone(ctx context.Context, zone entities.Zone) error
+		DeleteZone(ctx context.Context, zoneID string) error
+		CreateZoneSubscription(ctx context.Context, sub entities.ZoneSubscription) error
+		DeleteZoneSubscription(ctx context.Context, subID string) error
+		GetBot(ctx context.Context, botID string) (entities.Bot, error)
+	}
+)
+
+// Zone manages the geographic zones and the chats subscribed to them.
+type Zone struct {
+	repo ZoneRepo
+}
+
+func NewZoneUCase(repo ZoneRepo) *Zone {
+	return &Zone{
+		repo: repo,
+	}
+}
+
+func (z Zone) validate(zone entities.Zone) error {
+	if strings.TrimSpace(zone.TenantID) == "" {
+		return errors.Wrap(ErrInvalidZone, "tenantID is required")
+	}
+
+	if strings.TrimSpace(zone.Name) == "" {
+		return errors.Wrap(ErrInvalidZone, "name is required")
+	}
+
+	if err := zone.Geometry.Validate(); err != nil {
+		return errors.Wrap(ErrInvalidZone, err.Error())
+	}
+
+	return nil
+}
+
+// Create stores a new zone, a tenant scoped call creates it for its own tenant.
+func (z Zone) Create(ctx context.Context, zone entities.Zone) (entities.Zone, error) {
+	if tenantID, ok := TenantFromContext(ctx); ok {
+		zone.TenantID = tenantID
+	}
+
+	if err := z.validate(zone); err != nil {
+		return entities.Zone{}, err
+	}
+
+	zone.ID = primitive.NewObjectID()
+	zone.CreatedAt = time.Now().UTC()
+
+	if err := z.repo.CreateZone(ctx, zone); err != nil {
+		return entities.Zone{}, errors.Wrap(err, "repo.CreateZone")
+	}
+
+	return zone, nil
+}
+
+func (z Zone) Get(ctx context.Context, zoneID string) (entities.Zone, error) {
+	zone, err := z.repo.GetZone(ctx, zoneID)
+	if err != nil {
+		return zone, errors.Wrap(err, "repo.GetZone")
+	}
+
+	if !visible(ctx, zone.TenantID) {
+		return entities.Zone{}, errors.Wrapf(entities.ErrRecordNotFound, "zone %s", zoneID)
+	}
+
+	return zone, nil
+}
+
+// List returns the zones of the tenant, all of them for an empty one. A tenant scoped call lists its own
+// tenant only.
+func (z Zone) List(ctx context.Context, tenantID string) ([]entities.Zone, error) {
+	if scope, ok := TenantFromContext(ctx); ok {
+		tenantID = scope
+	}
+
+	zones, err := z.repo.ListZones(ctx, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListZones")
+	}
+
+	return zones, nil
+}
+
+// Update replaces the name and the geometry of the zone, it stays with its tenant.
+func (z Zone) Update(ctx context.Context, zone entities.Zone) error {
+	existing, err := z.Get(ctx, zone.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	zone.TenantID = existing.TenantID
+	zone.CreatedAt = existing.CreatedAt
+
+	if err = z.validate(zone); err != nil {
+		return err
+	}
+
+	if err = z.repo.UpdateZone(ctx, zone); err != nil {
+		return errors.Wrap(err, "repo.UpdateZone")
+	}
+
+	return nil
+}
+
+// Delete removes the zone with its subscriptions.
+func (z Zone) Delete(ctx context.Context, zoneID string) error {
+	if _, err := z.Get(ctx, zoneID); err != nil {
+		return err
+	}
+
+	if err := z.repo.DeleteZone(ctx, zoneID); err != nil {
+		return errors.Wrap(err, "repo.DeleteZone")
+	}
+
+	return nil
+}
+
+// Subscribe makes the chat get the alerts of the detections inside the zone, the bot of the subscription
+// must belong to the tenant of the zone.
+func (z Zone) Subscribe(ctx context.Context, sub entities.ZoneSubscription) (entities.ZoneSubscription, error) {
+	zone, err := z.Get(ctx, sub.ZoneID)
+	if err != nil {
+		return entities.ZoneSubscription{}, err
+	}
+
+	if sub.ChatID == 0 {
+		return entities.ZoneSubscription{}, errors.Wrap(ErrInvalidZone, "chatID is required")
+	}
+
+	filters, invalid := parseRules(sub.Rules)
+	if invalid != "" {
+		return entities.ZoneSubscription{}, errors.Wrap(ErrInvalidZone, invalid)
+	}
+
+	sub.Filters = filters
+
+	if sub.BotID != "" {
+		bot, err := z.repo.GetBot(ctx, sub.BotID)
+		if err != nil {
+			return entities.ZoneSubscription{}, errors.Wrap(err, "repo.GetBot")
+		}
+
+		if bot.TenantID != zone.TenantID {
+			return entities.ZoneSubscription{}, errors.Wrapf(ErrInvalidZone, "bot %s belongs to another tenant", sub.BotID)
+		}
+	}
+
+	sub.ID = primitive.NewObjectID()
+	sub.CreatedAt = time.Now().UTC()
+
+	if err = z.repo.CreateZoneSubscription(ctx, sub); err != nil {
+		return entities.ZoneSubscription{}, errors.Wrap(err, "repo.CreateZoneSubscription")
+	}
+
+	return sub, nil
+}
+
+func (z Zone) ListSubscriptions(ctx context.Context, zoneID string) ([]entities.ZoneSubscription, error) {
+	if _, err := z.Get(ctx, zoneID); err != nil {
+		return nil, err
+	}
+
+	subs, err := z.repo.ListZoneSubscriptions(ctx, zoneID)
+	if err != nil {
+		return nil, errors.Wrap(err, "repo.ListZoneSubscriptions")
+	}
+
+	return subs, nil
+}
+
+// SetSubscriptionRules replaces the filter rules of the subscription, no rules let every alert through.
+func (z Zone) SetSubscriptionRules(ctx context.Context, zoneID, subID string, rules []string) error {
+	filters, invalid := parseRules(rules)
+	if invalid != "" {
+		return errors.Wrap(ErrInvalidZone, invalid)
+	}
+
+	sub, err := z.subscription(ctx, zoneID, subID)
+	if err != nil {
+		return err
+	}
+
+	sub.Rules, sub.Filters = rules, filters
+
+	if err = z.repo.UpdateZoneSubscription(ctx, sub); err != nil {
+		return errors.Wrap(err, "repo.UpdateZoneSubscription")
+	}
+
+	return nil
+}
+
+// subscription returns the subscription of the zone.
+func (z Zone) subscription(ctx context.Context, zoneID, subID string) (entities.ZoneSubscription, error) {
+	if _, err := z.Get(ctx, zoneID); err != nil {
+		return entities.ZoneSubscription{}, err
+	}
+
+	sub, err := z.repo.GetZoneSubscription(ctx, subID)
+	if err != nil {
+		return sub, errors.Wrap(err, "repo.GetZoneSubscription")
+	}
+
+	if sub.ZoneID != zoneID {
+		return entities.ZoneSubscription{}, errors.Wrapf(entities.ErrRecordNotFound, "subscription %s", subID)
+	}
+
+	return sub, nil
+}
+
+func (z Zone) Unsubscribe(ctx context.Context, zoneID, subID string) error {
+	if _, err := z.subscription(ctx, zoneID, subID); err != nil {
+		return err
+	}
+
+	if err := z.repo.DeleteZoneSubscription(ctx, subID); err != nil {
+		return errors.Wrap(err, "repo.DeleteZoneSubscription")
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// BroadcastTarget selects the clients of a broadcast, zero fields match every client.
+type BroadcastTarget struct {
+	TenantID string
+	Region   string
+	Tags     []string // Tags - clients carrying at least one of them
+}
+
+type BroadcastFailure struct {
+	ClientID string
+	ChatID   int64
+	Error    string
+	At       time.Time
+}
+
+// Broadcast is an announcement sent to many chats in the background, the counters tell its progress.
+type Broadcast struct {
+	ID         string
+	Text       string
+	Target     BroadcastTarget
+	Status     string // Status - pending, running, done or canceled
+	Total      int
+	Sent       int
+	Failed     int
+	Failures   []BroadcastFailure
+	Error      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt time.Time // FinishedAt - zero until the broadcast is done or canceled
+}
+
+// CreateBroadcast queues the announcement, it returns before anything is sent.
+func (t TGNotificationServiceClient) CreateBroadcast(ctx context.Context, text string, target BroadcastTarget) (Broadcast, error) {
+	res, err := t.broadcasts.CreateBroadcastV1(ctx, &api.CreateBroadcastRequest{
+		Text: text,
+		Target: &api.BroadcastTarget{
+			TenantId: target.TenantID,
+			Region:   target.Region,
+			Tags:     target.Tags,
+		},
+	})
+	if err != nil {
+		return Broadcast{}, errors.Wrap(err, "broadcasts.CreateBroadcastV1")
+	}
+
+	return broadcastFromProto(res), nil
+}
+
+func (t TGNotificationServiceClient) GetBroadcast(ctx context.Context, id string) (Broadcast, error) {
+	res, err := t.broadcasts.GetBroadcastV1(ctx, &api.GetBroadcastRequest{Id: id})
+	if err != nil {
+		return Broadcast{}, errors.Wrap(err, "broadcasts.GetBroadcastV1")
+	}
+
+	return broadcastFromProto(res), nil
+}
+
+// ListBroadcasts returns all the broadcasts newest first.
+func (t TGNotificationServiceClient) ListBroadcasts(ctx context.Context) ([]Broadcast, error) {
+	res, err := t.broadcasts.ListBroadcastsV1(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, errors.Wrap(err, "broadcasts.ListBroadcastsV1")
+	}
+
+	broadcasts := make([]Broadcast, 0, len(res.GetBroadcasts()))
+	for _, broadcast := range res.GetBroadcasts() {
+		broadcasts = append(broadcasts, broadcastFromProto(broadcast))
+	}
+
+	return broadcasts, nil
+}
+
+func (t TGNotificationServiceClient) CancelBroadcast(ctx context.Context, id string) (Broadcast, error) {
+	res, err := t.broadcasts.CancelBroadcastV1(ctx, &api.CancelBroadcastRequest{Id: id})
+	if err != nil {
+		return Broadcast{}, errors.Wrap(err, "broadcasts.CancelBroadcastV1")
+	}
+
+	return broadcastFromProto(res), nil
+}
+
+func broadcastFromProto(broadcast *api.Broadcast) Broadcast {
+	res := Broadcast{
+		ID:   broadcast.GetId(),
+		Text: broadcast.GetText(),
+		Target: BroadcastTarget{
+			TenantID: broadcast.GetTarget().GetTenantId(),
+			Region:   broadcast.GetTarget().GetRegion(),
+			Tags:     broadcast.GetTarget().GetTags(),
+		},
+		Status:    broadcast.GetStatus(),
+		Total:     int(broadcast.GetTotal()),
+		Sent:      int(broadcast.GetSent()),
+		Failed:    int(broadcast.GetFailed()),
+		Failures:  make([]BroadcastFailure, 0, len(broadcast.GetFailures())),
+		Error:     broadcast.GetError(),
+		CreatedAt: broadcast.GetCreatedAt().AsTime(),
+		UpdatedAt: broadcast.GetUpdatedAt().AsTime(),
+	}
+
+	if broadcast.GetFinishedAt() != nil {
+		res.FinishedAt = broadcast.GetFinishedAt().AsTime()
+	}
+
+	for _, failure := range broadcast.GetFailures() {
+		res.Failures = append(res.Failures, BroadcastFailure{
+			ClientID: failure.GetClientId(),
+			ChatID:   failure.GetChatId(),
+			Error:    failure.GetError(),
+			At:       failure.GetAt().AsTime(),
+		})
+	}
+
+	return res
+}
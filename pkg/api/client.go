@@ -9,8 +9,11 @@ import (
 )
 
 type TGNotificationServiceClient struct {
-	client api.ClientServiceClient
-	conn   *grpc.ClientConn
+	client     api.ClientServiceClient
+	sensors    api.SensorServiceClient
+	deliveries api.DeliveryServiceClient
+	broadcasts api.BroadcastServiceClient
+	conn       *grpc.ClientConn
 }
 
 func NewTGNotificationServiceClient(address string) (*TGNotificationServiceClient, error) {
@@ -20,8 +23,11 @@ func NewTGNotificationServiceClient(address string) (*TGNotificationServiceClien
 	}
 
 	return &TGNotificationServiceClient{
-		client: api.NewClientServiceClient(conn),
-		conn:   conn,
+		client:     api.NewClientServiceClient(conn),
+		sensors:    api.NewSensorServiceClient(conn),
+		deliveries: api.NewDeliveryServiceClient(conn),
+		broadcasts: api.NewBroadcastServiceClient(conn),
+		conn:       conn,
 	}, nil
 }
 
@@ -37,6 +43,31 @@ func (t TGNotificationServiceClient) Create(ctx context.Context, clientID string
 	return nil
 }
 
+// CreateWithBot registers the client chat served by one of the registered bots, with its region and tags.
+func (t TGNotificationServiceClient) CreateWithBot(ctx context.Context, client Client) error {
+	_, err := t.client.CreateClientV1(ctx, &api.CreateClientRequest{
+		ClientId: client.ClientID,
+		ChatId:   client.ChatID,
+		BotId:    client.BotID,
+		Region:   client.Region,
+		Tags:     client.Tags,
+	})
+	if err != nil {
+		return errors.Wrap(err, "client.CreateClientV1")
+	}
+
+	return nil
+}
+
+func (t TGNotificationServiceClient) Get(ctx context.Context, clientID string) (Client, error) {
+	res, err := t.client.GetClientV1(ctx, &api.GetClientRequest{ClientId: clientID})
+	if err != nil {
+		return Client{}, errors.Wrap(err, "client.GetClientV1")
+	}
+
+	return clientFromProto(res), nil
+}
+
 func (t TGNotificationServiceClient) Delete(ctx context.Context, clientID string) error {
 	_, err := t.client.DeleteClientV1(ctx, &api.DeleteClientRequest{ClientId: clientID})
 	if err != nil {
@@ -53,3 +84,27 @@ func (t TGNotificationServiceClient) Shutdown(_ context.Context) error {
 
 	return nil
 }
+
+// TestNotification identifies the Telegram message a test alert was delivered as.
+type TestNotification struct {
+	RequestID         string
+	ChatID            int64
+	MessageID         int64
+	LocationMessageID int64
+}
+
+// SendTestNotification sends a labelled test alert to the client, sensorID may name a sensor of the client
+// to include its location. A Telegram failure is returned with the Bot API error.
+func (t TGNotificationServiceClient) SendTestNotification(ctx context.Context, clientID, sensorID string) (TestNotification, error) {
+	res, err := t.client.SendTestNotificationV1(ctx, &api.SendTestNotificationRequest{ClientId: clientID, SensorId: sensorID})
+	if err != nil {
+		return TestNotification{}, errors.Wrap(err, "client.SendTestNotificationV1")
+	}
+
+	return TestNotification{
+		RequestID:         res.GetRequestId(),
+		ChatID:            res.GetChatId(),
+		MessageID:         res.GetMessageId(),
+		LocationMessageID: res.GetLocationMessageId(),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Delivery is an alert delivered to a client chat.
+type Delivery struct {
+	RequestID         string
+	ClientID          string
+	ChatID            int64
+	BotID             string
+	MessageID         int64
+	LocationMessageID int64
+	Text              string
+	Status            string // Status - sent, updated or retracted
+	SentAt            time.Time
+	// AudioMessageID, SpectrogramMessageID - the replies with the recording of an audio alert and its spectrogram
+	AudioMessageID       int64
+	SpectrogramMessageID int64
+	PhotoMessageID       int64 // PhotoMessageID - the reply with the photo of an alert that referenced one
+}
+
+// DeliveryFilter selects deliveries, zero fields match everything.
+type DeliveryFilter struct {
+	RequestID string
+	ClientID  string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+// ListDeliveries returns the newest deliveries matching the filter.
+func (t TGNotificationServiceClient) ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	req := &api.ListDeliveriesRequest{
+		RequestId: filter.RequestID,
+		ClientId:  filter.ClientID,
+		Limit:     int32(filter.Limit),
+	}
+	if !filter.Since.IsZero() {
+		req.Since = timestamppb.New(filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		req.Until = timestamppb.New(filter.Until)
+	}
+
+	res, err := t.deliveries.ListDeliveriesV1(ctx, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "deliveries.ListDeliveriesV1")
+	}
+
+	deliveries := make([]Delivery, 0, len(res.GetDeliveries()))
+	for _, delivery := range res.GetDeliveries() {
+		deliveries = append(deliveries, Delivery{
+			RequestID:            delivery.GetRequestId(),
+			ClientID:             delivery.GetClientId(),
+			ChatID:               delivery.GetChatId(),
+			BotID:                delivery.GetBotId(),
+			MessageID:            delivery.GetMessageId(),
+			LocationMessageID:    delivery.GetLocationMessageId(),
+			Text:                 delivery.GetText(),
+			Status:               delivery.GetStatus(),
+			SentAt:               delivery.GetSentAt().AsTime(),
+			AudioMessageID:       delivery.GetAudioMessageId(),
+			SpectrogramMessageID: delivery.GetSpectrogramMessageId(),
+			PhotoMessageID:       delivery.GetPhotoMessageId(),
+		})
+	}
+
+	return deliveries, nil
+}
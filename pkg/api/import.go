@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"io"
+
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Client is a registration of a client chat, an empty BotID means the default bot.
+type Client struct {
+	ClientID string
+	ChatID   int64
+	BotID    string
+	Region   string
+	Tags     []string
+}
+
+func clientToProto(client Client) *api.Client {
+	return &api.Client{
+		ClientId: client.ClientID,
+		ChatId:   client.ChatID,
+		BotId:    client.BotID,
+		Region:   client.Region,
+		Tags:     client.Tags,
+	}
+}
+
+func clientFromProto(client *api.Client) Client {
+	return Client{
+		ClientID: client.GetClientId(),
+		ChatID:   client.GetChatId(),
+		BotID:    client.GetBotId(),
+		Region:   client.GetRegion(),
+		Tags:     client.GetTags(),
+	}
+}
+
+type ImportRowResult struct {
+	Line     int
+	ClientID string
+	Status   string // Status - created, updated, unchanged or invalid
+	Error    string
+}
+
+type ImportReport struct {
+	DryRun    bool
+	Created   int
+	Updated   int
+	Unchanged int
+	Invalid   int
+	Rows      []ImportRowResult
+}
+
+// ImportClients creates or updates the clients in one stream, with dryRun the service only validates them.
+func (t TGNotificationServiceClient) ImportClients(ctx context.Context, clients []Client, dryRun bool) (ImportReport, error) {
+	stream, err := t.client.ImportClientsV1(ctx)
+	if err != nil {
+		return ImportReport{}, errors.Wrap(err, "client.ImportClientsV1")
+	}
+
+	for _, client := range clients {
+		err = stream.Send(&api.ImportClientRequest{
+			Client: clientToProto(client),
+			DryRun: dryRun,
+		})
+
+		// io.EOF means the server has stopped the stream, the reason comes with CloseAndRecv.
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return ImportReport{}, errors.Wrap(err, "stream.Send")
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return ImportReport{}, errors.Wrap(err, "stream.CloseAndRecv")
+	}
+
+	report := ImportReport{
+		DryRun:    res.GetDryRun(),
+		Created:   int(res.GetCreated()),
+		Updated:   int(res.GetUpdated()),
+		Unchanged: int(res.GetUnchanged()),
+		Invalid:   int(res.GetInvalid()),
+		Rows:      make([]ImportRowResult, 0, len(res.GetRows())),
+	}
+
+	for _, row := range res.GetRows() {
+		report.Rows = append(report.Rows, ImportRowResult{
+			Line:     int(row.GetLine()),
+			ClientID: row.GetClientId(),
+			Status:   row.GetStatus(),
+			Error:    row.GetError(),
+		})
+	}
+
+	return report, nil
+}
+
+// ExportClients calls fn for every registered client, it stops at the first error of fn.
+func (t TGNotificationServiceClient) ExportClients(ctx context.Context, fn func(Client) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := t.client.ExportClientsV1(ctx, &emptypb.Empty{})
+	if err != nil {
+		return errors.Wrap(err, "client.ExportClientsV1")
+	}
+
+	for {
+		res, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "stream.Recv")
+		}
+
+		if err = fn(clientFromProto(res)); err != nil {
+			return err
+		}
+	}
+}
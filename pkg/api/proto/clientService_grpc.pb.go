@@ -24,7 +24,11 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ClientServiceClient interface {
 	CreateClientV1(ctx context.Context, in *CreateClientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetClientV1(ctx context.Context, in *GetClientRequest, opts ...grpc.CallOption) (*Client, error)
 	DeleteClientV1(ctx context.Context, in *DeleteClientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ImportClientsV1(ctx context.Context, opts ...grpc.CallOption) (ClientService_ImportClientsV1Client, error)
+	ExportClientsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (ClientService_ExportClientsV1Client, error)
+	SendTestNotificationV1(ctx context.Context, in *SendTestNotificationRequest, opts ...grpc.CallOption) (*SendTestNotificationResponse, error)
 }
 
 type clientServiceClient struct {
@@ -44,6 +48,15 @@ func (c *clientServiceClient) CreateClientV1(ctx context.Context, in *CreateClie
 	return out, nil
 }
 
+func (c *clientServiceClient) GetClientV1(ctx context.Context, in *GetClientRequest, opts ...grpc.CallOption) (*Client, error) {
+	out := new(Client)
+	err := c.cc.Invoke(ctx, "/api.ClientService/GetClientV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *clientServiceClient) DeleteClientV1(ctx context.Context, in *DeleteClientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/api.ClientService/DeleteClientV1", in, out, opts...)
@@ -53,12 +66,91 @@ func (c *clientServiceClient) DeleteClientV1(ctx context.Context, in *DeleteClie
 	return out, nil
 }
 
+func (c *clientServiceClient) ImportClientsV1(ctx context.Context, opts ...grpc.CallOption) (ClientService_ImportClientsV1Client, error) {
+	stream, err := c.cc.NewStream(ctx, &ClientService_ServiceDesc.Streams[0], "/api.ClientService/ImportClientsV1", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &clientServiceImportClientsV1Client{stream}
+	return x, nil
+}
+
+type ClientService_ImportClientsV1Client interface {
+	Send(*ImportClientRequest) error
+	CloseAndRecv() (*ImportClientsResponse, error)
+	grpc.ClientStream
+}
+
+type clientServiceImportClientsV1Client struct {
+	grpc.ClientStream
+}
+
+func (x *clientServiceImportClientsV1Client) Send(m *ImportClientRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *clientServiceImportClientsV1Client) CloseAndRecv() (*ImportClientsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportClientsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *clientServiceClient) ExportClientsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (ClientService_ExportClientsV1Client, error) {
+	stream, err := c.cc.NewStream(ctx, &ClientService_ServiceDesc.Streams[1], "/api.ClientService/ExportClientsV1", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &clientServiceExportClientsV1Client{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ClientService_ExportClientsV1Client interface {
+	Recv() (*Client, error)
+	grpc.ClientStream
+}
+
+type clientServiceExportClientsV1Client struct {
+	grpc.ClientStream
+}
+
+func (x *clientServiceExportClientsV1Client) Recv() (*Client, error) {
+	m := new(Client)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *clientServiceClient) SendTestNotificationV1(ctx context.Context, in *SendTestNotificationRequest, opts ...grpc.CallOption) (*SendTestNotificationResponse, error) {
+	out := new(SendTestNotificationResponse)
+	err := c.cc.Invoke(ctx, "/api.ClientService/SendTestNotificationV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ClientServiceServer is the server API for ClientService service.
 // All implementations must embed UnimplementedClientServiceServer
 // for forward compatibility
 type ClientServiceServer interface {
 	CreateClientV1(context.Context, *CreateClientRequest) (*emptypb.Empty, error)
+	GetClientV1(context.Context, *GetClientRequest) (*Client, error)
 	DeleteClientV1(context.Context, *DeleteClientRequest) (*emptypb.Empty, error)
+	ImportClientsV1(ClientService_ImportClientsV1Server) error
+	ExportClientsV1(*emptypb.Empty, ClientService_ExportClientsV1Server) error
+	SendTestNotificationV1(context.Context, *SendTestNotificationRequest) (*SendTestNotificationResponse, error)
 	mustEmbedUnimplementedClientServiceServer()
 }
 
@@ -69,9 +161,21 @@ type UnimplementedClientServiceServer struct {
 func (UnimplementedClientServiceServer) CreateClientV1(context.Context, *CreateClientRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateClientV1 not implemented")
 }
+func (UnimplementedClientServiceServer) GetClientV1(context.Context, *GetClientRequest) (*Client, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClientV1 not implemented")
+}
 func (UnimplementedClientServiceServer) DeleteClientV1(context.Context, *DeleteClientRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteClientV1 not implemented")
 }
+func (UnimplementedClientServiceServer) ImportClientsV1(ClientService_ImportClientsV1Server) error {
+	return status.Errorf(codes.Unimplemented, "method ImportClientsV1 not implemented")
+}
+func (UnimplementedClientServiceServer) ExportClientsV1(*emptypb.Empty, ClientService_ExportClientsV1Server) error {
+	return status.Errorf(codes.Unimplemented, "method ExportClientsV1 not implemented")
+}
+func (UnimplementedClientServiceServer) SendTestNotificationV1(context.Context, *SendTestNotificationRequest) (*SendTestNotificationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTestNotificationV1 not implemented")
+}
 func (UnimplementedClientServiceServer) mustEmbedUnimplementedClientServiceServer() {}
 
 // UnsafeClientServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -103,6 +207,24 @@ func _ClientService_CreateClientV1_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClientService_GetClientV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientServiceServer).GetClientV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.ClientService/GetClientV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientServiceServer).GetClientV1(ctx, req.(*GetClientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ClientService_DeleteClientV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteClientRequest)
 	if err := dec(in); err != nil {
@@ -121,6 +243,71 @@ func _ClientService_DeleteClientV1_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ClientService_ImportClientsV1_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ClientServiceServer).ImportClientsV1(&clientServiceImportClientsV1Server{stream})
+}
+
+type ClientService_ImportClientsV1Server interface {
+	SendAndClose(*ImportClientsResponse) error
+	Recv() (*ImportClientRequest, error)
+	grpc.ServerStream
+}
+
+type clientServiceImportClientsV1Server struct {
+	grpc.ServerStream
+}
+
+func (x *clientServiceImportClientsV1Server) SendAndClose(m *ImportClientsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *clientServiceImportClientsV1Server) Recv() (*ImportClientRequest, error) {
+	m := new(ImportClientRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ClientService_ExportClientsV1_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClientServiceServer).ExportClientsV1(m, &clientServiceExportClientsV1Server{stream})
+}
+
+type ClientService_ExportClientsV1Server interface {
+	Send(*Client) error
+	grpc.ServerStream
+}
+
+type clientServiceExportClientsV1Server struct {
+	grpc.ServerStream
+}
+
+func (x *clientServiceExportClientsV1Server) Send(m *Client) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ClientService_SendTestNotificationV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTestNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientServiceServer).SendTestNotificationV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.ClientService/SendTestNotificationV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientServiceServer).SendTestNotificationV1(ctx, req.(*SendTestNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ClientService_ServiceDesc is the grpc.ServiceDesc for ClientService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -132,10 +319,625 @@ var ClientService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateClientV1",
 			Handler:    _ClientService_CreateClientV1_Handler,
 		},
+		{
+			MethodName: "GetClientV1",
+			Handler:    _ClientService_GetClientV1_Handler,
+		},
 		{
 			MethodName: "DeleteClientV1",
 			Handler:    _ClientService_DeleteClientV1_Handler,
 		},
+		{
+			MethodName: "SendTestNotificationV1",
+			Handler:    _ClientService_SendTestNotificationV1_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportClientsV1",
+			Handler:       _ClientService_ImportClientsV1_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportClientsV1",
+			Handler:       _ClientService_ExportClientsV1_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/clientService.proto",
+}
+
+// SensorServiceClient is the client API for SensorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SensorServiceClient interface {
+	CreateSensorV1(ctx context.Context, in *Sensor, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetSensorV1(ctx context.Context, in *GetSensorRequest, opts ...grpc.CallOption) (*Sensor, error)
+	ListSensorsV1(ctx context.Context, in *ListSensorsRequest, opts ...grpc.CallOption) (*ListSensorsResponse, error)
+	UpdateSensorV1(ctx context.Context, in *Sensor, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteSensorV1(ctx context.Context, in *DeleteSensorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type sensorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSensorServiceClient(cc grpc.ClientConnInterface) SensorServiceClient {
+	return &sensorServiceClient{cc}
+}
+
+func (c *sensorServiceClient) CreateSensorV1(ctx context.Context, in *Sensor, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/api.SensorService/CreateSensorV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) GetSensorV1(ctx context.Context, in *GetSensorRequest, opts ...grpc.CallOption) (*Sensor, error) {
+	out := new(Sensor)
+	err := c.cc.Invoke(ctx, "/api.SensorService/GetSensorV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) ListSensorsV1(ctx context.Context, in *ListSensorsRequest, opts ...grpc.CallOption) (*ListSensorsResponse, error) {
+	out := new(ListSensorsResponse)
+	err := c.cc.Invoke(ctx, "/api.SensorService/ListSensorsV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) UpdateSensorV1(ctx context.Context, in *Sensor, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/api.SensorService/UpdateSensorV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sensorServiceClient) DeleteSensorV1(ctx context.Context, in *DeleteSensorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/api.SensorService/DeleteSensorV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SensorServiceServer is the server API for SensorService service.
+// All implementations must embed UnimplementedSensorServiceServer
+// for forward compatibility
+type SensorServiceServer interface {
+	CreateSensorV1(context.Context, *Sensor) (*emptypb.Empty, error)
+	GetSensorV1(context.Context, *GetSensorRequest) (*Sensor, error)
+	ListSensorsV1(context.Context, *ListSensorsRequest) (*ListSensorsResponse, error)
+	UpdateSensorV1(context.Context, *Sensor) (*emptypb.Empty, error)
+	DeleteSensorV1(context.Context, *DeleteSensorRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSensorServiceServer()
+}
+
+// UnimplementedSensorServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSensorServiceServer struct {
+}
+
+func (UnimplementedSensorServiceServer) CreateSensorV1(context.Context, *Sensor) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSensorV1 not implemented")
+}
+func (UnimplementedSensorServiceServer) GetSensorV1(context.Context, *GetSensorRequest) (*Sensor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSensorV1 not implemented")
+}
+func (UnimplementedSensorServiceServer) ListSensorsV1(context.Context, *ListSensorsRequest) (*ListSensorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSensorsV1 not implemented")
+}
+func (UnimplementedSensorServiceServer) UpdateSensorV1(context.Context, *Sensor) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSensorV1 not implemented")
+}
+func (UnimplementedSensorServiceServer) DeleteSensorV1(context.Context, *DeleteSensorRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSensorV1 not implemented")
+}
+func (UnimplementedSensorServiceServer) mustEmbedUnimplementedSensorServiceServer() {}
+
+// UnsafeSensorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SensorServiceServer will
+// result in compilation errors.
+type UnsafeSensorServiceServer interface {
+	mustEmbedUnimplementedSensorServiceServer()
+}
+
+func RegisterSensorServiceServer(s grpc.ServiceRegistrar, srv SensorServiceServer) {
+	s.RegisterService(&SensorService_ServiceDesc, srv)
+}
+
+func _SensorService_CreateSensorV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Sensor)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).CreateSensorV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.SensorService/CreateSensorV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).CreateSensorV1(ctx, req.(*Sensor))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_GetSensorV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSensorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).GetSensorV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.SensorService/GetSensorV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).GetSensorV1(ctx, req.(*GetSensorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_ListSensorsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSensorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).ListSensorsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.SensorService/ListSensorsV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).ListSensorsV1(ctx, req.(*ListSensorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_UpdateSensorV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Sensor)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).UpdateSensorV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.SensorService/UpdateSensorV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).UpdateSensorV1(ctx, req.(*Sensor))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SensorService_DeleteSensorV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSensorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SensorServiceServer).DeleteSensorV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.SensorService/DeleteSensorV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SensorServiceServer).DeleteSensorV1(ctx, req.(*DeleteSensorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SensorService_ServiceDesc is the grpc.ServiceDesc for SensorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SensorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.SensorService",
+	HandlerType: (*SensorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSensorV1",
+			Handler:    _SensorService_CreateSensorV1_Handler,
+		},
+		{
+			MethodName: "GetSensorV1",
+			Handler:    _SensorService_GetSensorV1_Handler,
+		},
+		{
+			MethodName: "ListSensorsV1",
+			Handler:    _SensorService_ListSensorsV1_Handler,
+		},
+		{
+			MethodName: "UpdateSensorV1",
+			Handler:    _SensorService_UpdateSensorV1_Handler,
+		},
+		{
+			MethodName: "DeleteSensorV1",
+			Handler:    _SensorService_DeleteSensorV1_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/proto/clientService.proto",
+}
+
+// DeliveryServiceClient is the client API for DeliveryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeliveryServiceClient interface {
+	ListDeliveriesV1(ctx context.Context, in *ListDeliveriesRequest, opts ...grpc.CallOption) (*ListDeliveriesResponse, error)
+}
+
+type deliveryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeliveryServiceClient(cc grpc.ClientConnInterface) DeliveryServiceClient {
+	return &deliveryServiceClient{cc}
+}
+
+func (c *deliveryServiceClient) ListDeliveriesV1(ctx context.Context, in *ListDeliveriesRequest, opts ...grpc.CallOption) (*ListDeliveriesResponse, error) {
+	out := new(ListDeliveriesResponse)
+	err := c.cc.Invoke(ctx, "/api.DeliveryService/ListDeliveriesV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeliveryServiceServer is the server API for DeliveryService service.
+// All implementations must embed UnimplementedDeliveryServiceServer
+// for forward compatibility
+type DeliveryServiceServer interface {
+	ListDeliveriesV1(context.Context, *ListDeliveriesRequest) (*ListDeliveriesResponse, error)
+	mustEmbedUnimplementedDeliveryServiceServer()
+}
+
+// UnimplementedDeliveryServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDeliveryServiceServer struct {
+}
+
+func (UnimplementedDeliveryServiceServer) ListDeliveriesV1(context.Context, *ListDeliveriesRequest) (*ListDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeliveriesV1 not implemented")
+}
+func (UnimplementedDeliveryServiceServer) mustEmbedUnimplementedDeliveryServiceServer() {}
+
+// UnsafeDeliveryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeliveryServiceServer will
+// result in compilation errors.
+type UnsafeDeliveryServiceServer interface {
+	mustEmbedUnimplementedDeliveryServiceServer()
+}
+
+func RegisterDeliveryServiceServer(s grpc.ServiceRegistrar, srv DeliveryServiceServer) {
+	s.RegisterService(&DeliveryService_ServiceDesc, srv)
+}
+
+func _DeliveryService_ListDeliveriesV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeliveryServiceServer).ListDeliveriesV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.DeliveryService/ListDeliveriesV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeliveryServiceServer).ListDeliveriesV1(ctx, req.(*ListDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DeliveryService_ServiceDesc is the grpc.ServiceDesc for DeliveryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeliveryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.DeliveryService",
+	HandlerType: (*DeliveryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDeliveriesV1",
+			Handler:    _DeliveryService_ListDeliveriesV1_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/proto/clientService.proto",
+}
+
+// StatsServiceClient is the client API for StatsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StatsServiceClient interface {
+	GetStatsV1(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+}
+
+type statsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStatsServiceClient(cc grpc.ClientConnInterface) StatsServiceClient {
+	return &statsServiceClient{cc}
+}
+
+func (c *statsServiceClient) GetStatsV1(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, "/api.StatsService/GetStatsV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatsServiceServer is the server API for StatsService service.
+// All implementations must embed UnimplementedStatsServiceServer
+// for forward compatibility
+type StatsServiceServer interface {
+	GetStatsV1(context.Context, *GetStatsRequest) (*Stats, error)
+	mustEmbedUnimplementedStatsServiceServer()
+}
+
+// UnimplementedStatsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedStatsServiceServer struct {
+}
+
+func (UnimplementedStatsServiceServer) GetStatsV1(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatsV1 not implemented")
+}
+func (UnimplementedStatsServiceServer) mustEmbedUnimplementedStatsServiceServer() {}
+
+// UnsafeStatsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StatsServiceServer will
+// result in compilation errors.
+type UnsafeStatsServiceServer interface {
+	mustEmbedUnimplementedStatsServiceServer()
+}
+
+func RegisterStatsServiceServer(s grpc.ServiceRegistrar, srv StatsServiceServer) {
+	s.RegisterService(&StatsService_ServiceDesc, srv)
+}
+
+func _StatsService_GetStatsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).GetStatsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.StatsService/GetStatsV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).GetStatsV1(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// StatsService_ServiceDesc is the grpc.ServiceDesc for StatsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StatsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.StatsService",
+	HandlerType: (*StatsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatsV1",
+			Handler:    _StatsService_GetStatsV1_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/proto/clientService.proto",
+}
+
+// BroadcastServiceClient is the client API for BroadcastService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BroadcastServiceClient interface {
+	CreateBroadcastV1(ctx context.Context, in *CreateBroadcastRequest, opts ...grpc.CallOption) (*Broadcast, error)
+	GetBroadcastV1(ctx context.Context, in *GetBroadcastRequest, opts ...grpc.CallOption) (*Broadcast, error)
+	ListBroadcastsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListBroadcastsResponse, error)
+	CancelBroadcastV1(ctx context.Context, in *CancelBroadcastRequest, opts ...grpc.CallOption) (*Broadcast, error)
+}
+
+type broadcastServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBroadcastServiceClient(cc grpc.ClientConnInterface) BroadcastServiceClient {
+	return &broadcastServiceClient{cc}
+}
+
+func (c *broadcastServiceClient) CreateBroadcastV1(ctx context.Context, in *CreateBroadcastRequest, opts ...grpc.CallOption) (*Broadcast, error) {
+	out := new(Broadcast)
+	err := c.cc.Invoke(ctx, "/api.BroadcastService/CreateBroadcastV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *broadcastServiceClient) GetBroadcastV1(ctx context.Context, in *GetBroadcastRequest, opts ...grpc.CallOption) (*Broadcast, error) {
+	out := new(Broadcast)
+	err := c.cc.Invoke(ctx, "/api.BroadcastService/GetBroadcastV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *broadcastServiceClient) ListBroadcastsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListBroadcastsResponse, error) {
+	out := new(ListBroadcastsResponse)
+	err := c.cc.Invoke(ctx, "/api.BroadcastService/ListBroadcastsV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *broadcastServiceClient) CancelBroadcastV1(ctx context.Context, in *CancelBroadcastRequest, opts ...grpc.CallOption) (*Broadcast, error) {
+	out := new(Broadcast)
+	err := c.cc.Invoke(ctx, "/api.BroadcastService/CancelBroadcastV1", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BroadcastServiceServer is the server API for BroadcastService service.
+// All implementations must embed UnimplementedBroadcastServiceServer
+// for forward compatibility
+type BroadcastServiceServer interface {
+	CreateBroadcastV1(context.Context, *CreateBroadcastRequest) (*Broadcast, error)
+	GetBroadcastV1(context.Context, *GetBroadcastRequest) (*Broadcast, error)
+	ListBroadcastsV1(context.Context, *emptypb.Empty) (*ListBroadcastsResponse, error)
+	CancelBroadcastV1(context.Context, *CancelBroadcastRequest) (*Broadcast, error)
+	mustEmbedUnimplementedBroadcastServiceServer()
+}
+
+// UnimplementedBroadcastServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBroadcastServiceServer struct {
+}
+
+func (UnimplementedBroadcastServiceServer) CreateBroadcastV1(context.Context, *CreateBroadcastRequest) (*Broadcast, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBroadcastV1 not implemented")
+}
+func (UnimplementedBroadcastServiceServer) GetBroadcastV1(context.Context, *GetBroadcastRequest) (*Broadcast, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBroadcastV1 not implemented")
+}
+func (UnimplementedBroadcastServiceServer) ListBroadcastsV1(context.Context, *emptypb.Empty) (*ListBroadcastsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBroadcastsV1 not implemented")
+}
+func (UnimplementedBroadcastServiceServer) CancelBroadcastV1(context.Context, *CancelBroadcastRequest) (*Broadcast, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelBroadcastV1 not implemented")
+}
+func (UnimplementedBroadcastServiceServer) mustEmbedUnimplementedBroadcastServiceServer() {}
+
+// UnsafeBroadcastServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BroadcastServiceServer will
+// result in compilation errors.
+type UnsafeBroadcastServiceServer interface {
+	mustEmbedUnimplementedBroadcastServiceServer()
+}
+
+func RegisterBroadcastServiceServer(s grpc.ServiceRegistrar, srv BroadcastServiceServer) {
+	s.RegisterService(&BroadcastService_ServiceDesc, srv)
+}
+
+func _BroadcastService_CreateBroadcastV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBroadcastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BroadcastServiceServer).CreateBroadcastV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BroadcastService/CreateBroadcastV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BroadcastServiceServer).CreateBroadcastV1(ctx, req.(*CreateBroadcastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BroadcastService_GetBroadcastV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBroadcastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BroadcastServiceServer).GetBroadcastV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BroadcastService/GetBroadcastV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BroadcastServiceServer).GetBroadcastV1(ctx, req.(*GetBroadcastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BroadcastService_ListBroadcastsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BroadcastServiceServer).ListBroadcastsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BroadcastService/ListBroadcastsV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BroadcastServiceServer).ListBroadcastsV1(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BroadcastService_CancelBroadcastV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelBroadcastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BroadcastServiceServer).CancelBroadcastV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BroadcastService/CancelBroadcastV1",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BroadcastServiceServer).CancelBroadcastV1(ctx, req.(*CancelBroadcastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BroadcastService_ServiceDesc is the grpc.ServiceDesc for BroadcastService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BroadcastService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "api.BroadcastService",
+	HandlerType: (*BroadcastServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateBroadcastV1",
+			Handler:    _BroadcastService_CreateBroadcastV1_Handler,
+		},
+		{
+			MethodName: "GetBroadcastV1",
+			Handler:    _BroadcastService_GetBroadcastV1_Handler,
+		},
+		{
+			MethodName: "ListBroadcastsV1",
+			Handler:    _BroadcastService_ListBroadcastsV1_Handler,
+		},
+		{
+			MethodName: "CancelBroadcastV1",
+			Handler:    _BroadcastService_CancelBroadcastV1_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/clientService.proto",
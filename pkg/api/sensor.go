@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+
+	api "github.com/Imm0bilize/gunshot-telegram-notifier/pkg/api/proto"
+	"github.com/pkg/errors"
+)
+
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Sensor is a gunshot detector, its alerts are delivered to every client in ClientIDs.
+type Sensor struct {
+	ID        string
+	Name      string
+	Location  *Location
+	Address   string
+	ClientIDs []string
+	Enabled   bool
+	Tags      []string
+}
+
+func (s Sensor) toProto() *api.Sensor {
+	res := &api.Sensor{
+		Id:        s.ID,
+		Name:      s.Name,
+		Address:   s.Address,
+		ClientIds: s.ClientIDs,
+		Enabled:   s.Enabled,
+		Tags:      s.Tags,
+	}
+
+	if s.Location != nil {
+		res.Location = &api.Location{Latitude: s.Location.Latitude, Longitude: s.Location.Longitude}
+	}
+
+	return res
+}
+
+func sensorFromProto(s *api.Sensor) Sensor {
+	res := Sensor{
+		ID:        s.GetId(),
+		Name:      s.GetName(),
+		Address:   s.GetAddress(),
+		ClientIDs: s.GetClientIds(),
+		Enabled:   s.GetEnabled(),
+		Tags:      s.GetTags(),
+	}
+
+	if location := s.GetLocation(); location != nil {
+		res.Location = &Location{Latitude: location.GetLatitude(), Longitude: location.GetLongitude()}
+	}
+
+	return res
+}
+
+func (t TGNotificationServiceClient) CreateSensor(ctx context.Context, sensor Sensor) error {
+	if _, err := t.sensors.CreateSensorV1(ctx, sensor.toProto()); err != nil {
+		return errors.Wrap(err, "sensors.CreateSensorV1")
+	}
+
+	return nil
+}
+
+func (t TGNotificationServiceClient) GetSensor(ctx context.Context, sensorID string) (Sensor, error) {
+	res, err := t.sensors.GetSensorV1(ctx, &api.GetSensorRequest{Id: sensorID})
+	if err != nil {
+		return Sensor{}, errors.Wrap(err, "sensors.GetSensorV1")
+	}
+
+	return sensorFromProto(res), nil
+}
+
+// ListSensors returns the sensors of the client, all sensors for an empty clientID.
+func (t TGNotificationServiceClient) ListSensors(ctx context.Context, clientID string) ([]Sensor, error) {
+	res, err := t.sensors.ListSensorsV1(ctx, &api.ListSensorsRequest{ClientId: clientID})
+	if err != nil {
+		return nil, errors.Wrap(err, "sensors.ListSensorsV1")
+	}
+
+	sensors := make([]Sensor, 0, len(res.GetSensors()))
+	for _, sensor := range res.GetSensors() {
+		sensors = append(sensors, sensorFromProto(sensor))
+	}
+
+	return sensors, nil
+}
+
+func (t TGNotificationServiceClient) UpdateSensor(ctx context.Context, sensor Sensor) error {
+	if _, err := t.sensors.UpdateSensorV1(ctx, sensor.toProto()); err != nil {
+		return errors.Wrap(err, "sensors.UpdateSensorV1")
+	}
+
+	return nil
+}
+
+func (t TGNotificationServiceClient) DeleteSensor(ctx context.Context, sensorID string) error {
+	if _, err := t.sensors.DeleteSensorV1(ctx, &api.DeleteSensorRequest{Id: sensorID}); err != nil {
+		return errors.Wrap(err, "sensors.DeleteSensorV1")
+	}
+
+	return nil
+}